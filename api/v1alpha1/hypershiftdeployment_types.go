@@ -37,6 +37,36 @@ const (
 	RemovingReason             = "Removing"
 	AsExpectedReason           = "AsExpected"
 	NodePoolProvision          = "NodePoolsProvisioned"
+	CircuitOpenReason          = "CircuitOpen"
+	UnexpectedSecretTypeReason = "UnexpectedSecretType"
+	MaintenanceWindowReason    = "OutsideMaintenanceWindow"
+	SecretTooLargeReason       = "SecretTooLarge"
+	PermissionDeniedReason     = "PermissionDenied"
+	FeedbackStaleReason        = "NoRecentFeedback"
+	ImageRegistryManagedReason = "Managed"
+	ImageRegistryRemovedReason = "Removed"
+	WebhookFailedReason        = "WebhookFailed"
+	NoMatchingPayloadReason    = "NoMatchingPayload"
+	UpdateConflictReason       = "UpdateConflict"
+	InsufficientNodesReason    = "InsufficientNodes"
+	BudgetExhaustedReason      = "BudgetExhausted"
+	SchemaViolationReason      = "SchemaViolation"
+	MissingFieldsReason        = "MissingRequiredFields"
+	ApplyFailedReason          = "ApplyFailed"
+	DeprovisionTimeoutReason   = "DeprovisionTimeout"
+	FeedbackReceivedReason     = "FeedbackReceived"
+	InfraIDMismatchReason      = "InfraIDMismatch"
+	WorkAPINotInstalledReason  = "NotInstalled"
+	SecretNotFoundReason       = "SecretNotFound"
+	PayloadTooLargeReason      = "PayloadTooLarge"
+	DryRunReason               = "DryRun"
+	ConfirmationRequiredReason = "ConfirmationRequired"
+	PreExistingResourcesReason = "PreExistingResources"
+	UnknownPlatformReason      = "UnknownPlatform"
+	ClusterMismatchReason      = "NodePoolClusterMismatch"
+	ManifestsNotReadyReason    = "ManifestsNotReady"
+	AnnotationPendingReason    = "AnnotationPending"
+	ForeignOwnerReason         = "OwnedByAnotherHypershiftDeployment"
 
 	// PlatformConfigured indicates (if status is true) that the
 	// platform configuration specified for the platform provider has been applied
@@ -71,6 +101,128 @@ const (
 	// WorkConfigured indicates the status of applying the ManifestWork
 	WorkConfigured ConditionType = "ManifestWorkConfigured"
 
+	// SubnetZoneConflict indicates (if status is true) that the same subnet is mapped to
+	// different availability zones across the HostedCluster and NodePools
+	SubnetZoneConflict ConditionType = "SubnetZoneConflict"
+
+	// TargetClusterCircuitOpen indicates (if status is true) that the HostingCluster repeatedly failed
+	// to apply the ManifestWork and reconciling is paused until the cooldown expires
+	TargetClusterCircuitOpen ConditionType = "TargetClusterCircuitOpen"
+
+	// VersionSkewViolation indicates (if status is true) that a NodePool release is out of the
+	// supported version skew with the HostedCluster release
+	VersionSkewViolation ConditionType = "VersionSkewViolation"
+
+	// PullSecretTypeMismatch is a warning (if status is true) that the referenced pull secret is not of
+	// type kubernetes.io/dockerconfigjson, the pull secret is still propagated
+	PullSecretTypeMismatch ConditionType = "PullSecretTypeMismatch"
+
+	// DeferredUntil indicates (if status is true) that disruptive changes are held back until the
+	// next maintenance window, the message contains the start of the window
+	DeferredUntil ConditionType = "DeferredUntil"
+
+	// MachineCIDROutOfRange indicates (if status is true) that a NodePool machineCIDR is not within the
+	// HostedCluster machine network or overlaps the machineCIDR of another NodePool
+	MachineCIDROutOfRange ConditionType = "MachineCIDROutOfRange"
+
+	// PropagatedSecretTooLarge is a warning (if status is true) that a secret propagated to the HostingCluster
+	// is large enough to push the ManifestWork over its size limit, the secret is still propagated
+	PropagatedSecretTooLarge ConditionType = "PropagatedSecretTooLarge"
+
+	// InsufficientPermissions indicates (if status is true) that the controller is not allowed to write
+	// the ManifestWork in the HostingCluster namespace, the message contains the denied verb and resource
+	InsufficientPermissions ConditionType = "InsufficientPermissions"
+
+	// FeedbackStale is a warning (if status is true) that the work agent has not reported on the ManifestWork
+	// within the staleness window while a change is waiting to be applied, the status may be outdated
+	FeedbackStale ConditionType = "FeedbackStale"
+
+	// ImageRegistryDisabled indicates (if status is true) that the internal image registry of the hosted cluster
+	// is disabled, the reason holds the management state of the image registry operator
+	ImageRegistryDisabled ConditionType = "ImageRegistryDisabled"
+
+	// PhaseNotificationFailed is a warning (if status is true) that the phase transition webhook kept failing,
+	// the message contains the last error, the notification is still retried
+	PhaseNotificationFailed ConditionType = "PhaseNotificationFailed"
+
+	// DeleteOptionIneffective is a warning (if status is true) that the delete option of the ManifestWork does
+	// not act on its payload, ie an orphaning rule that matches no payload object
+	DeleteOptionIneffective ConditionType = "DeleteOptionIneffective"
+
+	// MissingPlatformCredentials indicates (if status is true) that credentials the platform of the HostedCluster
+	// needs are not on the hub, the message lists them, the ManifestWork is not built until they are found
+	MissingPlatformCredentials ConditionType = "MissingPlatformCredentials"
+
+	// ManifestWorkConflict indicates (if status is true) that the ManifestWork kept being changed by another
+	// writer while it was updated, and the update still conflicted once the retries ran out
+	ManifestWorkConflict ConditionType = "ManifestWorkConflict"
+
+	// HighAvailabilityUnmet is a warning (if status is true) that the HostedCluster asks for a HighlyAvailable
+	// availability policy but its NodePools can not spread the replicas over enough nodes
+	HighAvailabilityUnmet ConditionType = "HighAvailabilityUnmet"
+
+	// ReconcileThrottled indicates (if status is true) that the reconcile budget of the controller is
+	// exhausted and the ManifestWork is not written until the budget refills
+	ReconcileThrottled ConditionType = "ReconcileThrottled"
+
+	// SpecInvalid indicates (if status is true) that the spec does not match the schema of the CRD the controller
+	// is built with, the message lists the field errors and nothing is scaffolded until they are fixed
+	SpecInvalid ConditionType = "SpecInvalid"
+
+	// ValidConfiguration indicates (if status is true) that the HostedClusterSpec has the fields its platform
+	// requires, when false the message lists the missing fields and no ManifestWork is written
+	ValidConfiguration ConditionType = "ValidConfiguration"
+
+	// NodePoolsRemoved indicates (if status is true) that NodePools removed from the spec are being deleted from
+	// the HostingCluster, the message lists them until the work agent applied their removal
+	NodePoolsRemoved ConditionType = "NodePoolsRemoved"
+
+	// ManifestApplied indicates (if status is true) that the work agent applied every manifest of the ManifestWork,
+	// when false the message lists the kind and name of each failing manifest with the error of the work agent
+	ManifestApplied ConditionType = "ManifestApplied"
+
+	// DeprovisionStuck indicates (if status is true) that the HostedCluster and NodePools of a deleted
+	// HypershiftDeployment are still being cleaned up from the HostingCluster past the expected time
+	DeprovisionStuck ConditionType = "DeprovisionStuck"
+
+	// WorkAPIUnavailable indicates (if status is true) that the hub does not serve the ManifestWork API, nothing is
+	// applied to the HostingCluster until it is installed and the controller restarted
+	WorkAPIUnavailable ConditionType = "WorkAPIUnavailable"
+
+	// PullSecretMissing indicates (if status is true) that the pull secret of the HostedCluster is not in the
+	// HypershiftDeployment namespace, the ManifestWork is not updated until it is created
+	PullSecretMissing ConditionType = "PullSecretMissing"
+
+	// UnmetDependencies indicates (if status is true) that settings of the HostedClusterSpec need secrets that are
+	// not on the hub or miss the key the HyperShift operator reads, the message lists them, the ManifestWork is not
+	// built until they are met
+	UnmetDependencies ConditionType = "UnmetDependencies"
+
+	// LargeScaleDownBlocked indicates (if status is true) that NodePool replica reductions past the scale down guard
+	// keep their applied replicas until they are confirmed with an annotation, the message lists them
+	LargeScaleDownBlocked ConditionType = "LargeScaleDownBlocked"
+
+	// OrphanedSpokeResources is a warning (if status is true) that the HostedCluster or NodePools of the ManifestWork
+	// were already on the HostingCluster when it was created, ie left over from a previous deployment of the same
+	// name, the message lists them and they should be cleaned up
+	OrphanedSpokeResources ConditionType = "OrphanedSpokeResources"
+
+	// UnsupportedPlatform indicates (if status is true) that the HostedCluster or a NodePool has a platform type
+	// this controller does not know, the ManifestWork is not written until the platform type is fixed
+	UnsupportedPlatform ConditionType = "UnsupportedPlatform"
+
+	// Ready indicates (if status is true) that every manifest of the ManifestWork is applied and available on the
+	// HostingCluster, when false the message lists the manifests that are not
+	Ready ConditionType = "Ready"
+
+	// ConflictingAnnotations is a warning (if status is true) that an annotation of the HypershiftDeployment can not
+	// take effect with its spec, the spec takes precedence and the message lists each annotation left pending
+	ConflictingAnnotations ConditionType = "ConflictingAnnotations"
+
+	// Conflict indicates (if status is true) that the ManifestWork named after the infra-id was created by another
+	// HypershiftDeployment, it is left untouched and the message names its owner
+	Conflict ConditionType = "Conflict"
+
 	InfraOverrideDestroy   = "ORPHAN"
 	InfraConfigureOnly     = "INFRA-ONLY"
 	DeleteHostingNamespace = "DELETE-HOSTING-NAMESPACE"
@@ -82,8 +234,9 @@ const (
 // HypershiftDeploymentSpec defines the desired state of HypershiftDeployment
 type HypershiftDeploymentSpec struct {
 	// Infrastructure instructions and pointers so either ClusterDeployment generates what is needed or
-	// skips it when the user provides the infrastructure values
+	// skips it when the user provides the infrastructure values, it can be inherited from the TemplateRef
 	// +immutable
+	// +optional
 	Infrastructure InfraSpec `json:"infrastructure"`
 
 	// Infrastructure ID, this is used to tag resources in the Cloud Provider, it will be generated
@@ -92,6 +245,12 @@ type HypershiftDeploymentSpec struct {
 	// +optional
 	InfraID string `json:"infra-id,omitempty"`
 
+	// Cluster ID, a UUID set on the HostedCluster in place of the generated one, used to preserve the
+	// identity of a migrated cluster
+	// +immutable
+	// +optional
+	ClusterID string `json:"cluster-id,omitempty"`
+
 	// InfrastructureOverride allows support for special cases
 	//   OverrideDestroy = "ORPHAN"
 	//   InfraConfigureOnly = "INFRA-ONLY"
@@ -108,7 +267,8 @@ type HypershiftDeploymentSpec struct {
 	//HostingCluster only applies to ManifestWork, and specifies which managedCluster's namespace the manifestwork will be applied to.
 	//If not specified, the controller will flag an error condition.
 	//The HostingCluster would be the management cluster of the hostedcluster and nodepool generated
-	//by the hypershiftDeployment
+	//by the hypershiftDeployment, it can be inherited from the TemplateRef
+	// +optional
 	HostingCluster string `json:"hostingCluster"`
 
 	// HostedCluster that will be applied to the ManagementCluster by ACM, if omitted, it will be generated
@@ -134,10 +294,202 @@ type HypershiftDeploymentSpec struct {
 
 	// Credentials are ARN's that are used for standing up the resources in the cluster.
 	Credentials *CredentialARNs `json:"credentials,omitempty"`
+
+	// ControlPlaneTolerations are set as spec.tolerations of the HostedCluster, so the control plane pods can be
+	// scheduled onto tainted nodes of the HostingCluster. The HyperShift API used by this controller has no
+	// tolerations, they need a HostingCluster with a HyperShift operator that supports them
+	// +optional
+	ControlPlaneTolerations []corev1.Toleration `json:"controlPlaneTolerations,omitempty"`
+
+	// MaintenanceWindow restricts disruptive changes, NodePool scale downs and release upgrades, to a
+	// recurring time range. Other changes are applied immediately. When omitted, all changes are applied immediately.
+	// +optional
+	MaintenanceWindow *MaintenanceWindow `json:"maintenanceWindow,omitempty"`
+
+	// ImageRegistry configures the internal image registry of the hosted cluster, the registry is managed
+	// when omitted
+	// +optional
+	ImageRegistry *ImageRegistry `json:"imageRegistry,omitempty"`
+
+	// FeedbackRules are JSONPaths of the HostedCluster or NodePool status, added to the status feedback
+	// collected by the ManifestWork. Each value is reported in a condition of the HypershiftDeployment
+	// +optional
+	FeedbackRules []FeedbackRule `json:"feedbackRules,omitempty"`
+
+	// TemplateRef references a HypershiftDeploymentTemplate of the HypershiftDeployment namespace, the fields
+	// not set by the HypershiftDeployment are inherited from the template
+	// +optional
+	TemplateRef *corev1.LocalObjectReference `json:"templateRef,omitempty"`
+
+	// DryRun renders the manifestwork payload to the ConfigMap of status.renderedManifests instead of creating
+	// or updating the manifestwork. The secret data is redacted
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// DeleteOptions decide which objects of the HostingCluster are deleted with the HypershiftDeployment, they
+	// replace the delete option the ManifestWork gets from the override when the HypershiftDeployment is deleted
+	// +optional
+	DeleteOptions *DeleteOptions `json:"deleteOptions,omitempty"`
+
+	// Proxy is the cluster-wide proxy of the hosted cluster, it replaces the Proxy configuration of the
+	// HostedClusterSpec or HostedClusterRef
+	// +optional
+	Proxy *Proxy `json:"proxy,omitempty"`
+
+	// AdditionalTrustBundle references a ConfigMap of the HypershiftDeployment namespace with a PEM-encoded X.509
+	// certificate bundle under the ca-bundle.crt key, added to the hosted control plane and nodes. It replaces the
+	// additionalTrustBundle of the HostedClusterSpec or HostedClusterRef
+	// +optional
+	AdditionalTrustBundle *corev1.LocalObjectReference `json:"additionalTrustBundle,omitempty"`
+
+	// PullKubeconfigToHub copies the admin kubeconfig secret of the hosted cluster to the secret of
+	// status.kubeconfig.hubSecret, in the HypershiftDeployment namespace. The copy grants cluster-admin on the hosted
+	// cluster to anyone who can read the secrets of this namespace on the hub, and it stays valid until the kubeconfig
+	// is rotated on the HostingCluster. Only set it when the namespace is restricted to the hosted cluster admins
+	// +optional
+	PullKubeconfigToHub bool `json:"pullKubeconfigToHub,omitempty"`
+}
+
+// DeleteOptions is the delete option of the ManifestWork when the HypershiftDeployment is deleted
+type DeleteOptions struct {
+	// PropagationPolicy of the ManifestWork deletion, Foreground deletes the objects of the payload from the
+	// HostingCluster, Orphan keeps them and SelectivelyOrphan only keeps the objects of the OrphaningRules
+	// +kubebuilder:validation:Enum=Foreground;Orphan;SelectivelyOrphan
+	PropagationPolicy DeletePropagationPolicy `json:"propagationPolicy"`
+
+	// OrphaningRules are the objects of the payload kept on the HostingCluster by the SelectivelyOrphan policy
+	// +optional
+	OrphaningRules []OrphaningRule `json:"orphaningRules,omitempty"`
+}
+
+type DeletePropagationPolicy string
+
+const (
+	DeletePropagationForeground        DeletePropagationPolicy = "Foreground"
+	DeletePropagationOrphan            DeletePropagationPolicy = "Orphan"
+	DeletePropagationSelectivelyOrphan DeletePropagationPolicy = "SelectivelyOrphan"
+)
+
+// OrphaningRule identifies an object of the ManifestWork payload
+type OrphaningRule struct {
+	// Group of the object, empty for the core API group
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Resource is the lowercase plural of the object kind, e.g. secrets or hostedclusters
+	Resource string `json:"resource"`
+
+	// Namespace of the object, empty for a cluster scoped object
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the object
+	Name string `json:"name"`
+}
+
+// Proxy is the cluster-wide proxy configuration of the hosted cluster
+type Proxy struct {
+	// HTTPProxy is the URL of the proxy for HTTP requests
+	// +optional
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// HTTPSProxy is the URL of the proxy for HTTPS requests
+	// +optional
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// NoProxy is a comma-separated list of hostnames, domains and CIDRs excluded from proxying
+	// +optional
+	NoProxy string `json:"noProxy,omitempty"`
+
+	// ReadinessEndpoints are URLs checked through the proxy before it is used
+	// +optional
+	ReadinessEndpoints []string `json:"readinessEndpoints,omitempty"`
+
+	// TrustedCA references a ConfigMap of the HypershiftDeployment namespace with the CA bundle of the proxy
+	// under the ca-bundle.crt key
+	// +optional
+	TrustedCA *corev1.LocalObjectReference `json:"trustedCA,omitempty"`
+}
+
+// ImageRegistry configures the image registry operator of the hosted cluster
+type ImageRegistry struct {
+	// ManagementState of the image registry operator, Removed disables the internal image registry
+	// +kubebuilder:validation:Enum=Managed;Removed
+	// +kubebuilder:default=Managed
+	ManagementState ImageRegistryManagementState `json:"managementState"`
 }
 
+type ImageRegistryManagementState string
+
+// FeedbackRule reads a JSONPath of the status of the HostedCluster or of every NodePool. The value is reported in
+// the condition named after the resource kind followed by the rule name, e.g. HostedClusterEtcdReady
+type FeedbackRule struct {
+	// Resource the JSONPath is read from
+	// +kubebuilder:validation:Enum=HostedCluster;NodePool
+	Resource FeedbackResource `json:"resource"`
+
+	// Name of the feedback value, in UpperCamelCase
+	// +kubebuilder:validation:Pattern=`^[A-Z][a-zA-Z0-9]*$`
+	Name string `json:"name"`
+
+	// Path is the JSONPath of the value, e.g. .status.version.desired.image
+	Path string `json:"path"`
+}
+
+type FeedbackResource string
+
+const (
+	FeedbackHostedCluster FeedbackResource = "HostedCluster"
+	FeedbackNodePool      FeedbackResource = "NodePool"
+)
+
+const (
+	ImageRegistryManaged ImageRegistryManagementState = "Managed"
+	ImageRegistryRemoved ImageRegistryManagementState = "Removed"
+)
+
+// MaintenanceWindow is a recurring time range in UTC
+type MaintenanceWindow struct {
+	// Start is the time of day the window opens, in the 24 hour HH:MM format
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// Duration is how long the window stays open, at most 24h
+	Duration metav1.Duration `json:"duration"`
+
+	// Days of the week the window opens on, every day when omitted
+	// +optional
+	Days []Weekday `json:"days,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Sunday;Monday;Tuesday;Wednesday;Thursday;Friday;Saturday
+type Weekday string
+
 type CredentialARNs struct {
 	AWS *AWSCredentials `json:"aws,omitempty"`
+
+	// AzureIdentity authenticates an Azure HostedCluster with a managed identity instead of the credentials secret
+	// of hostedClusterSpec.platform.azure.credentials, no credentials secret is copied to the HostingCluster
+	// +optional
+	AzureIdentity *AzureIdentity `json:"azureIdentity,omitempty"`
+}
+
+// AzureIdentity is the managed identity an Azure HostedCluster authenticates with
+type AzureIdentity struct {
+	// ClientID is the client id of the managed identity of the control plane
+	ClientID string `json:"clientID"`
+
+	// TenantID is the Azure AD tenant of the managed identity
+	TenantID string `json:"tenantID"`
+
+	// SubscriptionID is the subscription of the cluster resources, it sets platform.azure.subscriptionID of the
+	// HostedCluster
+	SubscriptionID string `json:"subscriptionID"`
+
+	// MachineIdentityID is the resource id of the managed identity of the nodes, it sets
+	// platform.azure.machineIdentityID of the HostedCluster
+	// +optional
+	MachineIdentityID string `json:"machineIdentityID,omitempty"`
 }
 
 type AWSCredentials struct {
@@ -152,8 +504,61 @@ type HypershiftNodePools struct {
 
 	// Spec stores the NodePoolSpec you wan to use. If omitted, it will be generated
 	Spec hypv1alpha1.NodePoolSpec `json:"spec"`
+
+	// MachineCIDR is the machine network of this NodePool, it must be within the HostedCluster machine
+	// network and must not overlap the machine network of another NodePool. It is only validated on the hub,
+	// the HyperShift NodePool API used by this controller has no machine network
+	// +optional
+	MachineCIDR string `json:"machineCIDR,omitempty"`
+
+	// TuningConfig references the ConfigMaps with the TuneD profiles of this NodePool, they are copied to the
+	// HostingCluster with the NodePool. The HyperShift NodePool API used by this controller has no tuningConfig,
+	// it needs a HostingCluster with a HyperShift operator that supports it
+	// +optional
+	TuningConfig []corev1.LocalObjectReference `json:"tuningConfig,omitempty"`
+
+	// Arch is the CPU architecture of the nodes of this NodePool, amd64 or arm64. arm64 is only supported on AWS
+	// and requires a multi-arch release image. If omitted, the architecture of the release image is used
+	// +optional
+	Arch string `json:"arch,omitempty"`
+
+	// Kubevirt holds the settings of the VMs of a KubeVirt NodePool the HyperShift NodePool API of the controller
+	// does not have, they are written to spec.platform.kubevirt of the NodePool
+	// +optional
+	Kubevirt *KubevirtNodePoolOptions `json:"kubevirt,omitempty"`
+
+	// NodeVolumeDetachTimeout is how long the volumes of a drained node are waited for to be detached before the
+	// node is deleted. The HyperShift NodePool API of the controller does not have it, it is written to
+	// spec.nodeVolumeDetachTimeout of the NodePool
+	// +optional
+	NodeVolumeDetachTimeout *metav1.Duration `json:"nodeVolumeDetachTimeout,omitempty"`
+}
+
+// KubevirtNodePoolOptions are the networking and scheduling settings of the VMs of a KubeVirt NodePool
+type KubevirtNodePoolOptions struct {
+	// NetworkInterfaceMultiQueue enables the multi queue of the virtio network interfaces of the VMs, Enable or Disable
+	// +optional
+	NetworkInterfaceMultiQueue string `json:"networkInterfaceMultiqueue,omitempty"`
+
+	// Affinity groups or spreads the VMs of the NodePool on the nodes of the infrastructure cluster
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
 }
 
+const (
+	// NodePoolArchAMD64 is the x86_64 architecture of a NodePool
+	NodePoolArchAMD64 = "amd64"
+
+	// NodePoolArchARM64 is the aarch64 architecture of a NodePool
+	NodePoolArchARM64 = "arm64"
+
+	// KubevirtMultiQueueEnable enables the multi queue of the network interfaces of a KubeVirt NodePool
+	KubevirtMultiQueueEnable = "Enable"
+
+	// KubevirtMultiQueueDisable disables the multi queue of the network interfaces of a KubeVirt NodePool
+	KubevirtMultiQueueDisable = "Disable"
+)
+
 type InfraSpec struct {
 	// Configure the infrastructure using the provided CloudProvider, or user provided
 	// +immutable
@@ -204,6 +609,110 @@ type HypershiftDeploymentStatus struct {
 
 	//Show which phase of curation is currently being processed
 	Phase CurrentPhase `json:"phase,omitempty"`
+
+	// IdempotencyKeys records, per side effecting operation, the token of the trigger it last ran for,
+	// so repeated reconciles do not run the same operation twice
+	// +optional
+	IdempotencyKeys map[string]string `json:"idempotencyKeys,omitempty"`
+
+	// ReleaseResolution is the latest stable release resolved for the HostedCluster and NodePools scaffolded
+	// without a release image
+	// +optional
+	ReleaseResolution *ReleaseResolution `json:"releaseResolution,omitempty"`
+
+	// APIEndpoint is the URL of the API server of the HostedCluster, empty until the control plane endpoint
+	// is reported by the work agent
+	// +optional
+	APIEndpoint string `json:"apiEndpoint,omitempty"`
+
+	// RenderedManifests references the ConfigMap of the HypershiftDeployment namespace the manifestwork payload
+	// is rendered to, as YAML, while spec.dryRun is true
+	// +optional
+	RenderedManifests *corev1.LocalObjectReference `json:"renderedManifests,omitempty"`
+
+	// NodePools is the status of the NodePools of spec.nodePools, as reported by the work agent
+	// +optional
+	NodePools []NodePoolStatus `json:"nodePools,omitempty"`
+
+	// Ready is true when every manifest of the ManifestWork is applied and available on the HostingCluster,
+	// like the Ready condition
+	// +optional
+	Ready bool `json:"ready,omitempty"`
+
+	// Kubeconfig locates the admin kubeconfig secret of the HostedCluster, set once the HostedCluster is available
+	// +optional
+	Kubeconfig *KubeconfigStatus `json:"kubeconfig,omitempty"`
+
+	// History holds the latest condition status and phase transitions, oldest first. It is bounded, the oldest
+	// transitions are dropped once it is full
+	// +optional
+	History []TransitionEvent `json:"history,omitempty"`
+}
+
+// TransitionEvent is a change of the status of a condition, or of the phase, of the HypershiftDeployment
+type TransitionEvent struct {
+	// Time is when the transition happened
+	Time metav1.Time `json:"time"`
+
+	// Type is the type of the condition that changed, Phase when the phase changed
+	Type string `json:"type"`
+
+	// From is the previous status of the condition or the previous phase, empty when there was none
+	// +optional
+	From string `json:"from,omitempty"`
+
+	// To is the new status of the condition or the new phase
+	To string `json:"to"`
+
+	// Reason is the reason of the condition after the transition, empty for a phase
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// KubeconfigStatus locates the admin kubeconfig secret HyperShift creates for the HostedCluster
+type KubeconfigStatus struct {
+	// Name is the name of the secret on the HostingCluster
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the HostingCluster the secret is in, the hosting namespace
+	Namespace string `json:"namespace"`
+
+	// HubSecret references the copy of the secret in the HypershiftDeployment namespace, while
+	// spec.pullKubeconfigToHub is true
+	// +optional
+	HubSecret *corev1.LocalObjectReference `json:"hubSecret,omitempty"`
+}
+
+// NodePoolStatus is the status of a NodePool of the HostedCluster, read from the manifestwork status feedback
+type NodePoolStatus struct {
+	// Name is the name of the NodePool
+	Name string `json:"name"`
+
+	// DesiredReplicas is the number of nodes requested by the NodePool spec, unset when the NodePool autoscales
+	// or until it is reported
+	// +optional
+	DesiredReplicas *int32 `json:"desiredReplicas,omitempty"`
+
+	// CurrentReplicas is the number of nodes of the NodePool, unset until it is reported
+	// +optional
+	CurrentReplicas *int32 `json:"currentReplicas,omitempty"`
+
+	// Conditions holds the Ready condition of the NodePool
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ReleaseResolution is a release image resolved from the stable release stream
+type ReleaseResolution struct {
+	// Image is the pull spec of the resolved release
+	Image string `json:"image"`
+
+	// ResolvedTime is when the release stream was last read successfully
+	ResolvedTime metav1.Time `json:"resolvedTime"`
+
+	// Stale is true when the last read of the release stream failed and the previously resolved release is used
+	// +optional
+	Stale bool `json:"stale,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,84 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PhaseReady is a HypershiftDeployment whose HostedCluster is available
+	PhaseReady CurrentPhase = "Ready"
+	// PhaseProvisioning is a HypershiftDeployment that is still being configured
+	PhaseProvisioning CurrentPhase = "Provisioning"
+	// PhaseFailed is a HypershiftDeployment with a misconfiguration that needs a user action
+	PhaseFailed CurrentPhase = "Failed"
+	// PhaseDeleting is a HypershiftDeployment that is being removed
+	PhaseDeleting CurrentPhase = "Deleting"
+)
+
+// HypershiftDeploymentSummaryStatus counts the HypershiftDeployments of a namespace by phase
+type HypershiftDeploymentSummaryStatus struct {
+	// Total number of HypershiftDeployments in the namespace
+	Total int32 `json:"total"`
+
+	// Ready is the number of HypershiftDeployments with an available HostedCluster
+	Ready int32 `json:"ready"`
+
+	// Provisioning is the number of HypershiftDeployments still being configured
+	Provisioning int32 `json:"provisioning"`
+
+	// Failed is the number of misconfigured HypershiftDeployments
+	Failed int32 `json:"failed"`
+
+	// Deleting is the number of HypershiftDeployments being removed
+	Deleting int32 `json:"deleting"`
+
+	// LastUpdated is the last time the counts changed
+	// +optional
+	LastUpdated *metav1.Time `json:"lastUpdated,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=hypershiftdeploymentsummaries,shortName=hds-summary,scope=Namespaced
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="TOTAL",type="integer",JSONPath=".status.total"
+// +kubebuilder:printcolumn:name="READY",type="integer",JSONPath=".status.ready"
+// +kubebuilder:printcolumn:name="PROVISIONING",type="integer",JSONPath=".status.provisioning"
+// +kubebuilder:printcolumn:name="FAILED",type="integer",JSONPath=".status.failed"
+// +kubebuilder:printcolumn:name="DELETING",type="integer",JSONPath=".status.deleting"
+
+// HypershiftDeploymentSummary is maintained by the controller and summarizes the HypershiftDeployments of its namespace
+type HypershiftDeploymentSummary struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status HypershiftDeploymentSummaryStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// HypershiftDeploymentSummaryList contains a list of HypershiftDeploymentSummary
+type HypershiftDeploymentSummaryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HypershiftDeploymentSummary `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HypershiftDeploymentSummary{}, &HypershiftDeploymentSummaryList{})
+}
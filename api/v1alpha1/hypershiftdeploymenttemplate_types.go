@@ -0,0 +1,54 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HypershiftDeploymentTemplateSpec holds the defaults of the HypershiftDeployments referencing the template
+type HypershiftDeploymentTemplateSpec struct {
+	// Template is the HypershiftDeployment spec the referencing HypershiftDeployments inherit from, every
+	// field a HypershiftDeployment sets wins over the template. The templateRef of a template is ignored.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Template HypershiftDeploymentSpec `json:"template"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=hypershiftdeploymenttemplates,shortName=hd-template,scope=Namespaced
+
+// HypershiftDeploymentTemplate holds defaults shared by the HypershiftDeployments of its namespace
+type HypershiftDeploymentTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec HypershiftDeploymentTemplateSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// HypershiftDeploymentTemplateList contains a list of HypershiftDeploymentTemplate
+type HypershiftDeploymentTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HypershiftDeploymentTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HypershiftDeploymentTemplate{}, &HypershiftDeploymentTemplateList{})
+}
@@ -58,6 +58,21 @@ func (in *AWSPlatform) DeepCopy() *AWSPlatform {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureIdentity) DeepCopyInto(out *AzureIdentity) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureIdentity.
+func (in *AzureIdentity) DeepCopy() *AzureIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AzurePlatform) DeepCopyInto(out *AzurePlatform) {
 	*out = *in
@@ -81,6 +96,11 @@ func (in *CredentialARNs) DeepCopyInto(out *CredentialARNs) {
 		*out = new(AWSCredentials)
 		**out = **in
 	}
+	if in.AzureIdentity != nil {
+		in, out := &in.AzureIdentity, &out.AzureIdentity
+		*out = new(AzureIdentity)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialARNs.
@@ -93,6 +113,41 @@ func (in *CredentialARNs) DeepCopy() *CredentialARNs {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeleteOptions) DeepCopyInto(out *DeleteOptions) {
+	*out = *in
+	if in.OrphaningRules != nil {
+		in, out := &in.OrphaningRules, &out.OrphaningRules
+		*out = make([]OrphaningRule, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeleteOptions.
+func (in *DeleteOptions) DeepCopy() *DeleteOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(DeleteOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FeedbackRule) DeepCopyInto(out *FeedbackRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeedbackRule.
+func (in *FeedbackRule) DeepCopy() *FeedbackRule {
+	if in == nil {
+		return nil
+	}
+	out := new(FeedbackRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HypershiftDeployment) DeepCopyInto(out *HypershiftDeployment) {
 	*out = *in
@@ -183,6 +238,48 @@ func (in *HypershiftDeploymentSpec) DeepCopyInto(out *HypershiftDeploymentSpec)
 		*out = new(CredentialARNs)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ControlPlaneTolerations != nil {
+		in, out := &in.ControlPlaneTolerations, &out.ControlPlaneTolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(MaintenanceWindow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageRegistry != nil {
+		in, out := &in.ImageRegistry, &out.ImageRegistry
+		*out = new(ImageRegistry)
+		**out = **in
+	}
+	if in.FeedbackRules != nil {
+		in, out := &in.FeedbackRules, &out.FeedbackRules
+		*out = make([]FeedbackRule, len(*in))
+		copy(*out, *in)
+	}
+	if in.TemplateRef != nil {
+		in, out := &in.TemplateRef, &out.TemplateRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.DeleteOptions != nil {
+		in, out := &in.DeleteOptions, &out.DeleteOptions
+		*out = new(DeleteOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(Proxy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdditionalTrustBundle != nil {
+		in, out := &in.AdditionalTrustBundle, &out.AdditionalTrustBundle
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HypershiftDeploymentSpec.
@@ -205,6 +302,42 @@ func (in *HypershiftDeploymentStatus) DeepCopyInto(out *HypershiftDeploymentStat
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.IdempotencyKeys != nil {
+		in, out := &in.IdempotencyKeys, &out.IdempotencyKeys
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ReleaseResolution != nil {
+		in, out := &in.ReleaseResolution, &out.ReleaseResolution
+		*out = new(ReleaseResolution)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RenderedManifests != nil {
+		in, out := &in.RenderedManifests, &out.RenderedManifests
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.NodePools != nil {
+		in, out := &in.NodePools, &out.NodePools
+		*out = make([]NodePoolStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Kubeconfig != nil {
+		in, out := &in.Kubeconfig, &out.Kubeconfig
+		*out = new(KubeconfigStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]TransitionEvent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HypershiftDeploymentStatus.
@@ -217,10 +350,176 @@ func (in *HypershiftDeploymentStatus) DeepCopy() *HypershiftDeploymentStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HypershiftDeploymentSummary) DeepCopyInto(out *HypershiftDeploymentSummary) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HypershiftDeploymentSummary.
+func (in *HypershiftDeploymentSummary) DeepCopy() *HypershiftDeploymentSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(HypershiftDeploymentSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HypershiftDeploymentSummary) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HypershiftDeploymentSummaryList) DeepCopyInto(out *HypershiftDeploymentSummaryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HypershiftDeploymentSummary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HypershiftDeploymentSummaryList.
+func (in *HypershiftDeploymentSummaryList) DeepCopy() *HypershiftDeploymentSummaryList {
+	if in == nil {
+		return nil
+	}
+	out := new(HypershiftDeploymentSummaryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HypershiftDeploymentSummaryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HypershiftDeploymentSummaryStatus) DeepCopyInto(out *HypershiftDeploymentSummaryStatus) {
+	*out = *in
+	if in.LastUpdated != nil {
+		in, out := &in.LastUpdated, &out.LastUpdated
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HypershiftDeploymentSummaryStatus.
+func (in *HypershiftDeploymentSummaryStatus) DeepCopy() *HypershiftDeploymentSummaryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HypershiftDeploymentSummaryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HypershiftDeploymentTemplate) DeepCopyInto(out *HypershiftDeploymentTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HypershiftDeploymentTemplate.
+func (in *HypershiftDeploymentTemplate) DeepCopy() *HypershiftDeploymentTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(HypershiftDeploymentTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HypershiftDeploymentTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HypershiftDeploymentTemplateList) DeepCopyInto(out *HypershiftDeploymentTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HypershiftDeploymentTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HypershiftDeploymentTemplateList.
+func (in *HypershiftDeploymentTemplateList) DeepCopy() *HypershiftDeploymentTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(HypershiftDeploymentTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HypershiftDeploymentTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HypershiftDeploymentTemplateSpec) DeepCopyInto(out *HypershiftDeploymentTemplateSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HypershiftDeploymentTemplateSpec.
+func (in *HypershiftDeploymentTemplateSpec) DeepCopy() *HypershiftDeploymentTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HypershiftDeploymentTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HypershiftNodePools) DeepCopyInto(out *HypershiftNodePools) {
 	*out = *in
 	in.Spec.DeepCopyInto(&out.Spec)
+	if in.TuningConfig != nil {
+		in, out := &in.TuningConfig, &out.TuningConfig
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Kubevirt != nil {
+		in, out := &in.Kubevirt, &out.Kubevirt
+		*out = new(KubevirtNodePoolOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeVolumeDetachTimeout != nil {
+		in, out := &in.NodeVolumeDetachTimeout, &out.NodeVolumeDetachTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HypershiftNodePools.
@@ -233,6 +532,21 @@ func (in *HypershiftNodePools) DeepCopy() *HypershiftNodePools {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageRegistry) DeepCopyInto(out *ImageRegistry) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageRegistry.
+func (in *ImageRegistry) DeepCopy() *ImageRegistry {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageRegistry)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InfraSpec) DeepCopyInto(out *InfraSpec) {
 	*out = *in
@@ -254,6 +568,114 @@ func (in *InfraSpec) DeepCopy() *InfraSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeconfigStatus) DeepCopyInto(out *KubeconfigStatus) {
+	*out = *in
+	if in.HubSecret != nil {
+		in, out := &in.HubSecret, &out.HubSecret
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeconfigStatus.
+func (in *KubeconfigStatus) DeepCopy() *KubeconfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeconfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtNodePoolOptions) DeepCopyInto(out *KubevirtNodePoolOptions) {
+	*out = *in
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtNodePoolOptions.
+func (in *KubevirtNodePoolOptions) DeepCopy() *KubevirtNodePoolOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtNodePoolOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	out.Duration = in.Duration
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]Weekday, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolStatus) DeepCopyInto(out *NodePoolStatus) {
+	*out = *in
+	if in.DesiredReplicas != nil {
+		in, out := &in.DesiredReplicas, &out.DesiredReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CurrentReplicas != nil {
+		in, out := &in.CurrentReplicas, &out.CurrentReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolStatus.
+func (in *NodePoolStatus) DeepCopy() *NodePoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrphaningRule) DeepCopyInto(out *OrphaningRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrphaningRule.
+func (in *OrphaningRule) DeepCopy() *OrphaningRule {
+	if in == nil {
+		return nil
+	}
+	out := new(OrphaningRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Platforms) DeepCopyInto(out *Platforms) {
 	*out = *in
@@ -278,3 +700,60 @@ func (in *Platforms) DeepCopy() *Platforms {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Proxy) DeepCopyInto(out *Proxy) {
+	*out = *in
+	if in.ReadinessEndpoints != nil {
+		in, out := &in.ReadinessEndpoints, &out.ReadinessEndpoints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TrustedCA != nil {
+		in, out := &in.TrustedCA, &out.TrustedCA
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Proxy.
+func (in *Proxy) DeepCopy() *Proxy {
+	if in == nil {
+		return nil
+	}
+	out := new(Proxy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseResolution) DeepCopyInto(out *ReleaseResolution) {
+	*out = *in
+	in.ResolvedTime.DeepCopyInto(&out.ResolvedTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseResolution.
+func (in *ReleaseResolution) DeepCopy() *ReleaseResolution {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseResolution)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransitionEvent) DeepCopyInto(out *TransitionEvent) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransitionEvent.
+func (in *TransitionEvent) DeepCopy() *TransitionEvent {
+	if in == nil {
+		return nil
+	}
+	out := new(TransitionEvent)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,9 @@
+// Package crd embeds the CustomResourceDefinitions generated with `make manifests`
+package crd
+
+import _ "embed"
+
+// HypershiftDeployments is the CustomResourceDefinition of the HypershiftDeployment kind
+//
+//go:embed cluster.open-cluster-management.io_hypershiftdeployments.yaml
+var HypershiftDeployments []byte
@@ -27,6 +27,83 @@ const (
 	// CreatedByHypershiftDeployment is an annotation that is used to show ownership via infra-ids
 	CreatedByHypershiftDeployment = "hypershift-deployment.open-cluster-management.io/created-by"
 
+	// CollectSupportAnnotation triggers a one time dump of the rendered payload, manifestwork and conditions
+	// of a HypershiftDeployment to a ConfigMap, the annotation is removed once the collection is done.
+	// Setting the annotation again with a new value collects a new bundle. The ConfigMap is deleted with the
+	// HypershiftDeployment
+	CollectSupportAnnotation = "hypershift-deployment.open-cluster-management.io/collect-support"
+
+	// NodePoolMachineCIDRAnnotation records the machineCIDR of a HypershiftDeployment NodePool on the NodePool.
+	// The pinned NodePool API has no machine network, nothing on the HostingCluster reads the annotation, the
+	// machineCIDR is only validated on the hub
+	NodePoolMachineCIDRAnnotation = "hypershift-deployment.open-cluster-management.io/machine-cidr"
+
+	// ImageRegistryManagementStateAnnotation carries the management state of the image registry operator of a
+	// HypershiftDeployment to the HostedCluster
+	ImageRegistryManagementStateAnnotation = "hypershift-deployment.open-cluster-management.io/image-registry-management-state"
+
+	// AzureIdentityClientIDAnnotation carries the client id of the managed identity of an Azure HypershiftDeployment
+	// to the HostedCluster
+	AzureIdentityClientIDAnnotation = "hypershift-deployment.open-cluster-management.io/azure-identity-client-id"
+
+	// AzureIdentityTenantIDAnnotation carries the tenant of the managed identity of an Azure HypershiftDeployment
+	// to the HostedCluster
+	AzureIdentityTenantIDAnnotation = "hypershift-deployment.open-cluster-management.io/azure-identity-tenant-id"
+
+	// PhaseAnnotation mirrors the phase of the HypershiftDeployment, for the controllers coordinating on it
+	// without reading the status conditions
+	PhaseAnnotation = "hypershift-deployment.open-cluster-management.io/phase"
+
+	// LastAppliedHashAnnotation holds the sha256 of the payload of the manifestwork last applied for the
+	// HypershiftDeployment, it is removed when there is no manifestwork
+	LastAppliedHashAnnotation = "hypershift-deployment.open-cluster-management.io/last-applied-hash"
+
+	// ManifestWorkChunkLabel holds the name of the manifestwork a chunk carries the NodePools of, when the payload
+	// is too large for a single manifestwork
+	ManifestWorkChunkLabel = "hypershift-deployment.open-cluster-management.io/manifestwork-chunk-of"
+
+	// HypershiftDeploymentFieldManager is the field manager of the server side apply patches
+	HypershiftDeploymentFieldManager = "hypershift-deployment-controller"
+
+	// TransformedSecretAnnotation holds the name of the secret a payload object was transformed from
+	TransformedSecretAnnotation = "hypershift-deployment.open-cluster-management.io/transformed-secret"
+
+	// SourceSecretAnnotation holds the namespace/name of the hub secret a propagated secret is copied from
+	SourceSecretAnnotation = "hypershift-deployment.open-cluster-management.io/source-secret"
+
+	// SourceResourceVersionAnnotation holds the resourceVersion of the hub secret a propagated secret is copied from
+	SourceResourceVersionAnnotation = "hypershift-deployment.open-cluster-management.io/source-resource-version"
+
+	// IdempotencyKeySupportBundle is the status idempotency key of the support bundle collection
+	IdempotencyKeySupportBundle = "support-bundle"
+
+	// IdempotencyKeyAutoImportSecret is the status idempotency key of the auto import secret creation
+	IdempotencyKeyAutoImportSecret = "auto-import-secret"
+
+	// IdempotencyKeyPhaseNotification is the status idempotency key of the phase transition webhook, it holds
+	// the last phase notified
+	IdempotencyKeyPhaseNotification = "phase-notification"
+
+	// RefreshReleaseAnnotation bypasses the release cache on the next resolution of the stable release, each new
+	// value triggers one refresh
+	RefreshReleaseAnnotation = "hypershift-deployment.open-cluster-management.io/refresh-release"
+
+	// IdempotencyKeyReleaseRefresh is the status idempotency key of the release refresh, it holds the last
+	// RefreshReleaseAnnotation value handled
+	IdempotencyKeyReleaseRefresh = "release-refresh"
+
+	// ConfirmScaleDownAnnotation confirms the NodePool replica reductions blocked by the scale down guard, each new
+	// value confirms the reductions blocked when it is set
+	ConfirmScaleDownAnnotation = "hypershift-deployment.open-cluster-management.io/confirm-scale-down"
+
+	// IdempotencyKeyScaleDownConfirmation is the status idempotency key of the scale down confirmation, it holds
+	// the last ConfirmScaleDownAnnotation value applied
+	IdempotencyKeyScaleDownConfirmation = "scale-down-confirmation"
+
+	// ReconcilePriorityLabel sets the priority class of a HypershiftDeployment in the reconcile queue, one of
+	// high, normal or low, normal when not set or unknown
+	ReconcilePriorityLabel = "hypershift-deployment.open-cluster-management.io/reconcile-priority"
+
 	// CCredsSuffix Cloud Credential Suffix
 	CCredsSuffix = "-cloud-credentials" // #nosec G101
 
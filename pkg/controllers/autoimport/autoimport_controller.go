@@ -128,9 +128,24 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			log.V(INFO).Info("Wait for the hosted cluster kubeconfig to be created", "secret", secretNamespaceName.String())
 			return ctrl.Result{}, nil
 		}
-		if err := ensureAutoImportSecret(r, managedClusterName, &kubeconfig); err != nil {
+		if err != nil {
 			return ctrl.Result{}, err
 		}
+
+		// the auto import secret is created once per kubeconfig, the import controller removes it
+		// after the import so it must not be recreated on every reconcile
+		token := fmt.Sprintf("%s/%s", kubeconfig.UID, kubeconfig.ResourceVersion)
+		if !helper.SideEffectDone(&hyd, constant.IdempotencyKeyAutoImportSecret, token) {
+			if err := ensureAutoImportSecret(r, managedClusterName, &kubeconfig); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			patch := client.MergeFrom(hyd.DeepCopy())
+			helper.RecordSideEffect(&hyd, constant.IdempotencyKeyAutoImportSecret, token)
+			if err := r.Status().Patch(ctx, &hyd, patch); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
 	}
 
 	// Make sure we don't create the ManagedCluster if it is detached
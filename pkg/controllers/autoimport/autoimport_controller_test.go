@@ -250,6 +250,62 @@ func TestReconcileCreate(t *testing.T) {
 	}
 }
 
+func TestAutoImportSecretCreatedOnce(t *testing.T) {
+	ctx := context.Background()
+	air := GetAutoImportReconciler()
+
+	hyd := GetHypershiftDeployment(HYD_NAMESPACE, HYD_NAME, "id1", HYD_NAMESPACE)
+	kubeconfig := GetHostedClusterKubeconfig(HYD_NAMESPACE, helper.HostedKubeconfigName(hyd))
+	assert.Nil(t, air.Client.Create(ctx, hyd, &crclient.CreateOptions{}), "")
+	assert.Nil(t, air.Client.Create(ctx, kubeconfig, &crclient.CreateOptions{}), "")
+	assert.Nil(t, air.Client.Create(ctx, GetManagedCluster(HYD_NAMESPACE), &crclient.CreateOptions{}), "")
+
+	autoImportSecretKey := getNamespaceName(helper.ManagedClusterName(hyd), "auto-import-secret")
+
+	_, err := air.Reconcile(ctx, getRequest())
+	assert.Nil(t, err, "reconcile was successful")
+
+	var autoImportSecret corev1.Secret
+	assert.Nil(t, air.Client.Get(ctx, autoImportSecretKey, &autoImportSecret), "secret resource is retrieved")
+
+	// the import controller removes the secret once the cluster is imported
+	assert.Nil(t, air.Client.Delete(ctx, &autoImportSecret), "")
+
+	// reconciles that run before the create managed cluster annotation is persisted
+	clearCreateMCAnnotation := func() {
+		var current hydapi.HypershiftDeployment
+		assert.Nil(t, air.Client.Get(ctx, getNamespaceName(HYD_NAMESPACE, HYD_NAME), &current), "")
+		delete(current.Annotations, createManagedClusterAnnotation)
+		assert.Nil(t, air.Client.Update(ctx, &current), "")
+	}
+
+	for i := 0; i < 3; i++ {
+		clearCreateMCAnnotation()
+		_, err = air.Reconcile(ctx, getRequest())
+		assert.Nil(t, err, "reconcile was successful")
+	}
+
+	err = air.Client.Get(ctx, autoImportSecretKey, &autoImportSecret)
+	assert.True(t, k8serrors.IsNotFound(err), "secret is not recreated for the same kubeconfig")
+
+	// a new kubeconfig triggers the auto import again
+	assert.Nil(t, air.Client.Get(ctx, getNamespaceName(kubeconfig.Namespace, kubeconfig.Name), kubeconfig), "")
+	kubeconfig.Data["kubeconfig"] = []byte("rotated")
+	assert.Nil(t, air.Client.Update(ctx, kubeconfig), "")
+
+	clearCreateMCAnnotation()
+	_, err = air.Reconcile(ctx, getRequest())
+	assert.Nil(t, err, "reconcile was successful")
+
+	assert.Nil(t, air.Client.Get(ctx, autoImportSecretKey, &autoImportSecret), "secret resource is retrieved")
+	assert.Equal(t, []byte("rotated"), autoImportSecret.Data["kubeconfig"], "secret is created from the new kubeconfig")
+
+	var result hydapi.HypershiftDeployment
+	assert.Nil(t, air.Client.Get(ctx, getNamespaceName(HYD_NAMESPACE, HYD_NAME), &result), "hypershift deployment resource is retrieved")
+	assert.Equal(t, fmt.Sprintf("%s/%s", kubeconfig.UID, kubeconfig.ResourceVersion),
+		result.Status.IdempotencyKeys[constant.IdempotencyKeyAutoImportSecret], "kubeconfig token is recorded in the status")
+}
+
 func TestReconcileDelete(t *testing.T) {
 	hyd := GetHypershiftDeployment(HYD_NAMESPACE, HYD_NAME, "id1", HYD_NAMESPACE)
 
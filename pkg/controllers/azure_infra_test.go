@@ -22,6 +22,8 @@ func getFakeAzureHD() *hydapi.HypershiftDeployment {
 	testHD.Spec.Infrastructure.Platform = &hypdeployment.Platforms{Azure: &hypdeployment.AzurePlatform{}}
 	ScaffoldAzureHostedClusterSpec(testHD, infraOut)
 	ScaffoldAzureNodePoolSpec(testHD, infraOut)
+	// the subscription comes from the provider secret when the infrastructure is configured
+	testHD.Spec.HostedClusterSpec.Platform.Azure.SubscriptionID = "00000000-0000-0000-0000-000000000000"
 	return testHD
 }
 
@@ -0,0 +1,222 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	workv1 "open-cluster-management.io/api/work/v1"
+)
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+type targetCircuit struct {
+	state circuitState
+	// failures counts the failed manifestwork generations of each HypershiftDeployment
+	failures map[types.UID]int
+	// observed is the last apply result recorded for each HypershiftDeployment, the Applied condition
+	// of a generation is only counted once per status
+	observed map[types.UID]applyResult
+	// openedAt is when the circuit was opened, or when the half-open trial was let through
+	openedAt time.Time
+}
+
+// applyResult is the status of the Applied condition of a manifestwork generation
+type applyResult struct {
+	generation int64
+	applied    bool
+}
+
+func (c *targetCircuit) failureCount() int {
+	n := 0
+	for _, f := range c.failures {
+		n += f
+	}
+
+	return n
+}
+
+// targetCircuitBreaker tracks the apply failures per hosting cluster, each failed manifestwork generation
+// of a HypershiftDeployment is a failure. Once a hosting cluster reaches the threshold the circuit opens and the HypershiftDeployments targeting it are not reconciled
+// until the cooldown expires, then a single trial reconcile is let through (half-open) to decide if the
+// circuit closes again or stays open for another cooldown.
+type targetCircuitBreaker struct {
+	sync.Mutex
+
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	circuits map[string]*targetCircuit
+}
+
+func newTargetCircuitBreaker(threshold int, cooldown time.Duration) *targetCircuitBreaker {
+	return &targetCircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		circuits:  map[string]*targetCircuit{},
+	}
+}
+
+func (b *targetCircuitBreaker) enabled() bool {
+	return b != nil && b.threshold > 0
+}
+
+func (b *targetCircuitBreaker) circuit(cluster string) *targetCircuit {
+	c, ok := b.circuits[cluster]
+	if !ok {
+		c = &targetCircuit{failures: map[types.UID]int{}, observed: map[types.UID]applyResult{}}
+		b.circuits[cluster] = c
+	}
+
+	return c
+}
+
+// allow returns true if the hosting cluster can be reconciled, otherwise it returns the time left
+// before the next trial reconcile is allowed
+func (b *targetCircuitBreaker) allow(cluster string) (bool, time.Duration) {
+	if !b.enabled() {
+		return true, 0
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	c := b.circuit(cluster)
+	if c.state == circuitClosed {
+		return true, 0
+	}
+
+	if elapsed := b.now().Sub(c.openedAt); elapsed < b.cooldown {
+		return false, b.cooldown - elapsed
+	}
+
+	// let a single trial through, the others wait for its outcome or another cooldown
+	c.state = circuitHalfOpen
+	c.openedAt = b.now()
+
+	return true, 0
+}
+
+// observe returns false when the result was already recorded for the HypershiftDeployment. An unchanged
+// manifestwork keeps its generation, so the recovery of the hosting cluster is the same generation turning applied
+func (c *targetCircuit) observe(uid types.UID, result applyResult) bool {
+	if r, ok := c.observed[uid]; ok && r == result {
+		return false
+	}
+
+	c.observed[uid] = result
+
+	return true
+}
+
+// recordFailure counts a failed manifestwork generation of a HypershiftDeployment, the circuit opens
+// once the threshold is reached or when the half-open trial fails
+func (b *targetCircuitBreaker) recordFailure(cluster string, uid types.UID, generation int64) {
+	if !b.enabled() {
+		return
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	c := b.circuit(cluster)
+	if !c.observe(uid, applyResult{generation: generation}) {
+		return
+	}
+
+	c.failures[uid]++
+
+	if c.state == circuitHalfOpen || (c.state == circuitClosed && c.failureCount() >= b.threshold) {
+		c.state = circuitOpen
+		c.openedAt = b.now()
+	}
+}
+
+// recordSuccess resets the failures of the HypershiftDeployment, the failures of the others are kept.
+// The circuit closes when the half-open trial succeeds
+func (b *targetCircuitBreaker) recordSuccess(cluster string, uid types.UID, generation int64) {
+	if !b.enabled() {
+		return
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	c := b.circuit(cluster)
+	if !c.observe(uid, applyResult{generation: generation, applied: true}) {
+		return
+	}
+
+	delete(c.failures, uid)
+
+	if c.state == circuitHalfOpen {
+		c.state = circuitClosed
+		c.failures = map[types.UID]int{}
+	}
+}
+
+// forget drops what was recorded for a deleted HypershiftDeployment
+func (b *targetCircuitBreaker) forget(cluster string, uid types.UID) {
+	if !b.enabled() {
+		return
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	if c, ok := b.circuits[cluster]; ok {
+		delete(c.failures, uid)
+		delete(c.observed, uid)
+	}
+}
+
+// recordApplyResult feeds the Applied condition of the manifestwork of a HypershiftDeployment to the
+// breaker, conditions that were not observed against the current manifestwork generation are ignored
+// and each status of a generation is only recorded once
+func (b *targetCircuitBreaker) recordApplyResult(cluster string, uid types.UID, work *workv1.ManifestWork) {
+	cond := meta.FindStatusCondition(work.Status.Conditions, workv1.WorkApplied)
+	if cond == nil || cond.ObservedGeneration != work.Generation {
+		return
+	}
+
+	switch cond.Status {
+	case metav1.ConditionTrue:
+		b.recordSuccess(cluster, uid, work.Generation)
+	case metav1.ConditionFalse:
+		b.recordFailure(cluster, uid, work.Generation)
+	}
+}
+
+func (r *HypershiftDeploymentReconciler) targetCircuitBreaker() *targetCircuitBreaker {
+	r.circuitBreakerOnce.Do(func() {
+		r.circuitBreaker = newTargetCircuitBreaker(r.CircuitBreakerThreshold, r.CircuitBreakerCooldown)
+	})
+
+	return r.circuitBreaker
+}
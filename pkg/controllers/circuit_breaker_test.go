@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+func TestTargetCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	b := newTargetCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	b.recordFailure("local-cluster", "hd1", 1)
+	allowed, _ := b.allow("local-cluster")
+	assert.True(t, allowed, "true when the failures are below the threshold")
+
+	b.recordFailure("local-cluster", "hd1", 1)
+	allowed, _ = b.allow("local-cluster")
+	assert.True(t, allowed, "true when the same manifestwork generation is recorded again")
+
+	b.recordFailure("local-cluster", "hd2", 1)
+	allowed, retryAfter := b.allow("local-cluster")
+	assert.False(t, allowed, "false when the threshold is reached")
+	assert.Equal(t, time.Minute, retryAfter, "retry once the cooldown expires")
+
+	allowed, _ = b.allow("other-cluster")
+	assert.True(t, allowed, "true for a hosting cluster with no failures")
+
+	// half-open, a single trial is let through
+	now = now.Add(time.Minute)
+	allowed, _ = b.allow("local-cluster")
+	assert.True(t, allowed, "true for the trial reconcile after the cooldown")
+	allowed, _ = b.allow("local-cluster")
+	assert.False(t, allowed, "false while the trial reconcile is in flight")
+
+	b.recordFailure("local-cluster", "hd1", 2)
+	now = now.Add(30 * time.Second)
+	allowed, retryAfter = b.allow("local-cluster")
+	assert.False(t, allowed, "false when the trial reconcile failed")
+	assert.Equal(t, 30*time.Second, retryAfter, "cooldown restarts when the trial reconcile failed")
+
+	now = now.Add(30 * time.Second)
+	allowed, _ = b.allow("local-cluster")
+	assert.True(t, allowed, "true for the next trial reconcile")
+
+	b.recordFailure("local-cluster", "hd1", 2)
+	allowed, _ = b.allow("local-cluster")
+	assert.False(t, allowed, "false while the trial is in flight, its stale failure is not counted again")
+
+	// the hosting cluster recovers, the unchanged manifestwork keeps the generation that failed
+	b.recordSuccess("local-cluster", "hd1", 2)
+	b.recordFailure("local-cluster", "hd1", 3)
+	allowed, _ = b.allow("local-cluster")
+	assert.True(t, allowed, "true when the circuit closed and the failure count was reset")
+
+	// the success of a HypershiftDeployment does not reset the failures of the others
+	b.recordFailure("local-cluster", "hd2", 2)
+	b.recordSuccess("local-cluster", "hd3", 1)
+	allowed, _ = b.allow("local-cluster")
+	assert.False(t, allowed, "false when the other HypershiftDeployments keep failing")
+
+	b.forget("local-cluster", "hd1")
+	assert.Equal(t, 1, b.circuit("local-cluster").failureCount(), "the failures of a deleted HypershiftDeployment are dropped")
+
+	disabled := newTargetCircuitBreaker(0, time.Minute)
+	for i := 0; i < 5; i++ {
+		disabled.recordFailure("local-cluster", "hd1", int64(i))
+	}
+	allowed, _ = disabled.allow("local-cluster")
+	assert.True(t, allowed, "true when the circuit breaker is disabled")
+}
+
+func TestTargetClusterCircuitOpenCondition(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client:                  client,
+		Log:                     ctrl.Log.WithName("tester"),
+		CircuitBreakerThreshold: 2,
+		CircuitBreakerCooldown:  time.Minute,
+	}
+
+	now := time.Now()
+	hdr.targetCircuitBreaker().now = func() time.Time { return now }
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	// a new manifestwork generation is written when bumped, each status of a generation is only recorded once
+	setApplied := func(status metav1.ConditionStatus, bump bool) {
+		var mw workv1.ManifestWork
+		assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+		if bump {
+			mw.Generation++
+			assert.Nil(t, client.Update(ctx, &mw), "is nil when the manifestwork is updated")
+		}
+		meta.SetStatusCondition(&mw.Status.Conditions, metav1.Condition{
+			Type:               workv1.WorkApplied,
+			Status:             status,
+			ObservedGeneration: mw.Generation,
+			Reason:             "AppliedManifestWorkComplete",
+		})
+		assert.Nil(t, client.Status().Update(ctx, &mw), "is nil when the manifestwork status is updated")
+	}
+
+	circuitCondition := func() *metav1.Condition {
+		var resultHD hyd.HypershiftDeployment
+		assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+		return meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.TargetClusterCircuitOpen))
+	}
+
+	setApplied(metav1.ConditionFalse, true)
+
+	res, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Zero(t, res.RequeueAfter, "no requeue below the threshold")
+	assert.Nil(t, circuitCondition(), "circuit is not reported below the threshold")
+
+	res, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Zero(t, res.RequeueAfter, "the same failed generation is not counted twice")
+	assert.Nil(t, circuitCondition(), "circuit is not reported below the threshold")
+
+	setApplied(metav1.ConditionFalse, true)
+
+	res, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Equal(t, time.Minute, res.RequeueAfter, "requeue once the cooldown expires")
+
+	c := circuitCondition()
+	assert.NotNil(t, c, "TargetClusterCircuitOpen condition is reported")
+	assert.Equal(t, metav1.ConditionTrue, c.Status, "true when the threshold is reached")
+	assert.Equal(t, hyd.CircuitOpenReason, c.Reason)
+
+	// half-open recovery, the trial reconcile goes through and the hosting cluster applies the work
+	now = now.Add(time.Minute)
+	res, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Zero(t, res.RequeueAfter, "the trial reconcile is let through")
+
+	// the hosting cluster recovers and applies the unchanged manifestwork
+	setApplied(metav1.ConditionTrue, false)
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	c = circuitCondition()
+	assert.Equal(t, metav1.ConditionFalse, c.Status, "false once the hosting cluster applies the work")
+	allowed, _ := hdr.targetCircuitBreaker().allow("local-cluster")
+	assert.True(t, allowed, "true when the circuit is closed")
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+// staleConditionRetention is how long a resolved warning stays in the status before it is pruned
+const staleConditionRetention = time.Hour
+
+// knownConditionTypes are the conditions reported by this release, any other one was left by an older release
+var knownConditionTypes = sets.NewString(
+	string(hypdeployment.PlatformConfigured),
+	string(hypdeployment.PlatformIAMConfigured),
+	string(hypdeployment.ProviderSecretConfigured),
+	string(hypdeployment.HostedClusterAvailable),
+	string(hypdeployment.HostedClusterProgress),
+	string(hypdeployment.Nodepool),
+	string(hypdeployment.WorkProgressing),
+	string(hypdeployment.WorkApplied),
+	string(hypdeployment.WorkAvailable),
+	string(hypdeployment.WorkDegraded),
+	string(hypdeployment.WorkConfigured),
+	string(hypdeployment.SubnetZoneConflict),
+	string(hypdeployment.TargetClusterCircuitOpen),
+	string(hypdeployment.VersionSkewViolation),
+	string(hypdeployment.PullSecretTypeMismatch),
+	string(hypdeployment.DeferredUntil),
+	string(hypdeployment.MachineCIDROutOfRange),
+	string(hypdeployment.PropagatedSecretTooLarge),
+	string(hypdeployment.InsufficientPermissions),
+	string(hypdeployment.FeedbackStale),
+	string(hypdeployment.ImageRegistryDisabled),
+	string(hypdeployment.PhaseNotificationFailed),
+	string(hypdeployment.DeleteOptionIneffective),
+	string(hypdeployment.MissingPlatformCredentials),
+	string(hypdeployment.ManifestWorkConflict),
+	string(hypdeployment.HighAvailabilityUnmet),
+	string(hypdeployment.ReconcileThrottled),
+	string(hypdeployment.SpecInvalid),
+	string(hypdeployment.ValidConfiguration),
+	string(hypdeployment.NodePoolsRemoved),
+	string(hypdeployment.ManifestApplied),
+	string(hypdeployment.DeprovisionStuck),
+	string(hypdeployment.WorkAPIUnavailable),
+	string(hypdeployment.PullSecretMissing),
+	string(hypdeployment.UnmetDependencies),
+	string(hypdeployment.LargeScaleDownBlocked),
+	string(hypdeployment.OrphanedSpokeResources),
+	string(hypdeployment.UnsupportedPlatform),
+	string(hypdeployment.Ready),
+	string(hypdeployment.ConflictingAnnotations),
+	string(hypdeployment.Conflict),
+)
+
+// resolvedWarningTypes are the warnings raised while a problem lasts and set to false once it is resolved
+var resolvedWarningTypes = sets.NewString(
+	string(hypdeployment.SubnetZoneConflict),
+	string(hypdeployment.TargetClusterCircuitOpen),
+	string(hypdeployment.VersionSkewViolation),
+	string(hypdeployment.PullSecretTypeMismatch),
+	string(hypdeployment.DeferredUntil),
+	string(hypdeployment.MachineCIDROutOfRange),
+	string(hypdeployment.PropagatedSecretTooLarge),
+	string(hypdeployment.InsufficientPermissions),
+	string(hypdeployment.FeedbackStale),
+	string(hypdeployment.PhaseNotificationFailed),
+	string(hypdeployment.DeleteOptionIneffective),
+	string(hypdeployment.MissingPlatformCredentials),
+	string(hypdeployment.ManifestWorkConflict),
+	string(hypdeployment.HighAvailabilityUnmet),
+	string(hypdeployment.ReconcileThrottled),
+	string(hypdeployment.SpecInvalid),
+	string(hypdeployment.NodePoolsRemoved),
+	string(hypdeployment.DeprovisionStuck),
+	string(hypdeployment.WorkAPIUnavailable),
+	string(hypdeployment.PullSecretMissing),
+	string(hypdeployment.UnmetDependencies),
+	string(hypdeployment.LargeScaleDownBlocked),
+	string(hypdeployment.OrphanedSpokeResources),
+	string(hypdeployment.UnsupportedPlatform),
+	string(hypdeployment.ConflictingAnnotations),
+	string(hypdeployment.Conflict),
+)
+
+// staleConditions lists the conditions no longer relevant to the HypershiftDeployment:
+//   - a condition type this release does not report, or the condition of a removed feedback rule
+//   - the NodePool condition once the HypershiftDeployment has no NodePool
+//   - a warning resolved for longer than staleConditionRetention
+func staleConditions(hyd *hypdeployment.HypershiftDeployment, now time.Time) []string {
+	rules := sets.NewString()
+	for _, r := range hyd.Spec.FeedbackRules {
+		rules.Insert(string(feedbackRuleConditionType(r)))
+	}
+
+	stale := []string{}
+	for _, c := range hyd.Status.Conditions {
+		unknown := !knownConditionTypes.Has(c.Type) && !rules.Has(c.Type)
+		noNodePool := c.Type == string(hypdeployment.Nodepool) && len(hyd.Spec.NodePools) == 0 && len(hyd.Spec.NodePoolsRef) == 0
+		resolved := resolvedWarningTypes.Has(c.Type) && c.Status == metav1.ConditionFalse &&
+			now.Sub(c.LastTransitionTime.Time) > staleConditionRetention
+
+		if unknown || noNodePool || resolved {
+			stale = append(stale, c.Type)
+		}
+	}
+
+	return stale
+}
+
+// pruneStaleConditions removes the stale conditions from the status of the HypershiftDeployment
+func (r *HypershiftDeploymentReconciler) pruneStaleConditions(ctx context.Context, hyd *hypdeployment.HypershiftDeployment) error {
+	stale := staleConditions(hyd, r.currentTime())
+	if len(stale) == 0 {
+		return nil
+	}
+
+	patch := client.MergeFrom(hyd.DeepCopy())
+	for _, t := range stale {
+		meta.RemoveStatusCondition(&hyd.Status.Conditions, t)
+	}
+
+	r.Log.Info(fmt.Sprintf("Pruning the stale conditions: %s", strings.Join(stale, ", ")))
+	return client.IgnoreNotFound(r.Client.Status().Patch(ctx, hyd, patch))
+}
@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+func condition(t hyd.ConditionType, status metav1.ConditionStatus, transition time.Time) metav1.Condition {
+	return metav1.Condition{
+		Type:               string(t),
+		Status:             status,
+		Reason:             hyd.AsExpectedReason,
+		LastTransitionTime: metav1.NewTime(transition),
+	}
+}
+
+func TestStaleConditions(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	testHD := getHDforManifestWork()
+	testHD.Spec.FeedbackRules = []hyd.FeedbackRule{{Resource: hyd.FeedbackHostedCluster, Name: "EtcdReady", Path: ".status.etcd"}}
+	testHD.Status.Conditions = []metav1.Condition{
+		condition(hyd.WorkConfigured, metav1.ConditionTrue, now.Add(-48*time.Hour)),
+		condition(hyd.Nodepool, metav1.ConditionTrue, now.Add(-48*time.Hour)),
+		condition("HostedClusterEtcdReady", metav1.ConditionTrue, now.Add(-48*time.Hour)),
+		condition("HostedClusterRemovedRule", metav1.ConditionTrue, now),
+		condition("LegacyCondition", metav1.ConditionTrue, now),
+		condition(hyd.VersionSkewViolation, metav1.ConditionTrue, now.Add(-48*time.Hour)),
+		condition(hyd.SubnetZoneConflict, metav1.ConditionFalse, now.Add(-30*time.Minute)),
+		condition(hyd.NodePoolsRemoved, metav1.ConditionFalse, now.Add(-2*time.Hour)),
+		condition(hyd.ManifestApplied, metav1.ConditionFalse, now.Add(-2*time.Hour)),
+	}
+
+	assert.Equal(t, []string{"HostedClusterRemovedRule", "LegacyCondition", string(hyd.NodePoolsRemoved)}, staleConditions(testHD, now),
+		"the unknown conditions, the removed feedback rules and the long resolved warnings are stale")
+
+	testHD.Spec.NodePools = nil
+	assert.Contains(t, staleConditions(testHD, now), string(hyd.Nodepool), "the NodePool condition is stale without NodePools")
+}
+
+func TestPruneStaleConditions(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	now := time.Now()
+	testHD.Status.Conditions = []metav1.Condition{
+		condition("LegacyCondition", metav1.ConditionTrue, now),
+		condition(hyd.PullSecretMissing, metav1.ConditionFalse, now.Add(-2*time.Hour)),
+		condition(hyd.DeferredUntil, metav1.ConditionFalse, now),
+	}
+	assert.Nil(t, client.Status().Update(ctx, testHD), "the status is updated")
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+		now:    func() time.Time { return now },
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.Nil(t, meta.FindStatusCondition(resultHD.Status.Conditions, "LegacyCondition"), "the unknown condition is pruned")
+	assert.Nil(t, meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.PullSecretMissing)), "the long resolved warning is pruned")
+	assert.NotNil(t, meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.DeferredUntil)), "the recently resolved warning is kept")
+	assert.True(t, meta.IsStatusConditionTrue(resultHD.Status.Conditions, string(hyd.WorkConfigured)), "the current conditions are kept")
+}
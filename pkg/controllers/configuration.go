@@ -18,22 +18,31 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
+	configv1 "github.com/openshift/api/config/v1"
 	apifixtures "github.com/openshift/hypershift/api/fixtures"
 	hyp "github.com/openshift/hypershift/api/v1alpha1"
 	"github.com/pkg/errors"
 
 	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
 	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	workv1 "open-cluster-management.io/api/work/v1"
 )
 
+// maxConfigurationReferences bounds the secrets, and separately the configMaps, copied to the manifestwork payload
+// by a gathering step
+const maxConfigurationReferences = 100
+
 type override func(obj metav1.Object)
 
 func overrideNamespace(hostingNamespace string) override {
@@ -55,6 +64,7 @@ func duplicateSecretWithOverride(in *corev1.Secret, ops ...override) *corev1.Sec
 
 	out.SetName(in.GetName())
 	out.SetLabels(in.GetLabels())
+	out.SetAnnotations(sourceSecretAnnotations(in))
 	out.Data = in.Data
 
 	for _, o := range ops {
@@ -64,6 +74,27 @@ func duplicateSecretWithOverride(in *corev1.Secret, ops ...override) *corev1.Sec
 	return out
 }
 
+// sourceSecretAnnotations returns the annotations tracing a propagated secret back to its hub secret. A secret read
+// from the hub is the source, a copy keeps the source of the secret it was copied from, a scaffolded secret has none
+func sourceSecretAnnotations(in *corev1.Secret) map[string]string {
+	if len(in.GetResourceVersion()) != 0 {
+		return map[string]string{
+			constant.SourceSecretAnnotation:          in.GetNamespace() + "/" + in.GetName(),
+			constant.SourceResourceVersionAnnotation: in.GetResourceVersion(),
+		}
+	}
+
+	source, ok := in.GetAnnotations()[constant.SourceSecretAnnotation]
+	if !ok {
+		return nil
+	}
+
+	return map[string]string{
+		constant.SourceSecretAnnotation:          source,
+		constant.SourceResourceVersionAnnotation: in.GetAnnotations()[constant.SourceResourceVersionAnnotation],
+	}
+}
+
 func (r *HypershiftDeploymentReconciler) generateSecret(ctx context.Context, key types.NamespacedName, ops ...override) (*corev1.Secret, error) {
 	origin := &corev1.Secret{}
 	if err := r.Get(ctx, key, origin); err != nil {
@@ -83,6 +114,8 @@ func duplicateConfigMapWithOverride(in *corev1.ConfigMap, ops ...override) *core
 
 	out.SetName(in.GetName())
 	out.SetLabels(in.GetLabels())
+	out.Data = in.Data
+	out.BinaryData = in.BinaryData
 
 	for _, o := range ops {
 		o(out)
@@ -115,8 +148,11 @@ func (r *HypershiftDeploymentReconciler) ensureConfiguration(ctx context.Context
 		//source:
 		// hyd.Spec.HostedClusterSpec.Configuration.SecretRefs
 		// hyd.Spec.HostedClusterSpec.SecretEncryption.KMS.AWS.Auth
+		// hyd.Spec.HostedClusterSpec.SecretEncryption.KMS.IBMCloud.Auth.Unmanaged
 		// hyd.Spec.HostedClusterSpec.SecretEncryption.AESCBC.ActiveKey
 		// hyd.Spec.HostedClusterSpec.SecretEncryption.AESCBC.BackupKey
+		// hyd.Spec.HostedClusterSpec.Etcd.Unmanaged.TLS.ClientSecret
+		// hyd.Spec.HostedClusterSpec.AuditWebhook
 		secretRefs := []secretResource{}
 
 		//source:
@@ -181,6 +217,20 @@ func (r *HypershiftDeploymentReconciler) ensureConfiguration(ctx context.Context
 				if encr.Type == hyp.KMS && encr.KMS != nil && encr.KMS.AWS != nil && len(encr.KMS.AWS.Auth.Credentials.Name) != 0 {
 					secretRefs = append(secretRefs, secretResource{secretRef: encr.KMS.AWS.Auth.Credentials})
 				}
+				if encr.Type == hyp.KMS && encr.KMS != nil && encr.KMS.IBMCloud != nil && encr.KMS.IBMCloud.Auth.Unmanaged != nil &&
+					len(encr.KMS.IBMCloud.Auth.Unmanaged.Credentials.Name) != 0 {
+					secretRefs = append(secretRefs, secretResource{secretRef: encr.KMS.IBMCloud.Auth.Unmanaged.Credentials})
+				}
+			}
+
+			// Managed etcd storage is part of the HostedCluster spec and the pinned API has no restore from a snapshot,
+			// so only an unmanaged etcd references a secret, its client TLS secret
+			if hcSpec.Etcd.ManagementType == hyp.Unmanaged && hcSpec.Etcd.Unmanaged != nil && len(hcSpec.Etcd.Unmanaged.TLS.ClientSecret.Name) != 0 {
+				secretRefs = append(secretRefs, secretResource{secretRef: hcSpec.Etcd.Unmanaged.TLS.ClientSecret})
+			}
+
+			if hcSpec.AuditWebhook != nil && len(hcSpec.AuditWebhook.Name) != 0 {
+				secretRefs = append(secretRefs, secretResource{secretRef: *hcSpec.AuditWebhook})
 			}
 
 			if hcSpec.AdditionalTrustBundle != nil && len(hcSpec.AdditionalTrustBundle.Name) != 0 {
@@ -192,7 +242,8 @@ func (r *HypershiftDeploymentReconciler) ensureConfiguration(ctx context.Context
 			}
 
 			// Get AWS secrets externally for configure=F and using objectRef
-			if !hyd.Spec.Infrastructure.Configure && len(hyd.Spec.HostedClusterRef.Name) != 0 && hcSpec.Platform.AWS != nil {
+			if !hyd.Spec.Infrastructure.Configure && len(hyd.Spec.HostedClusterRef.Name) != 0 &&
+				hcSpec.Platform.Type != hyp.NonePlatform && hcSpec.Platform.AWS != nil {
 				if len(hcSpec.Platform.AWS.ControlPlaneOperatorCreds.Name) != 0 {
 					secretRefs = append(secretRefs, secretResource{secretRef: hcSpec.Platform.AWS.ControlPlaneOperatorCreds})
 				}
@@ -213,6 +264,31 @@ func (r *HypershiftDeploymentReconciler) ensureConfiguration(ctx context.Context
 			}
 		}
 
+		// Only the references of the HostedCluster and NodePools are gathered, the copied secrets and configMaps are
+		// never read for further references, so a reference loop between them can not make the gathering recurse
+		// A secret the controller can generate is not the same resource as a reference that must exist on the hub
+		uniqueSecretRefs := []secretResource{}
+		secretNames := sets.NewString()
+		for _, se := range secretRefs {
+			if se.createSecretFunc == nil {
+				if secretNames.Has(se.secretRef.Name) {
+					continue
+				}
+				secretNames.Insert(se.secretRef.Name)
+			}
+			uniqueSecretRefs = append(uniqueSecretRefs, se)
+		}
+		secretRefs = uniqueSecretRefs
+		if len(secretRefs) > maxConfigurationReferences {
+			allErr = append(allErr, fmt.Errorf("%d secrets are referenced, only the first %d are copied", len(secretRefs), maxConfigurationReferences))
+			secretRefs = secretRefs[:maxConfigurationReferences]
+		}
+
+		configMapRefs, err := boundedReferences("configMaps", configMapRefs)
+		if err != nil {
+			allErr = append(allErr, err)
+		}
+
 		for _, se := range secretRefs {
 			// 1. Use user provided secret
 			k := genKey(se.secretRef, hyd)
@@ -225,6 +301,20 @@ func (r *HypershiftDeploymentReconciler) ensureConfiguration(ctx context.Context
 				// 2. Use existing secret in manifestwork payload
 				secret, err = getManifestPayloadSecretByName(&manifestwork.Spec.Workload.Manifests, se.secretRef.Name)
 
+				// the secret was transformed, keep the transformed object of the manifestwork
+				if secret == nil {
+					transformed, err := getTransformedSecretInManifestPayload(&manifestwork.Spec.Workload.Manifests, se.secretRef.Name)
+					if err != nil {
+						allErr = append(allErr, err)
+						continue
+					}
+
+					if transformed != nil {
+						*payload = append(*payload, workv1.Manifest{RawExtension: runtime.RawExtension{Object: transformed}})
+						continue
+					}
+				}
+
 				if secret == nil &&
 					hyd.Spec.Infrastructure.Configure &&
 					se.createSecretFunc != nil {
@@ -258,6 +348,437 @@ func (r *HypershiftDeploymentReconciler) ensureConfiguration(ctx context.Context
 	}
 }
 
+// appendNodePoolTuningConfigs copies the TuneD profile ConfigMaps referenced by spec.tuningConfig of the NodePools
+// to the hosting namespace, a ConfigMap referenced by several NodePools or already in the payload is added once
+func (r *HypershiftDeploymentReconciler) appendNodePoolTuningConfigs(ctx context.Context) loadManifest {
+	return func(hyd *hypdeployment.HypershiftDeployment, payload *[]workv1.Manifest) error {
+		added := sets.NewString()
+		refs := []corev1.LocalObjectReference{}
+
+		for _, m := range *payload {
+			if cm, ok := m.Object.(*corev1.ConfigMap); ok {
+				added.Insert(cm.Name)
+			}
+		}
+
+		for _, m := range *payload {
+			np, ok := m.Object.(*unstructured.Unstructured)
+			if !ok || np.GetKind() != "NodePool" {
+				continue
+			}
+
+			tuningConfig, _, err := unstructured.NestedSlice(np.Object, "spec", "tuningConfig")
+			if err != nil {
+				return fmt.Errorf("failed to read the tuningConfig of NodePool %s, err: %w", np.GetName(), err)
+			}
+
+			for _, t := range tuningConfig {
+				ref, ok := t.(map[string]interface{})
+				if !ok {
+					continue
+				}
+
+				name, _, _ := unstructured.NestedString(ref, "name")
+				if len(name) == 0 || added.Has(name) {
+					continue
+				}
+
+				added.Insert(name)
+				refs = append(refs, corev1.LocalObjectReference{Name: name})
+			}
+		}
+
+		for _, ref := range refs {
+			k := genKey(ref, hyd)
+			cm, err := r.generateConfigMap(ctx, k, overrideNamespace(helper.GetHostingNamespace(hyd)))
+			if err != nil {
+				r.Log.Error(err, fmt.Sprintf("failed to copy tuning configMap %s", k))
+				return err
+			}
+
+			*payload = append(*payload, workv1.Manifest{RawExtension: runtime.RawExtension{Object: cm}})
+		}
+
+		return nil
+	}
+}
+
+// boundedReferences removes the duplicated references and keeps at most maxConfigurationReferences of them,
+// an error reports the dropped references
+func boundedReferences(kind string, refs []corev1.LocalObjectReference) ([]corev1.LocalObjectReference, error) {
+	names := sets.NewString()
+	out := []corev1.LocalObjectReference{}
+	for _, ref := range refs {
+		if len(ref.Name) == 0 || names.Has(ref.Name) {
+			continue
+		}
+
+		names.Insert(ref.Name)
+		out = append(out, ref)
+	}
+
+	if len(out) > maxConfigurationReferences {
+		return out[:maxConfigurationReferences], fmt.Errorf("%d %s are referenced, only the first %d are copied", len(out), kind, maxConfigurationReferences)
+	}
+
+	return out, nil
+}
+
 func genKey(r corev1.LocalObjectReference, hyd *hypdeployment.HypershiftDeployment) types.NamespacedName {
 	return types.NamespacedName{Name: r.Name, Namespace: hyd.GetNamespace()}
 }
+
+// getOAuthInConfigurationItems returns the OAuth cluster configuration carried by the HostedCluster
+// configuration items, nil is returned when no OAuth configuration is present
+func getOAuthInConfigurationItems(hcSpec *hyp.HostedClusterSpec) (*configv1.OAuth, error) {
+	if hcSpec == nil || hcSpec.Configuration == nil {
+		return nil, nil
+	}
+
+	for _, item := range hcSpec.Configuration.Items {
+		if len(item.Raw) == 0 {
+			continue
+		}
+
+		tm := metav1.TypeMeta{}
+		if err := json.Unmarshal(item.Raw, &tm); err != nil {
+			return nil, fmt.Errorf("failed to decode HostedCluster configuration item, err: %w", err)
+		}
+
+		if tm.Kind != "OAuth" || tm.GroupVersionKind().Group != configv1.GroupName {
+			continue
+		}
+
+		oauth := &configv1.OAuth{}
+		if err := json.Unmarshal(item.Raw, oauth); err != nil {
+			return nil, fmt.Errorf("failed to decode OAuth configuration item, err: %w", err)
+		}
+
+		return oauth, nil
+	}
+
+	return nil, nil
+}
+
+// identityProviderReferences lists the secrets and configMaps referenced by the OAuth configuration,
+// including every identity provider and the login templates
+func identityProviderReferences(oauth *configv1.OAuth) ([]corev1.LocalObjectReference, []corev1.LocalObjectReference) {
+	secretRefs := []corev1.LocalObjectReference{}
+	configMapRefs := []corev1.LocalObjectReference{}
+
+	if oauth == nil {
+		return secretRefs, configMapRefs
+	}
+
+	addSecret := func(ref configv1.SecretNameReference) {
+		if len(ref.Name) != 0 {
+			secretRefs = append(secretRefs, corev1.LocalObjectReference{Name: ref.Name})
+		}
+	}
+
+	addConfigMap := func(ref configv1.ConfigMapNameReference) {
+		if len(ref.Name) != 0 {
+			configMapRefs = append(configMapRefs, corev1.LocalObjectReference{Name: ref.Name})
+		}
+	}
+
+	addRemoteConnection := func(info configv1.OAuthRemoteConnectionInfo) {
+		addConfigMap(info.CA)
+		addSecret(info.TLSClientCert)
+		addSecret(info.TLSClientKey)
+	}
+
+	for _, idp := range oauth.Spec.IdentityProviders {
+		switch {
+		case idp.BasicAuth != nil:
+			addRemoteConnection(idp.BasicAuth.OAuthRemoteConnectionInfo)
+		case idp.GitHub != nil:
+			addSecret(idp.GitHub.ClientSecret)
+			addConfigMap(idp.GitHub.CA)
+		case idp.GitLab != nil:
+			addSecret(idp.GitLab.ClientSecret)
+			addConfigMap(idp.GitLab.CA)
+		case idp.Google != nil:
+			addSecret(idp.Google.ClientSecret)
+		case idp.HTPasswd != nil:
+			addSecret(idp.HTPasswd.FileData)
+		case idp.Keystone != nil:
+			addRemoteConnection(idp.Keystone.OAuthRemoteConnectionInfo)
+		case idp.LDAP != nil:
+			addSecret(idp.LDAP.BindPassword)
+			addConfigMap(idp.LDAP.CA)
+		case idp.OpenID != nil:
+			addSecret(idp.OpenID.ClientSecret)
+			addConfigMap(idp.OpenID.CA)
+		case idp.RequestHeader != nil:
+			addConfigMap(idp.RequestHeader.ClientCA)
+		}
+	}
+
+	addSecret(oauth.Spec.Templates.Login)
+	addSecret(oauth.Spec.Templates.ProviderSelection)
+	addSecret(oauth.Spec.Templates.Error)
+
+	return secretRefs, configMapRefs
+}
+
+// appendIdentityProviderReferences copies the secrets and configMaps referenced by the OAuth identity
+// providers of the HostedCluster to the manifestwork payload, resources already in the payload are skipped
+func (r *HypershiftDeploymentReconciler) appendIdentityProviderReferences(ctx context.Context) loadManifest {
+	return func(hyd *hypdeployment.HypershiftDeployment, payload *[]workv1.Manifest) error {
+		hostedCluster := getHostedClusterInManifestPayload(payload)
+		if hostedCluster == nil {
+			return nil
+		}
+
+		oauth, err := getOAuthInConfigurationItems(&hostedCluster.Spec)
+		if err != nil {
+			return err
+		}
+
+		secretRefs, configMapRefs := identityProviderReferences(oauth)
+
+		var allErr []error
+		secretRefs, err = boundedReferences("identity provider secrets", secretRefs)
+		if err != nil {
+			allErr = append(allErr, err)
+		}
+
+		configMapRefs, err = boundedReferences("identity provider configMaps", configMapRefs)
+		if err != nil {
+			allErr = append(allErr, err)
+		}
+
+		for _, ref := range secretRefs {
+			if isInManifestPayload(payload, "Secret", ref.Name) {
+				continue
+			}
+
+			k := genKey(ref, hyd)
+			secret, err := r.generateSecret(ctx, k, overrideNamespace(helper.GetHostingNamespace(hyd)))
+			if err != nil {
+				r.Log.Error(err, fmt.Sprintf("failed to copy identity provider secret %s", k))
+				allErr = append(allErr, err)
+				continue
+			}
+
+			*payload = append(*payload, workv1.Manifest{RawExtension: runtime.RawExtension{Object: secret}})
+		}
+
+		for _, ref := range configMapRefs {
+			if isInManifestPayload(payload, "ConfigMap", ref.Name) {
+				continue
+			}
+
+			k := genKey(ref, hyd)
+			cm, err := r.generateConfigMap(ctx, k, overrideNamespace(helper.GetHostingNamespace(hyd)))
+			if err != nil {
+				r.Log.Error(err, fmt.Sprintf("failed to copy identity provider configMap %s", k))
+				allErr = append(allErr, err)
+				continue
+			}
+
+			*payload = append(*payload, workv1.Manifest{RawExtension: runtime.RawExtension{Object: cm}})
+		}
+
+		return utilerrors.NewAggregate(allErr)
+	}
+}
+
+// operatorConfigurationReferences lists the secrets and configMaps referenced by the cluster operator configurations
+// of the HostedCluster configuration items, the OAuth configuration is handled with the identity providers
+func operatorConfigurationReferences(hcSpec *hyp.HostedClusterSpec) ([]corev1.LocalObjectReference, []corev1.LocalObjectReference, error) {
+	secretRefs := []corev1.LocalObjectReference{}
+	configMapRefs := []corev1.LocalObjectReference{}
+
+	if hcSpec == nil || hcSpec.Configuration == nil {
+		return secretRefs, configMapRefs, nil
+	}
+
+	addSecret := func(ref configv1.SecretNameReference) {
+		if len(ref.Name) != 0 {
+			secretRefs = append(secretRefs, corev1.LocalObjectReference{Name: ref.Name})
+		}
+	}
+
+	addConfigMap := func(ref configv1.ConfigMapNameReference) {
+		if len(ref.Name) != 0 {
+			configMapRefs = append(configMapRefs, corev1.LocalObjectReference{Name: ref.Name})
+		}
+	}
+
+	for _, item := range hcSpec.Configuration.Items {
+		if len(item.Raw) == 0 {
+			continue
+		}
+
+		tm := metav1.TypeMeta{}
+		if err := json.Unmarshal(item.Raw, &tm); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode HostedCluster configuration item, err: %w", err)
+		}
+
+		if tm.GroupVersionKind().Group != configv1.GroupName {
+			continue
+		}
+
+		var err error
+		switch tm.Kind {
+		case "APIServer":
+			cfg := &configv1.APIServer{}
+			if err = json.Unmarshal(item.Raw, cfg); err == nil {
+				addConfigMap(cfg.Spec.ClientCA)
+				for _, cert := range cfg.Spec.ServingCerts.NamedCertificates {
+					addSecret(cert.ServingCertificate)
+				}
+			}
+		case "Authentication":
+			cfg := &configv1.Authentication{}
+			if err = json.Unmarshal(item.Raw, cfg); err == nil {
+				addConfigMap(cfg.Spec.OAuthMetadata)
+				if cfg.Spec.WebhookTokenAuthenticator != nil {
+					addSecret(cfg.Spec.WebhookTokenAuthenticator.KubeConfig)
+				}
+			}
+		case "Build":
+			cfg := &configv1.Build{}
+			if err = json.Unmarshal(item.Raw, cfg); err == nil {
+				addConfigMap(cfg.Spec.AdditionalTrustedCA)
+			}
+		case "Image":
+			cfg := &configv1.Image{}
+			if err = json.Unmarshal(item.Raw, cfg); err == nil {
+				addConfigMap(cfg.Spec.AdditionalTrustedCA)
+			}
+		case "Proxy":
+			cfg := &configv1.Proxy{}
+			if err = json.Unmarshal(item.Raw, cfg); err == nil {
+				addConfigMap(cfg.Spec.TrustedCA)
+			}
+		case "Scheduler":
+			cfg := &configv1.Scheduler{}
+			if err = json.Unmarshal(item.Raw, cfg); err == nil {
+				addConfigMap(cfg.Spec.Policy)
+			}
+		}
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode %s configuration item, err: %w", tm.Kind, err)
+		}
+	}
+
+	return secretRefs, configMapRefs, nil
+}
+
+// appendOperatorConfigurationReferences copies the secrets and configMaps referenced by the cluster operator
+// configurations of the HostedCluster to the manifestwork payload, resources already in the payload are skipped.
+// HyperShift only syncs the resources listed in the configuration secretRefs and configMapRefs to the hosted
+// cluster, the references missing from the lists are added to the HostedCluster of the payload.
+func (r *HypershiftDeploymentReconciler) appendOperatorConfigurationReferences(ctx context.Context) loadManifest {
+	return func(hyd *hypdeployment.HypershiftDeployment, payload *[]workv1.Manifest) error {
+		hostedCluster := getHostedClusterInManifestPayload(payload)
+		if hostedCluster == nil {
+			return nil
+		}
+
+		secretRefs, configMapRefs, err := operatorConfigurationReferences(&hostedCluster.Spec)
+		if err != nil {
+			return err
+		}
+
+		var allErr []error
+		secretRefs, err = boundedReferences("operator configuration secrets", secretRefs)
+		if err != nil {
+			allErr = append(allErr, err)
+		}
+
+		configMapRefs, err = boundedReferences("operator configuration configMaps", configMapRefs)
+		if err != nil {
+			allErr = append(allErr, err)
+		}
+
+		if err := listConfigurationReferences(payload, secretRefs, configMapRefs); err != nil {
+			return err
+		}
+
+		for _, ref := range secretRefs {
+			if isInManifestPayload(payload, "Secret", ref.Name) {
+				continue
+			}
+
+			k := genKey(ref, hyd)
+			secret, err := r.generateSecret(ctx, k, overrideNamespace(helper.GetHostingNamespace(hyd)))
+			if err != nil {
+				r.Log.Error(err, fmt.Sprintf("failed to copy operator configuration secret %s", k))
+				allErr = append(allErr, err)
+				continue
+			}
+
+			*payload = append(*payload, workv1.Manifest{RawExtension: runtime.RawExtension{Object: secret}})
+		}
+
+		for _, ref := range configMapRefs {
+			if isInManifestPayload(payload, "ConfigMap", ref.Name) {
+				continue
+			}
+
+			k := genKey(ref, hyd)
+			cm, err := r.generateConfigMap(ctx, k, overrideNamespace(helper.GetHostingNamespace(hyd)))
+			if err != nil {
+				r.Log.Error(err, fmt.Sprintf("failed to copy operator configuration configMap %s", k))
+				allErr = append(allErr, err)
+				continue
+			}
+
+			*payload = append(*payload, workv1.Manifest{RawExtension: runtime.RawExtension{Object: cm}})
+		}
+
+		return utilerrors.NewAggregate(allErr)
+	}
+}
+
+// listConfigurationReferences adds the references missing from the configuration secretRefs and configMapRefs
+// of the HostedCluster of the payload, in the order they are referenced
+func listConfigurationReferences(payload *[]workv1.Manifest, secretRefs, configMapRefs []corev1.LocalObjectReference) error {
+	for _, m := range *payload {
+		hc, ok := m.Object.(*unstructured.Unstructured)
+		if !ok || hc.GetKind() != "HostedCluster" {
+			continue
+		}
+
+		for field, refs := range map[string][]corev1.LocalObjectReference{"secretRefs": secretRefs, "configMapRefs": configMapRefs} {
+			listed, _, err := unstructured.NestedSlice(hc.Object, "spec", "configuration", field)
+			if err != nil {
+				return fmt.Errorf("failed to read the configuration %s of HostedCluster %s, err: %w", field, hc.GetName(), err)
+			}
+
+			names := sets.NewString()
+			for _, l := range listed {
+				if ref, ok := l.(map[string]interface{}); ok {
+					name, _, _ := unstructured.NestedString(ref, "name")
+					names.Insert(name)
+				}
+			}
+
+			added := false
+			for _, ref := range refs {
+				if names.Has(ref.Name) {
+					continue
+				}
+
+				names.Insert(ref.Name)
+				listed = append(listed, map[string]interface{}{"name": ref.Name})
+				added = true
+			}
+
+			if !added {
+				continue
+			}
+
+			if err := unstructured.SetNestedSlice(hc.Object, listed, "spec", "configuration", field); err != nil {
+				return fmt.Errorf("failed to list the configuration %s of HostedCluster %s, err: %w", field, hc.GetName(), err)
+			}
+		}
+	}
+
+	return nil
+}
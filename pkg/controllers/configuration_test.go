@@ -3,12 +3,18 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	configv1 "github.com/openshift/api/config/v1"
 	apifixtures "github.com/openshift/hypershift/api/fixtures"
 	hyp "github.com/openshift/hypershift/api/v1alpha1"
 	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
@@ -430,6 +436,64 @@ func TestHDKmsEncryptionSecret(t *testing.T) {
 	assert.Len(t, err.(utilerrors.Aggregate).Errors(), 1, "kms encryption secrets not found")
 }
 
+func TestHDIBMCloudKmsEncryptionSecret(t *testing.T) {
+	r := GetHypershiftDeploymentReconciler()
+	ctx := context.Background()
+
+	kmsSec := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ibm-kms-key",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			hyp.IBMCloudIAMAPIKeySecretKey: []byte(`api-key`),
+		},
+	}
+	err := r.Create(ctx, kmsSec)
+	defer r.Delete(ctx, kmsSec)
+	assert.Nil(t, err, "kms encryption secret should be created with no error")
+
+	// Test Unmanaged IBM Cloud kms - the credentials secret is copied
+	testHD := getHDforSecretEncryption(false)
+	scaffoldHostedClusterSpec(testHD)
+	testHD.Spec.HostedClusterSpec.SecretEncryption = &hyp.SecretEncryptionSpec{
+		Type: hyp.KMS,
+		KMS: &hyp.KMSSpec{
+			Provider: hyp.IBMCloud,
+			IBMCloud: &hyp.IBMCloudKMSSpec{
+				Region: "us-south",
+				Auth: hyp.IBMCloudKMSAuthSpec{
+					Type: hyp.IBMCloudKMSUnmanagedAuth,
+					Unmanaged: &hyp.IBMCloudKMSUnmanagedAuthSpec{
+						Credentials: corev1.LocalObjectReference{Name: "test-ibm-kms-key"},
+					},
+				},
+			},
+		},
+	}
+	m, err := scaffoldManifestwork(testHD)
+	assert.Nil(t, err)
+	payload := []workv1.Manifest{}
+	r.appendHostedCluster(ctx)(testHD, &payload)
+	err = r.ensureConfiguration(ctx, m)(testHD, &payload)
+	assert.Nil(t, err)
+	assert.Len(t, payload, 2, "2 manifestwork payload which is the hc & kms encryption secret")
+	payloadSec, _ := getManifestPayloadSecretByName(&payload, "test-ibm-kms-key")
+	assert.NotNil(t, payloadSec, "is not nil when kms secret is found")
+	assert.Equal(t, testHD.Spec.HostingNamespace, payloadSec.Namespace)
+
+	// Test Managed IBM Cloud kms - no credentials secret to copy
+	testHD.Spec.HostedClusterSpec.SecretEncryption.KMS.IBMCloud.Auth = hyp.IBMCloudKMSAuthSpec{
+		Type:    hyp.IBMCloudKMSManagedAuth,
+		Managed: &hyp.IBMCloudKMSManagedAuthSpec{},
+	}
+	payload = []workv1.Manifest{}
+	r.appendHostedCluster(ctx)(testHD, &payload)
+	err = r.ensureConfiguration(ctx, m)(testHD, &payload)
+	assert.Nil(t, err)
+	assert.Len(t, payload, 1, "only the hc, the managed kms has no credentials")
+}
+
 // Test configmap in nodepool is added to manifestwork payload
 func TestNodePoolConfigMaps(t *testing.T) {
 	client := initClient()
@@ -512,3 +576,538 @@ func TestNodePoolConfigMaps(t *testing.T) {
 	// Find nodepool configmap in payload
 	assert.True(t, containsInPayload(payload, cm, testHD.Spec.HostingNamespace), "true if configmap is found in the payload")
 }
+
+func TestNodePoolTuningConfigMaps(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-host"
+	testHD.Spec.HostingNamespace = "multicluster-engine"
+
+	for _, name := range []string{"tuned-hugepages", "tuned-realtime"} {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: testHD.GetNamespace(),
+			},
+			Data: map[string]string{
+				"tuning": "apiVersion: tuned.openshift.io/v1\nkind: Tuned",
+			},
+		}
+		client.Create(ctx, cm)
+		defer client.Delete(ctx, cm)
+	}
+
+	secondPool := testHD.Spec.NodePools[0].DeepCopy()
+	secondPool.Name = "test1-rt"
+	testHD.Spec.NodePools = append(testHD.Spec.NodePools, secondPool)
+	testHD.Spec.NodePools[0].TuningConfig = []corev1.LocalObjectReference{{Name: "tuned-hugepages"}}
+	testHD.Spec.NodePools[1].TuningConfig = []corev1.LocalObjectReference{{Name: "tuned-hugepages"}, {Name: "tuned-realtime"}}
+
+	payload := []workv1.Manifest{}
+	assert.Nil(t, hdr.appendNodePool(ctx)(testHD, &payload), "err nil when the NodePools are added")
+	assert.Nil(t, hdr.appendNodePoolTuningConfigs(ctx)(testHD, &payload), "err nil when the tuning configMaps are added")
+
+	tuningConfigs := map[string][]string{}
+	configMaps := map[string]int{}
+	for _, wl := range payload {
+		switch o := wl.Object.(type) {
+		case *unstructured.Unstructured:
+			refs, _, _ := unstructured.NestedSlice(o.Object, "spec", "tuningConfig")
+			for _, ref := range refs {
+				tuningConfigs[o.GetName()] = append(tuningConfigs[o.GetName()], ref.(map[string]interface{})["name"].(string))
+			}
+		case *corev1.ConfigMap:
+			assert.Equal(t, testHD.Spec.HostingNamespace, o.Namespace, "configMap is in the hosting namespace")
+			configMaps[o.Name]++
+		}
+	}
+
+	assert.Equal(t, []string{"tuned-hugepages"}, tuningConfigs["test1"], "tuningConfig is propagated to the NodePool")
+	assert.Equal(t, []string{"tuned-hugepages", "tuned-realtime"}, tuningConfigs["test1-rt"], "tuningConfig is propagated to the NodePool")
+	assert.Equal(t, map[string]int{"tuned-hugepages": 1, "tuned-realtime": 1}, configMaps, "each tuning configMap is added once")
+
+	// a missing tuning configMap fails the payload
+	testHD.Spec.NodePools[0].TuningConfig = []corev1.LocalObjectReference{{Name: "tuned-missing"}}
+	payload = []workv1.Manifest{}
+	assert.Nil(t, hdr.appendNodePool(ctx)(testHD, &payload), "err nil when the NodePools are added")
+	assert.NotNil(t, hdr.appendNodePoolTuningConfigs(ctx)(testHD, &payload), "err when the tuning configMap is missing")
+}
+
+// Test secrets referenced by the OAuth identity providers are added to manifestwork payload
+func TestIdentityProviderSecrets(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingNamespace = "multicluster-engine"
+
+	htpasswd := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "htpass-secret", Namespace: testHD.GetNamespace()},
+		Data:       map[string][]byte{"htpasswd": []byte("user:password")},
+	}
+	client.Create(ctx, htpasswd)
+	defer client.Delete(ctx, htpasswd)
+
+	oidc := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "oidc-client-secret", Namespace: testHD.GetNamespace()},
+		Data:       map[string][]byte{"clientSecret": []byte("oidc-secret")},
+	}
+	client.Create(ctx, oidc)
+	defer client.Delete(ctx, oidc)
+
+	oauth := &configv1.OAuth{
+		TypeMeta:   metav1.TypeMeta{Kind: "OAuth", APIVersion: configv1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.OAuthSpec{
+			IdentityProviders: []configv1.IdentityProvider{
+				{
+					Name: "htpasswd",
+					IdentityProviderConfig: configv1.IdentityProviderConfig{
+						Type:     configv1.IdentityProviderTypeHTPasswd,
+						HTPasswd: &configv1.HTPasswdIdentityProvider{FileData: configv1.SecretNameReference{Name: htpasswd.Name}},
+					},
+				},
+				{
+					Name: "oidc",
+					IdentityProviderConfig: configv1.IdentityProviderConfig{
+						Type: configv1.IdentityProviderTypeOpenID,
+						OpenID: &configv1.OpenIDIdentityProvider{
+							ClientID:     "hypershift",
+							ClientSecret: configv1.SecretNameReference{Name: oidc.Name},
+							Issuer:       "https://oidc.example.com",
+						},
+					},
+				},
+			},
+		},
+	}
+	raw, err := json.Marshal(oauth)
+	assert.Nil(t, err)
+	testHD.Spec.HostedClusterSpec.Configuration = &hyp.ClusterConfiguration{
+		Items: []runtime.RawExtension{{Raw: raw}},
+	}
+
+	payload := []workv1.Manifest{}
+	hdr.appendHostedCluster(ctx)(testHD, &payload)
+	err = hdr.appendIdentityProviderReferences(ctx)(testHD, &payload)
+	assert.Nil(t, err, "err nil when the identity provider secrets are found")
+	assert.Len(t, payload, 3, "3 manifestwork payload which is the hc, htpasswd & oidc secret")
+
+	for _, name := range []string{htpasswd.Name, oidc.Name} {
+		payloadSec, _ := getManifestPayloadSecretByName(&payload, name)
+		assert.NotNil(t, payloadSec, "is not nil when identity provider secret is found")
+		assert.Equal(t, testHD.Spec.HostingNamespace, payloadSec.Namespace, "secret is moved to the hosting namespace")
+	}
+
+	// secrets already in the payload are not duplicated
+	err = hdr.appendIdentityProviderReferences(ctx)(testHD, &payload)
+	assert.Nil(t, err)
+	assert.Len(t, payload, 3, "identity provider secrets are only loaded once")
+
+	// missing secret is reported
+	client.Delete(ctx, oidc)
+	payload = []workv1.Manifest{}
+	hdr.appendHostedCluster(ctx)(testHD, &payload)
+	err = hdr.appendIdentityProviderReferences(ctx)(testHD, &payload)
+	assert.Len(t, err.(utilerrors.Aggregate).Errors(), 1, "oidc client secret not found")
+}
+
+// Test the resources referenced by the cluster operator configurations are added to manifestwork payload
+func TestOperatorConfigurationReferences(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingNamespace = "multicluster-engine"
+
+	trustedCA := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "user-ca-bundle", Namespace: testHD.GetNamespace()},
+		Data:       map[string]string{"ca-bundle.crt": "-----BEGIN CERTIFICATE-----"},
+	}
+	client.Create(ctx, trustedCA)
+
+	servingCert := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-serving-cert", Namespace: testHD.GetNamespace()},
+		Data:       map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")},
+	}
+	client.Create(ctx, servingCert)
+
+	proxy := &configv1.Proxy{
+		TypeMeta:   metav1.TypeMeta{Kind: "Proxy", APIVersion: configv1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.ProxySpec{
+			HTTPSProxy: "https://proxy.example.com:3128",
+			TrustedCA:  configv1.ConfigMapNameReference{Name: trustedCA.Name},
+		},
+	}
+	apiServer := &configv1.APIServer{
+		TypeMeta:   metav1.TypeMeta{Kind: "APIServer", APIVersion: configv1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.APIServerSpec{
+			ServingCerts: configv1.APIServerServingCerts{
+				NamedCertificates: []configv1.APIServerNamedServingCert{
+					{Names: []string{"api.example.com"}, ServingCertificate: configv1.SecretNameReference{Name: servingCert.Name}},
+				},
+			},
+		},
+	}
+
+	items := []runtime.RawExtension{}
+	for _, o := range []interface{}{proxy, apiServer} {
+		raw, err := json.Marshal(o)
+		assert.Nil(t, err)
+		items = append(items, runtime.RawExtension{Raw: raw})
+	}
+	testHD.Spec.HostedClusterSpec.Configuration = &hyp.ClusterConfiguration{
+		ConfigMapRefs: []corev1.LocalObjectReference{{Name: trustedCA.Name}},
+		Items:         items,
+	}
+
+	payload := []workv1.Manifest{}
+	hdr.appendHostedCluster(ctx)(testHD, &payload)
+	err := hdr.appendOperatorConfigurationReferences(ctx)(testHD, &payload)
+	assert.Nil(t, err, "err nil when the operator configuration references are found")
+	assert.Len(t, payload, 3, "3 manifestwork payload which is the hc, trusted CA configMap & serving certificate secret")
+
+	payloadSec, _ := getManifestPayloadSecretByName(&payload, servingCert.Name)
+	if assert.NotNil(t, payloadSec, "is not nil when the serving certificate secret is found") {
+		assert.Equal(t, testHD.Spec.HostingNamespace, payloadSec.Namespace, "secret is moved to the hosting namespace")
+	}
+
+	var payloadCM *corev1.ConfigMap
+	for _, m := range payload {
+		if cm, ok := m.Object.(*corev1.ConfigMap); ok && cm.Name == trustedCA.Name {
+			payloadCM = cm
+		}
+	}
+	if assert.NotNil(t, payloadCM, "is not nil when the trusted CA configMap is found") {
+		assert.Equal(t, testHD.Spec.HostingNamespace, payloadCM.Namespace, "configMap is moved to the hosting namespace")
+		assert.Equal(t, trustedCA.Data, payloadCM.Data)
+	}
+
+	// the references are listed for HyperShift to sync them to the hosted cluster
+	hc := getHostedClusterInManifestPayload(&payload)
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: trustedCA.Name}}, hc.Spec.Configuration.ConfigMapRefs, "the listed reference is kept once")
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: servingCert.Name}}, hc.Spec.Configuration.SecretRefs, "the missing reference is listed")
+	assert.Len(t, hc.Spec.Configuration.Items, 2, "the configuration items are unchanged")
+
+	// resources already in the payload are not duplicated
+	err = hdr.appendOperatorConfigurationReferences(ctx)(testHD, &payload)
+	assert.Nil(t, err)
+	assert.Len(t, payload, 3, "operator configuration references are only loaded once")
+
+	// missing configMap is reported
+	client.Delete(ctx, trustedCA)
+	payload = []workv1.Manifest{}
+	hdr.appendHostedCluster(ctx)(testHD, &payload)
+	err = hdr.appendOperatorConfigurationReferences(ctx)(testHD, &payload)
+	assert.Len(t, err.(utilerrors.Aggregate).Errors(), 1, "trusted CA configMap not found")
+}
+
+// Test the proxy and additional trust bundle of the HypershiftDeployment replace the ones of the HostedClusterSpec
+// and their configMaps are added to the manifestwork payload
+func TestProxyAndAdditionalTrustBundle(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingNamespace = "multicluster-engine"
+
+	trustBundle := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "user-ca-bundle", Namespace: testHD.GetNamespace()},
+		Data:       map[string]string{"ca-bundle.crt": "-----BEGIN CERTIFICATE-----"},
+	}
+	client.Create(ctx, trustBundle)
+
+	proxyCA := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "proxy-ca", Namespace: testHD.GetNamespace()},
+		Data:       map[string]string{"ca-bundle.crt": "-----BEGIN CERTIFICATE-----"},
+	}
+	client.Create(ctx, proxyCA)
+
+	items := []runtime.RawExtension{}
+	for _, o := range []interface{}{
+		&configv1.Proxy{
+			TypeMeta:   metav1.TypeMeta{Kind: "Proxy", APIVersion: configv1.GroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Spec:       configv1.ProxySpec{HTTPSProxy: "https://old-proxy.example.com:3128"},
+		},
+		&configv1.Image{
+			TypeMeta:   metav1.TypeMeta{Kind: "Image", APIVersion: configv1.GroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		},
+	} {
+		raw, err := json.Marshal(o)
+		assert.Nil(t, err)
+		items = append(items, runtime.RawExtension{Raw: raw})
+	}
+	testHD.Spec.HostedClusterSpec.Configuration = &hyp.ClusterConfiguration{Items: items}
+	testHD.Spec.HostedClusterSpec.AdditionalTrustBundle = &corev1.LocalObjectReference{Name: "other-ca-bundle"}
+
+	testHD.Spec.Proxy = &hyd.Proxy{
+		HTTPProxy:  "http://proxy.example.com:3128",
+		HTTPSProxy: "http://proxy.example.com:3128",
+		NoProxy:    ".cluster.local,10.0.0.0/16",
+		TrustedCA:  &corev1.LocalObjectReference{Name: proxyCA.Name},
+	}
+	testHD.Spec.AdditionalTrustBundle = &corev1.LocalObjectReference{Name: trustBundle.Name}
+
+	m, err := scaffoldManifestwork(testHD)
+	assert.Nil(t, err)
+	payload := []workv1.Manifest{}
+	assert.Nil(t, hdr.appendHostedCluster(ctx)(testHD, &payload), "err nil when the hostedcluster is scaffolded")
+	assert.Nil(t, hdr.ensureConfiguration(ctx, m)(testHD, &payload), "err nil when the trust bundle is found")
+	assert.Nil(t, hdr.appendOperatorConfigurationReferences(ctx)(testHD, &payload), "err nil when the proxy CA is found")
+
+	hc := getHostedClusterInManifestPayload(&payload)
+	if assert.NotNil(t, hc, "is not nil when the hostedcluster is in the payload") {
+		assert.Equal(t, trustBundle.Name, hc.Spec.AdditionalTrustBundle.Name, "the trust bundle of the HypershiftDeployment wins")
+		assert.Equal(t, []corev1.LocalObjectReference{{Name: proxyCA.Name}}, hc.Spec.Configuration.ConfigMapRefs, "the proxy CA is listed")
+
+		proxies := []configv1.Proxy{}
+		for _, item := range hc.Spec.Configuration.Items {
+			p := configv1.Proxy{}
+			assert.Nil(t, json.Unmarshal(item.Raw, &p))
+			if p.Kind == "Proxy" {
+				proxies = append(proxies, p)
+			}
+		}
+		assert.Len(t, hc.Spec.Configuration.Items, 2, "the other configuration items are kept")
+		if assert.Len(t, proxies, 1, "the proxy of the HostedClusterSpec is replaced") {
+			assert.Equal(t, "cluster", proxies[0].Name)
+			assert.Equal(t, "http://proxy.example.com:3128", proxies[0].Spec.HTTPSProxy)
+			assert.Equal(t, ".cluster.local,10.0.0.0/16", proxies[0].Spec.NoProxy)
+			assert.Equal(t, proxyCA.Name, proxies[0].Spec.TrustedCA.Name)
+		}
+	}
+
+	for _, name := range []string{trustBundle.Name, proxyCA.Name} {
+		var payloadCM *corev1.ConfigMap
+		for _, m := range payload {
+			if cm, ok := m.Object.(*corev1.ConfigMap); ok && cm.Name == name {
+				payloadCM = cm
+			}
+		}
+		if assert.NotNil(t, payloadCM, "is not nil when the configMap %s is found", name) {
+			assert.Equal(t, testHD.Spec.HostingNamespace, payloadCM.Namespace, "configMap is moved to the hosting namespace")
+		}
+	}
+	assert.Len(t, payload, 3, "3 manifestwork payload which is the hc, the trust bundle & the proxy CA configMaps")
+}
+
+// Test the etcd configuration and its referenced secret are added to manifestwork payload
+func TestEtcdConfiguration(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingNamespace = "multicluster-engine"
+
+	storageClass := "gp3-csi"
+	size := resource.MustParse("16Gi")
+	testHD.Spec.HostedClusterSpec.Etcd = hyp.EtcdSpec{
+		ManagementType: hyp.Managed,
+		Managed: &hyp.ManagedEtcdSpec{
+			Storage: hyp.ManagedEtcdStorageSpec{
+				Type: hyp.PersistentVolumeEtcdStorage,
+				PersistentVolume: &hyp.PersistentVolumeEtcdStorageSpec{
+					StorageClassName: &storageClass,
+					Size:             &size,
+				},
+			},
+		},
+	}
+
+	m, err := scaffoldManifestwork(testHD)
+	assert.Nil(t, err)
+	payload := []workv1.Manifest{}
+	hdr.appendHostedCluster(ctx)(testHD, &payload)
+	err = hdr.ensureConfiguration(ctx, m)(testHD, &payload)
+	assert.Nil(t, err, "err nil when managed etcd has no referenced secret")
+
+	hc := getHostedClusterInManifestPayload(&payload)
+	assert.NotNil(t, hc, "is not nil when the hostedcluster is in the payload")
+	assert.Equal(t, testHD.Spec.HostedClusterSpec.Etcd, hc.Spec.Etcd, "managed etcd configuration is propagated")
+
+	etcdClient := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "etcd-client-tls", Namespace: testHD.GetNamespace()},
+		Data: map[string][]byte{
+			"etcd-client.crt":    []byte("cert"),
+			"etcd-client.key":    []byte("key"),
+			"etcd-client-ca.crt": []byte("ca"),
+		},
+	}
+	client.Create(ctx, etcdClient)
+	defer client.Delete(ctx, etcdClient)
+
+	testHD.Spec.HostedClusterSpec.Etcd = hyp.EtcdSpec{
+		ManagementType: hyp.Unmanaged,
+		Unmanaged: &hyp.UnmanagedEtcdSpec{
+			Endpoint: "https://etcd-client:2379",
+			TLS:      hyp.EtcdTLSConfig{ClientSecret: corev1.LocalObjectReference{Name: etcdClient.Name}},
+		},
+	}
+
+	payload = []workv1.Manifest{}
+	hdr.appendHostedCluster(ctx)(testHD, &payload)
+	err = hdr.ensureConfiguration(ctx, m)(testHD, &payload)
+	assert.Nil(t, err, "err nil when the etcd client secret is found")
+
+	payloadSec, _ := getManifestPayloadSecretByName(&payload, etcdClient.Name)
+	assert.NotNil(t, payloadSec, "is not nil when the etcd client secret is found")
+	assert.Equal(t, testHD.Spec.HostingNamespace, payloadSec.Namespace, "secret is moved to the hosting namespace")
+	assert.Equal(t, etcdClient.Data, payloadSec.Data)
+}
+
+func TestPropagatedSecretSourceAnnotations(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	pullSecret := getPullSecret(testHD)
+	assert.Nil(t, client.Create(ctx, pullSecret), "is nil when the pull secret is created")
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var mw workv1.ManifestWork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+
+	propagated, err := getManifestPayloadSecretByName(&mw.Spec.Workload.Manifests, pullSecret.Name)
+	assert.Nil(t, err)
+	assert.NotNil(t, propagated, "pull secret is propagated")
+	assert.Equal(t, pullSecret.Namespace+"/"+pullSecret.Name, propagated.Annotations[constant.SourceSecretAnnotation], "source is the hub pull secret")
+	assert.Equal(t, pullSecret.ResourceVersion, propagated.Annotations[constant.SourceResourceVersionAnnotation], "source resourceVersion is the hub pull secret's")
+
+	// a scaffolded secret has no hub source
+	scaffolded, err := getManifestPayloadSecretByName(&mw.Spec.Workload.Manifests, testHD.Name+"-cpo-creds")
+	assert.Nil(t, err)
+	assert.NotNil(t, scaffolded, "aws credentials are propagated")
+	_, found := scaffolded.Annotations[constant.SourceSecretAnnotation]
+	assert.False(t, found, "scaffolded secret has no source")
+
+	// the annotation follows the hub secret
+	pullSecret.Data["extra"] = []byte("value")
+	assert.Nil(t, client.Update(ctx, pullSecret), "is nil when the pull secret is updated")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+	propagated, err = getManifestPayloadSecretByName(&mw.Spec.Workload.Manifests, pullSecret.Name)
+	assert.Nil(t, err)
+	assert.Equal(t, pullSecret.ResourceVersion, propagated.Annotations[constant.SourceResourceVersionAnnotation], "source resourceVersion is updated")
+}
+
+// gettingClient counts the Gets of the objects named with the prefix
+type gettingClient struct {
+	client.Client
+	prefix string
+	gets   int
+}
+
+func (c *gettingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if strings.HasPrefix(key.Name, c.prefix) {
+		c.gets++
+	}
+
+	return c.Client.Get(ctx, key, obj)
+}
+
+func TestConfigurationReferenceChainIsBounded(t *testing.T) {
+	ctx := context.Background()
+	fakeClient := initClient()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostedClusterSpec.SecretEncryption = nil
+
+	// every secret references the next one, the last one loops back to the first
+	chainLength := 2 * maxConfigurationReferences
+	for i := 0; i < chainLength; i++ {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        fmt.Sprintf("chain-%d", i),
+				Namespace:   testHD.Namespace,
+				Annotations: map[string]string{"example.com/references": fmt.Sprintf("chain-%d", (i+1)%chainLength)},
+			},
+			Data: map[string][]byte{"key": []byte("value")},
+		}
+		assert.Nil(t, fakeClient.Create(ctx, secret), "secret is created")
+	}
+
+	configMapRefs := []corev1.LocalObjectReference{}
+	for i := 0; i < chainLength; i++ {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("chain-cm-%d", i), Namespace: testHD.Namespace}}
+		assert.Nil(t, fakeClient.Create(ctx, cm), "configMap is created")
+		configMapRefs = append(configMapRefs, corev1.LocalObjectReference{Name: cm.Name})
+	}
+
+	counting := &gettingClient{Client: fakeClient, prefix: "chain-"}
+	hdr := &HypershiftDeploymentReconciler{
+		Client: counting,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	// the start of the chain is referenced twice
+	testHD.Spec.HostedClusterSpec.Configuration = &hyp.ClusterConfiguration{
+		SecretRefs: []corev1.LocalObjectReference{{Name: "chain-0"}, {Name: "chain-0"}},
+	}
+
+	payload := []workv1.Manifest{}
+	hdr.appendHostedCluster(ctx)(testHD, &payload)
+	assert.Nil(t, hdr.ensureConfiguration(ctx, &workv1.ManifestWork{})(testHD, &payload), "err nil when the configuration is gathered")
+
+	chained := 0
+	for _, m := range payload {
+		if secret, ok := m.Object.(*corev1.Secret); ok && strings.HasPrefix(secret.Name, "chain-") {
+			chained++
+			assert.Equal(t, "chain-0", secret.Name, "only the referenced secret is copied")
+			assert.Empty(t, secret.Annotations["example.com/references"], "references of a copied secret are not propagated")
+		}
+	}
+	assert.Equal(t, 1, chained, "referenced secret is copied once")
+	assert.Equal(t, 1, counting.gets, "references of the copied secret are not followed")
+
+	// too many references
+	testHD.Spec.HostedClusterSpec.Configuration.ConfigMapRefs = configMapRefs
+	counting.gets = 0
+
+	payload = []workv1.Manifest{}
+	hdr.appendHostedCluster(ctx)(testHD, &payload)
+	err := hdr.ensureConfiguration(ctx, &workv1.ManifestWork{})(testHD, &payload)
+	assert.NotNil(t, err, "err not nil when too many configMaps are referenced")
+	assert.Contains(t, err.Error(), fmt.Sprintf("%d configMaps are referenced", chainLength))
+	assert.Equal(t, 1+maxConfigurationReferences, counting.gets, "gathering is bounded")
+}
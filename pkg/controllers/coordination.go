@@ -0,0 +1,104 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
+)
+
+// syncCoordinationAnnotations mirrors the phase of the HypershiftDeployment and the hash of its applied manifestwork
+// payload to its annotations, the HypershiftDeployment is only patched when they changed
+func (r *HypershiftDeploymentReconciler) syncCoordinationAnnotations(ctx context.Context, key types.NamespacedName) error {
+	hyd := &hypdeployment.HypershiftDeployment{}
+	if err := r.Get(ctx, key, hyd); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	// the finalizers are removed, the HypershiftDeployment is gone
+	if hyd.DeletionTimestamp != nil && len(hyd.Finalizers) == 0 {
+		return nil
+	}
+
+	hash, err := r.appliedPayloadHash(ctx, hyd)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(hyd.DeepCopy())
+	annotations := hyd.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	changed := false
+	if phase := string(helper.GetPhase(hyd)); annotations[constant.PhaseAnnotation] != phase {
+		annotations[constant.PhaseAnnotation] = phase
+		changed = true
+	}
+
+	if current, ok := annotations[constant.LastAppliedHashAnnotation]; len(hash) == 0 && ok {
+		delete(annotations, constant.LastAppliedHashAnnotation)
+		changed = true
+	} else if len(hash) != 0 && current != hash {
+		annotations[constant.LastAppliedHashAnnotation] = hash
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	hyd.SetAnnotations(annotations)
+	return client.IgnoreNotFound(r.Patch(ctx, hyd, patch))
+}
+
+// appliedPayloadHash returns the sha256 of the payload of the manifestwork of the HypershiftDeployment, empty
+// when there is no manifestwork
+func (r *HypershiftDeploymentReconciler) appliedPayloadHash(ctx context.Context, hyd *hypdeployment.HypershiftDeployment) (string, error) {
+	if len(helper.GetHostingCluster(hyd)) == 0 || r.WorkAPIUnavailable {
+		return "", nil
+	}
+
+	m := &workv1.ManifestWork{}
+	if err := r.Get(ctx, getManifestWorkKey(hyd), m); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	raw, err := json.Marshal(m.Spec.Workload.Manifests)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+)
+
+func TestCoordinationAnnotations(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.Equal(t, string(hyd.PhaseProvisioning), resultHD.Annotations[constant.PhaseAnnotation], "phase annotation is set")
+
+	hash := resultHD.Annotations[constant.LastAppliedHashAnnotation]
+	assert.Len(t, hash, 64, "hash of the applied payload is set")
+
+	// nothing changed
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.Equal(t, hash, resultHD.Annotations[constant.LastAppliedHashAnnotation], "hash is stable for the same payload")
+
+	// the payload changes
+	replicas := int32(5)
+	resultHD.Spec.NodePools[0].Spec.Replicas = &replicas
+	assert.Nil(t, client.Update(ctx, &resultHD), "is nil when the HypershiftDeployment is updated")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.NotEqual(t, hash, resultHD.Annotations[constant.LastAppliedHashAnnotation], "hash follows the applied payload")
+	assert.Len(t, resultHD.Annotations[constant.LastAppliedHashAnnotation], 64)
+
+	// the status changes
+	var mw workv1.ManifestWork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+	meta.SetStatusCondition(&resultHD.Status.Conditions, metav1.Condition{Type: string(hyd.HostedClusterAvailable), Status: metav1.ConditionTrue, Reason: "AsExpected"})
+	assert.Nil(t, client.Status().Update(ctx, &resultHD), "is nil when the HypershiftDeployment status is updated")
+
+	assert.Nil(t, hdr.syncCoordinationAnnotations(ctx, getNN), "err nil when the annotations are synced")
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.Equal(t, string(hyd.PhaseReady), resultHD.Annotations[constant.PhaseAnnotation], "phase annotation follows the status")
+
+	// the manifestwork is gone
+	assert.Nil(t, client.Delete(ctx, &mw), "is nil when the manifestwork is deleted")
+	assert.Nil(t, hdr.syncCoordinationAnnotations(ctx, getNN), "err nil when the annotations are synced")
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	_, found := resultHD.Annotations[constant.LastAppliedHashAnnotation]
+	assert.False(t, found, "hash is removed without a manifestwork")
+}
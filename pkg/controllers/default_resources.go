@@ -23,15 +23,16 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/hypershift/api/fixtures"
 	hyp "github.com/openshift/hypershift/api/v1alpha1"
 	"github.com/openshift/hypershift/cmd/infra/aws"
 	"github.com/openshift/hypershift/cmd/infra/azure"
-	"github.com/openshift/hypershift/cmd/version"
 	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
 	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
 	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -45,12 +46,15 @@ import (
 var resLog = ctrl.Log.WithName("resource-render")
 
 func getReleaseImagePullSpec() string {
-
-	defaultVersion, err := version.LookupDefaultOCPVersion()
+	image, _, err := defaultReleaseResolver.resolve(false)
 	if err != nil {
+		resLog.Info(err.Error())
+	}
+
+	if len(image) == 0 {
 		return constant.ReleaseImage
 	}
-	return defaultVersion.PullSpec
+	return image
 }
 
 func (r *HypershiftDeploymentReconciler) scaffoldHostedCluster(ctx context.Context, hyd *hypdeployment.HypershiftDeployment) (*unstructured.Unstructured, error) {
@@ -104,9 +108,202 @@ func (r *HypershiftDeploymentReconciler) scaffoldHostedCluster(ctx context.Conte
 		hostedCluster.SetAnnotations(transferHostedClusterAnnotations(hyd.Annotations, hostedCluster.GetAnnotations()))
 	}
 
+	// A HostedCluster without infraID uses the infra-id of the HypershiftDeployment, it names the manifestwork
+	if _, found, _ := unstructured.NestedMap(hostedCluster.Object, "spec"); found && len(hyd.Spec.InfraID) != 0 {
+		if infraID, _, _ := unstructured.NestedString(hostedCluster.Object, "spec", "infraID"); len(infraID) == 0 {
+			if err := unstructured.SetNestedField(hostedCluster.Object, hyd.Spec.InfraID, "spec", "infraID"); err != nil {
+				return nil, fmt.Errorf("failed to set the infra-id of hypershiftDeployment: %v:%v, err: %w", hyd.Namespace, hyd.Name, err)
+			}
+		}
+	}
+
+	// The cluster ID override wins over the one of the HostedClusterSpec or HostedClusterRef
+	if len(hyd.Spec.ClusterID) != 0 {
+		if err := unstructured.SetNestedField(hostedCluster.Object, hyd.Spec.ClusterID, "spec", "clusterID"); err != nil {
+			return nil, fmt.Errorf("failed to set the cluster-id of hypershiftDeployment: %v:%v, err: %w", hyd.Namespace, hyd.Name, err)
+		}
+	}
+
+	if err := appendControlPlaneTolerations(hostedCluster, hyd.Spec.ControlPlaneTolerations); err != nil {
+		return nil, fmt.Errorf("failed to set the control plane tolerations of hypershiftDeployment: %v:%v, err: %w", hyd.Namespace, hyd.Name, err)
+	}
+
+	if err := setProxyConfiguration(hostedCluster, hyd.Spec.Proxy); err != nil {
+		return nil, fmt.Errorf("failed to set the proxy of hypershiftDeployment: %v:%v, err: %w", hyd.Namespace, hyd.Name, err)
+	}
+
+	// The trust bundle override wins over the one of the HostedClusterSpec or HostedClusterRef, its configMap is
+	// copied with the other configuration references
+	if bundle := hyd.Spec.AdditionalTrustBundle; bundle != nil && len(bundle.Name) != 0 {
+		if err := unstructured.SetNestedMap(hostedCluster.Object, map[string]interface{}{"name": bundle.Name}, "spec", "additionalTrustBundle"); err != nil {
+			return nil, fmt.Errorf("failed to set the additional trust bundle of hypershiftDeployment: %v:%v, err: %w", hyd.Namespace, hyd.Name, err)
+		}
+	}
+
+	// The HostedCluster has no image registry configuration, the management state is carried as an annotation
+	if hyd.Spec.ImageRegistry != nil {
+		annotations := hostedCluster.GetAnnotations()
+		annotations[constant.ImageRegistryManagementStateAnnotation] = string(imageRegistryManagementState(hyd))
+		hostedCluster.SetAnnotations(annotations)
+	}
+
+	if err := setAzureIdentity(hostedCluster, azureIdentity(hyd)); err != nil {
+		return nil, fmt.Errorf("failed to set the azure identity of hypershiftDeployment: %v:%v, err: %w", hyd.Namespace, hyd.Name, err)
+	}
+
 	return hostedCluster, nil
 }
 
+// azureIdentity returns the managed identity of an Azure HypershiftDeployment, nil when it uses a credentials secret
+func azureIdentity(hyd *hypdeployment.HypershiftDeployment) *hypdeployment.AzureIdentity {
+	if hyd.Spec.Credentials == nil {
+		return nil
+	}
+
+	return hyd.Spec.Credentials.AzureIdentity
+}
+
+// setAzureIdentity replaces the credentials secret of an Azure HostedCluster with the managed identity, the
+// HostedCluster has no client and tenant id so they are carried as annotations. The subscription and machine
+// identity of the managed identity fill the HostedCluster, a different value already set is an error
+func setAzureIdentity(hostedCluster *unstructured.Unstructured, identity *hypdeployment.AzureIdentity) error {
+	if identity == nil {
+		return nil
+	}
+
+	if _, found, _ := unstructured.NestedMap(hostedCluster.Object, "spec", "platform", "azure"); !found {
+		return nil
+	}
+
+	unstructured.RemoveNestedField(hostedCluster.Object, "spec", "platform", "azure", "credentials")
+
+	for _, f := range []struct {
+		field string
+		value string
+	}{
+		{"subscriptionID", identity.SubscriptionID},
+		{"machineIdentityID", identity.MachineIdentityID},
+	} {
+		if len(f.value) == 0 {
+			continue
+		}
+
+		existing, _, _ := unstructured.NestedString(hostedCluster.Object, "spec", "platform", "azure", f.field)
+		if len(existing) != 0 && existing != f.value {
+			return fmt.Errorf("platform.azure.%s %q of the HostedCluster does not match credentials.azureIdentity.%s %q", f.field, existing, f.field, f.value)
+		}
+
+		if err := unstructured.SetNestedField(hostedCluster.Object, f.value, "spec", "platform", "azure", f.field); err != nil {
+			return err
+		}
+	}
+
+	annotations := hostedCluster.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[constant.AzureIdentityClientIDAnnotation] = identity.ClientID
+	annotations[constant.AzureIdentityTenantIDAnnotation] = identity.TenantID
+	hostedCluster.SetAnnotations(annotations)
+
+	return nil
+}
+
+// imageRegistryManagementState returns the management state of the internal image registry, Managed when not configured
+func imageRegistryManagementState(hyd *hypdeployment.HypershiftDeployment) hypdeployment.ImageRegistryManagementState {
+	if hyd.Spec.ImageRegistry == nil || len(hyd.Spec.ImageRegistry.ManagementState) == 0 {
+		return hypdeployment.ImageRegistryManaged
+	}
+
+	return hyd.Spec.ImageRegistry.ManagementState
+}
+
+// appendControlPlaneTolerations adds the tolerations to spec.tolerations of the HostedCluster, tolerations
+// already present, like the ones of a HostedClusterRef, are kept. The pinned HostedCluster API has no
+// tolerations, a HyperShift operator without them prunes the field on the hosting cluster
+func appendControlPlaneTolerations(hostedCluster *unstructured.Unstructured, tolerations []corev1.Toleration) error {
+	if len(tolerations) == 0 {
+		return nil
+	}
+
+	existing, _, err := unstructured.NestedSlice(hostedCluster.Object, "spec", "tolerations")
+	if err != nil {
+		return err
+	}
+
+	for i := range tolerations {
+		t, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&tolerations[i])
+		if err != nil {
+			return err
+		}
+
+		found := false
+		for _, e := range existing {
+			if equality.Semantic.DeepEqual(e, t) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			existing = append(existing, t)
+		}
+	}
+
+	return unstructured.SetNestedSlice(hostedCluster.Object, existing, "spec", "tolerations")
+}
+
+// setProxyConfiguration replaces the Proxy of the configuration items of the HostedCluster with the proxy, the
+// configMap of its trustedCA is copied with the other operator configuration references
+func setProxyConfiguration(hostedCluster *unstructured.Unstructured, proxy *hypdeployment.Proxy) error {
+	if proxy == nil {
+		return nil
+	}
+
+	cfg := &configv1.Proxy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: configv1.GroupVersion.String(),
+			Kind:       "Proxy",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.ProxySpec{
+			HTTPProxy:          proxy.HTTPProxy,
+			HTTPSProxy:         proxy.HTTPSProxy,
+			NoProxy:            proxy.NoProxy,
+			ReadinessEndpoints: proxy.ReadinessEndpoints,
+		},
+	}
+	if proxy.TrustedCA != nil {
+		cfg.Spec.TrustedCA = configv1.ConfigMapNameReference{Name: proxy.TrustedCA.Name}
+	}
+
+	item, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cfg)
+	if err != nil {
+		return err
+	}
+	delete(item, "status")
+
+	existing, _, err := unstructured.NestedSlice(hostedCluster.Object, "spec", "configuration", "items")
+	if err != nil {
+		return err
+	}
+
+	items := []interface{}{}
+	for _, e := range existing {
+		u := unstructured.Unstructured{}
+		if m, ok := e.(map[string]interface{}); ok {
+			u.Object = m
+		}
+
+		if u.GroupVersionKind().Group == configv1.GroupName && u.GetKind() == "Proxy" {
+			continue
+		}
+		items = append(items, e)
+	}
+
+	return unstructured.SetNestedSlice(hostedCluster.Object, append(items, item), "spec", "configuration", "items")
+}
+
 var checkHostedClusterAnnotations = map[string]bool{
 	hyp.DisablePKIReconciliationAnnotation:        true,
 	hyp.IdentityProviderOverridesAnnotationPrefix: true,
@@ -151,23 +348,22 @@ func spsMap(service hyp.ServiceType, psType hyp.PublishingStrategyType) hyp.Serv
 
 func ScaffoldAzureHostedClusterSpec(hyd *hypdeployment.HypershiftDeployment, infraOut *azure.CreateInfraOutput) {
 	scaffoldHostedClusterSpec(hyd)
-	ap := &hyp.AzurePlatformSpec{}
-	ap.Location = infraOut.Location
-	ap.MachineIdentityID = infraOut.MachineIdentityID
-	ap.ResourceGroupName = infraOut.ResourceGroupName
-	ap.SecurityGroupName = infraOut.SecurityGroupName
-	ap.SubnetName = infraOut.SubnetName
-	ap.VnetID = infraOut.VNetID
-	ap.VnetName = infraOut.VnetName
-	ap.Credentials.Name = hyd.Name + constant.CCredsSuffix //This is generated and the secret is created below
 	hyd.Spec.HostedClusterSpec.DNS = *scaffoldDnsSpec(infraOut.BaseDomain, infraOut.PrivateZoneID, infraOut.PublicZoneID)
-	hyd.Spec.HostedClusterSpec.Platform.Azure = ap
+	hyd.Spec.HostedClusterSpec.Platform.Azure = scaffoldAzurePlatformSpec(hyd, infraOut)
 	hyd.Spec.HostedClusterSpec.Platform.Type = hyp.AzurePlatform
 	hyd.Spec.HostedClusterSpec.InfraID = hyd.Spec.InfraID
 }
 
 func ScaffoldAWSHostedClusterSpec(hyd *hypdeployment.HypershiftDeployment, infraOut *aws.CreateInfraOutput) {
 	scaffoldHostedClusterSpec(hyd)
+	var cloudProviderConfig *hyp.AWSCloudProviderConfig
+	endpointAccess := hyp.Public
+	if hyd.Spec.HostedClusterSpec.Platform.AWS != nil {
+		cloudProviderConfig = hyd.Spec.HostedClusterSpec.Platform.AWS.CloudProviderConfig
+		if len(hyd.Spec.HostedClusterSpec.Platform.AWS.EndpointAccess) != 0 {
+			endpointAccess = hyd.Spec.HostedClusterSpec.Platform.AWS.EndpointAccess
+		}
+	}
 	hyd.Spec.HostedClusterSpec.DNS = *scaffoldDnsSpec(infraOut.BaseDomain, infraOut.PrivateZoneID, infraOut.PublicZoneID)
 	hyd.Spec.HostedClusterSpec.InfraID = hyd.Spec.InfraID
 	hyd.Spec.HostedClusterSpec.Networking.MachineCIDR = infraOut.ComputeCIDR
@@ -176,7 +372,7 @@ func ScaffoldAWSHostedClusterSpec(hyd *hypdeployment.HypershiftDeployment, infra
 		ControlPlaneOperatorCreds: corev1.LocalObjectReference{Name: hyd.Name + "-cpo-creds"},
 		KubeCloudControllerCreds:  corev1.LocalObjectReference{Name: hyd.Name + "-cloud-ctrl-creds"},
 		NodePoolManagementCreds:   corev1.LocalObjectReference{Name: hyd.Name + "-node-mgmt-creds"},
-		EndpointAccess:            hyp.Public,
+		EndpointAccess:            endpointAccess,
 		ResourceTags: []hyp.AWSResourceTag{
 			//set the resource tags to prevent the work always updating the hostedcluster resource on the hosting cluster.
 			{
@@ -186,7 +382,7 @@ func ScaffoldAWSHostedClusterSpec(hyd *hypdeployment.HypershiftDeployment, infra
 		},
 	}
 	hyd.Spec.HostedClusterSpec.Platform.AWS = ap
-	hyd.Spec.HostedClusterSpec.Platform.AWS.CloudProviderConfig = scaffoldCloudProviderConfig(infraOut)
+	hyd.Spec.HostedClusterSpec.Platform.AWS.CloudProviderConfig = scaffoldCloudProviderConfig(cloudProviderConfig, infraOut)
 	hyd.Spec.HostedClusterSpec.Platform.Type = hyp.AWSPlatform
 	//Fill in missing values if present from infraOut
 	if hyd.Spec.HostedClusterSpec.Networking.PodCIDR == "" {
@@ -236,7 +432,7 @@ func scaffoldHostedClusterSpec(hyd *hypdeployment.HypershiftDeployment) {
 				// Defaults for all platforms
 				PullSecret: corev1.LocalObjectReference{Name: hyd.Name + "-pull-secret"},
 				Release: hyp.Release{
-					Image: getReleaseImagePullSpec(), //.DownloadURL,
+					Image: defaultReleaseImage(hyd), //.DownloadURL,
 				},
 				Services: []hyp.ServicePublishingStrategyMapping{},
 			}
@@ -285,14 +481,60 @@ func scaffoldDnsSpec(baseDomain string, privateZoneID string, publicZoneID strin
 	}
 }
 
-func scaffoldCloudProviderConfig(infraOut *aws.CreateInfraOutput) *hyp.AWSCloudProviderConfig {
-	return &hyp.AWSCloudProviderConfig{
-		Subnet: &hyp.AWSResourceReference{
+// scaffoldAzurePlatformSpec fills the fields missing from the supplied azure platform with the created
+// infrastructure, the supplied resource group, vnet and subnet are kept
+func scaffoldAzurePlatformSpec(hyd *hypdeployment.HypershiftDeployment, infraOut *azure.CreateInfraOutput) *hyp.AzurePlatformSpec {
+	ap := &hyp.AzurePlatformSpec{}
+	if hyd.Spec.HostedClusterSpec.Platform.Azure != nil {
+		ap = hyd.Spec.HostedClusterSpec.Platform.Azure.DeepCopy()
+	}
+
+	fill := func(field *string, value string) {
+		if len(*field) == 0 {
+			*field = value
+		}
+	}
+
+	// the managed identity wins over the machine identity created with the infrastructure
+	if identity := azureIdentity(hyd); identity != nil {
+		fill(&ap.SubscriptionID, identity.SubscriptionID)
+		fill(&ap.MachineIdentityID, identity.MachineIdentityID)
+	}
+	fill(&ap.Location, infraOut.Location)
+	fill(&ap.MachineIdentityID, infraOut.MachineIdentityID)
+	fill(&ap.ResourceGroupName, infraOut.ResourceGroupName)
+	fill(&ap.SecurityGroupName, infraOut.SecurityGroupName)
+	fill(&ap.SubnetName, infraOut.SubnetName)
+	fill(&ap.VnetID, infraOut.VNetID)
+	fill(&ap.VnetName, infraOut.VnetName)
+	if azureIdentity(hyd) == nil {
+		fill(&ap.Credentials.Name, hyd.Name+constant.CCredsSuffix) //This is generated and the secret is created below
+	}
+
+	return ap
+}
+
+// scaffoldCloudProviderConfig fills the fields missing from the supplied cloudProviderConfig with the
+// created infrastructure, the supplied VPC, subnet and zone are kept
+func scaffoldCloudProviderConfig(in *hyp.AWSCloudProviderConfig, infraOut *aws.CreateInfraOutput) *hyp.AWSCloudProviderConfig {
+	out := &hyp.AWSCloudProviderConfig{}
+	if in != nil {
+		out = in.DeepCopy()
+	}
+
+	if out.Subnet == nil {
+		out.Subnet = &hyp.AWSResourceReference{
 			ID: &infraOut.Zones[0].SubnetID,
-		},
-		VPC:  infraOut.VPCID,
-		Zone: infraOut.Zones[0].Name,
+		}
+	}
+	if len(out.VPC) == 0 {
+		out.VPC = infraOut.VPCID
+	}
+	if len(out.Zone) == 0 {
+		out.Zone = infraOut.Zones[0].Name
 	}
+
+	return out
 }
 
 func ScaffoldAzureNodePoolSpec(hyd *hypdeployment.HypershiftDeployment, infraOut *azure.CreateInfraOutput) {
@@ -330,7 +572,7 @@ func ScaffoldAWSNodePoolSpec(hyd *hypdeployment.HypershiftDeployment, infraOut *
 				ID: &infraOut.Zones[0].SubnetID,
 			}
 		}
-		if np.Spec.Platform.AWS.SecurityGroups == nil {
+		if len(np.Spec.Platform.AWS.SecurityGroups) == 0 {
 			np.Spec.Platform.AWS.SecurityGroups = []hyp.AWSResourceReference{
 				{
 					ID: &infraOut.SecurityGroupID,
@@ -366,7 +608,7 @@ func ScaffoldNodePoolSpec(hyd *hypdeployment.HypershiftDeployment) {
 						Type: hyp.NonePlatform,
 					},
 					Release: hyp.Release{
-						Image: getReleaseImagePullSpec(), //.DownloadURL,,
+						Image: defaultReleaseImage(hyd), //.DownloadURL,,
 					},
 				},
 			},
@@ -393,7 +635,8 @@ func scaffoldAWSNodePoolPlatform(infraOut *aws.CreateInfraOutput) *hyp.AWSNodePo
 	}
 }
 
-func ScaffoldNodePool(hyd *hypdeployment.HypershiftDeployment, npName string, npSpec map[string]interface{}) *unstructured.Unstructured {
+// ScaffoldNodePool wraps the NodePool spec in a NodePool of the hosting namespace, the instance type aliases are resolved
+func ScaffoldNodePool(hyd *hypdeployment.HypershiftDeployment, npName string, npSpec map[string]interface{}, aliases *InstanceTypeAliases) (*unstructured.Unstructured, error) {
 	np := &unstructured.Unstructured{}
 	np.SetAPIVersion(hyp.GroupVersion.String())
 	np.SetKind("NodePool")
@@ -404,7 +647,34 @@ func ScaffoldNodePool(hyd *hypdeployment.HypershiftDeployment, npName string, np
 	})
 
 	np.Object["spec"] = npSpec
-	return np
+	if err := resolveInstanceTypeAliases(np, aliases); err != nil {
+		return nil, err
+	}
+
+	return np, nil
+}
+
+// scaffoldKubevirtNodePoolOptions writes the KubeVirt settings the pinned HyperShift NodePool API does not have to
+// spec.platform.kubevirt of the NodePool
+func scaffoldKubevirtNodePoolOptions(np *unstructured.Unstructured, opts *hypdeployment.KubevirtNodePoolOptions) error {
+	if len(opts.NetworkInterfaceMultiQueue) != 0 {
+		if err := unstructured.SetNestedField(np.Object, opts.NetworkInterfaceMultiQueue, "spec", "platform", "kubevirt", "networkInterfaceMultiqueue"); err != nil {
+			return err
+		}
+	}
+
+	if opts.Affinity != nil {
+		affinity, err := runtime.DefaultUnstructuredConverter.ToUnstructured(opts.Affinity)
+		if err != nil {
+			return err
+		}
+
+		if err := unstructured.SetNestedMap(np.Object, affinity, "spec", "platform", "kubevirt", "affinity"); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func ScaffoldAWSSecrets(hyd *hypdeployment.HypershiftDeployment, hc *hyp.HostedCluster) []*corev1.Secret {
@@ -445,14 +715,14 @@ func ScaffoldAWSSecrets(hyd *hypdeployment.HypershiftDeployment, hc *hyp.HostedC
 	return secrets
 }
 
-func ScaffoldAzureCloudCredential(hyd *hypdeployment.HypershiftDeployment, creds *fixtures.AzureCreds) *corev1.Secret {
+func ScaffoldAzureCloudCredential(hyd *hypdeployment.HypershiftDeployment, hc *hyp.HostedCluster, creds *fixtures.AzureCreds) *corev1.Secret {
 	return &corev1.Secret{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "v1",
 			Kind:       "Secret",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      hyd.Spec.HostedClusterSpec.Platform.Azure.Credentials.Name,
+			Name:      hc.Spec.Platform.Azure.Credentials.Name,
 			Namespace: helper.GetHostingNamespace(hyd),
 			Labels: map[string]string{
 				constant.AutoInfraLabelName: hyd.Spec.InfraID,
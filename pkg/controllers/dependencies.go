@@ -0,0 +1,136 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	hyp "github.com/openshift/hypershift/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+// secretDependency is a setting of the HostedClusterSpec that needs a secret with the key the HyperShift operator reads
+type secretDependency struct {
+	field string
+	name  string
+	key   string
+	// generated secrets do not have to be on the hub
+	generated bool
+}
+
+// unmetDependencies lists the settings of the HostedClusterSpec whose secret is not set, not on the hub or without
+// the key the HyperShift operator reads. They are checked together before the payload is scaffolded, so every
+// unmet dependency is reported at once:
+//   - secretEncryption aescbc, the activeKey and backupKey secrets, the activeKey is generated with configure
+//   - secretEncryption kms, the credentials of the AWS kms or of the Unmanaged IBM Cloud kms
+//   - auditWebhook, the secret with the webhook kubeconfig
+//
+// The spec of a HostedClusterRef is not checked.
+func (r *HypershiftDeploymentReconciler) unmetDependencies(ctx context.Context, hyd *hypdeployment.HypershiftDeployment) ([]string, error) {
+	hcSpec := hyd.Spec.HostedClusterSpec
+	if hcSpec == nil {
+		return nil, nil
+	}
+
+	unmet := []string{}
+	deps := []secretDependency{}
+
+	if encr := hcSpec.SecretEncryption; encr != nil {
+		switch encr.Type {
+		case hyp.AESCBC:
+			if encr.AESCBC == nil {
+				unmet = append(unmet, "hostedClusterSpec.secretEncryption.aescbc is required by hostedClusterSpec.secretEncryption.type aescbc")
+				break
+			}
+
+			deps = append(deps, secretDependency{
+				field:     "secretEncryption.aescbc.activeKey",
+				name:      encr.AESCBC.ActiveKey.Name,
+				key:       hyp.AESCBCKeySecretKey,
+				generated: hyd.Spec.Infrastructure.Configure,
+			})
+			if encr.AESCBC.BackupKey != nil {
+				deps = append(deps, secretDependency{field: "secretEncryption.aescbc.backupKey", name: encr.AESCBC.BackupKey.Name, key: hyp.AESCBCKeySecretKey})
+			}
+
+		case hyp.KMS:
+			kms := encr.KMS
+			if kms == nil {
+				unmet = append(unmet, "hostedClusterSpec.secretEncryption.kms is required by hostedClusterSpec.secretEncryption.type kms")
+				break
+			}
+
+			switch kms.Provider {
+			case hyp.AWS:
+				if kms.AWS == nil {
+					unmet = append(unmet, "hostedClusterSpec.secretEncryption.kms.aws is required by hostedClusterSpec.secretEncryption.kms.provider AWS")
+					break
+				}
+				deps = append(deps, secretDependency{field: "secretEncryption.kms.aws.auth.credentials", name: kms.AWS.Auth.Credentials.Name, key: hyp.AWSCredentialsFileSecretKey})
+
+			case hyp.IBMCloud:
+				if kms.IBMCloud == nil {
+					unmet = append(unmet, "hostedClusterSpec.secretEncryption.kms.ibmcloud is required by hostedClusterSpec.secretEncryption.kms.provider IBMCloud")
+					break
+				}
+
+				if kms.IBMCloud.Auth.Type != hyp.IBMCloudKMSUnmanagedAuth {
+					break
+				}
+				if kms.IBMCloud.Auth.Unmanaged == nil {
+					unmet = append(unmet, "hostedClusterSpec.secretEncryption.kms.ibmcloud.auth.unmanaged is required by hostedClusterSpec.secretEncryption.kms.ibmcloud.auth.type Unmanaged")
+					break
+				}
+				deps = append(deps, secretDependency{field: "secretEncryption.kms.ibmcloud.auth.unmanaged.credentials", name: kms.IBMCloud.Auth.Unmanaged.Credentials.Name, key: hyp.IBMCloudIAMAPIKeySecretKey})
+			}
+		}
+	}
+
+	if hcSpec.AuditWebhook != nil {
+		deps = append(deps, secretDependency{field: "auditWebhook", name: hcSpec.AuditWebhook.Name, key: hyp.AuditWebhookKubeconfigKey})
+	}
+
+	for _, dep := range deps {
+		if len(dep.name) == 0 {
+			unmet = append(unmet, fmt.Sprintf("hostedClusterSpec.%s.name is not set", dep.field))
+			continue
+		}
+
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: hyd.Namespace, Name: dep.name}, secret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+
+			if !dep.generated {
+				unmet = append(unmet, fmt.Sprintf("secret %s/%s of hostedClusterSpec.%s", hyd.Namespace, dep.name, dep.field))
+			}
+			continue
+		}
+
+		if _, ok := secret.Data[dep.key]; !ok {
+			unmet = append(unmet, fmt.Sprintf("key %s in secret %s/%s of hostedClusterSpec.%s", dep.key, hyd.Namespace, dep.name, dep.field))
+		}
+	}
+
+	return unmet, nil
+}
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	hyp "github.com/openshift/hypershift/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+func getDependencySecret(name string, data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Data:       data,
+	}
+}
+
+func getHDwithDependencies() *hyd.HypershiftDeployment {
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Spec.HostedClusterSpec.SecretEncryption = &hyp.SecretEncryptionSpec{
+		Type: hyp.KMS,
+		KMS: &hyp.KMSSpec{
+			Provider: hyp.AWS,
+			AWS: &hyp.AWSKMSSpec{
+				Region:    "us-east-1",
+				ActiveKey: hyp.AWSKMSKeyEntry{ARN: "arn:aws:kms:us-east-1:123456789012:key/test1"},
+				Auth:      hyp.AWSKMSAuthSpec{Credentials: corev1.LocalObjectReference{Name: "test1-kms-creds"}},
+			},
+		},
+	}
+	testHD.Spec.HostedClusterSpec.AuditWebhook = &corev1.LocalObjectReference{Name: "test1-audit-webhook"}
+
+	return testHD
+}
+
+func TestUnmetDependencies(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	testHD := getHDwithDependencies()
+	unmet, err := hdr.unmetDependencies(ctx, testHD)
+	assert.Nil(t, err, "err nil when the dependencies are checked")
+	assert.Equal(t, []string{
+		"secret default/test1-kms-creds of hostedClusterSpec.secretEncryption.kms.aws.auth.credentials",
+		"secret default/test1-audit-webhook of hostedClusterSpec.auditWebhook",
+	}, unmet, "every missing secret is listed")
+
+	client.Create(ctx, getDependencySecret("test1-kms-creds", map[string][]byte{hyp.AWSCredentialsFileSecretKey: []byte("[default]")}))
+	client.Create(ctx, getDependencySecret("test1-audit-webhook", map[string][]byte{"kubeconfig": []byte("apiVersion: v1")}))
+
+	unmet, err = hdr.unmetDependencies(ctx, testHD)
+	assert.Nil(t, err, "err nil when the dependencies are checked")
+	assert.Equal(t, []string{
+		"key webhook-kubeconfig in secret default/test1-audit-webhook of hostedClusterSpec.auditWebhook",
+	}, unmet, "the secret needs the key the HyperShift operator reads")
+
+	// the settings need their own spec
+	testHD.Spec.HostedClusterSpec.SecretEncryption = &hyp.SecretEncryptionSpec{Type: hyp.AESCBC}
+	testHD.Spec.HostedClusterSpec.AuditWebhook = &corev1.LocalObjectReference{}
+	unmet, err = hdr.unmetDependencies(ctx, testHD)
+	assert.Nil(t, err, "err nil when the dependencies are checked")
+	assert.Equal(t, []string{
+		"hostedClusterSpec.secretEncryption.aescbc is required by hostedClusterSpec.secretEncryption.type aescbc",
+		"hostedClusterSpec.auditWebhook.name is not set",
+	}, unmet)
+
+	// the active key is generated with configure
+	testHD.Spec.HostedClusterSpec.SecretEncryption.AESCBC = &hyp.AESCBCSpec{ActiveKey: corev1.LocalObjectReference{Name: "test1-etcd-encryption-key"}}
+	testHD.Spec.HostedClusterSpec.AuditWebhook = nil
+	unmet, _ = hdr.unmetDependencies(ctx, testHD)
+	assert.Equal(t, []string{"secret default/test1-etcd-encryption-key of hostedClusterSpec.secretEncryption.aescbc.activeKey"}, unmet)
+
+	testHD.Spec.Infrastructure.Configure = true
+	unmet, _ = hdr.unmetDependencies(ctx, testHD)
+	assert.Empty(t, unmet, "the missing active key is generated")
+}
+
+func TestUnmetDependenciesCondition(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDwithDependencies()
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+	client.Create(ctx, getDependencySecret("test1-kms-creds", map[string][]byte{hyp.AWSCredentialsFileSecretKey: []byte("[default]")}))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when the unmet dependencies are reported")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	c := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.UnmetDependencies))
+	if assert.NotNil(t, c, "UnmetDependencies condition is reported") {
+		assert.Equal(t, metav1.ConditionTrue, c.Status)
+		assert.Equal(t, hyd.MisConfiguredReason, c.Reason)
+		assert.Equal(t, "Unmet dependencies: secret default/test1-audit-webhook of hostedClusterSpec.auditWebhook", c.Message)
+	}
+
+	var mw workv1.ManifestWork
+	assert.NotNil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "the manifestwork is not created")
+
+	// once met, the secrets are shipped with the HostedCluster
+	client.Create(ctx, getDependencySecret("test1-audit-webhook", map[string][]byte{hyp.AuditWebhookKubeconfigKey: []byte("apiVersion: v1")}))
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	c = meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.UnmetDependencies))
+	if assert.NotNil(t, c, "UnmetDependencies condition is kept") {
+		assert.Equal(t, metav1.ConditionFalse, c.Status, "false once the dependencies are met")
+	}
+
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "the manifestwork is created")
+	for _, name := range []string{"test1-kms-creds", "test1-audit-webhook"} {
+		s, err := getManifestPayloadSecretByName(&mw.Spec.Workload.Manifests, name)
+		assert.Nil(t, err, "err nil when the payload is read")
+		assert.NotNil(t, s, "the secret %s is shipped", name)
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
+)
+
+const (
+	// deprovisionInitialRequeue is the delay before the first check that the manifestwork payload is cleaned up
+	deprovisionInitialRequeue = 20 * time.Second
+
+	// deprovisionMaxRequeue caps the delay between the checks of a clean up that takes long
+	deprovisionMaxRequeue = 5 * time.Minute
+)
+
+// deprovisionWait is the clean up of the manifestwork payload of a HypershiftDeployment being deleted
+type deprovisionWait struct {
+	since    time.Time
+	attempts int
+}
+
+// deprovisionRequeue returns when to check again that the manifestwork payload is cleaned up, the delay doubles
+// at each check up to deprovisionMaxRequeue. The DeprovisionStuck condition is set once the clean up has taken
+// longer than DeprovisionStuckAfter, counted from the deletion of the HypershiftDeployment.
+func (r *HypershiftDeploymentReconciler) deprovisionRequeue(hyd *hypdeployment.HypershiftDeployment) time.Duration {
+	now := r.currentTime()
+
+	w := deprovisionWait{since: now}
+	if hyd.DeletionTimestamp != nil {
+		w.since = hyd.DeletionTimestamp.Time
+	}
+	if v, ok := r.deprovisionWaits.Load(hyd.UID); ok {
+		w = v.(deprovisionWait)
+	}
+
+	requeue := deprovisionInitialRequeue << w.attempts
+	if requeue >= deprovisionMaxRequeue || requeue <= 0 {
+		requeue = deprovisionMaxRequeue
+	} else {
+		w.attempts++
+	}
+	r.deprovisionWaits.Store(hyd.UID, w)
+
+	if waited := now.Sub(w.since); r.DeprovisionStuckAfter > 0 && waited >= r.DeprovisionStuckAfter {
+		setStatusCondition(
+			hyd,
+			hypdeployment.DeprovisionStuck,
+			metav1.ConditionTrue,
+			fmt.Sprintf("The manifestwork %s is still being cleaned up from HostingCluster %s after %s, checking again in %s",
+				getManifestWorkKey(hyd), helper.GetHostingCluster(hyd), waited.Round(time.Second), requeue),
+			hypdeployment.DeprovisionTimeoutReason,
+		)
+	}
+
+	return requeue
+}
+
+// deprovisionDone forgets the clean up wait once the manifestwork is removed
+func (r *HypershiftDeploymentReconciler) deprovisionDone(hyd *hypdeployment.HypershiftDeployment) {
+	r.deprovisionWaits.Delete(hyd.UID)
+	resolveStatusCondition(hyd, hypdeployment.DeprovisionStuck)
+}
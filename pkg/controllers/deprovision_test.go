@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+)
+
+func TestDeprovisionRequeue(t *testing.T) {
+	now := time.Date(2022, time.May, 2, 12, 0, 0, 0, time.UTC)
+	hdr := &HypershiftDeploymentReconciler{
+		Log:                   ctrl.Log.WithName("tester"),
+		DeprovisionStuckAfter: 30 * time.Minute,
+		now:                   func() time.Time { return now },
+	}
+
+	testHD := getHDforManifestWork()
+	testHD.UID = "test1-uid"
+	testHD.DeletionTimestamp = &metav1.Time{Time: now}
+
+	for _, expected := range []time.Duration{20 * time.Second, 40 * time.Second, 80 * time.Second, 160 * time.Second, 5 * time.Minute, 5 * time.Minute} {
+		assert.Equal(t, expected, hdr.deprovisionRequeue(testHD), "the requeue backs off")
+	}
+	assert.Nil(t, meta.FindStatusCondition(testHD.Status.Conditions, string(hyd.DeprovisionStuck)), "not stuck within the max wait")
+
+	now = now.Add(30 * time.Minute)
+	assert.Equal(t, 5*time.Minute, hdr.deprovisionRequeue(testHD), "the requeue is capped")
+
+	c := meta.FindStatusCondition(testHD.Status.Conditions, string(hyd.DeprovisionStuck))
+	assert.NotNil(t, c, "DeprovisionStuck condition is reported")
+	assert.Equal(t, metav1.ConditionTrue, c.Status, "true past the max wait")
+	assert.Equal(t, hyd.DeprovisionTimeoutReason, c.Reason)
+	assert.Contains(t, c.Message, "after 30m0s")
+
+	hdr.deprovisionDone(testHD)
+	assert.False(t, meta.IsStatusConditionTrue(testHD.Status.Conditions, string(hyd.DeprovisionStuck)), "false once cleaned up")
+	assert.Equal(t, 20*time.Second, hdr.deprovisionRequeue(testHD), "the backoff restarts once cleaned up")
+
+	// the condition is disabled
+	hdr.DeprovisionStuckAfter = 0
+	disabledHD := getHDforManifestWork()
+	disabledHD.UID = "test2-uid"
+	disabledHD.DeletionTimestamp = &metav1.Time{Time: now.Add(-time.Hour)}
+	hdr.deprovisionRequeue(disabledHD)
+	assert.Nil(t, meta.FindStatusCondition(disabledHD.Status.Conditions, string(hyd.DeprovisionStuck)), "no condition when disabled")
+}
+
+func TestDeleteManifestworkWaitCleanUpBackoff(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.UID = "test1-uid"
+
+	// the work agent keeps the manifestwork until the payload is cleaned up
+	mw, _ := scaffoldManifestwork(testHD)
+	mw.Finalizers = []string{"cluster.open-cluster-management.io/manifest-work-cleanup"}
+	assert.Nil(t, client.Create(ctx, mw), "the manifestwork is created")
+	assert.Nil(t, client.Delete(ctx, mw), "the manifestwork is being deleted")
+
+	for _, expected := range []time.Duration{20 * time.Second, 40 * time.Second} {
+		res, err := hdr.deleteManifestworkWaitCleanUp(ctx, testHD)
+		assert.Nil(t, err, "err nil while the manifestwork is cleaned up")
+		assert.Equal(t, expected, res.RequeueAfter, "the requeue backs off")
+	}
+
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), mw), "the manifestwork is found")
+	mw.Finalizers = nil
+	assert.Nil(t, client.Update(ctx, mw), "the manifestwork is cleaned up")
+
+	res, err := hdr.deleteManifestworkWaitCleanUp(ctx, testHD)
+	assert.Nil(t, err, "err nil when the manifestwork is removed")
+	assert.True(t, res.IsZero(), "no requeue once the manifestwork is removed")
+
+	_, found := hdr.deprovisionWaits.Load(testHD.UID)
+	assert.False(t, found, "the wait is forgotten once the manifestwork is removed")
+
+	var removed workv1.ManifestWork
+	assert.NotNil(t, client.Get(ctx, getManifestWorkKey(testHD), &removed), "the manifestwork is removed")
+}
+
+func TestDeletedBeforeFinalizer(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Finalizers = []string{"example.com/keep"}
+
+	assert.Nil(t, client.Create(ctx, testHD), "HypershiftDeployment resource is created")
+	assert.Nil(t, client.Create(ctx, getPullSecret(testHD)), "the pull secret is created")
+	assert.Nil(t, client.Delete(ctx, testHD), "HypershiftDeployment resource is being deleted")
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when the deleted HypershiftDeployment is skipped")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.False(t, controllerutil.ContainsFinalizer(&resultHD, constant.DestroyFinalizer), "the finalizer is not added once deleted")
+
+	var mw workv1.ManifestWork
+	assert.True(t, apierrors.IsNotFound(client.Get(ctx, getManifestWorkKey(testHD), &mw)), "the manifestwork is not created")
+
+	resultHD.Finalizers = nil
+	assert.Nil(t, client.Update(ctx, &resultHD), "the other finalizer is removed")
+}
+
+func TestInfraOnlyDeleteWaitsForManifestWork(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+
+	assert.Nil(t, client.Create(ctx, testHD), "HypershiftDeployment resource is created")
+	assert.Nil(t, client.Create(ctx, getPullSecret(testHD)), "the pull secret is created")
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var mw workv1.ManifestWork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "the manifestwork is created")
+
+	// the override is set once the manifestwork is applied, then the HypershiftDeployment is deleted
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	resultHD.Spec.Override = hyd.InfraConfigureOnly
+	assert.Nil(t, client.Update(ctx, &resultHD), "HypershiftDeployment resource is updated")
+	assert.Nil(t, client.Delete(ctx, &resultHD), "HypershiftDeployment resource is being deleted")
+
+	res, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil while the manifestwork is cleaned up")
+	assert.NotZero(t, res.RequeueAfter, "requeued until the manifestwork is gone")
+
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "the HypershiftDeployment is kept")
+	assert.True(t, controllerutil.ContainsFinalizer(&resultHD, constant.DestroyFinalizer), "the finalizer is kept until the manifestwork is gone")
+}
+
+func TestDeleteCompletesOnceManagedClusterCleanedUp(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Finalizers = []string{constant.DestroyFinalizer, constant.ManagedClusterCleanupFinalizer}
+
+	assert.Nil(t, client.Create(ctx, testHD), "HypershiftDeployment resource is created")
+	assert.Nil(t, client.Create(ctx, getPullSecret(testHD)), "the pull secret is created")
+	assert.Nil(t, client.Delete(ctx, testHD), "HypershiftDeployment resource is being deleted")
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil while the ManagedCluster is cleaned up")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "the HypershiftDeployment is kept while the ManagedCluster is cleaned up")
+	assert.True(t, controllerutil.ContainsFinalizer(&resultHD, constant.DestroyFinalizer), "the finalizer is kept")
+
+	// the auto import controller removes its finalizer once the ManagedCluster is gone
+	oldHD := resultHD.DeepCopy()
+	controllerutil.RemoveFinalizer(&resultHD, constant.ManagedClusterCleanupFinalizer)
+	assert.Nil(t, client.Update(ctx, &resultHD), "the ManagedCluster clean up finalizer is removed")
+	assert.True(t, hypershiftDeploymentChanged.Update(event.UpdateEvent{ObjectOld: oldHD, ObjectNew: &resultHD}), "the finalizer removal is reconciled")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when the HypershiftDeployment is cleaned up")
+	assert.True(t, apierrors.IsNotFound(client.Get(ctx, getNN, &resultHD)), "the HypershiftDeployment is deleted")
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/yaml"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+)
+
+const renderedManifestsKey = "manifests.yaml"
+
+func renderedManifestsName(hyd *hypdeployment.HypershiftDeployment) string {
+	return hyd.Name + "-rendered-manifests"
+}
+
+// renderManifestsYAML serializes the payload to a multi document YAML, secret data is redacted
+func renderManifestsYAML(payload []workv1.Manifest) (string, error) {
+	redacted, err := redactManifests(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to redact the manifestwork payload, err: %w", err)
+	}
+
+	docs := []string{}
+	for _, obj := range redacted {
+		b, err := yaml.Marshal(obj)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal the manifestwork payload, err: %w", err)
+		}
+		docs = append(docs, string(b))
+	}
+
+	return strings.Join(docs, "---\n"), nil
+}
+
+// saveRenderedManifests writes the payload rendered in dry run to a ConfigMap next to the HypershiftDeployment,
+// the caller persists the reference set in the status
+func (r *HypershiftDeploymentReconciler) saveRenderedManifests(ctx context.Context, hyd *hypdeployment.HypershiftDeployment, payload []workv1.Manifest) error {
+	rendered, err := renderManifestsYAML(payload)
+	if err != nil {
+		return err
+	}
+
+	cm := &corev1.ConfigMap{}
+	cm.SetName(renderedManifestsName(hyd))
+	cm.SetNamespace(hyd.Namespace)
+
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		if cm.Labels == nil {
+			cm.Labels = map[string]string{}
+		}
+		cm.Labels[constant.InfraLabelName] = hyd.Spec.InfraID
+		cm.Data = map[string]string{renderedManifestsKey: rendered}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to save the rendered manifests %s/%s, err: %w", cm.Namespace, cm.Name, err)
+	}
+
+	hyd.Status.RenderedManifests = &corev1.LocalObjectReference{Name: cm.Name}
+	return nil
+}
+
+// removeRenderedManifests deletes the ConfigMap of a previous dry run, the caller persists the status
+func (r *HypershiftDeploymentReconciler) removeRenderedManifests(ctx context.Context, hyd *hypdeployment.HypershiftDeployment) error {
+	if hyd.Status.RenderedManifests == nil {
+		return nil
+	}
+
+	cm := &corev1.ConfigMap{}
+	cm.SetName(hyd.Status.RenderedManifests.Name)
+	cm.SetNamespace(hyd.Namespace)
+	if err := r.Delete(ctx, cm); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete the rendered manifests %s/%s, err: %w", cm.Namespace, cm.Name, err)
+	}
+
+	hyd.Status.RenderedManifests = nil
+	return nil
+}
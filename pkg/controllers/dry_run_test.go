@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+func TestDryRun(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Spec.DryRun = true
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var mw workv1.ManifestWork
+	assert.True(t, apierrors.IsNotFound(client.Get(ctx, getManifestWorkKey(testHD), &mw)), "the manifestwork is not created in dry run")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	c := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.WorkConfigured))
+	if assert.NotNil(t, c, "WorkConfigured condition is reported") {
+		assert.Equal(t, metav1.ConditionFalse, c.Status)
+		assert.Equal(t, hyd.DryRunReason, c.Reason)
+	}
+
+	if assert.NotNil(t, resultHD.Status.RenderedManifests, "the rendered manifests are referenced") {
+		assert.Equal(t, "test1-rendered-manifests", resultHD.Status.RenderedManifests.Name)
+	}
+
+	cmKey := types.NamespacedName{Namespace: testHD.Namespace, Name: "test1-rendered-manifests"}
+	var cm corev1.ConfigMap
+	assert.Nil(t, client.Get(ctx, cmKey, &cm), "the rendered manifests are saved")
+	rendered := cm.Data[renderedManifestsKey]
+	assert.Contains(t, rendered, "kind: HostedCluster")
+	assert.Contains(t, rendered, "kind: NodePool")
+	assert.Contains(t, rendered, "name: test1-pull-secret")
+	assert.Contains(t, rendered, redactedValue, "the secret data is redacted")
+	assert.NotContains(t, rendered, base64.StdEncoding.EncodeToString([]byte("docker-pull-secret")))
+
+	// the manifestwork is created once the dry run is turned off
+	resultHD.Spec.DryRun = false
+	assert.Nil(t, client.Update(ctx, &resultHD), "HypershiftDeployment resource is updated")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "the manifestwork is created")
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.Nil(t, resultHD.Status.RenderedManifests, "the rendered manifests are no longer referenced")
+	assert.True(t, apierrors.IsNotFound(client.Get(ctx, cmKey, &cm)), "the rendered manifests are deleted")
+	assert.True(t, meta.IsStatusConditionTrue(resultHD.Status.Conditions, string(hyd.WorkConfigured)))
+}
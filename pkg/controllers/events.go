@@ -0,0 +1,56 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
+)
+
+// The reasons of the events recorded on the HypershiftDeployments
+const (
+	ManifestWorkCreatedEvent = "ManifestWorkCreated"
+	ManifestWorkUpdatedEvent = "ManifestWorkUpdated"
+	ManifestWorkDeletedEvent = "ManifestWorkDeleted"
+	PullSecretNotFoundEvent  = "PullSecretNotFound"
+	ValidationFailedEvent    = "ValidationFailed"
+)
+
+// recordEvent records an event on the HypershiftDeployment, nothing is recorded without a Recorder
+func (r *HypershiftDeploymentReconciler) recordEvent(hyd *hypdeployment.HypershiftDeployment, eventType, reason, messageFmt string, args ...interface{}) {
+	if r.Recorder == nil {
+		return
+	}
+
+	r.Recorder.Eventf(hyd, eventType, reason, messageFmt, args...)
+}
+
+// validationFailed reports a spec that can not be applied in the condition and in a Warning event naming the
+// hosting cluster
+func (r *HypershiftDeploymentReconciler) validationFailed(
+	hyd *hypdeployment.HypershiftDeployment,
+	conditionType hypdeployment.ConditionType,
+	conditionStatus metav1.ConditionStatus,
+	message string,
+	reason string) error {
+	r.recordEvent(hyd, corev1.EventTypeWarning, ValidationFailedEvent, "Validation failed for hosting cluster %s: %s", helper.GetHostingCluster(hyd), message)
+
+	return r.updateStatusConditionsOnChange(hyd, conditionType, conditionStatus, message, reason)
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+// recordedEvents drains the events recorded so far
+func recordedEvents(recorder *record.FakeRecorder) []string {
+	events := []string{}
+	for {
+		select {
+		case e := <-recorder.Events:
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+func TestManifestWorkEvents(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	recorder := record.NewFakeRecorder(10)
+	hdr := &HypershiftDeploymentReconciler{
+		Client:   client,
+		Log:      ctrl.Log.WithName("tester"),
+		Recorder: recorder,
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Equal(t, []string{"Normal ManifestWorkCreated Created ManifestWork local-cluster/test1-abcde on hosting cluster local-cluster"},
+		recordedEvents(recorder), "the creation is recorded")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Empty(t, recordedEvents(recorder), "nothing is recorded when the manifestwork is unchanged")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	replicas := int32(3)
+	resultHD.Spec.NodePools[0].Spec.NodeCount = &replicas
+	assert.Nil(t, client.Update(ctx, &resultHD), "HypershiftDeployment resource is updated")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Equal(t, []string{"Normal ManifestWorkUpdated Updated ManifestWork local-cluster/test1-abcde on hosting cluster local-cluster"},
+		recordedEvents(recorder), "the update is recorded")
+
+	// the manifestwork has no payload left, it is deleted right away
+	mw, _ := scaffoldManifestwork(testHD)
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), mw), "the manifestwork is found")
+	mw.Spec.Workload.Manifests = nil
+	assert.Nil(t, client.Update(ctx, mw), "the manifestwork is emptied")
+
+	_, err = hdr.deleteManifestworkWaitCleanUp(ctx, &resultHD)
+	assert.Nil(t, err, "err nil when the manifestwork is deleted")
+	assert.Equal(t, []string{"Normal ManifestWorkDeleted Deleted ManifestWork local-cluster/test1-abcde on hosting cluster local-cluster"},
+		recordedEvents(recorder), "the deletion is recorded")
+}
+
+func TestWarningEvents(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Spec.ClusterID = "not-a-uuid"
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	recorder := record.NewFakeRecorder(10)
+	hdr := &HypershiftDeploymentReconciler{
+		Client:   client,
+		Log:      ctrl.Log.WithName("tester"),
+		Recorder: recorder,
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when the invalid spec is reported")
+	assert.Equal(t, []string{`Warning ValidationFailed Validation failed for hosting cluster local-cluster: cluster-id "not-a-uuid" is not a valid UUID`},
+		recordedEvents(recorder), "the validation failure is recorded")
+
+	// the pull secret was never created
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	resultHD.Spec.ClusterID = ""
+	assert.Nil(t, client.Update(ctx, &resultHD), "HypershiftDeployment resource is updated")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when the missing pull secret is reported")
+	assert.Equal(t, []string{"Warning PullSecretNotFound Pull secret default/test1-pull-secret not found, the ManifestWork local-cluster/test1-abcde on hosting cluster local-cluster is not updated"},
+		recordedEvents(recorder), "the missing pull secret is recorded")
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+// redactedSecretValue replaces the values of the exported secrets
+const redactedSecretValue = "REDACTED"
+
+// ExportManifestsToDir renders the manifestwork payload of the HypershiftDeployment and writes each manifest
+// to dir/<kind>/<name>.yaml, so the HostedCluster can be moved to a GitOps repository. The secrets are written
+// as stubs, their keys are kept and their values are redacted.
+func (r *HypershiftDeploymentReconciler) ExportManifestsToDir(hyd *hypdeployment.HypershiftDeployment, dir string) error {
+	ctx := context.TODO()
+
+	providerSecret := &corev1.Secret{}
+	if name := hyd.Spec.Infrastructure.CloudProvider.Name; len(name) != 0 {
+		if err := r.Get(ctx, types.NamespacedName{Namespace: hyd.Namespace, Name: name}, providerSecret); err != nil {
+			return fmt.Errorf("failed to get the provider secret %s/%s, err: %w", hyd.Namespace, name, err)
+		}
+	}
+
+	m, err := scaffoldManifestwork(hyd)
+	if err != nil {
+		return err
+	}
+
+	// the generated values, like the etcd encryption key, are kept from the applied manifestwork
+	if err := r.Get(ctx, getManifestWorkKey(hyd), m); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get the manifestwork %s, err: %w", getManifestWorkKey(hyd), err)
+	}
+
+	payload, err := r.renderManifestPayload(ctx, hyd, providerSecret, m)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range payload {
+		u, err := manifestToUnstructured(p)
+		if err != nil {
+			return fmt.Errorf("failed to read the manifest, err: %w", err)
+		}
+
+		if u.GetKind() == "Secret" {
+			redactSecret(u.Object)
+		}
+
+		out, err := yaml.Marshal(u.Object)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s %s, err: %w", u.GetKind(), u.GetName(), err)
+		}
+
+		kindDir := filepath.Join(dir, strings.ToLower(u.GetKind()))
+		if err := os.MkdirAll(kindDir, 0o755); err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(filepath.Join(kindDir, u.GetName()+".yaml"), out, 0o644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// redactSecret replaces the values of a secret with a placeholder, the keys are kept
+func redactSecret(obj map[string]interface{}) {
+	keys := []string{}
+	for _, field := range []string{"data", "stringData"} {
+		values, _ := obj[field].(map[string]interface{})
+		for k := range values {
+			keys = append(keys, k)
+		}
+		delete(obj, field)
+	}
+
+	if len(keys) == 0 {
+		return
+	}
+
+	sort.Strings(keys)
+	stub := map[string]interface{}{}
+	for _, k := range keys {
+		stub[k] = redactedSecretValue
+	}
+	obj["stringData"] = stub
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
+)
+
+func readExportedManifest(t *testing.T, path string) *unstructured.Unstructured {
+	raw, err := os.ReadFile(path)
+	assert.Nil(t, err, "err nil when %s is written", path)
+
+	u := &unstructured.Unstructured{}
+	assert.Nil(t, yaml.Unmarshal(raw, &u.Object), "err nil when %s is yaml", path)
+
+	return u
+}
+
+func TestExportManifestsToDir(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	pullSecret := getPullSecret(testHD)
+	client.Create(ctx, pullSecret)
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	dir := t.TempDir()
+	assert.Nil(t, hdr.ExportManifestsToDir(testHD, dir), "err nil when the manifests are exported")
+
+	hc := readExportedManifest(t, filepath.Join(dir, "hostedcluster", testHD.Name+".yaml"))
+	assert.Equal(t, "HostedCluster", hc.GetKind())
+	assert.Equal(t, helper.GetHostingNamespace(testHD), hc.GetNamespace())
+
+	np := readExportedManifest(t, filepath.Join(dir, "nodepool", testHD.Spec.NodePools[0].Name+".yaml"))
+	assert.Equal(t, "NodePool", np.GetKind())
+
+	ns := readExportedManifest(t, filepath.Join(dir, "namespace", helper.GetHostingNamespace(testHD)+".yaml"))
+	assert.Equal(t, "Namespace", ns.GetKind())
+
+	secret := readExportedManifest(t, filepath.Join(dir, "secret", pullSecret.Name+".yaml"))
+	assert.Equal(t, "Secret", secret.GetKind())
+	_, found := secret.Object["data"]
+	assert.False(t, found, "secret data is not exported")
+	stub, _, _ := unstructured.NestedStringMap(secret.Object, "stringData")
+	assert.Len(t, stub, len(pullSecret.Data), "every key of the secret is kept")
+	for k := range pullSecret.Data {
+		assert.Equal(t, redactedSecretValue, stub[k], "value of %s is redacted", k)
+	}
+
+	// no secret value is written to the directory
+	assert.Nil(t, filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		raw, err := os.ReadFile(path)
+		for _, v := range pullSecret.Data {
+			assert.NotContains(t, string(raw), string(v), "%s has no secret value", path)
+		}
+		return err
+	}))
+}
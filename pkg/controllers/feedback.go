@@ -0,0 +1,95 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	condmeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
+)
+
+// feedbackObservation is the manifestwork status last reported by the work agent, and when it was first seen
+type feedbackObservation struct {
+	generation int64
+	status     workv1.ManifestWorkStatus
+	at         time.Time
+}
+
+// observeFeedback records when the work agent last reported on the manifestwork and sets the FeedbackStale
+// condition when a change has been waiting longer than FeedbackStaleAfter without a report. The manifestwork
+// status does not change once the applied state is stable, so only a manifestwork generation the work agent
+// has not applied yet is expected to be reported on. It returns when to check again, 0 when not needed.
+func (r *HypershiftDeploymentReconciler) observeFeedback(hyd *hypdeployment.HypershiftDeployment, m *workv1.ManifestWork) time.Duration {
+	if r.FeedbackStaleAfter <= 0 {
+		return 0
+	}
+
+	now := r.currentTime()
+
+	last := feedbackObservation{generation: m.Generation, status: *m.Status.DeepCopy(), at: now}
+	if v, ok := r.feedbackObserved.Load(hyd.UID); ok {
+		// a new generation restarts the window, the work agent had nothing to report before it
+		if o := v.(feedbackObservation); o.generation == m.Generation && reflect.DeepEqual(o.status, m.Status) {
+			last = o
+		}
+	}
+	r.feedbackObserved.Store(hyd.UID, last)
+
+	applied := condmeta.FindStatusCondition(m.Status.Conditions, workv1.WorkApplied)
+	if applied != nil && applied.ObservedGeneration == m.Generation {
+		resolveStatusCondition(hyd, hypdeployment.FeedbackStale)
+		return 0
+	}
+
+	if remaining := last.at.Add(r.FeedbackStaleAfter).Sub(now); remaining > 0 {
+		resolveStatusCondition(hyd, hypdeployment.FeedbackStale)
+		return remaining
+	}
+
+	setStatusCondition(
+		hyd,
+		hypdeployment.FeedbackStale,
+		metav1.ConditionTrue,
+		fmt.Sprintf("No feedback from the work agent of HostingCluster %s since %s, the status may be outdated",
+			helper.GetHostingCluster(hyd), last.at.UTC().Format(time.RFC3339)),
+		hypdeployment.FeedbackStaleReason,
+	)
+
+	return 0
+}
+
+// feedbackPollRequeue returns FeedbackPollInterval while the HostedCluster is not available or its NodePools are
+// not ready, so their feedback is synced at least that often on top of the manifestwork watch. 0 once they settled
+func (r *HypershiftDeploymentReconciler) feedbackPollRequeue(hyd *hypdeployment.HypershiftDeployment) time.Duration {
+	if r.FeedbackPollInterval <= 0 {
+		return 0
+	}
+
+	if condmeta.IsStatusConditionTrue(hyd.Status.Conditions, string(hypdeployment.HostedClusterAvailable)) &&
+		condmeta.IsStatusConditionTrue(hyd.Status.Conditions, string(hypdeployment.Nodepool)) {
+		return 0
+	}
+
+	return r.FeedbackPollInterval
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+// feedbackRuleName keeps the custom feedback values apart from the lowercase default ones
+var feedbackRuleName = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+
+// feedbackRuleConditionType is the condition reporting the values of the rule, e.g. HostedClusterEtcdReady
+func feedbackRuleConditionType(r hypdeployment.FeedbackRule) hypdeployment.ConditionType {
+	return hypdeployment.ConditionType(string(r.Resource) + r.Name)
+}
+
+// feedbackRuleJsonPaths are the JSONPaths of the custom feedback rules of the resource, merged into the default ones
+func feedbackRuleJsonPaths(rules []hypdeployment.FeedbackRule, resource hypdeployment.FeedbackResource) []workv1.JsonPath {
+	out := []workv1.JsonPath{}
+	for _, r := range rules {
+		if r.Resource == resource {
+			out = append(out, workv1.JsonPath{Name: r.Name, Path: r.Path})
+		}
+	}
+
+	return out
+}
+
+// feedbackValueString formats a feedback value of any of the types the work agent reports
+func feedbackValueString(v workv1.FieldValue) (string, bool) {
+	switch {
+	case v.String != nil:
+		return *v.String, true
+	case v.Integer != nil:
+		return strconv.FormatInt(*v.Integer, 10), true
+	case v.Boolean != nil:
+		return strconv.FormatBool(*v.Boolean), true
+	}
+
+	return "", false
+}
+
+// feedbackRuleConditions reports the values of the custom feedback rules. A NodePool rule lists the value of every
+// NodePool in the message. A rule without any value yet is not reported.
+func feedbackRuleConditions(m *workv1.ManifestWork, hyd *hypdeployment.HypershiftDeployment, idMap map[workv1.ResourceIdentifier]workv1.ManifestConfigOption) []metav1.Condition {
+	out := []metav1.Condition{}
+	if len(hyd.Spec.FeedbackRules) == 0 {
+		return out
+	}
+
+	// values by rule name, then by resource name
+	hcValues := map[string]string{}
+	npValues := map[string]map[string]string{}
+
+	for _, obj := range m.Status.ResourceStatus.Manifests {
+		id := resourceMeta(obj.ResourceMeta).ToIdentifier()
+		if _, ok := idMap[id]; !ok {
+			continue
+		}
+
+		for _, fv := range obj.StatusFeedbacks.Values {
+			v, ok := feedbackValueString(fv.Value)
+			if !ok {
+				continue
+			}
+
+			switch id.Resource {
+			case HostedClusterResource:
+				hcValues[fv.Name] = v
+			case NodePoolResource:
+				if npValues[fv.Name] == nil {
+					npValues[fv.Name] = map[string]string{}
+				}
+				npValues[fv.Name][id.Name] = v
+			}
+		}
+	}
+
+	for _, r := range hyd.Spec.FeedbackRules {
+		msg := ""
+		switch r.Resource {
+		case hypdeployment.FeedbackHostedCluster:
+			v, ok := hcValues[r.Name]
+			if !ok {
+				continue
+			}
+			msg = v
+		case hypdeployment.FeedbackNodePool:
+			values := npValues[r.Name]
+			if len(values) == 0 {
+				continue
+			}
+
+			names := make([]string, 0, len(values))
+			for np := range values {
+				names = append(names, np)
+			}
+			sort.Strings(names)
+
+			pairs := make([]string, 0, len(names))
+			for _, np := range names {
+				pairs = append(pairs, fmt.Sprintf("%s: %s", np, values[np]))
+			}
+			msg = strings.Join(pairs, "; ")
+		default:
+			continue
+		}
+
+		out = append(out, metav1.Condition{
+			Type:    string(feedbackRuleConditionType(r)),
+			Status:  metav1.ConditionTrue,
+			Reason:  hypdeployment.FeedbackReceivedReason,
+			Message: msg,
+		})
+	}
+
+	return out
+}
@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	hyp "github.com/openshift/hypershift/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
+)
+
+func TestValidateFeedbackRules(t *testing.T) {
+	valid := []hyd.FeedbackRule{
+		{Resource: hyd.FeedbackHostedCluster, Name: "EtcdReady", Path: `.status.conditions[?(@.type=="EtcdAvailable")].status`},
+		{Resource: hyd.FeedbackNodePool, Name: "EtcdReady", Path: ".status.replicas"},
+	}
+	assert.Nil(t, validateFeedbackRules(valid), "err nil when the rules are valid")
+	assert.Nil(t, validateFeedbackRules(nil), "err nil when there is no rule")
+
+	cases := []struct {
+		name string
+		rule hyd.FeedbackRule
+		err  string
+	}{
+		{"unsupported resource", hyd.FeedbackRule{Resource: "Secret", Name: "Data", Path: ".data"}, `resource "Secret" is not supported`},
+		{"lowercase name", hyd.FeedbackRule{Resource: hyd.FeedbackHostedCluster, Name: "reason", Path: ".status.version"}, "must be UpperCamelCase"},
+		{"reserved name", hyd.FeedbackRule{Resource: hyd.FeedbackHostedCluster, Name: "Available", Path: ".status.version"}, "reserved for the HostedClusterAvailable condition"},
+		{"duplicate name", hyd.FeedbackRule{Resource: hyd.FeedbackNodePool, Name: "EtcdReady", Path: ".status.version"}, "already used by another NodePool rule"},
+		{"empty path", hyd.FeedbackRule{Resource: hyd.FeedbackHostedCluster, Name: "Version", Path: " "}, "path is empty"},
+		{"invalid path", hyd.FeedbackRule{Resource: hyd.FeedbackHostedCluster, Name: "Version", Path: ".status.conditions[?(@.type=="}, "is not a valid JSONPath"},
+	}
+
+	for _, c := range cases {
+		err := validateFeedbackRules(append(valid, c.rule))
+		if assert.NotNil(t, err, c.name) {
+			assert.Contains(t, err.Error(), c.err, c.name)
+		}
+	}
+}
+
+func TestFeedbackRules(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Spec.FeedbackRules = []hyd.FeedbackRule{
+		{Resource: hyd.FeedbackHostedCluster, Name: "DesiredImage", Path: ".status.version.desired.image"},
+		{Resource: hyd.FeedbackNodePool, Name: "Replicas", Path: ".status.replicas"},
+	}
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var mw workv1.ManifestWork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+
+	paths := map[string][]string{}
+	for _, cfg := range mw.Spec.ManifestConfigs {
+		for _, rule := range cfg.FeedbackRules {
+			for _, p := range rule.JsonPaths {
+				paths[cfg.ResourceIdentifier.Resource] = append(paths[cfg.ResourceIdentifier.Resource], p.Name+"="+p.Path)
+			}
+		}
+	}
+	assert.Contains(t, paths[HostedClusterResource], "DesiredImage=.status.version.desired.image", "the custom rule is added to the HostedCluster")
+	assert.Contains(t, paths[HostedClusterResource], Reason+`=.status.conditions[?(@.type=="Available")].reason`, "the default rules are kept")
+	assert.Contains(t, paths[NodePoolResource], "Replicas=.status.replicas", "the custom rule is added to the NodePool")
+	assert.NotContains(t, paths[NodePoolResource], "DesiredImage=.status.version.desired.image", "the HostedCluster rule is not added to the NodePool")
+
+	image := "quay.io/openshift-release-dev/ocp-release:4.10.15-x86_64"
+	replicas := int64(2)
+	mw.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{
+		{
+			ResourceMeta: workv1.ManifestResourceMeta{
+				Group:     hyp.GroupVersion.Group,
+				Resource:  HostedClusterResource,
+				Name:      testHD.Name,
+				Namespace: helper.GetHostingNamespace(testHD),
+			},
+			StatusFeedbacks: workv1.StatusFeedbackResult{Values: []workv1.FeedbackValue{
+				{Name: "DesiredImage", Value: workv1.FieldValue{Type: workv1.String, String: &image}},
+			}},
+		},
+		{
+			ResourceMeta: workv1.ManifestResourceMeta{
+				Group:     hyp.GroupVersion.Group,
+				Resource:  NodePoolResource,
+				Name:      testHD.Spec.NodePools[0].Name,
+				Namespace: helper.GetHostingNamespace(testHD),
+			},
+			StatusFeedbacks: workv1.StatusFeedbackResult{Values: []workv1.FeedbackValue{
+				{Name: "Replicas", Value: workv1.FieldValue{Type: workv1.Integer, Integer: &replicas}},
+			}},
+		},
+	}
+	assert.Nil(t, client.Update(ctx, &mw), "the manifestwork status is updated")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+
+	c := meta.FindStatusCondition(resultHD.Status.Conditions, "HostedClusterDesiredImage")
+	if assert.NotNil(t, c, "HostedClusterDesiredImage condition is reported") {
+		assert.Equal(t, metav1.ConditionTrue, c.Status)
+		assert.Equal(t, hyd.FeedbackReceivedReason, c.Reason)
+		assert.Equal(t, image, c.Message, "the message is the feedback value")
+	}
+
+	c = meta.FindStatusCondition(resultHD.Status.Conditions, "NodePoolReplicas")
+	if assert.NotNil(t, c, "NodePoolReplicas condition is reported") {
+		assert.Equal(t, testHD.Spec.NodePools[0].Name+": 2", c.Message, "the value is reported for every NodePool")
+	}
+
+	// an invalid JSONPath is reported instead of failing the work agent
+	resultHD.Spec.FeedbackRules[0].Path = ".status.version[?(@.image=="
+	assert.Nil(t, client.Update(ctx, &resultHD), "HypershiftDeployment resource is updated")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when the invalid rule is reported")
+
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	c = meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.WorkConfigured))
+	assert.Equal(t, metav1.ConditionFalse, c.Status, "false when a JSONPath is invalid")
+	assert.Equal(t, hyd.MisConfiguredReason, c.Reason)
+	assert.Contains(t, c.Message, "feedbackRules[0] path")
+}
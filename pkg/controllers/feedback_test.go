@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+func TestFeedbackStaleCondition(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	now := time.Date(2022, time.May, 2, 12, 0, 0, 0, time.UTC)
+	hdr := &HypershiftDeploymentReconciler{
+		Client:             client,
+		Log:                ctrl.Log.WithName("tester"),
+		FeedbackStaleAfter: 10 * time.Minute,
+		now:                func() time.Time { return now },
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	// the work agent applied the first generation, the second one is pending
+	var mw workv1.ManifestWork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+	mw.Generation = 2
+	meta.SetStatusCondition(&mw.Status.Conditions, metav1.Condition{
+		Type:               workv1.WorkApplied,
+		Status:             metav1.ConditionTrue,
+		Reason:             "AppliedManifestWorkComplete",
+		ObservedGeneration: 1,
+	})
+	assert.Nil(t, client.Update(ctx, &mw), "is nil when the manifestwork is updated")
+
+	res, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Equal(t, 10*time.Minute, res.RequeueAfter, "requeued when the work agent runs out of time to report")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.False(t, meta.IsStatusConditionTrue(resultHD.Status.Conditions, string(hyd.FeedbackStale)), "not stale within the window")
+
+	// no report from the work agent past the window
+	now = now.Add(11 * time.Minute)
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	cond := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.FeedbackStale))
+	assert.NotNil(t, cond, "FeedbackStale condition is set")
+	if cond != nil {
+		assert.Equal(t, metav1.ConditionTrue, cond.Status, "stale past the window")
+		assert.Equal(t, hyd.FeedbackStaleReason, cond.Reason)
+		assert.Contains(t, cond.Message, "2022-05-02T12:00:00Z", "message contains the time of the last feedback")
+		assert.Contains(t, cond.Message, "local-cluster", "message contains the HostingCluster")
+	}
+
+	// the work agent reports again
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+	meta.SetStatusCondition(&mw.Status.Conditions, metav1.Condition{
+		Type:               workv1.WorkApplied,
+		Status:             metav1.ConditionTrue,
+		Reason:             "AppliedManifestWorkComplete",
+		ObservedGeneration: 2,
+	})
+	assert.Nil(t, client.Update(ctx, &mw), "is nil when the manifestwork is updated")
+
+	res, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Zero(t, res.RequeueAfter, "not requeued once the feedback is current")
+
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	cond = meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.FeedbackStale))
+	assert.Equal(t, metav1.ConditionFalse, cond.Status, "not stale once the work agent reports")
+
+	// the applied state stays the same, it is not stale
+	now = now.Add(time.Hour)
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.False(t, meta.IsStatusConditionTrue(resultHD.Status.Conditions, string(hyd.FeedbackStale)), "not stale when nothing is pending")
+}
+
+func TestFeedbackPollRequeue(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	res, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Zero(t, res.RequeueAfter, "not polled when disabled")
+
+	hdr.FeedbackPollInterval = 20 * time.Second
+	res, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Equal(t, 20*time.Second, res.RequeueAfter, "polled while the HostedCluster is not available")
+
+	available := metav1.Condition{Type: string(hyd.HostedClusterAvailable), Status: metav1.ConditionTrue, Reason: "AsExpected"}
+	meta.SetStatusCondition(&testHD.Status.Conditions, available)
+	assert.Equal(t, 20*time.Second, hdr.feedbackPollRequeue(testHD), "polled while the NodePools are not ready")
+
+	ready := metav1.Condition{Type: string(hyd.Nodepool), Status: metav1.ConditionTrue, Reason: hyd.NodePoolProvision}
+	meta.SetStatusCondition(&testHD.Status.Conditions, ready)
+	assert.Zero(t, hdr.feedbackPollRequeue(testHD), "not polled once the HostedCluster and NodePools settled")
+}
+
+func TestFeedbackJsonPathsExtension(t *testing.T) {
+	defaults := hostedClusterFeedbackJsonPaths
+	defer func() { hostedClusterFeedbackJsonPaths = defaults }()
+
+	extra := workv1.JsonPath{Name: "etcdReady", Path: `.status.conditions[?(@.type=="EtcdAvailable")].status`}
+	hostedClusterFeedbackJsonPaths = append(append([]workv1.JsonPath{}, defaults...), extra)
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.FeedbackRules = []hyd.FeedbackRule{
+		{Name: "Platform", Resource: hyd.FeedbackHostedCluster, Path: ".spec.platform.type"},
+	}
+
+	for i := 0; i < 2; i++ {
+		for id, cfg := range getManifestWorkConfigs(testHD) {
+			paths := cfg.FeedbackRules[0].JsonPaths
+			if id.Resource != HostedClusterResource {
+				assert.NotContains(t, paths, extra, "the HostedCluster value is not added to the NodePools")
+				continue
+			}
+
+			assert.Len(t, paths, len(defaults)+2, "the defaults, the package extension and the custom rule are registered")
+			assert.Contains(t, paths, extra, "the value appended to the package is registered")
+			assert.Equal(t, "Platform", paths[len(paths)-1].Name, "the custom rule comes last")
+		}
+	}
+	assert.Len(t, hostedClusterFeedbackJsonPaths, len(defaults)+1, "the custom rules are not appended to the package defaults")
+}
@@ -18,26 +18,32 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/google/uuid"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	utilrand "k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/component-base/featuregate"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
-	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
@@ -49,6 +55,9 @@ import (
 	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
 )
 
+// reconcileTimeoutRequeueAfter is the delay before a HypershiftDeployment that exceeded the reconcile timeout is retried
+const reconcileTimeoutRequeueAfter = 10 * time.Second
+
 // HypershiftDeploymentReconciler reconciles a HypershiftDeployment object
 type HypershiftDeploymentReconciler struct {
 	client.Client
@@ -57,17 +66,128 @@ type HypershiftDeploymentReconciler struct {
 	ctx           context.Context
 	Log           logr.Logger
 
+	// Recorder records the events of the HypershiftDeployments, no event is recorded when nil
+	Recorder record.EventRecorder
+
 	InfraHandler            InfraHandler
 	ValidateClusterSecurity bool
+
+	// ValidatePermissions checks with a SelfSubjectAccessReview that the controller can write the
+	// manifestwork in the HostingCluster namespace before the manifestwork is created or updated
+	ValidatePermissions bool
+
+	// ValidateSpecSchema validates the spec, once the template is applied, against the schema of the CRD the
+	// controller is built with before anything is scaffolded
+	ValidateSpecSchema bool
+
+	// ManifestWorkGracePeriod delays the first manifestwork creation, counted from the HypershiftDeployment creation
+	ManifestWorkGracePeriod time.Duration
+
+	// ManifestWorkConflictRetries is the number of times a manifestwork write that conflicts is retried on a
+	// fresh copy of the manifestwork, 0 disables the retries
+	ManifestWorkConflictRetries int
+
+	// ManifestWorkChunkSize is the serialized size of a manifestwork payload past which the NodePools are written
+	// to additional manifestworks, defaultManifestWorkChunkSize when 0
+	ManifestWorkChunkSize int
+
+	// ScaleDownGuardPercent is the largest NodePool replica reduction, in percent of the applied replicas, written
+	// without the ConfirmScaleDownAnnotation, the larger ones keep the applied replicas. 0 disables the guard
+	ScaleDownGuardPercent int
+
+	// CircuitBreakerThreshold is the number of consecutive apply failures on a hosting cluster before
+	// the HypershiftDeployments targeting it stop being reconciled, 0 disables the circuit breaker
+	CircuitBreakerThreshold int
+	// CircuitBreakerCooldown is how long the circuit stays open before a trial reconcile is allowed
+	CircuitBreakerCooldown time.Duration
+
+	// ReconcileBudget is the number of manifestwork writes allowed per ReconcileBudgetWindow across all the
+	// HypershiftDeployments, the others are requeued until the budget refills, 0 disables the budget
+	ReconcileBudget int
+	// ReconcileBudgetWindow is the window over which the ReconcileBudget refills
+	ReconcileBudgetWindow time.Duration
+
+	// ReconcileTimeout bounds the time of a single reconcile, a HypershiftDeployment that runs out of time
+	// is requeued so it does not hold the worker, 0 disables the bound
+	ReconcileTimeout time.Duration
+
+	// DeprovisionStuckAfter is how long the manifestwork payload of a deleted HypershiftDeployment can take to be
+	// cleaned up before the DeprovisionStuck condition is set, 0 disables the condition
+	DeprovisionStuckAfter time.Duration
+
+	// FeedbackStaleAfter is how long the work agent can go without reporting on a pending manifestwork
+	// change before the FeedbackStale condition is set, 0 disables the check
+	FeedbackStaleAfter time.Duration
+
+	// FeedbackPollInterval requeues the HypershiftDeployments whose HostedCluster is not available or NodePools are
+	// not ready, so the status feedback is synced at least this often, 0 only syncs it on the manifestwork events
+	FeedbackPollInterval time.Duration
+
+	// InstanceTypeAliases resolves the friendly instance type names of the NodePools, the instance types
+	// are used as is when nil
+	InstanceTypeAliases *InstanceTypeAliases
+
+	// WorkAPIUnavailable is set when the hub did not serve the ManifestWork API at startup, the manifestworks are
+	// not watched and the HypershiftDeployments only report the WorkAPIUnavailable condition
+	WorkAPIUnavailable bool
+
+	// FeatureGate enables the features that are still being rolled out, all features are off when nil
+	FeatureGate featuregate.FeatureGate
+
+	// SecretTransformer rewrites the secrets before they are added to the manifestwork, the secrets are
+	// propagated in cleartext when nil. It is a hook for the controllers embedding the reconciler, the
+	// manager of this repository does not set it
+	SecretTransformer SecretTransformer
+
+	circuitBreakerOnce sync.Once
+	circuitBreaker     *targetCircuitBreaker
+
+	reconcileBudgetOnce sync.Once
+	reconcileBudget     *reconcileBudget
+
+	priorityQueueOnce sync.Once
+	priorityQueue     *priorityQueue
+
+	// availableObserved holds the UIDs of the HypershiftDeployments already reported to the
+	// HostedCluster available duration metric
+	availableObserved sync.Map
+
+	// configuredObserved holds the UIDs of the HypershiftDeployments already reported to the
+	// platform configured duration metric
+	configuredObserved sync.Map
+
+	// manifestWorkChange is the most significant change made to the manifestworks by the current reconcile,
+	// counted by the reconcile metric
+	manifestWorkChange string
+
+	// feedbackObserved holds, per HypershiftDeployment UID, the last manifestwork status reported by the work agent
+	feedbackObserved sync.Map
+
+	// deprovisionWaits holds, per HypershiftDeployment UID, the clean up wait of the deleted HypershiftDeployments
+	deprovisionWaits sync.Map
+
+	// infraIDLocks holds, per HypershiftDeployment namespaced name, the lock serializing the infra-id generation
+	infraIDLocks sync.Map
+
+	// releaseResolver resolves the release of the HostedClusters and NodePools scaffolded without release image,
+	// the cache shared by the package is used when nil
+	releaseResolver *releaseResolver
+
+	// now is the clock of the maintenance window, the feedback staleness, the reconcile budget and the deprovision
+	// wait, time.Now when nil
+	now func() time.Time
 }
 
 //+kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=hypershiftdeployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=hypershiftdeployments/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=hypershiftdeployments/finalizers,verbs=update
+//+kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=hypershiftdeploymenttemplates,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=secrets,verbs=create;get;list;patch;update;watch;deletecollection
-//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=create;delete;get;list;patch;update;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=hypershift.openshift.io,resources=hostedclusters;nodepools,verbs=create;delete;get;list;patch;update;watch
 //+kubebuilder:rbac:groups=work.open-cluster-management.io,resources=manifestworks,verbs=create;delete;get;list;patch;update;watch
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=selfsubjectaccessreviews,verbs=create
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -79,6 +199,45 @@ type HypershiftDeploymentReconciler struct {
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.10.0/pkg/reconcile
 func (r *HypershiftDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	// the worker took its request off the controller queue, hand it the next pending one
+	r.reconcileQueue().flush()
+
+	r.manifestWorkChange = ""
+	res, err := r.reconcileWithTimeout(ctx, req)
+
+	// The annotations follow the status left by the reconcile, whatever its outcome
+	if syncErr := r.syncCoordinationAnnotations(ctx, req.NamespacedName); syncErr != nil {
+		r.Log.Error(syncErr, "failed to sync the coordination annotations")
+		if err == nil {
+			err = syncErr
+		}
+	}
+
+	reconcileTotal.WithLabelValues(r.reconcileResult(err)).Inc()
+
+	return res, err
+}
+
+func (r *HypershiftDeploymentReconciler) reconcileWithTimeout(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if r.ReconcileTimeout <= 0 {
+		return r.reconcile(ctx, req)
+	}
+
+	// The reconciler runs with a single worker, the deadline cancels the client and cloud calls of a slow
+	// HypershiftDeployment, which is then added back to the end of the queue so the others can progress
+	timeoutCtx, cancel := context.WithTimeout(ctx, r.ReconcileTimeout)
+	defer cancel()
+
+	res, err := r.reconcile(timeoutCtx, req)
+	if errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		r.Log.Info(fmt.Sprintf("Reconcile: %s exceeded %s, requeue after %s", req, r.ReconcileTimeout, reconcileTimeoutRequeueAfter), "error", err)
+		return ctrl.Result{RequeueAfter: reconcileTimeoutRequeueAfter}, nil
+	}
+
+	return res, err
+}
+
+func (r *HypershiftDeploymentReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	r.Log = log.FromContext(ctx)
 	log := r.Log
 	r.ctx = ctx
@@ -92,12 +251,66 @@ func (r *HypershiftDeploymentReconciler) Reconcile(ctx context.Context, req ctrl
 		return ctrl.Result{}, nil
 	}
 
+	// The conditions of older releases, removed feedback rules and NodePools, and the long resolved warnings are dropped
+	if err := r.pruneStaleConditions(ctx, &hyd); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Nothing can be applied without the work API, the HypershiftDeployment is reported instead of failing every reconcile
+	if r.WorkAPIUnavailable {
+		log.Info("The ManifestWork API is not installed on the hub")
+		return ctrl.Result{}, r.updateStatusConditionsOnChange(&hyd, hypdeployment.WorkAPIUnavailable, metav1.ConditionTrue,
+			fmt.Sprintf("The %s API is not installed on the hub, install it and restart the controller", workv1.GroupVersion), hypdeployment.WorkAPINotInstalledReason)
+	}
+
+	// The fields the HypershiftDeployment does not set are inherited from its template, the merged spec is scaffolded
+	// but not written back, see templateBase
+	if err := r.applyTemplate(ctx, &hyd); err != nil {
+		log.Error(err, "Could not apply the template")
+		return ctrl.Result{RequeueAfter: 30 * time.Second, Requeue: true},
+			r.updateStatusConditionsOnChange(&hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+	}
+
+	// A spec the installed CRD let through, or completed by the template, is rejected before it fails mid-scaffold.
+	// The deletion is not blocked by an invalid spec
+	if r.ValidateSpecSchema && hyd.DeletionTimestamp == nil {
+		fieldErrs, err := validateSpecSchema(&hyd)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if len(fieldErrs) != 0 {
+			log.Error(fieldErrs.ToAggregate(), "The spec does not match the HypershiftDeployment schema")
+			return ctrl.Result{}, r.updateStatusConditionsOnChange(&hyd, hypdeployment.SpecInvalid, metav1.ConditionTrue,
+				fieldErrs.ToAggregate().Error(), hypdeployment.SchemaViolationReason)
+		}
+		if meta.FindStatusCondition(hyd.Status.Conditions, string(hypdeployment.SpecInvalid)) != nil {
+			if err := r.updateStatusConditionsOnChange(&hyd, hypdeployment.SpecInvalid, metav1.ConditionFalse, "", hypdeployment.AsExpectedReason); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	// An annotation the spec keeps from taking effect is reported, the spec takes precedence and the reconcile goes on
+	if hyd.DeletionTimestamp == nil {
+		if conflicts := conflictingAnnotations(&hyd); len(conflicts) != 0 {
+			log.Info(fmt.Sprintf("Conflicting annotations: %s", strings.Join(conflicts, "; ")))
+			if err := r.updateStatusConditionsOnChange(&hyd, hypdeployment.ConflictingAnnotations, metav1.ConditionTrue,
+				strings.Join(conflicts, "; "), hypdeployment.AnnotationPendingReason); err != nil {
+				return ctrl.Result{}, err
+			}
+		} else if meta.FindStatusCondition(hyd.Status.Conditions, string(hypdeployment.ConflictingAnnotations)) != nil {
+			if err := r.updateStatusConditionsOnChange(&hyd, hypdeployment.ConflictingAnnotations, metav1.ConditionFalse, "", hypdeployment.AsExpectedReason); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
 	var providerSecret corev1.Secret
 	var err error
 
 	configureInfra := hyd.Spec.Infrastructure.Configure
 	if configureInfra ||
-		(hyd.Spec.HostedClusterSpec != nil && hyd.Spec.HostedClusterSpec.Platform.Azure != nil) {
+		(hyd.Spec.HostedClusterSpec != nil && hyd.Spec.HostedClusterSpec.Platform.Azure != nil && azureIdentity(&hyd) == nil) {
 		secretName := hyd.Spec.Infrastructure.CloudProvider.Name
 		err = r.Client.Get(r.ctx, types.NamespacedName{Namespace: hyd.Namespace, Name: secretName}, &providerSecret)
 		if err != nil {
@@ -115,11 +328,33 @@ func (r *HypershiftDeploymentReconciler) Reconcile(ctx context.Context, req ctrl
 	}
 
 	if hyd.Spec.InfraID == "" {
-		hyd.Spec.InfraID = fmt.Sprintf("%s-%s", hyd.GetName(), utilrand.String(5))
+		if hyd.Spec.HostedClusterSpec == nil || len(hyd.Spec.HostedClusterSpec.InfraID) == 0 {
+			if err := helper.ValidateInfraIDName(hyd.GetName()); err != nil {
+				log.Error(err, "failed to generate the infra-id")
+				return ctrl.Result{}, r.updateStatusConditionsOnChange(&hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+			}
+		}
+
+		// persist the infra-id before anything is named after it
+		if err := r.ensureInfraID(ctx, &hyd); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update infra-id: \"%s\" and error: %w", hyd.Spec.InfraID, err)
+		}
 		log.Info("Using INFRA-ID: " + hyd.Spec.InfraID)
 	}
 
+	// the infra-id labels the HypershiftDeployment and names the manifestwork, check it before either is written
+	if err := validateManifestWorkName(hyd.Spec.InfraID); err != nil && hyd.DeletionTimestamp == nil {
+		log.Error(err, "invalid infra-id")
+		return ctrl.Result{}, r.updateStatusConditionsOnChange(&hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+	}
+
 	if !controllerutil.ContainsFinalizer(&hyd, constant.DestroyFinalizer) {
+		// a finalizer can not be added once deleted, nothing is applied to the HostingCluster before it is set
+		if hyd.DeletionTimestamp != nil {
+			log.Info("The HypershiftDeployment was deleted before its finalizer was set, nothing to clean up")
+			return ctrl.Result{}, nil
+		}
+
 		controllerutil.AddFinalizer(&hyd, constant.DestroyFinalizer)
 
 		if hyd.Labels == nil {
@@ -136,14 +371,29 @@ func (r *HypershiftDeploymentReconciler) Reconcile(ctx context.Context, req ctrl
 
 	// Destroying Platform infrastructure used by the HypershiftDeployment scheduled for deletion
 	if hyd.DeletionTimestamp != nil {
+		r.availableObserved.Delete(hyd.UID)
+		r.configuredObserved.Delete(hyd.UID)
+		r.feedbackObserved.Delete(hyd.UID)
+		r.infraIDLocks.Delete(req.NamespacedName)
 		return r.destroyHypershift(&hyd, &providerSecret)
 	}
 
 	if configureInfra {
+		// The infrastructure scaffolding would turn the HostedCluster into a cloud one
+		if hyd.Spec.HostedClusterSpec != nil && hyd.Spec.HostedClusterSpec.Platform.Type == hyp.NonePlatform {
+			return ctrl.Result{}, r.updateStatusConditionsOnChange(&hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse,
+				"The None platform uses the user supplied infrastructure, HypershiftDeployment.Spec.Infrastructure.Configure must be false", hypdeployment.MisConfiguredReason)
+		}
+
 		if hyd.Spec.Infrastructure.Platform == nil {
 			return ctrl.Result{}, r.updateMissingInfrastructureParameterCondition(&hyd, "Missing value HypershiftDeployment.Spec.Infrastructure.Platform")
 		}
 
+		// the HostedCluster and NodePools scaffolded without release image use the latest stable release
+		if err := r.resolveDefaultRelease(&hyd); err != nil {
+			return ctrl.Result{}, err
+		}
+
 		if hyd.Spec.Infrastructure.Platform.AWS != nil {
 			if requeue, err := r.createAWSInfra(&hyd, &providerSecret); err != nil || requeue.Requeue {
 				return requeue, err
@@ -195,7 +445,8 @@ func (r *HypershiftDeploymentReconciler) setDefaultValueForHostedCluster(ctx con
 
 	spec := hyd.Spec.HostedClusterSpec
 	needsUpdate := false
-	if spec.ClusterID == "" {
+	// A cluster-id override is set on the HostedCluster when it is scaffolded
+	if spec.ClusterID == "" && len(hyd.Spec.ClusterID) == 0 {
 		hyd.Spec.HostedClusterSpec.ClusterID = uuid.NewString()
 		needsUpdate = true
 		log.Info("Setting clusterID", "clusterID", hyd.Spec.HostedClusterSpec.ClusterID)
@@ -245,7 +496,24 @@ func setStatusCondition(hyd *hypdeployment.HypershiftDeployment, conditionType h
 		Message:            message,
 		Reason:             reason,
 	}
+
+	from, phase := "", helper.GetPhase(hyd)
+	if c := meta.FindStatusCondition(hyd.Status.Conditions, condition.Type); c != nil {
+		from = string(c.Status)
+	}
 	meta.SetStatusCondition(&hyd.Status.Conditions, condition)
+
+	// a status change, not a new message or reason, is kept in the history, so is the phase change it causes
+	at := meta.FindStatusCondition(hyd.Status.Conditions, condition.Type).LastTransitionTime
+	if from != string(status) {
+		recordTransition(hyd, hypdeployment.TransitionEvent{Time: at, Type: condition.Type, From: from, To: string(status), Reason: reason})
+	} else {
+		at = metav1.Now()
+	}
+	if newPhase := helper.GetPhase(hyd); newPhase != phase {
+		recordTransition(hyd, hypdeployment.TransitionEvent{Time: at, Type: phaseHistoryType, From: string(phase), To: string(newPhase)})
+	}
+
 	return condition
 }
 
@@ -284,7 +552,10 @@ func (r *HypershiftDeploymentReconciler) updateStatusConditionsOnChange(
 		setStatusCondition(hyd, conditionType, conditionStatus, message, reason)
 
 		// use Patch with merge to minimize the update conflicts
-		err = r.Client.Status().Patch(r.ctx, hyd, client.MergeFrom(inHyd))
+		err = r.patchStatus(r.ctx, hyd, inHyd)
+		if err == nil && conditionType == hypdeployment.PlatformConfigured {
+			r.observePlatformConfigured(inHyd, hyd)
+		}
 		if err != nil {
 			if apierrors.IsConflict(err) {
 				r.Log.Error(err, "Conflict encountered when updating HypershiftDeployment.Status")
@@ -297,6 +568,19 @@ func (r *HypershiftDeploymentReconciler) updateStatusConditionsOnChange(
 	return err
 }
 
+// patchStatus patches the status of the HypershiftDeployment from a copy, like removeCollectSupportAnnotation.
+// The API server returns the stored spec, it would replace the spec merged with the template
+func (r *HypershiftDeploymentReconciler) patchStatus(ctx context.Context, hyd, inHyd *hypdeployment.HypershiftDeployment) error {
+	patched := hyd.DeepCopy()
+	if err := r.Client.Status().Patch(ctx, patched, client.MergeFrom(inHyd)); err != nil {
+		return err
+	}
+
+	hyd.ResourceVersion = patched.ResourceVersion
+	hyd.Status = patched.Status
+	return nil
+}
+
 func (r *HypershiftDeploymentReconciler) patchHypershiftDeploymentResource(hyd *hypdeployment.HypershiftDeployment) error {
 
 	// Reduce the risk of a patch conflict
@@ -305,16 +589,76 @@ func (r *HypershiftDeploymentReconciler) patchHypershiftDeploymentResource(hyd *
 		return err
 	}
 
+	// only the changes of the reconcile are patched, the values inherited from the template are not persisted
+	base, err := r.templateBase(r.ctx, &inHyd)
+	if err != nil {
+		return err
+	}
+
 	hyd.ResourceVersion = inHyd.ResourceVersion
-	err := r.Client.Patch(r.ctx, hyd, client.MergeFrom(&inHyd))
+	err = r.Client.Patch(r.ctx, hyd, client.MergeFrom(base))
 	if err != nil {
 		if apierrors.IsConflict(err) {
 			r.Log.Error(err, "Conflict encountered when patching HypershiftDeployment")
 		} else {
 			r.Log.Error(err, "Failed to update HypershiftDeployment resource")
 		}
+		return err
 	}
-	return err
+
+	// the patched HypershiftDeployment is the stored one, the reconcile goes on with the template merged
+	return r.applyTemplate(r.ctx, hyd)
+}
+
+// ensureInfraID generates and persists the infra-id of a HypershiftDeployment that has none, exactly once. The
+// generation is serialized per HypershiftDeployment and the patch is conditioned on the resourceVersion it was
+// generated from, a reconcile that loses the race adopts the infra-id persisted by the other one
+func (r *HypershiftDeploymentReconciler) ensureInfraID(ctx context.Context, hyd *hypdeployment.HypershiftDeployment) error {
+	key := types.NamespacedName{Namespace: hyd.Namespace, Name: hyd.Name}
+	lock, _ := r.infraIDLocks.LoadOrStore(key, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		inHyd := &hypdeployment.HypershiftDeployment{}
+		if err := r.Client.Get(ctx, key, inHyd); err != nil {
+			return err
+		}
+
+		if len(inHyd.Spec.InfraID) != 0 {
+			r.Log.Info("The infra-id was already persisted by another reconcile")
+			hyd.Spec.InfraID = inHyd.Spec.InfraID
+			hyd.ResourceVersion = inHyd.ResourceVersion
+			return nil
+		}
+
+		// The infraID of a user supplied HostedClusterSpec is kept, so both name the same resources
+		if hyd.Spec.HostedClusterSpec != nil && len(hyd.Spec.HostedClusterSpec.InfraID) != 0 {
+			hyd.Spec.InfraID = hyd.Spec.HostedClusterSpec.InfraID
+		} else {
+			hyd.Spec.InfraID = helper.InfraIDFor(hyd.GetName(), hyd.GetUID())
+		}
+
+		// the values inherited from the template are not persisted with the infra-id
+		base, err := r.templateBase(ctx, inHyd)
+		if err != nil {
+			hyd.Spec.InfraID = ""
+			return err
+		}
+
+		hyd.ResourceVersion = inHyd.ResourceVersion
+		if err := r.Client.Patch(ctx, hyd, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{})); err != nil {
+			hyd.Spec.InfraID = ""
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// the patched HypershiftDeployment is the stored one, the reconcile goes on with the template merged
+	return r.applyTemplate(ctx, hyd)
 }
 
 func (r *HypershiftDeploymentReconciler) destroyHypershift(hyd *hypdeployment.HypershiftDeployment, providerSecret *corev1.Secret) (ctrl.Result, error) {
@@ -329,22 +673,22 @@ func (r *HypershiftDeploymentReconciler) destroyHypershift(hyd *hypdeployment.Hy
 		return ctrl.Result{}, nil
 	}
 
-	if hyd.Spec.Override != hypdeployment.InfraConfigureOnly {
-		log.Info("Removing Manifestwork and wait for hostedcluster and nodepool to be cleaned up.")
-		res, err := r.deleteManifestworkWaitCleanUp(ctx, hyd)
+	// INFRA-ONLY does not write a manifestwork, but one applied before the override was set is still cleaned up,
+	// the finalizer is only removed once the manifestwork is gone
+	log.Info("Removing Manifestwork and wait for hostedcluster and nodepool to be cleaned up.")
+	res, err := r.deleteManifestworkWaitCleanUp(ctx, hyd)
 
-		if stErr := r.Client.Status().Patch(ctx, hyd, client.MergeFrom(inHyd)); stErr != nil {
-			r.Log.Error(stErr, "Failed to patch HypershiftDeployment.Status while deleting manifestwork")
-		}
+	if stErr := r.patchStatus(ctx, hyd, inHyd); stErr != nil {
+		r.Log.Error(stErr, "Failed to patch HypershiftDeployment.Status while deleting manifestwork")
+	}
 
-		if err != nil {
-			return res, fmt.Errorf("failed to delete manifestwork %v", err)
-		}
+	if err != nil {
+		return res, fmt.Errorf("failed to delete manifestwork %v", err)
+	}
 
-		// wait for the nodepools and hostedcluster in hosting namespace is deleted(via the work agent)
-		if !res.IsZero() {
-			return res, nil
-		}
+	// wait for the nodepools and hostedcluster in hosting namespace is deleted(via the work agent)
+	if !res.IsZero() {
+		return res, nil
 	}
 
 	if hyd.Spec.Override != hypdeployment.InfraOverrideDestroy &&
@@ -363,9 +707,11 @@ func (r *HypershiftDeploymentReconciler) destroyHypershift(hyd *hypdeployment.Hy
 	}
 
 	log.Info("Removing finalizer")
+	// only the finalizers are written, the spec holds the values inherited from the template
+	patch := client.MergeFromWithOptions(hyd.DeepCopy(), client.MergeFromWithOptimisticLock{})
 	controllerutil.RemoveFinalizer(hyd, constant.DestroyFinalizer)
 
-	if err := r.Client.Update(ctx, hyd); err != nil {
+	if err := r.Client.Patch(ctx, hyd, patch); err != nil {
 		//if apierrors.IsConflict(err) {
 		//	return ctrl.Result{Requeue: true}, nil
 		//}
@@ -374,31 +720,106 @@ func (r *HypershiftDeploymentReconciler) destroyHypershift(hyd *hypdeployment.Hy
 	return ctrl.Result{}, nil
 }
 
-// SetupWithManager sets up the controller with the Manager.
+// SetupWithManager sets up the controller with the Manager. The watch events are enqueued through the priority
+// queue of the reconciler, so the HypershiftDeployments of a higher ReconcilePriorityLabel are reconciled first.
 func (r *HypershiftDeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&hypdeployment.HypershiftDeployment{}).
-		Watches(&source.Kind{Type: &workv1.ManifestWork{}},
-			handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
-				an := obj.GetAnnotations()
-
-				if len(an) == 0 || len(an[constant.CreatedByHypershiftDeployment]) == 0 {
-					return []reconcile.Request{}
-				}
-
-				res := strings.Split(an[constant.CreatedByHypershiftDeployment], constant.NamespaceNameSeperator)
-
-				if len(res) != 2 {
-					r.Log.Error(fmt.Errorf("failed to get manifestwork's hypershiftDeployment"), "")
-					return []reconcile.Request{}
-				}
-
-				req := reconcile.Request{
-					NamespacedName: types.NamespacedName{Namespace: res[0], Name: res[1]},
-				}
-
-				return []reconcile.Request{req}
-			})).
-		WithOptions(controller.Options{MaxConcurrentReconciles: 1}).
-		Complete(r)
+	c, err := controller.New("hypershiftdeployment", mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: 1,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := mgr.Add(r.reconcileQueue()); err != nil {
+		return err
+	}
+
+	if err := c.Watch(&source.Kind{Type: &hypdeployment.HypershiftDeployment{}},
+		&priorityEnqueue{r: r, toRequests: hypershiftDeploymentOf}, hypershiftDeploymentChanged); err != nil {
+		return err
+	}
+
+	if err := c.Watch(&source.Kind{Type: &hypdeployment.HypershiftDeploymentTemplate{}},
+		&priorityEnqueue{r: r, toRequests: r.hypershiftDeploymentsOfTemplate}); err != nil {
+		return err
+	}
+
+	// A watch on a kind the hub does not serve keeps the manager from starting
+	if r.WorkAPIUnavailable {
+		return nil
+	}
+
+	if err := registerManagedManifestWorksCollector(mgr.GetClient()); err != nil {
+		return err
+	}
+
+	return c.Watch(&source.Kind{Type: &workv1.ManifestWork{}},
+		&priorityEnqueue{r: r, toRequests: r.hypershiftDeploymentOfManifestWork})
+}
+
+// hypershiftDeploymentChanged skips the updates of a HypershiftDeployment that do not change its spec, metadata or
+// deletion, like the status patches of the reconcile itself. The finalizers are watched for the deletion waiting on
+// the ManagedCluster clean up, the labels for the ReconcilePriorityLabel. The status feedback of the manifestwork is
+// still synced by the manifestwork watch.
+var hypershiftDeploymentChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if e.ObjectOld == nil || e.ObjectNew == nil {
+			return true
+		}
+
+		return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() ||
+			!equality.Semantic.DeepEqual(e.ObjectOld.GetAnnotations(), e.ObjectNew.GetAnnotations()) ||
+			!equality.Semantic.DeepEqual(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels()) ||
+			!equality.Semantic.DeepEqual(e.ObjectOld.GetFinalizers(), e.ObjectNew.GetFinalizers()) ||
+			!e.ObjectOld.GetDeletionTimestamp().Equal(e.ObjectNew.GetDeletionTimestamp())
+	},
+}
+
+// hypershiftDeploymentOfManifestWork maps a manifestwork to the HypershiftDeployment that created it
+func (r *HypershiftDeploymentReconciler) hypershiftDeploymentOfManifestWork(obj client.Object) []reconcile.Request {
+	an := obj.GetAnnotations()
+
+	if len(an) == 0 || len(an[constant.CreatedByHypershiftDeployment]) == 0 {
+		return []reconcile.Request{}
+	}
+
+	res := strings.Split(an[constant.CreatedByHypershiftDeployment], constant.NamespaceNameSeperator)
+
+	if len(res) != 2 {
+		r.Log.Error(fmt.Errorf("failed to get manifestwork's hypershiftDeployment"), "")
+		return []reconcile.Request{}
+	}
+
+	req := reconcile.Request{
+		NamespacedName: types.NamespacedName{Namespace: res[0], Name: res[1]},
+	}
+
+	return []reconcile.Request{req}
+}
+
+// specSuperseded returns true when the HypershiftDeployment was changed or removed since the reconcile read it,
+// the reconcile is working on a stale spec and must not act on it
+func (r *HypershiftDeploymentReconciler) specSuperseded(ctx context.Context, hyd *hypdeployment.HypershiftDeployment) (bool, error) {
+	current := &hypdeployment.HypershiftDeployment{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(hyd), current); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+
+		return false, err
+	}
+
+	if current.Generation != hyd.Generation {
+		r.Log.Info(fmt.Sprintf("HypershiftDeployment %s changed from generation %d to %d during the reconcile, requeue",
+			client.ObjectKeyFromObject(hyd), hyd.Generation, current.Generation))
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// featureEnabled returns true if the feature is turned on in the feature gate of the controller
+func (r *HypershiftDeploymentReconciler) featureEnabled(f featuregate.Feature) bool {
+	return r.FeatureGate != nil && r.FeatureGate.Enabled(f)
 }
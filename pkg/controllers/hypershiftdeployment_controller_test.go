@@ -5,7 +5,10 @@ import (
 
 	"fmt"
 	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/go-logr/zapr"
@@ -25,6 +28,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"k8s.io/apimachinery/pkg/runtime"
 
@@ -35,6 +39,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	fake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -262,6 +267,41 @@ func TestScaffoldAzureHostedClusterSpec(t *testing.T) {
 	assert.Equal(t, oAzure.SubnetName, testHD.Spec.HostedClusterSpec.Platform.Azure.SubnetName, "SubnetName should be "+oAzure.SubnetName)
 }
 
+func TestScaffoldAzureHostedClusterSpecKeepsSuppliedConfig(t *testing.T) {
+	testHD := getHypershiftDeployment("default", "test1", false)
+	testHD.Spec.Infrastructure.Platform = &hyd.Platforms{Azure: &hyd.AzurePlatform{}}
+	testHD.Spec.HostedClusterSpec = &hyp.HostedClusterSpec{
+		Platform: hyp.PlatformSpec{
+			Azure: &hyp.AzurePlatformSpec{
+				ResourceGroupName: "my-resource-group",
+				VnetName:          "my-vnet",
+				VnetID:            "/subscriptions/sub/resourceGroups/my-resource-group/providers/Microsoft.Network/virtualNetworks/my-vnet",
+				SubnetName:        "my-subnet",
+			},
+		},
+	}
+
+	oAzure := getAzureInfrastructureOut()
+	ScaffoldAzureHostedClusterSpec(testHD, oAzure)
+
+	ap := testHD.Spec.HostedClusterSpec.Platform.Azure
+	assert.Equal(t, "my-resource-group", ap.ResourceGroupName, "supplied resource group is kept")
+	assert.Equal(t, "my-vnet", ap.VnetName, "supplied vnet name is kept")
+	assert.Equal(t, "/subscriptions/sub/resourceGroups/my-resource-group/providers/Microsoft.Network/virtualNetworks/my-vnet", ap.VnetID, "supplied vnet ID is kept")
+	assert.Equal(t, "my-subnet", ap.SubnetName, "supplied subnet is kept")
+	assert.Equal(t, oAzure.Location, ap.Location, "missing location is filled")
+	assert.Equal(t, oAzure.SecurityGroupName, ap.SecurityGroupName, "missing security group is filled")
+	assert.Equal(t, testHD.Name+constant.CCredsSuffix, ap.Credentials.Name, "missing credentials are filled")
+
+	r := GetHypershiftDeploymentReconciler()
+	hc, err := r.scaffoldHostedCluster(context.Background(), testHD)
+	assert.Nil(t, err, "err nil when the HostedCluster is scaffolded")
+	rg, _, _ := unstructured.NestedString(hc.Object, "spec", "platform", "azure", "resourceGroup")
+	assert.Equal(t, "my-resource-group", rg, "resource group is propagated to the HostedCluster")
+	subnet, _, _ := unstructured.NestedString(hc.Object, "spec", "platform", "azure", "subnetName")
+	assert.Equal(t, "my-subnet", subnet, "subnet is propagated to the HostedCluster")
+}
+
 func TestScaffoldAWSHostedCluster(t *testing.T) {
 	r := GetHypershiftDeploymentReconciler()
 	ctx := context.Background()
@@ -296,6 +336,48 @@ func TestScaffoldAzureHostedCluster(t *testing.T) {
 	t.Log("ScaffoldHostedCluster was successful")
 }
 
+func TestScaffoldAzureHostedClusterWithIdentity(t *testing.T) {
+	r := GetHypershiftDeploymentReconciler()
+	ctx := context.Background()
+
+	testHD := getHypershiftDeployment("default", "test1", true)
+	testHD.Spec.Infrastructure.Platform = &hyd.Platforms{Azure: &hyd.AzurePlatform{}}
+	testHD.Spec.Credentials = &hyd.CredentialARNs{AzureIdentity: &hyd.AzureIdentity{
+		ClientID:          "11111111-1111-1111-1111-111111111111",
+		TenantID:          "22222222-2222-2222-2222-222222222222",
+		SubscriptionID:    "33333333-3333-3333-3333-333333333333",
+		MachineIdentityID: "/subscriptions/33333333-3333-3333-3333-333333333333/resourceGroups/rg/providers/Microsoft.ManagedIdentity/userAssignedIdentities/nodes",
+	}}
+
+	ScaffoldAzureHostedClusterSpec(testHD, getAzureInfrastructureOut())
+	assert.Empty(t, testHD.Spec.HostedClusterSpec.Platform.Azure.Credentials.Name, "no credentials secret is generated with a managed identity")
+	assert.Nil(t, validateAzureIdentity(testHD), "err nil when only the managed identity is configured")
+
+	hc, err := r.scaffoldHostedCluster(ctx, testHD)
+	assert.Nil(t, err, "err nil when the HostedCluster is scaffolded")
+
+	_, found, _ := unstructured.NestedMap(hc.Object, "spec", "platform", "azure", "credentials")
+	assert.False(t, found, "the HostedCluster has no credentials secret")
+	subscription, _, _ := unstructured.NestedString(hc.Object, "spec", "platform", "azure", "subscriptionID")
+	assert.Equal(t, "33333333-3333-3333-3333-333333333333", subscription, "the subscription of the identity is set")
+	machineIdentity, _, _ := unstructured.NestedString(hc.Object, "spec", "platform", "azure", "machineIdentityID")
+	assert.Equal(t, testHD.Spec.Credentials.AzureIdentity.MachineIdentityID, machineIdentity, "the machine identity of the identity is set")
+	assert.Equal(t, "11111111-1111-1111-1111-111111111111", hc.GetAnnotations()[constant.AzureIdentityClientIDAnnotation], "the client id is carried as an annotation")
+	assert.Equal(t, "22222222-2222-2222-2222-222222222222", hc.GetAnnotations()[constant.AzureIdentityTenantIDAnnotation], "the tenant id is carried as an annotation")
+
+	payload := []workv1.Manifest{{RawExtension: runtime.RawExtension{Object: hc}}}
+	assert.Nil(t, r.appendHostedClusterReferenceSecrets(ctx, getProviderSecret())(testHD, &payload), "err nil when the secrets are copied")
+	secrets := 0
+	for _, m := range payload {
+		if s, ok := m.Object.(*corev1.Secret); ok {
+			secrets++
+			_, hasCreds := s.Data["AZURE_CLIENT_SECRET"]
+			assert.False(t, hasCreds, "the azure credentials secret %s is not copied", s.Name)
+		}
+	}
+	assert.NotZero(t, secrets, "the pull secret is copied")
+}
+
 func TestScaffoldAWSNodePoolSpec(t *testing.T) {
 
 	testHD := getHypershiftDeployment("default", "test1", true)
@@ -484,7 +566,7 @@ func TestConfigureFalseWithManifestWork(t *testing.T) {
 	testHD := getHypershiftDeployment(getNN.Namespace, getNN.Name, false)
 	testHD.Spec.HostingCluster = "local-host"
 	testHD.Spec.HostingNamespace = "multicluster-engine"
-	testHD.Spec.InfraID = getNN.Name + "-AB1YZ"
+	testHD.Spec.InfraID = getNN.Name + "-ab1yz"
 
 	client.Create(context.Background(), testHD)
 
@@ -520,7 +602,7 @@ func TestConfigureFalseWithManifestWorkWithObjectRef(t *testing.T) {
 	testHD := getHypershiftDeployment(getNN.Namespace, getNN.Name, false)
 	testHD.Spec.HostingCluster = "local-host"
 	testHD.Spec.HostingNamespace = "multicluster-engine"
-	testHD.Spec.InfraID = getNN.Name + "-AB1YZ"
+	testHD.Spec.InfraID = getNN.Name + "-ab1yz"
 
 	client.Create(context.Background(), testHD)
 
@@ -742,3 +824,231 @@ func TestLocalObjectReferencesForHCandNP(t *testing.T) {
 	assert.Len(t, nps, 1, "nodepool is added in manifestwork from hypershiftdeployment nodePoolRef")
 	assert.Equal(t, nps[0].GetNamespace(), testHD.Spec.HostingNamespace)
 }
+
+// slowClient blocks the Get of a secret until the context is done, like an api server struggling with a large secret
+type slowClient struct {
+	client.Client
+	slowSecret string
+}
+
+func (c *slowClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if _, ok := obj.(*corev1.Secret); ok && key.Name == c.slowSecret {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	return c.Client.Get(ctx, key, obj)
+}
+
+func TestReconcileTimeoutFairness(t *testing.T) {
+	ctx := context.Background()
+
+	slowHD := getHDforManifestWork()
+	slowHD.Name = "slow"
+	slowHD.Spec.HostingCluster = "local-cluster"
+	slowHD.Spec.InfraID = "slow-abcde"
+	slowHD.Spec.HostedClusterSpec.InfraID = slowHD.Spec.InfraID
+	slowHD.Spec.NodePools[0].Spec.ClusterName = slowHD.Name
+	slowHD.Spec.HostedClusterSpec.PullSecret.Name = "slow-pull-secret"
+
+	fastHD := getHDforManifestWork()
+	fastHD.Name = "fast"
+	fastHD.Spec.HostingCluster = "local-cluster"
+	fastHD.Spec.InfraID = "fast-abcde"
+	fastHD.Spec.HostedClusterSpec.InfraID = fastHD.Spec.InfraID
+	fastHD.Spec.NodePools[0].Spec.ClusterName = fastHD.Name
+	fastHD.Spec.HostedClusterSpec.PullSecret.Name = "fast-pull-secret"
+
+	c := &slowClient{Client: initClient(), slowSecret: "slow-pull-secret"}
+	for _, o := range []client.Object{slowHD, fastHD, getPullSecret(slowHD), getPullSecret(fastHD)} {
+		assert.Nil(t, c.Create(ctx, o), "is nil when the resource is created")
+	}
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client:           c,
+		Log:              ctrl.Log.WithName("tester"),
+		ReconcileTimeout: 200 * time.Millisecond,
+	}
+
+	start := time.Now()
+	res, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: slowHD.Namespace, Name: slowHD.Name}})
+	assert.Nil(t, err, "err nil when the slow reconcile is requeued")
+	assert.Equal(t, reconcileTimeoutRequeueAfter, res.RequeueAfter, "the slow HypershiftDeployment is requeued")
+	assert.Less(t, time.Since(start), 5*time.Second, "the slow reconcile is bounded by the timeout")
+
+	res, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: fastHD.Namespace, Name: fastHD.Name}})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.NotEqual(t, reconcileTimeoutRequeueAfter, res.RequeueAfter, "the other HypershiftDeployment is not affected")
+
+	var mw workv1.ManifestWork
+	assert.Nil(t, c.Get(ctx, getManifestWorkKey(fastHD), &mw), "the manifestwork of the other HypershiftDeployment is created")
+	assert.NotNil(t, c.Get(ctx, getManifestWorkKey(slowHD), &mw), "the manifestwork of the slow HypershiftDeployment is not created")
+}
+
+func TestGeneratedInfraID(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Spec.InfraID = ""
+	testHD.Spec.HostedClusterSpec.InfraID = ""
+	testHD.UID = "5a0b1b2e-a6b5-4b6a-9f1e-3e0c4b7f6d21"
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	var resultHD hyd.HypershiftDeployment
+	infraIDs := []string{}
+	for i := 0; i < 2; i++ {
+		_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+		assert.Nil(t, err, "err nil when reconcile was successful")
+
+		assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+		infraIDs = append(infraIDs, resultHD.Spec.InfraID)
+	}
+
+	assert.Regexp(t, "^test1-[a-z0-9]{5}$", infraIDs[0], "the infra-id is generated from the name")
+	assert.Equal(t, infraIDs[0], infraIDs[1], "the infra-id is persisted")
+	assert.Equal(t, helper.InfraIDFor(testHD.Name, testHD.UID), infraIDs[0], "the infra-id is derived from the uid")
+
+	var mw workv1.ManifestWork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(&resultHD), &mw), "the manifestwork is named after the generated infra-id")
+}
+
+func TestGeneratedInfraIDConcurrently(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	// without uid every generation is random, two persisted generations would differ
+	testHD := getHDforManifestWork()
+	testHD.Spec.InfraID = ""
+	testHD.Spec.HostedClusterSpec.InfraID = ""
+	testHD.UID = ""
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	const reconciles = 10
+	infraIDs := make([]string, reconciles)
+	errs := make([]error, reconciles)
+
+	var wg sync.WaitGroup
+	for i := 0; i < reconciles; i++ {
+		var inHD hyd.HypershiftDeployment
+		assert.Nil(t, client.Get(ctx, getNN, &inHD), "is nil when HypershiftDeployment resource is found")
+
+		wg.Add(1)
+		go func(i int, hd *hyd.HypershiftDeployment) {
+			defer wg.Done()
+			errs[i] = hdr.ensureInfraID(ctx, hd)
+			infraIDs[i] = hd.Spec.InfraID
+		}(i, &inHD)
+	}
+	wg.Wait()
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.Regexp(t, "^test1-[a-z0-9]{5}$", resultHD.Spec.InfraID, "the infra-id is generated from the name")
+	for i := 0; i < reconciles; i++ {
+		assert.Nil(t, errs[i], "err nil when the infra-id is generated or adopted")
+		assert.Equal(t, resultHD.Spec.InfraID, infraIDs[i], "every reconcile uses the persisted infra-id")
+	}
+
+	// a stale copy adopts the infra-id persisted since it was read
+	staleHD := testHD.DeepCopy()
+	staleHD.Spec.InfraID = ""
+	assert.Nil(t, hdr.ensureInfraID(ctx, staleHD), "err nil when the infra-id is adopted")
+	assert.Equal(t, resultHD.Spec.InfraID, staleHD.Spec.InfraID, "the persisted infra-id is adopted")
+}
+
+func TestGeneratedInfraIDNameTooLong(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Name = strings.Repeat("a", 58)
+	testHD.Spec.InfraID = ""
+	testHD.Spec.HostedClusterSpec.InfraID = ""
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	key := types.NamespacedName{Namespace: testHD.Namespace, Name: testHD.Name}
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	assert.Nil(t, err, "err nil when the name is reported")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, key, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.Empty(t, resultHD.Spec.InfraID, "no infra-id is generated")
+
+	c := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.WorkConfigured))
+	assert.NotNil(t, c, "WorkConfigured condition is reported")
+	assert.Equal(t, metav1.ConditionFalse, c.Status, "false when the name is too long")
+	assert.Contains(t, c.Message, "too long to generate an infra-id")
+}
+
+func TestHypershiftDeploymentChangedPredicate(t *testing.T) {
+	oldHyd := getHypershiftDeployment("default", "test1", false)
+	oldHyd.Generation = 1
+	oldHyd.ResourceVersion = "1"
+
+	cases := []struct {
+		name   string
+		update func(*hyd.HypershiftDeployment)
+		expect bool
+	}{
+		{"status only", func(h *hyd.HypershiftDeployment) {
+			h.ResourceVersion = "2"
+			h.Status.Ready = true
+			meta.SetStatusCondition(&h.Status.Conditions, metav1.Condition{Type: string(hyd.Ready), Status: metav1.ConditionTrue, Reason: "Ready"})
+		}, false},
+		{"generation", func(h *hyd.HypershiftDeployment) {
+			h.ResourceVersion = "2"
+			h.Generation = 2
+		}, true},
+		{"annotations", func(h *hyd.HypershiftDeployment) {
+			h.ResourceVersion = "2"
+			h.Annotations = map[string]string{"test": "true"}
+		}, true},
+		{"deletion", func(h *hyd.HypershiftDeployment) {
+			h.ResourceVersion = "2"
+			now := metav1.Now()
+			h.DeletionTimestamp = &now
+		}, true},
+		{"finalizers", func(h *hyd.HypershiftDeployment) {
+			h.ResourceVersion = "2"
+			h.Finalizers = []string{constant.DestroyFinalizer}
+		}, true},
+		{"labels", func(h *hyd.HypershiftDeployment) {
+			h.ResourceVersion = "2"
+			h.Labels = map[string]string{constant.ReconcilePriorityLabel: "10"}
+		}, true},
+	}
+
+	for _, c := range cases {
+		newHyd := oldHyd.DeepCopy()
+		c.update(newHyd)
+		assert.Equal(t, c.expect, hypershiftDeploymentChanged.Update(event.UpdateEvent{ObjectOld: oldHyd, ObjectNew: newHyd}), c.name)
+	}
+
+	assert.True(t, hypershiftDeploymentChanged.Create(event.CreateEvent{Object: oldHyd}), "create")
+	assert.True(t, hypershiftDeploymentChanged.Delete(event.DeleteEvent{Object: oldHyd}), "delete")
+}
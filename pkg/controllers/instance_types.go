@@ -0,0 +1,119 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// instanceTypeFields are the NodePool spec fields holding the instance type of each platform
+var instanceTypeFields = [][]string{
+	{"spec", "platform", "aws", "instanceType"},
+	{"spec", "platform", "azure", "vmSize"},
+}
+
+// InstanceTypeAliases maps friendly names to the instance types of the cloud providers, ie small=t3.large
+type InstanceTypeAliases struct {
+	Aliases map[string]string
+
+	// Strict rejects the instance types that are neither an alias nor one of the instance types they
+	// resolve to, otherwise they are used as is. It has no effect without aliases
+	Strict bool
+}
+
+func (a *InstanceTypeAliases) String() string {
+	if a == nil {
+		return ""
+	}
+
+	pairs := []string{}
+	for alias, instanceType := range a.Aliases {
+		pairs = append(pairs, alias+"="+instanceType)
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// Set parses a comma separated list of alias=instanceType pairs, so the aliases can be set with a flag
+func (a *InstanceTypeAliases) Set(value string) error {
+	if a.Aliases == nil {
+		a.Aliases = map[string]string{}
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		if len(strings.TrimSpace(pair)) == 0 {
+			continue
+		}
+
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || len(strings.TrimSpace(kv[0])) == 0 || len(strings.TrimSpace(kv[1])) == 0 {
+			return fmt.Errorf("instance type alias %q is invalid, must be alias=instanceType", pair)
+		}
+
+		a.Aliases[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+
+	return nil
+}
+
+func (a *InstanceTypeAliases) resolve(instanceType string) (string, error) {
+	if a == nil || len(a.Aliases) == 0 {
+		return instanceType, nil
+	}
+
+	if canonical, ok := a.Aliases[instanceType]; ok {
+		return canonical, nil
+	}
+
+	if !a.Strict {
+		return instanceType, nil
+	}
+
+	for _, canonical := range a.Aliases {
+		if canonical == instanceType {
+			return instanceType, nil
+		}
+	}
+
+	return "", fmt.Errorf("instance type %q is not a known alias or instance type", instanceType)
+}
+
+// resolveInstanceTypeAliases replaces the instance type aliases of a NodePool with the instance types they map to
+func resolveInstanceTypeAliases(np *unstructured.Unstructured, aliases *InstanceTypeAliases) error {
+	for _, field := range instanceTypeFields {
+		instanceType, found, err := unstructured.NestedString(np.Object, field...)
+		if err != nil || !found || len(instanceType) == 0 {
+			continue
+		}
+
+		canonical, err := aliases.resolve(instanceType)
+		if err != nil {
+			return fmt.Errorf("nodePool %s: %w", np.GetName(), err)
+		}
+
+		if err := unstructured.SetNestedField(np.Object, canonical, field...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
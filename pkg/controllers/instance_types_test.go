@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+func TestInstanceTypeAliasesSet(t *testing.T) {
+	aliases := &InstanceTypeAliases{}
+	assert.Nil(t, aliases.Set("small=t3.large, medium=m5.xlarge"), "err nil when the aliases are valid")
+	assert.Equal(t, map[string]string{"small": "t3.large", "medium": "m5.xlarge"}, aliases.Aliases)
+	assert.Equal(t, "medium=m5.xlarge,small=t3.large", aliases.String())
+
+	assert.NotNil(t, aliases.Set("small"), "err when the instance type is missing")
+	assert.NotNil(t, aliases.Set("=t3.large"), "err when the alias is missing")
+}
+
+func TestScaffoldNodePoolInstanceTypeAliases(t *testing.T) {
+	testHD := getHDforManifestWork()
+	aliases := &InstanceTypeAliases{Aliases: map[string]string{"small": "t3.large", "azure-small": "Standard_D4s_v4"}}
+
+	cases := []struct {
+		name     string
+		field    []string
+		in       string
+		strict   bool
+		expected string
+		err      bool
+	}{
+		{"aws alias", []string{"aws", "instanceType"}, "small", false, "t3.large", false},
+		{"azure alias", []string{"azure", "vmSize"}, "azure-small", false, "Standard_D4s_v4", false},
+		{"instance type", []string{"aws", "instanceType"}, "m5.xlarge", false, "m5.xlarge", false},
+		{"aliased instance type under strict", []string{"aws", "instanceType"}, "t3.large", true, "t3.large", false},
+		{"alias under strict", []string{"aws", "instanceType"}, "small", true, "t3.large", false},
+		{"unknown alias under strict", []string{"aws", "instanceType"}, "tiny", true, "", true},
+	}
+
+	for _, c := range cases {
+		aliases.Strict = c.strict
+		npSpec := map[string]interface{}{}
+		assert.Nil(t, unstructured.SetNestedField(npSpec, c.in, append([]string{"platform"}, c.field...)...))
+
+		np, err := ScaffoldNodePool(testHD, "np1", npSpec, aliases)
+		if c.err {
+			assert.NotNil(t, err, c.name)
+			if err != nil {
+				assert.Equal(t, `nodePool np1: instance type "tiny" is not a known alias or instance type`, err.Error(), c.name)
+			}
+			continue
+		}
+
+		assert.Nil(t, err, c.name)
+		instanceType, _, _ := unstructured.NestedString(np.Object, append([]string{"spec", "platform"}, c.field...)...)
+		assert.Equal(t, c.expected, instanceType, c.name)
+	}
+
+	// no aliases configured
+	npSpec := map[string]interface{}{"platform": map[string]interface{}{"aws": map[string]interface{}{"instanceType": "small"}}}
+	np, err := ScaffoldNodePool(testHD, "np1", npSpec, nil)
+	assert.Nil(t, err, "err nil without aliases")
+	instanceType, _, _ := unstructured.NestedString(np.Object, "spec", "platform", "aws", "instanceType")
+	assert.Equal(t, "small", instanceType, "instance type is used as is without aliases")
+}
+
+func TestUnknownInstanceTypeAliasCondition(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Spec.NodePools[0].Spec.Platform.AWS.InstanceType = "tiny"
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client:              client,
+		Log:                 ctrl.Log.WithName("tester"),
+		InstanceTypeAliases: &InstanceTypeAliases{Aliases: map[string]string{"small": "t3.large"}, Strict: true},
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.NotNil(t, err, "err when the instance type is unknown")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+
+	c := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.WorkConfigured))
+	assert.NotNil(t, c, "WorkConfigured condition is reported")
+	if c != nil {
+		assert.Equal(t, metav1.ConditionFalse, c.Status, "false when the instance type is unknown")
+		assert.Equal(t, hyd.MisConfiguredReason, c.Reason)
+		assert.Contains(t, c.Message, `instance type "tiny" is not a known alias or instance type`)
+	}
+
+	var mw workv1.ManifestWork
+	assert.NotNil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "the manifestwork is not created")
+
+	// the alias resolves to the instance type
+	resultHD.Spec.NodePools[0].Spec.Platform.AWS.InstanceType = "small"
+	assert.Nil(t, client.Update(ctx, &resultHD), "is nil when the HypershiftDeployment is updated")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "the manifestwork is created")
+	np := getNodePoolInManifestWork(t, &mw, testHD.Spec.NodePools[0].Name)
+	assert.NotNil(t, np, "NodePool is in the manifestwork")
+	instanceType, _, _ := unstructured.NestedString(np.Object, "spec", "platform", "aws", "instanceType")
+	assert.Equal(t, "t3.large", instanceType, "alias is resolved in the manifestwork")
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	hyp "github.com/openshift/hypershift/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
+)
+
+// kubeconfigPullRequeue is how often the kubeconfig is looked for on the hub until it is synced from the HostingCluster
+const kubeconfigPullRequeue = 30 * time.Second
+
+func hubKubeconfigName(hyd *hypdeployment.HypershiftDeployment) string {
+	return hyd.Name + "-admin-kubeconfig"
+}
+
+// kubeconfigStatusFeedback locates the admin kubeconfig secret from the HostedCluster feedback, it returns false
+// until the HostedCluster is available and reports its kubeconfig. The hub copy of the status is kept.
+func kubeconfigStatusFeedback(m *workv1.ManifestWork, hyd *hypdeployment.HypershiftDeployment) (*hypdeployment.KubeconfigStatus, bool) {
+	if m == nil || hyd == nil {
+		return nil, false
+	}
+
+	id := workv1.ResourceIdentifier{
+		Group:     hyp.GroupVersion.Group,
+		Resource:  HostedClusterResource,
+		Name:      hyd.Name,
+		Namespace: helper.GetHostingNamespace(hyd),
+	}
+
+	for _, obj := range m.Status.ResourceStatus.Manifests {
+		if resourceMeta(obj.ResourceMeta).ToIdentifier() != id {
+			continue
+		}
+
+		available, ok := feedbackToCondition(hypdeployment.HostedClusterAvailable, obj.StatusFeedbacks.Values)
+		if !ok || available.Status != "True" {
+			return nil, false
+		}
+
+		for _, v := range obj.StatusFeedbacks.Values {
+			if v.Name != KubeconfigName || v.Value.String == nil || len(*v.Value.String) == 0 {
+				continue
+			}
+
+			kubeconfig := &hypdeployment.KubeconfigStatus{Name: *v.Value.String, Namespace: id.Namespace}
+			if hyd.Status.Kubeconfig != nil {
+				kubeconfig.HubSecret = hyd.Status.Kubeconfig.HubSecret
+			}
+			return kubeconfig, true
+		}
+	}
+
+	return nil, false
+}
+
+// pullKubeconfig copies the admin kubeconfig, synced from the HostingCluster to its namespace on the hub, to the
+// HypershiftDeployment namespace while spec.pullKubeconfigToHub is true, the copy is removed once it is false.
+// It returns true while the kubeconfig is not synced to the hub yet. The caller persists the status.
+func (r *HypershiftDeploymentReconciler) pullKubeconfig(ctx context.Context, hyd *hypdeployment.HypershiftDeployment) (bool, error) {
+	if !hyd.Spec.PullKubeconfigToHub {
+		return false, r.removeHubKubeconfig(ctx, hyd)
+	}
+
+	if hyd.Status.Kubeconfig == nil {
+		return false, nil
+	}
+
+	synced := &corev1.Secret{}
+	syncedKey := types.NamespacedName{Namespace: helper.GetHostingCluster(hyd), Name: helper.HostedKubeconfigName(hyd)}
+	if err := r.Get(ctx, syncedKey, synced); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.Log.Info(fmt.Sprintf("wait for the kubeconfig %s to be synced to the hub", syncedKey))
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to get the kubeconfig %s, err: %w", syncedKey, err)
+	}
+
+	secret := &corev1.Secret{}
+	secret.SetName(hubKubeconfigName(hyd))
+	secret.SetNamespace(hyd.Namespace)
+
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		if secret.Labels == nil {
+			secret.Labels = map[string]string{}
+		}
+		secret.Labels[constant.InfraLabelName] = hyd.Spec.InfraID
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+		secret.Annotations[constant.SourceSecretAnnotation] = syncedKey.String()
+		secret.Type = synced.Type
+		secret.Data = synced.Data
+		return nil
+	}); err != nil {
+		return false, fmt.Errorf("failed to copy the kubeconfig to %s/%s, err: %w", secret.Namespace, secret.Name, err)
+	}
+
+	hyd.Status.Kubeconfig.HubSecret = &corev1.LocalObjectReference{Name: secret.Name}
+	return false, nil
+}
+
+// removeHubKubeconfig deletes the hub copy of the kubeconfig, the caller persists the status
+func (r *HypershiftDeploymentReconciler) removeHubKubeconfig(ctx context.Context, hyd *hypdeployment.HypershiftDeployment) error {
+	if hyd.Status.Kubeconfig == nil || hyd.Status.Kubeconfig.HubSecret == nil {
+		return nil
+	}
+
+	secret := &corev1.Secret{}
+	secret.SetName(hyd.Status.Kubeconfig.HubSecret.Name)
+	secret.SetNamespace(hyd.Namespace)
+	if err := r.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete the kubeconfig %s/%s, err: %w", secret.Namespace, secret.Name, err)
+	}
+
+	hyd.Status.Kubeconfig.HubSecret = nil
+	return nil
+}
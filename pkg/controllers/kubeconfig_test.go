@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	hyp "github.com/openshift/hypershift/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
+)
+
+func TestKubeconfigStatusFeedback(t *testing.T) {
+	testHD := getHDforManifestWork()
+
+	str := func(s string) workv1.FeedbackValue {
+		return workv1.FeedbackValue{Value: workv1.FieldValue{Type: workv1.String, String: &s}}
+	}
+	named := func(name string, v workv1.FeedbackValue) workv1.FeedbackValue {
+		v.Name = name
+		return v
+	}
+	hcStatus := func(values ...workv1.FeedbackValue) workv1.ManifestCondition {
+		return workv1.ManifestCondition{
+			ResourceMeta: workv1.ManifestResourceMeta{
+				Group:     hyp.GroupVersion.Group,
+				Resource:  HostedClusterResource,
+				Name:      testHD.Name,
+				Namespace: helper.GetHostingNamespace(testHD),
+			},
+			StatusFeedbacks: workv1.StatusFeedbackResult{Values: values},
+		}
+	}
+
+	mw := &workv1.ManifestWork{}
+	syncManifestworkStatusToHypershiftDeployment(testHD, mw)
+	assert.Nil(t, testHD.Status.Kubeconfig, "nil until the work agent reports the HostedCluster")
+
+	// the kubeconfig is reported before the HostedCluster is available
+	mw.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{hcStatus(
+		named(Reason, str("WaitingForAvailable")), named(StatusFlag, str("False")), named(KubeconfigName, str("test1-admin-kubeconfig")),
+	)}
+	syncManifestworkStatusToHypershiftDeployment(testHD, mw)
+	assert.Nil(t, testHD.Status.Kubeconfig, "nil until the HostedCluster is available")
+
+	mw.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{hcStatus(
+		named(Reason, str("AsExpected")), named(StatusFlag, str("True")), named(KubeconfigName, str("test1-admin-kubeconfig")),
+	)}
+	syncManifestworkStatusToHypershiftDeployment(testHD, mw)
+	if assert.NotNil(t, testHD.Status.Kubeconfig, "set once the HostedCluster is available") {
+		assert.Equal(t, "test1-admin-kubeconfig", testHD.Status.Kubeconfig.Name)
+		assert.Equal(t, helper.GetHostingNamespace(testHD), testHD.Status.Kubeconfig.Namespace)
+	}
+	assert.Equal(t, helper.GetHostingNamespace(testHD)+"-test1-admin-kubeconfig", helper.HostedKubeconfigName(testHD), "the hub name follows the status")
+
+	// the hub copy is kept, and so is the status once the HostedCluster is no longer available
+	testHD.Status.Kubeconfig.HubSecret = &corev1.LocalObjectReference{Name: hubKubeconfigName(testHD)}
+	syncManifestworkStatusToHypershiftDeployment(testHD, mw)
+	assert.NotNil(t, testHD.Status.Kubeconfig.HubSecret, "the hub copy is kept")
+
+	mw.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{hcStatus(named(Reason, str("Degraded")), named(StatusFlag, str("False")))}
+	syncManifestworkStatusToHypershiftDeployment(testHD, mw)
+	assert.NotNil(t, testHD.Status.Kubeconfig, "kept while the HostedCluster is not available")
+}
+
+func TestPullKubeconfig(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Spec.PullKubeconfigToHub = true
+
+	pending, err := hdr.pullKubeconfig(ctx, testHD)
+	assert.Nil(t, err)
+	assert.False(t, pending, "nothing to pull before the kubeconfig is reported")
+
+	testHD.Status.Kubeconfig = &hyd.KubeconfigStatus{Name: testHD.Name + "-admin-kubeconfig", Namespace: helper.GetHostingNamespace(testHD)}
+	pending, err = hdr.pullKubeconfig(ctx, testHD)
+	assert.Nil(t, err)
+	assert.True(t, pending, "pending until the kubeconfig is synced to the hub")
+	assert.Nil(t, testHD.Status.Kubeconfig.HubSecret)
+
+	synced := &corev1.Secret{Data: map[string][]byte{"kubeconfig": []byte("admin-kubeconfig")}}
+	synced.Name = helper.HostedKubeconfigName(testHD)
+	synced.Namespace = testHD.Spec.HostingCluster
+	assert.Nil(t, client.Create(ctx, synced))
+
+	pending, err = hdr.pullKubeconfig(ctx, testHD)
+	assert.Nil(t, err)
+	assert.False(t, pending)
+	if assert.NotNil(t, testHD.Status.Kubeconfig.HubSecret, "the hub copy is referenced") {
+		copied := &corev1.Secret{}
+		assert.Nil(t, client.Get(ctx, types.NamespacedName{Namespace: testHD.Namespace, Name: testHD.Status.Kubeconfig.HubSecret.Name}, copied))
+		assert.Equal(t, synced.Data, copied.Data, "the kubeconfig is copied")
+		assert.Equal(t, synced.Namespace+"/"+synced.Name, copied.Annotations[constant.SourceSecretAnnotation])
+	}
+
+	// the copy is removed once the pull is turned off
+	testHD.Spec.PullKubeconfigToHub = false
+	pending, err = hdr.pullKubeconfig(ctx, testHD)
+	assert.Nil(t, err)
+	assert.False(t, pending)
+	assert.Nil(t, testHD.Status.Kubeconfig.HubSecret, "the hub copy is no longer referenced")
+	err = client.Get(ctx, types.NamespacedName{Namespace: testHD.Namespace, Name: hubKubeconfigName(testHD)}, &corev1.Secret{})
+	assert.True(t, apierrors.IsNotFound(err), "the hub copy is deleted")
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+const maintenanceWindowStartLayout = "15:04"
+
+var weekdays = map[hypdeployment.Weekday]time.Weekday{
+	"Sunday":    time.Sunday,
+	"Monday":    time.Monday,
+	"Tuesday":   time.Tuesday,
+	"Wednesday": time.Wednesday,
+	"Thursday":  time.Thursday,
+	"Friday":    time.Friday,
+	"Saturday":  time.Saturday,
+}
+
+func (r *HypershiftDeploymentReconciler) currentTime() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+
+	return time.Now()
+}
+
+func validateMaintenanceWindow(w *hypdeployment.MaintenanceWindow) error {
+	if w == nil {
+		return nil
+	}
+
+	if _, err := time.Parse(maintenanceWindowStartLayout, w.Start); err != nil {
+		return fmt.Errorf("maintenanceWindow start %q is invalid, must be in the HH:MM format", w.Start)
+	}
+
+	if w.Duration.Duration <= 0 || w.Duration.Duration > 24*time.Hour {
+		return fmt.Errorf("maintenanceWindow duration %s is invalid, must be greater than 0 and at most 24h", w.Duration.Duration)
+	}
+
+	for _, d := range w.Days {
+		if _, ok := weekdays[d]; !ok {
+			return fmt.Errorf("maintenanceWindow day %q is invalid, must be a day of the week", d)
+		}
+	}
+
+	return nil
+}
+
+// maintenanceWindowOpen returns true if now is inside the window, otherwise it returns the start
+// of the next window. The window must be validated first.
+func maintenanceWindowOpen(w *hypdeployment.MaintenanceWindow, now time.Time) (bool, time.Time) {
+	start, _ := time.Parse(maintenanceWindowStartLayout, w.Start)
+
+	allowed := func(d time.Weekday) bool {
+		if len(w.Days) == 0 {
+			return true
+		}
+
+		for _, day := range w.Days {
+			if weekdays[day] == d {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	now = now.UTC()
+	// start from yesterday, a window of up to 24h can still be open
+	for i := -1; i <= 7; i++ {
+		windowStart := time.Date(now.Year(), now.Month(), now.Day()+i, start.Hour(), start.Minute(), 0, 0, time.UTC)
+		if !allowed(windowStart.Weekday()) {
+			continue
+		}
+
+		if windowStart.After(now) {
+			return false, windowStart
+		}
+
+		if now.Before(windowStart.Add(w.Duration.Duration)) {
+			return true, windowStart
+		}
+	}
+
+	return false, time.Time{}
+}
+
+func manifestToUnstructured(m workv1.Manifest) (*unstructured.Unstructured, error) {
+	u := &unstructured.Unstructured{}
+
+	switch {
+	case len(m.Raw) != 0:
+		if err := u.UnmarshalJSON(m.Raw); err != nil {
+			return nil, err
+		}
+	case m.Object != nil:
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(m.Object)
+		if err != nil {
+			return nil, err
+		}
+		u.Object = obj
+	}
+
+	return u, nil
+}
+
+// deferDisruptiveChanges keeps the applied values in the payload for the changes that disrupt the
+// workloads, a NodePool scale down and a release upgrade of the HostedCluster or a NodePool. The
+// rest of the payload is left as is. It returns a description of each deferred change.
+func deferDisruptiveChanges(applied []workv1.Manifest, payload []workv1.Manifest) ([]string, error) {
+	existing := map[string]*unstructured.Unstructured{}
+	for _, m := range applied {
+		u, err := manifestToUnstructured(m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the applied manifestwork payload, err: %w", err)
+		}
+
+		existing[u.GetKind()+"/"+u.GetName()] = u
+	}
+
+	deferred := []string{}
+	for _, m := range payload {
+		u, ok := m.Object.(*unstructured.Unstructured)
+		if !ok || (u.GetKind() != "HostedCluster" && u.GetKind() != "NodePool") {
+			continue
+		}
+
+		old, ok := existing[u.GetKind()+"/"+u.GetName()]
+		if !ok {
+			continue
+		}
+
+		oldImage, _, _ := unstructured.NestedString(old.Object, "spec", "release", "image")
+		newImage, _, _ := unstructured.NestedString(u.Object, "spec", "release", "image")
+		if len(oldImage) != 0 && oldImage != newImage {
+			if err := unstructured.SetNestedField(u.Object, oldImage, "spec", "release", "image"); err != nil {
+				return nil, err
+			}
+			deferred = append(deferred, fmt.Sprintf("%s %s release %s", u.GetKind(), u.GetName(), newImage))
+		}
+
+		if u.GetKind() != "NodePool" {
+			continue
+		}
+
+		oldReplicas, oldFound, _ := unstructured.NestedInt64(old.Object, "spec", "replicas")
+		newReplicas, newFound, _ := unstructured.NestedInt64(u.Object, "spec", "replicas")
+		if oldFound && newFound && newReplicas < oldReplicas {
+			if err := unstructured.SetNestedField(u.Object, oldReplicas, "spec", "replicas"); err != nil {
+				return nil, err
+			}
+			deferred = append(deferred, fmt.Sprintf("NodePool %s scale down from %d to %d replicas", u.GetName(), oldReplicas, newReplicas))
+		}
+	}
+
+	return deferred, nil
+}
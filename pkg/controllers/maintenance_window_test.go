@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+func TestValidateMaintenanceWindow(t *testing.T) {
+	cases := []struct {
+		name   string
+		window *hyd.MaintenanceWindow
+		valid  bool
+	}{
+		{"no window", nil, true},
+		{"every day", &hyd.MaintenanceWindow{Start: "22:00", Duration: metav1.Duration{Duration: 4 * time.Hour}}, true},
+		{"weekend", &hyd.MaintenanceWindow{Start: "01:30", Duration: metav1.Duration{Duration: 24 * time.Hour}, Days: []hyd.Weekday{"Saturday", "Sunday"}}, true},
+		{"invalid start", &hyd.MaintenanceWindow{Start: "25:00", Duration: metav1.Duration{Duration: time.Hour}}, false},
+		{"no duration", &hyd.MaintenanceWindow{Start: "22:00"}, false},
+		{"duration longer than a day", &hyd.MaintenanceWindow{Start: "22:00", Duration: metav1.Duration{Duration: 25 * time.Hour}}, false},
+		{"invalid day", &hyd.MaintenanceWindow{Start: "22:00", Duration: metav1.Duration{Duration: time.Hour}, Days: []hyd.Weekday{"Someday"}}, false},
+	}
+
+	for _, c := range cases {
+		err := validateMaintenanceWindow(c.window)
+		assert.Equal(t, c.valid, err == nil, c.name)
+	}
+}
+
+func TestMaintenanceWindowOpen(t *testing.T) {
+	// Monday
+	now := time.Date(2022, time.May, 2, 23, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name   string
+		window *hyd.MaintenanceWindow
+		open   bool
+		next   time.Time
+	}{
+		{
+			name:   "open today",
+			window: &hyd.MaintenanceWindow{Start: "22:00", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+			open:   true,
+		},
+		{
+			name:   "closed today",
+			window: &hyd.MaintenanceWindow{Start: "20:00", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+			next:   time.Date(2022, time.May, 3, 20, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "still open from yesterday",
+			window: &hyd.MaintenanceWindow{Start: "23:30", Duration: metav1.Duration{Duration: 24 * time.Hour}, Days: []hyd.Weekday{"Sunday"}},
+			open:   true,
+		},
+		{
+			name:   "next on another day",
+			window: &hyd.MaintenanceWindow{Start: "22:00", Duration: metav1.Duration{Duration: 2 * time.Hour}, Days: []hyd.Weekday{"Saturday"}},
+			next:   time.Date(2022, time.May, 7, 22, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, c := range cases {
+		open, next := maintenanceWindowOpen(c.window, now)
+		assert.Equal(t, c.open, open, c.name)
+		if !c.open {
+			assert.Equal(t, c.next, next, c.name)
+		}
+	}
+}
+
+func getNodePoolInManifestWork(t *testing.T, mw *workv1.ManifestWork, name string) *unstructured.Unstructured {
+	for _, m := range mw.Spec.Workload.Manifests {
+		u, err := manifestToUnstructured(m)
+		assert.Nil(t, err, "err nil when the manifest is readable")
+
+		if u.GetKind() == "NodePool" && u.GetName() == name {
+			return u
+		}
+	}
+
+	return nil
+}
+
+func TestMaintenanceWindowDefersDisruptiveChanges(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	replicas := int32(3)
+	testHD.Spec.NodePools[0].Spec.Replicas = &replicas
+	testHD.Spec.NodePools[0].Spec.Release.Image = "quay.io/openshift-release-dev/ocp-release:4.10.15-x86_64"
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	// Monday 12:00
+	now := time.Date(2022, time.May, 2, 12, 0, 0, 0, time.UTC)
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+		now:    func() time.Time { return now },
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+
+	resultHD.Spec.MaintenanceWindow = &hyd.MaintenanceWindow{Start: "22:00", Duration: metav1.Duration{Duration: 4 * time.Hour}}
+	replicas = int32(1)
+	resultHD.Spec.NodePools[0].Spec.Replicas = &replicas
+	resultHD.Spec.NodePools[0].Spec.Release.Image = "quay.io/openshift-release-dev/ocp-release:4.10.16-x86_64"
+	resultHD.Spec.NodePools[0].Spec.Management.AutoRepair = true
+	assert.Nil(t, client.Update(ctx, &resultHD), "is nil when the HypershiftDeployment is updated")
+
+	// outside of the window
+	res, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Equal(t, 10*time.Hour, res.RequeueAfter, "requeued at the start of the maintenance window")
+
+	var mw workv1.ManifestWork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+
+	np := getNodePoolInManifestWork(t, &mw, testHD.Spec.NodePools[0].Name)
+	assert.NotNil(t, np, "NodePool is in the manifestwork")
+	npReplicas, _, _ := unstructured.NestedInt64(np.Object, "spec", "replicas")
+	assert.Equal(t, int64(3), npReplicas, "scale down is deferred")
+	npImage, _, _ := unstructured.NestedString(np.Object, "spec", "release", "image")
+	assert.Equal(t, "quay.io/openshift-release-dev/ocp-release:4.10.15-x86_64", npImage, "release upgrade is deferred")
+	autoRepair, _, _ := unstructured.NestedBool(np.Object, "spec", "management", "autoRepair")
+	assert.True(t, autoRepair, "non disruptive change is applied")
+
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	cond := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.DeferredUntil))
+	assert.NotNil(t, cond, "DeferredUntil condition is set")
+	assert.Equal(t, metav1.ConditionTrue, cond.Status, "changes are deferred")
+	assert.Equal(t, hyd.MaintenanceWindowReason, cond.Reason)
+	assert.Contains(t, cond.Message, "2022-05-02T22:00:00Z", "message contains the start of the window")
+	assert.True(t, meta.IsStatusConditionTrue(resultHD.Status.Conditions, string(hyd.WorkConfigured)), "manifestwork is configured")
+
+	// inside of the window
+	now = time.Date(2022, time.May, 3, 1, 0, 0, 0, time.UTC)
+	res, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Zero(t, res.RequeueAfter, "not requeued inside of the maintenance window")
+
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+
+	np = getNodePoolInManifestWork(t, &mw, testHD.Spec.NodePools[0].Name)
+	npReplicas, _, _ = unstructured.NestedInt64(np.Object, "spec", "replicas")
+	assert.Equal(t, int64(1), npReplicas, "scale down is applied")
+	npImage, _, _ = unstructured.NestedString(np.Object, "spec", "release", "image")
+	assert.Equal(t, "quay.io/openshift-release-dev/ocp-release:4.10.16-x86_64", npImage, "release upgrade is applied")
+
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	cond = meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.DeferredUntil))
+	assert.Equal(t, metav1.ConditionFalse, cond.Status, "nothing is deferred")
+}
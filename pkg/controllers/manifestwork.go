@@ -17,14 +17,20 @@ limitations under the License.
 package controllers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	hyp "github.com/openshift/hypershift/api/v1alpha1"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	condmeta "k8s.io/apimachinery/pkg/api/meta"
@@ -34,6 +40,8 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
 	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
 	workv1 "open-cluster-management.io/api/work/v1"
@@ -44,6 +52,7 @@ import (
 	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
 	hydclient "github.com/stolostron/hypershift-deployment-controller/pkg/client"
 	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/features"
 	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
 )
 
@@ -55,7 +64,13 @@ var (
 	StatusFlag            = "status"
 	Message               = "message"
 	Progress              = "progress"
+	APIEndpointHost       = "apiEndpointHost"
+	APIEndpointPort       = "apiEndpointPort"
+	DesiredReplicas       = "desiredReplicas"
+	CurrentReplicas       = "currentReplicas"
+	CreationTimestamp     = "creationTimestamp"
 	OwnerReference        = "owner"
+	KubeconfigName        = "kubeconfigName"
 )
 
 //loadManifest will get hostedclsuter's crs and put them to the manifest array
@@ -74,6 +89,10 @@ func scaffoldManifestwork(hyd *hypdeployment.HypershiftDeployment) (*workv1.Mani
 		return nil, fmt.Errorf("hypershiftDeployment.Spec.InfraID is not set or rendered")
 	}
 
+	if err := validateManifestWorkName(hyd.Spec.InfraID); err != nil {
+		return nil, err
+	}
+
 	k := getManifestWorkKey(hyd)
 
 	w := &workv1.ManifestWork{
@@ -84,10 +103,7 @@ func scaffoldManifestwork(hyd *hypdeployment.HypershiftDeployment) (*workv1.Mani
 			Name:      k.Name,
 			Namespace: k.Namespace,
 			Annotations: map[string]string{
-				constant.CreatedByHypershiftDeployment: fmt.Sprintf("%s%s%s",
-					hyd.GetNamespace(),
-					constant.NamespaceNameSeperator,
-					hyd.GetName()),
+				constant.CreatedByHypershiftDeployment: manifestWorkOwner(hyd),
 			},
 		},
 		Spec: workv1.ManifestWorkSpec{
@@ -106,10 +122,48 @@ func scaffoldManifestwork(hyd *hypdeployment.HypershiftDeployment) (*workv1.Mani
 	return w, nil
 }
 
+// manifestWorkOwner is the <namespace>/<name> of the HypershiftDeployment recorded in the manifestworks it creates
+func manifestWorkOwner(hyd *hypdeployment.HypershiftDeployment) string {
+	return fmt.Sprintf("%s%s%s", hyd.GetNamespace(), constant.NamespaceNameSeperator, hyd.GetName())
+}
+
+// foreignManifestWorkError is a manifestwork, named after the infra-id, that was created by another HypershiftDeployment
+type foreignManifestWorkError struct {
+	key   types.NamespacedName
+	owner string
+}
+
+func (e *foreignManifestWorkError) Error() string {
+	return fmt.Sprintf("manifestwork %s belongs to the HypershiftDeployment %s, it is not updated", e.key, e.owner)
+}
+
+// checkManifestWorkOwner returns a foreignManifestWorkError when the manifestwork was created by another
+// HypershiftDeployment, ie both share the infra-id. A manifestwork without owner is taken over
+func checkManifestWorkOwner(hyd *hypdeployment.HypershiftDeployment, m *workv1.ManifestWork) error {
+	owner := m.GetAnnotations()[constant.CreatedByHypershiftDeployment]
+	if len(owner) == 0 || owner == manifestWorkOwner(hyd) {
+		return nil
+	}
+
+	return &foreignManifestWorkError{key: client.ObjectKeyFromObject(m), owner: owner}
+}
+
+// manifestWorkOwnedByOther reports the manifestwork of another HypershiftDeployment in the Conflict condition, its
+// watch maps to the owner so the HypershiftDeployment is requeued to notice the name is free again
+func (r *HypershiftDeploymentReconciler) manifestWorkOwnedByOther(hyd *hypdeployment.HypershiftDeployment, err *foreignManifestWorkError) (ctrl.Result, error) {
+	r.Log.Info(err.Error())
+	return ctrl.Result{RequeueAfter: time.Minute * 1}, r.validationFailed(hyd, hypdeployment.Conflict, metav1.ConditionTrue,
+		fmt.Sprintf("The manifestwork %s was created by the HypershiftDeployment %s, change the infra-id of one of them", err.key, err.owner),
+		hypdeployment.ForeignOwnerReason)
+}
+
 func setManifestWorkSelectivelyDeleteOption(mw *workv1.ManifestWork, hyd *hypdeployment.HypershiftDeployment) {
 	hostingNamespace := helper.GetHostingNamespace(hyd)
 
-	if hyd.Spec.Override == hypdeployment.InfraOverrideDestroy {
+	// the delete options of the spec replace the option of the override, unless they would orphan nothing by mistake
+	if opts := hyd.Spec.DeleteOptions; opts != nil && validateDeleteOptions(opts) == nil {
+		mw.Spec.DeleteOption = deleteOptionOfSpec(opts)
+	} else if hyd.Spec.Override == hypdeployment.InfraOverrideDestroy {
 		mw.Spec.DeleteOption = &workv1.DeleteOption{
 			PropagationPolicy: workv1.DeletePropagationPolicyTypeOrphan,
 		}
@@ -132,6 +186,26 @@ func setManifestWorkSelectivelyDeleteOption(mw *workv1.ManifestWork, hyd *hypdep
 	}
 }
 
+// deleteOptionOfSpec is the manifestwork delete option of the spec.deleteOptions
+func deleteOptionOfSpec(opts *hypdeployment.DeleteOptions) *workv1.DeleteOption {
+	option := &workv1.DeleteOption{PropagationPolicy: workv1.DeletePropagationPolicyType(opts.PropagationPolicy)}
+	if opts.PropagationPolicy != hypdeployment.DeletePropagationSelectivelyOrphan {
+		return option
+	}
+
+	option.SelectivelyOrphan = &workv1.SelectivelyOrphan{OrphaningRules: []workv1.OrphaningRule{}}
+	for _, r := range opts.OrphaningRules {
+		option.SelectivelyOrphan.OrphaningRules = append(option.SelectivelyOrphan.OrphaningRules, workv1.OrphaningRule{
+			Group:     r.Group,
+			Resource:  r.Resource,
+			Namespace: r.Namespace,
+			Name:      r.Name,
+		})
+	}
+
+	return option
+}
+
 func getManifestWorkKey(hyd *hypdeployment.HypershiftDeployment) types.NamespacedName {
 	return types.NamespacedName{
 		Name:      generateManifestName(hyd),
@@ -151,6 +225,24 @@ func syncManifestworkStatusToHypershiftDeployment(
 	feedback := getStatusFeedbackAsCondition(work, hyd)
 	conds = append(conds, feedback...)
 
+	if applied, ok := manifestAppliedCondition(work); ok {
+		conds = append(conds, applied)
+	}
+
+	ready := manifestsReadyCondition(work)
+	hyd.Status.Ready = ready.Status == metav1.ConditionTrue
+	conds = append(conds, ready)
+
+	if endpoint, ok := getStatusFeedbackAPIEndpoint(work, hyd); ok {
+		hyd.Status.APIEndpoint = endpoint
+	}
+
+	hyd.Status.NodePools = nodePoolStatusFeedback(work, hyd)
+
+	if kubeconfig, ok := kubeconfigStatusFeedback(work, hyd); ok {
+		hyd.Status.Kubeconfig = kubeconfig
+	}
+
 	for _, cond := range conds {
 		setStatusCondition(
 			hyd,
@@ -162,6 +254,100 @@ func syncManifestworkStatusToHypershiftDeployment(
 	}
 }
 
+// manifestAppliedCondition aggregates the per manifest status of the work agent, a manifest failed when its Applied
+// condition is false or its Degraded condition is true. It returns false until the work agent reports the manifests.
+func manifestAppliedCondition(work *workv1.ManifestWork) (metav1.Condition, bool) {
+	if len(work.Status.ResourceStatus.Manifests) == 0 {
+		return metav1.Condition{}, false
+	}
+
+	failures := []string{}
+	for _, mc := range work.Status.ResourceStatus.Manifests {
+		failed := condmeta.FindStatusCondition(mc.Conditions, string(workv1.ManifestApplied))
+		if failed == nil || failed.Status != metav1.ConditionFalse {
+			failed = condmeta.FindStatusCondition(mc.Conditions, string(workv1.ManifestDegraded))
+			if failed != nil && failed.Status != metav1.ConditionTrue {
+				failed = nil
+			}
+		}
+
+		if failed == nil {
+			continue
+		}
+
+		msg := failed.Message
+		if len(msg) == 0 {
+			msg = failed.Reason
+		}
+
+		failures = append(failures, fmt.Sprintf("%s: %s", manifestConditionName(mc), msg))
+	}
+
+	if len(failures) == 0 {
+		return metav1.Condition{
+			Type:   string(hypdeployment.ManifestApplied),
+			Status: metav1.ConditionTrue,
+			Reason: hypdeployment.AsExpectedReason,
+		}, true
+	}
+
+	return metav1.Condition{
+		Type:    string(hypdeployment.ManifestApplied),
+		Status:  metav1.ConditionFalse,
+		Reason:  hypdeployment.ApplyFailedReason,
+		Message: strings.Join(failures, "; "),
+	}, true
+}
+
+// manifestsReadyCondition is true when the work agent reports every manifest of the manifestwork both applied and
+// available, a manifest it has not reported yet is not ready
+func manifestsReadyCondition(work *workv1.ManifestWork) metav1.Condition {
+	notReady := []string{}
+	for _, mc := range work.Status.ResourceStatus.Manifests {
+		pending := []string{}
+		for _, t := range []string{string(workv1.ManifestApplied), string(workv1.ManifestAvailable)} {
+			if !condmeta.IsStatusConditionTrue(mc.Conditions, t) {
+				pending = append(pending, "not "+strings.ToLower(t))
+			}
+		}
+
+		if len(pending) != 0 {
+			notReady = append(notReady, fmt.Sprintf("%s: %s", manifestConditionName(mc), strings.Join(pending, " and ")))
+		}
+	}
+
+	reported := len(work.Status.ResourceStatus.Manifests)
+	if unreported := len(work.Spec.Workload.Manifests) - reported; unreported > 0 || reported == 0 {
+		notReady = append(notReady, fmt.Sprintf("%d of %d manifests not reported by the work agent",
+			unreported, len(work.Spec.Workload.Manifests)))
+	}
+
+	if len(notReady) == 0 {
+		return metav1.Condition{
+			Type:   string(hypdeployment.Ready),
+			Status: metav1.ConditionTrue,
+			Reason: hypdeployment.AsExpectedReason,
+		}
+	}
+
+	return metav1.Condition{
+		Type:    string(hypdeployment.Ready),
+		Status:  metav1.ConditionFalse,
+		Reason:  hypdeployment.ManifestsNotReadyReason,
+		Message: strings.Join(notReady, "; "),
+	}
+}
+
+// manifestConditionName is the kind and the namespaced name of a manifest of the manifestwork status
+func manifestConditionName(mc workv1.ManifestCondition) string {
+	name := mc.ResourceMeta.Name
+	if len(mc.ResourceMeta.Namespace) != 0 {
+		name = mc.ResourceMeta.Namespace + "/" + name
+	}
+
+	return mc.ResourceMeta.Kind + " " + name
+}
+
 func (r *HypershiftDeploymentReconciler) validateHostedClusterAndNodePool(ctx context.Context, hcName string, hcSpec hyp.HostedClusterSpec, npSpec hyp.NodePoolSpec) error {
 	// Platform.Type in NodePool matches the HostedCluster
 	if npSpec.Platform.Type != hcSpec.Platform.Type {
@@ -183,6 +369,49 @@ func (r *HypershiftDeploymentReconciler) validateHostedClusterAndNodePool(ctx co
 	return nil
 }
 
+// validateManifestWorkPermissions asks the api server if the controller can write the manifestwork, so a
+// missing RBAC rule is reported with the denied verb instead of an opaque create or update failure. It
+// returns a message describing the denied access, empty when allowed.
+func (r *HypershiftDeploymentReconciler) validateManifestWorkPermissions(ctx context.Context, hyd *hypdeployment.HypershiftDeployment, exists bool) (string, error) {
+	if !r.ValidatePermissions {
+		return "", nil
+	}
+
+	verb := "create"
+	switch {
+	case r.featureEnabled(features.ManifestWorkServerSideApply):
+		verb = "patch"
+	case exists:
+		verb = "update"
+	}
+
+	ssar := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: helper.GetHostingCluster(hyd),
+				Verb:      verb,
+				Group:     workv1.GroupName,
+				Resource:  "manifestworks",
+			},
+		},
+	}
+
+	if err := r.Create(ctx, ssar); err != nil {
+		return "", fmt.Errorf("failed to review the manifestwork permissions, err: %w", err)
+	}
+
+	if ssar.Status.Allowed {
+		return "", nil
+	}
+
+	denied := fmt.Sprintf("not allowed to %s manifestworks.%s in namespace %s", verb, workv1.GroupName, helper.GetHostingCluster(hyd))
+	if len(ssar.Status.Reason) != 0 {
+		denied = fmt.Sprintf("%s: %s", denied, ssar.Status.Reason)
+	}
+
+	return denied, nil
+}
+
 // validateSecurityConstraints checks the given HypershiftDeployment has the right permission to work on a given hosting cluster
 // return true if all the checks passed or we are skipping validation, return false if any of the check fails
 func (r *HypershiftDeploymentReconciler) validateSecurityConstraints(ctx context.Context, hyd *hypdeployment.HypershiftDeployment) (bool, error) {
@@ -199,13 +428,13 @@ func (r *HypershiftDeploymentReconciler) validateSecurityConstraints(ctx context
 	bindings, err := clusterv1beta1.GetBoundManagedClusterSetBindings(hyd.Namespace, cbg)
 	if err != nil {
 		r.Log.Error(err, hyd.Namespace+" namespace needs at least one bound ManagedClusterSetBinding")
-		return false, r.updateStatusConditionsOnChange(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse,
+		return false, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse,
 			"a bound ManagedClusterSetBinding is required in namespace "+hyd.Namespace+" retrying again after a minute", hypdeployment.MisConfiguredReason)
 	}
 
 	if len(bindings) == 0 {
 		r.Log.Error(errors.New("missing a bound ManagedClusterSetBinding in namespace "+hyd.Namespace), hyd.Namespace+" namespace needs at least one bound ManagedClusterSetBinding")
-		return false, r.updateStatusConditionsOnChange(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse,
+		return false, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse,
 			"a bound ManagedClusterSetBinding is required in namespace "+hyd.Namespace+" retrying again after a minute", hypdeployment.MisConfiguredReason)
 	}
 
@@ -221,24 +450,24 @@ func (r *HypershiftDeploymentReconciler) validateSecurityConstraints(ctx context
 	switch {
 	case apierrors.IsNotFound(err):
 		r.Log.Error(err, "fail to find ManagedCluster: "+hyd.Spec.HostingCluster)
-		return false, r.updateStatusConditionsOnChange(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse,
+		return false, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse,
 			hyd.Spec.HostingCluster+" ManagedCluster is required. Retrying after a minute", hypdeployment.MisConfiguredReason)
 	case err != nil:
 		r.Log.Error(err, "error while trying to find ManagedCluster: "+hyd.Spec.HostingCluster)
-		return false, r.updateStatusConditionsOnChange(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse,
+		return false, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse,
 			hyd.Spec.HostingCluster+" ManagedCluster is required. Retrying after a minute", hypdeployment.MisConfiguredReason)
 	}
 
 	foundClusterSet, err := helper.IsClusterInClusterSet(r.Client, &managedCluster, clusterSets.List())
 	if err != nil {
 		r.Log.Error(err, "error while trying to determine if ManagedCluster: "+hyd.Spec.HostingCluster+" is in a ManagedClusterSet")
-		return false, r.updateStatusConditionsOnChange(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse,
+		return false, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse,
 			hyd.Spec.HostingCluster+" ManagedClusterSet is required. Retrying after a minute", hypdeployment.MisConfiguredReason)
 	}
 
 	if !foundClusterSet {
 		r.Log.Error(errors.New("Spec.HostingCluster is not in a ManagedClusterSet"), "Spec.HostingCluster needs to be a member of a ManagedClusterSet")
-		return false, r.updateStatusConditionsOnChange(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse,
+		return false, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse,
 			"HostingCluster needs to be a ManagedCluster that is a member of a ManagedClusterSet. Retrying after a minute", hypdeployment.MisConfiguredReason)
 	}
 
@@ -246,24 +475,47 @@ func (r *HypershiftDeploymentReconciler) validateSecurityConstraints(ctx context
 }
 
 func (r *HypershiftDeploymentReconciler) createOrUpdateMainfestwork(ctx context.Context, req ctrl.Request, hyd *hypdeployment.HypershiftDeployment, providerSecret *corev1.Secret) (ctrl.Result, error) {
+	// a support bundle is mostly asked for when the reconcile stops early, so it is collected on every exit
+	// with the payload and manifestwork known so far. A dry run does not collect it, see conflictingAnnotations
+	var bundlePayload []workv1.Manifest
+	var bundleWork *workv1.ManifestWork
+	defer func() {
+		if hyd.Spec.DryRun {
+			return
+		}
+		if err := r.collectSupportBundle(ctx, hyd, bundlePayload, bundleWork); err != nil {
+			r.Log.Error(err, "failed to collect the support bundle")
+		}
+	}()
 
 	// We need a HostingCluster if we use ManifestWork
 	if len(hyd.Spec.HostingCluster) == 0 {
 		r.Log.Error(errors.New(constant.HostingClusterMissing), "Spec.HostingCluster needs a ManagedCluster name")
-		return ctrl.Result{}, r.updateStatusConditionsOnChange(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, constant.HostingClusterMissing, hypdeployment.MisConfiguredReason)
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, constant.HostingClusterMissing, hypdeployment.MisConfiguredReason)
 	}
 
 	// Check that a valid spec, for infra.configure=T, is present and update the hypershiftDeployment.status.conditions
 	// Since you can omit the nodePool, we only check hostedClusterSpec
 	if hyd.Spec.HostedClusterSpec == nil && hyd.Spec.Infrastructure.Configure {
 		r.Log.Error(errors.New("missing value = nil"), "hypershiftDeployment.Spec.HostedClusterSpec is nil")
-		return ctrl.Result{}, r.updateStatusConditionsOnChange(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, "HostedClusterSpec is missing", hypdeployment.MisConfiguredReason)
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, "HostedClusterSpec is missing", hypdeployment.MisConfiguredReason)
 	}
 
 	// For infra.configure=F, either HostedClusterSpec or HostedClusterRef is required
 	if !hyd.Spec.Infrastructure.Configure && len(hyd.Spec.HostedClusterRef.Name) == 0 && hyd.Spec.HostedClusterSpec == nil {
 		r.Log.Error(errors.New("missing value = nil"), "hypershiftDeployment.Spec.HostedClusterSpec and hypershiftDeployment.Spec.HostedClusterRef are nil")
-		return ctrl.Result{}, r.updateStatusConditionsOnChange(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, "HostedClusterSpec or HostedClusterRef is required", hypdeployment.MisConfiguredReason)
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, "HostedClusterSpec or HostedClusterRef is required", hypdeployment.MisConfiguredReason)
+	}
+
+	if err := validateDeleteOptions(hyd.Spec.DeleteOptions); err != nil {
+		r.Log.Error(err, "delete options are invalid")
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+	}
+
+	// A platform type the CRD of an older release let through is scaffolded into a payload the HostingCluster rejects
+	if err := validatePlatformTypes(hyd); err != nil {
+		r.Log.Error(err, "platform type is not supported")
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.UnsupportedPlatform, metav1.ConditionTrue, err.Error(), hypdeployment.UnknownPlatformReason)
 	}
 
 	// Check hostedClusterRef and NodePoolRefs exist and their platform.type matches
@@ -272,18 +524,53 @@ func (r *HypershiftDeploymentReconciler) createOrUpdateMainfestwork(ctx context.
 		hc := &hyp.HostedCluster{}
 		if err := r.Get(ctx, client.ObjectKey{Namespace: hyd.Namespace, Name: hyd.Spec.HostedClusterRef.Name}, hc); err != nil {
 			r.Log.Error(errors.New("hostedCluster not found"), "hostedCluster %v is expected in namespace %v", hyd.Spec.HostedClusterRef.Name, hyd.Namespace)
-			return ctrl.Result{}, r.updateStatusConditionsOnChange(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, "hostedCluster not found", hypdeployment.MisConfiguredReason)
+			return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, "hostedCluster not found", hypdeployment.MisConfiguredReason)
 		}
 
 		for _, npRef := range hyd.Spec.NodePoolsRef {
 			np := &hyp.NodePool{}
 			if err := r.Get(ctx, client.ObjectKey{Namespace: hyd.Namespace, Name: npRef.Name}, np); err != nil {
 				r.Log.Error(errors.New("nodePool not found"), "nodePool %v is expected in namespace %v", npRef.Name, hyd.Namespace)
-				return ctrl.Result{}, r.updateStatusConditionsOnChange(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, "nodePool not found", hypdeployment.MisConfiguredReason)
+				return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, "nodePool not found", hypdeployment.MisConfiguredReason)
 			}
 
 			if err := r.validateHostedClusterAndNodePool(ctx, hc.Name, hc.Spec, np.Spec); err != nil {
-				return ctrl.Result{}, r.updateStatusConditionsOnChange(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+				return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+			}
+
+			if err := validateNodePoolPlatform(np.Name, hc.Spec.Platform.Type, np.Spec.Platform); err != nil {
+				r.Log.Error(err, "nodePool platform does not match the hostedCluster")
+				return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+			}
+
+			// a referenced NodePool is applied as is, like the HostedClusterRef
+			if err := validateNodePoolSecurityGroups(np.Name, np.Spec.Platform, false); err != nil {
+				return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+			}
+
+			if err := validateNodePoolLifecycle(np.Name, np.Spec); err != nil {
+				return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+			}
+
+			if err := validateNodePoolManagement(np.Name, np.Spec); err != nil {
+				return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+			}
+
+			if err := validateNodePoolAutoScaling(np.Name, np.Spec); err != nil {
+				return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+			}
+
+			if err := validateKubevirtNodePool(np.Name, np.Spec.Platform); err != nil {
+				return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+			}
+
+			if err := validateAzureNodePool(np.Name, np.Spec.Platform); err != nil {
+				return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+			}
+
+			if err := validateVersionSkew(hc.Spec.Release.Image, np.Name, np.Spec.Release.Image); err != nil {
+				r.Log.Error(err, "nodePool release is out of the supported version skew")
+				return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.VersionSkewViolation, metav1.ConditionTrue, err.Error(), hypdeployment.MisConfiguredReason)
 			}
 		}
 	}
@@ -292,9 +579,164 @@ func (r *HypershiftDeploymentReconciler) createOrUpdateMainfestwork(ctx context.
 	if hyd.Spec.HostedClusterSpec != nil && len(hyd.Spec.NodePools) != 0 {
 		for _, np := range hyd.Spec.NodePools {
 			if err := r.validateHostedClusterAndNodePool(ctx, hyd.Name, *hyd.Spec.HostedClusterSpec, np.Spec); err != nil {
-				return ctrl.Result{}, r.updateStatusConditionsOnChange(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+				return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+			}
+
+			if err := validateNodePoolPlatform(np.Name, hyd.Spec.HostedClusterSpec.Platform.Type, np.Spec.Platform); err != nil {
+				r.Log.Error(err, "nodePool platform does not match the hostedCluster")
+				return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+			}
+
+			if err := validateNodePoolSecurityGroups(np.Name, np.Spec.Platform, true); err != nil {
+				return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+			}
+
+			if err := validateNodePoolLifecycle(np.Name, np.Spec); err != nil {
+				return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+			}
+
+			if err := validateNodePoolManagement(np.Name, np.Spec); err != nil {
+				return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+			}
+
+			if err := validateNodePoolAutoScaling(np.Name, np.Spec); err != nil {
+				return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+			}
+
+			if err := validateKubevirtNodePool(np.Name, np.Spec.Platform); err != nil {
+				return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+			}
+
+			if err := validateKubevirtNodePoolOptions(np.Name, np.Spec.Platform, np.Kubevirt); err != nil {
+				return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+			}
+
+			if err := validateNodeVolumeDetachTimeout(np.Name, np.NodeVolumeDetachTimeout); err != nil {
+				return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+			}
+
+			if err := validateAzureNodePool(np.Name, np.Spec.Platform); err != nil {
+				return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+			}
+
+			releaseImage := np.Spec.Release.Image
+			if len(releaseImage) == 0 {
+				releaseImage = hyd.Spec.HostedClusterSpec.Release.Image
+			}
+			if err := validateNodePoolArch(np.Name, np.Arch, hyd.Spec.HostedClusterSpec.Platform.Type, releaseImage); err != nil {
+				return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
 			}
 		}
+
+		if err := validateSubnetZones(hyd.Spec.HostedClusterSpec, hyd.Spec.NodePools); err != nil {
+			r.Log.Error(err, "subnet to zone mapping is inconsistent")
+			return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.SubnetZoneConflict, metav1.ConditionTrue, err.Error(), hypdeployment.MisConfiguredReason)
+		}
+
+		if err := validateNodePoolMachineCIDRs(hyd.Spec.HostedClusterSpec, hyd.Spec.NodePools); err != nil {
+			r.Log.Error(err, "nodePool machineCIDR is out of range")
+			return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.MachineCIDROutOfRange, metav1.ConditionTrue, err.Error(), hypdeployment.MisConfiguredReason)
+		}
+
+		if err := validateNodePoolsVersionSkew(hyd.Spec.HostedClusterSpec, hyd.Spec.NodePools); err != nil {
+			r.Log.Error(err, "nodePool release is out of the supported version skew")
+			return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.VersionSkewViolation, metav1.ConditionTrue, err.Error(), hypdeployment.MisConfiguredReason)
+		}
+	}
+
+	if err := validateNonePlatform(hyd.Spec.HostedClusterSpec); err != nil {
+		r.Log.Error(err, "none platform is invalid")
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+	}
+
+	if err := validateAWSCloudProviderConfig(hyd.Spec.HostedClusterSpec); err != nil {
+		r.Log.Error(err, "aws cloud provider config is invalid")
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+	}
+
+	if err := validateAWSEndpointAccess(hyd.Spec.HostedClusterSpec); err != nil {
+		r.Log.Error(err, "aws endpoint access is invalid")
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+	}
+
+	if err := validateAzurePlatform(hyd.Spec.HostedClusterSpec); err != nil {
+		r.Log.Error(err, "azure platform is invalid")
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+	}
+
+	if err := validateAzureIdentity(hyd); err != nil {
+		r.Log.Error(err, "azure identity is invalid")
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+	}
+
+	if err := validateIBMCloudPlatform(hyd.Spec.HostedClusterSpec); err != nil {
+		r.Log.Error(err, "ibmcloud platform is invalid")
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+	}
+
+	if err := validateSecretEncryptionKMS(hyd.Spec.HostedClusterSpec); err != nil {
+		r.Log.Error(err, "kms secret encryption is invalid")
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+	}
+
+	if err := validateAgentPlatform(hyd.Spec.HostedClusterSpec); err != nil {
+		r.Log.Error(err, "agent platform is invalid")
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+	}
+
+	// A HostedClusterSpec missing a required field is rejected by the HyperShift operator once applied, so no
+	// manifestwork is written and the fields are rechecked later
+	if err := validateHostedClusterSpec(hyd); err != nil {
+		r.Log.Error(err, "hostedClusterSpec is missing required fields")
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, r.validationFailed(hyd, hypdeployment.ValidConfiguration, metav1.ConditionFalse, err.Error(), hypdeployment.MissingFieldsReason)
+	}
+	if hyd.Spec.HostedClusterSpec != nil {
+		setStatusCondition(hyd, hypdeployment.ValidConfiguration, metav1.ConditionTrue, "", hypdeployment.ConfiguredAsExpectedReason)
+	}
+
+	if err := validateClusterID(hyd.Spec.ClusterID); err != nil {
+		r.Log.Error(err, "cluster-id is invalid")
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+	}
+
+	if err := validateControlPlaneTolerations(hyd.Spec.ControlPlaneTolerations); err != nil {
+		r.Log.Error(err, "control plane tolerations are invalid")
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+	}
+
+	if err := validateProxy(hyd.Spec.Proxy); err != nil {
+		r.Log.Error(err, "proxy is invalid")
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+	}
+
+	if err := validateMaintenanceWindow(hyd.Spec.MaintenanceWindow); err != nil {
+		r.Log.Error(err, "maintenance window is invalid")
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+	}
+
+	if err := validateFeedbackRules(hyd.Spec.FeedbackRules); err != nil {
+		r.Log.Error(err, "feedback rules are invalid")
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+	}
+
+	missingCredentials, err := r.missingPlatformCredentials(ctx, hyd, providerSecret)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(missingCredentials) != 0 {
+		msg := "Missing platform credentials: " + strings.Join(missingCredentials, ", ")
+		r.Log.Error(errors.New(msg), "platform credentials are not on the hub")
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, r.validationFailed(hyd, hypdeployment.MissingPlatformCredentials, metav1.ConditionTrue, msg, hypdeployment.MisConfiguredReason)
+	}
+
+	unmet, err := r.unmetDependencies(ctx, hyd)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(unmet) != 0 {
+		msg := "Unmet dependencies: " + strings.Join(unmet, ", ")
+		r.Log.Error(errors.New(msg), "hostedClusterSpec settings need secrets that are not on the hub")
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, r.validationFailed(hyd, hypdeployment.UnmetDependencies, metav1.ConditionTrue, msg, hypdeployment.MisConfiguredReason)
 	}
 
 	passedSecurity, statusUpdateErr := r.validateSecurityConstraints(ctx, hyd)
@@ -314,51 +756,369 @@ func (r *HypershiftDeploymentReconciler) createOrUpdateMainfestwork(ctx context.
 	if hyd.Spec.HostedClusterSpec != nil && hyd.Spec.HostedClusterSpec.Platform.AWS != nil &&
 		(hyd.Spec.Credentials == nil || hyd.Spec.Credentials.AWS == nil) {
 		r.Log.Error(errors.New("hyd.Spec.Credentials.AWS == nil"), "missing IAM configuration")
-		return ctrl.Result{}, r.updateStatusConditionsOnChange(hyd, hypdeployment.PlatformIAMConfigured, metav1.ConditionFalse, "Missing Spec.Crednetials.AWS.* platform IAM", hypdeployment.MisConfiguredReason)
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.PlatformIAMConfigured, metav1.ConditionFalse, "Missing Spec.Crednetials.AWS.* platform IAM", hypdeployment.MisConfiguredReason)
 	}
 
 	inHyd := hyd.DeepCopy()
+	var feedbackRequeue time.Duration
+	chunks, err := r.listManifestWorkChunks(ctx, hyd)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 	// if the manifestwork is created, then move the status to hypershiftDeployment
 	if err := r.Get(ctx, getManifestWorkKey(hyd), m); err == nil {
-		syncManifestworkStatusToHypershiftDeployment(hyd, m)
+		var foreign *foreignManifestWorkError
+		if errors.As(checkManifestWorkOwner(hyd, m), &foreign) {
+			return r.manifestWorkOwnedByOther(hyd, foreign)
+		}
+		bundleWork = m
+
+		syncManifestworkStatusToHypershiftDeployment(hyd, mergeManifestWorkChunks(m, chunks))
+		feedbackRequeue = r.observeFeedback(hyd, m)
+		r.observeHostedClusterAvailable(inHyd, hyd)
+		r.targetCircuitBreaker().recordApplyResult(helper.GetHostingCluster(hyd), hyd.UID, m)
+	} else if apierrors.IsNotFound(err) {
+		// give other controllers a window to create the dependent resources before the first manifestwork
+		if remaining := time.Until(hyd.CreationTimestamp.Add(r.ManifestWorkGracePeriod)); r.ManifestWorkGracePeriod > 0 && remaining > 0 {
+			r.Log.Info(fmt.Sprintf("wait %s before creating the manifestwork %s", remaining.Round(time.Second), getManifestWorkKey(hyd)))
+			return ctrl.Result{RequeueAfter: remaining}, r.updateStatusConditionsOnChange(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse,
+				"Waiting for the grace period before creating the manifestwork", hypdeployment.BeingConfiguredReason)
+		}
 	}
 
-	payload := []workv1.Manifest{}
+	if allowed, retryAfter := r.targetCircuitBreaker().allow(helper.GetHostingCluster(hyd)); !allowed {
+		r.Log.Info(fmt.Sprintf("circuit is open for hostingCluster: %s, retry in %s", helper.GetHostingCluster(hyd), retryAfter))
+		setStatusCondition(
+			hyd,
+			hypdeployment.TargetClusterCircuitOpen,
+			metav1.ConditionTrue,
+			fmt.Sprintf("HostingCluster %s repeatedly failed to apply the manifestwork, retrying in %s", helper.GetHostingCluster(hyd), retryAfter.Round(time.Second)),
+			hypdeployment.CircuitOpenReason,
+		)
 
-	manifestFuncs := []loadManifest{
-		ensureTaregetNamespace,
-		r.appendHostedCluster(ctx),
-		r.appendNodePool(ctx),
-		r.appendHostedClusterReferenceSecrets(ctx, providerSecret),
-		r.ensureConfiguration(ctx, m),
+		return ctrl.Result{RequeueAfter: retryAfter}, r.patchStatus(r.ctx, hyd, inHyd)
 	}
 
-	for _, f := range manifestFuncs {
-		err := f(hyd, &payload)
-		if err != nil {
-			r.Log.Error(err, "failed to load payload to manifestwork")
+	if denied, err := r.validateManifestWorkPermissions(ctx, hyd, len(m.ResourceVersion) != 0); err != nil {
+		return ctrl.Result{}, err
+	} else if len(denied) != 0 {
+		r.Log.Info(denied)
+		setStatusCondition(hyd, hypdeployment.InsufficientPermissions, metav1.ConditionTrue, denied, hypdeployment.PermissionDeniedReason)
+		return ctrl.Result{RequeueAfter: time.Minute * 1}, r.patchStatus(r.ctx, hyd, inHyd)
+	}
+
+	payload, err := r.renderManifestPayload(ctx, hyd, providerSecret, m)
+	bundlePayload = payload
+	if err != nil {
+		var fetchErr *pullSecretFetchError
+		if errors.As(err, &fetchErr) {
+			return r.pullSecretFetchFailed(hyd, fetchErr)
+		}
+		return ctrl.Result{}, err
+	}
+
+	if err := validatePayloadMetadataKeys(payload); err != nil {
+		r.Log.Error(err, "manifestwork payload has invalid label or annotation keys")
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+	}
+
+	if err := validateNodePoolConfigs(payload); err != nil {
+		r.Log.Error(err, "manifestwork payload has invalid NodePool configMaps")
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+	}
+
+	if err := validateInfraID(hyd.Spec.InfraID, payload); err != nil {
+		r.Log.Error(err, "HostedCluster infraID does not match the infra-id")
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.InfraIDMismatchReason)
+	}
+
+	if err := validateNodePoolBinding(payload); err != nil {
+		r.Log.Error(err, "NodePools do not reference the HostedCluster")
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.ClusterMismatchReason)
+	}
+
+	applied := append([]workv1.Manifest{}, m.Spec.Workload.Manifests...)
+	for _, c := range chunks {
+		applied = append(applied, c.Spec.Workload.Manifests...)
+	}
+
+	// outside of the maintenance window, the disruptive changes keep the values of the applied manifestwork
+	deferred := []string{}
+	var deferredUntil time.Time
+	if w := hyd.Spec.MaintenanceWindow; w != nil && len(m.ResourceVersion) != 0 {
+		if open, next := maintenanceWindowOpen(w, r.currentTime()); !open {
+			if deferred, err = deferDisruptiveChanges(applied, payload); err != nil {
+				r.Log.Error(err, "failed to defer the disruptive changes")
+				return ctrl.Result{}, err
+			}
+			deferredUntil = next
+		}
+	}
+
+	// the large NodePool scale downs keep the applied replicas until they are confirmed
+	blocked := []string{}
+	confirmation, confirmed := scaleDownConfirmed(hyd)
+	if r.ScaleDownGuardPercent > 0 && len(m.ResourceVersion) != 0 && !confirmed {
+		if blocked, err = blockLargeScaleDowns(applied, payload, r.ScaleDownGuardPercent); err != nil {
+			r.Log.Error(err, "failed to check the NodePool scale downs")
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := validateHighAvailability(hyd.Spec.HostedClusterSpec, hyd.Spec.NodePools); err != nil {
+		r.Log.Info(err.Error())
+		setStatusCondition(hyd, hypdeployment.HighAvailabilityUnmet, metav1.ConditionTrue, err.Error(), hypdeployment.InsufficientNodesReason)
+	} else {
+		resolveStatusCondition(hyd, hypdeployment.HighAvailabilityUnmet)
+	}
+
+	if orphaned := orphanedSpokeResources(m, hyd); len(orphaned) != 0 {
+		msg := fmt.Sprintf("Resources were on the hosting cluster before manifestwork %s was created, "+
+			"they are left from a previous deployment and must be deleted from the hosting cluster to be recreated: %s",
+			getManifestWorkKey(hyd), strings.Join(orphaned, ", "))
+		r.Log.Info(msg)
+		setStatusCondition(hyd, hypdeployment.OrphanedSpokeResources, metav1.ConditionTrue, msg, hypdeployment.PreExistingResourcesReason)
+	} else {
+		resolveStatusCondition(hyd, hypdeployment.OrphanedSpokeResources)
+	}
+
+	// the payload is only rendered for review, the manifestwork is neither created nor updated
+	if hyd.Spec.DryRun {
+		if err := r.saveRenderedManifests(ctx, hyd, payload); err != nil {
+			r.Log.Error(err, "failed to save the rendered manifests")
+			return ctrl.Result{}, err
+		}
+
+		r.Log.Info(fmt.Sprintf("dry run, rendered the manifestwork %s payload to configMap %s/%s", getManifestWorkKey(hyd), hyd.Namespace, hyd.Status.RenderedManifests.Name))
+		setStatusCondition(
+			hyd,
+			hypdeployment.WorkConfigured,
+			metav1.ConditionFalse,
+			fmt.Sprintf("Dry run, the manifestwork payload is rendered to ConfigMap %s/%s", hyd.Namespace, hyd.Status.RenderedManifests.Name),
+			hypdeployment.DryRunReason,
+		)
+		return ctrl.Result{}, r.patchStatus(r.ctx, hyd, inHyd)
+	}
+
+	// the removed NodePools and secrets are deleted from the HostingCluster, not orphaned by the delete option
+	removal, err := removeManifests(m, payload)
+	if err != nil {
+		r.Log.Error(err, "failed to plan the removal of the NodePools and secrets")
+		return ctrl.Result{}, err
+	}
+
+	// the NodePools that do not fit in the manifestwork are written to its chunks
+	chunked, err := chunkPayload(payload, r.manifestWorkChunkSize())
+	if err != nil {
+		r.Log.Error(err, "manifestwork payload is too large")
+		return ctrl.Result{}, r.validationFailed(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.PayloadTooLargeReason)
+	}
+	rendered, chunksCfg := payload, mwCfg
+	payload = append(chunked[0], removal.kept...)
+	if len(chunked) > 1 {
+		if mwCfg, err = manifestConfigsOfPayload(mwCfg, payload); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
 
+	err = validateDeleteOption(removal.deleteOption, append(rendered, removal.kept...))
+	if err == nil && hyd.Spec.DeleteOptions != nil {
+		// the delete options of the spec are only written to the manifestwork when the HypershiftDeployment is deleted
+		err = validateDeleteOption(deleteOptionOfSpec(hyd.Spec.DeleteOptions), rendered)
+	}
+	if err != nil {
+		r.Log.Info(fmt.Sprintf("manifestwork %s: %s", getManifestWorkKey(hyd), err.Error()))
+		setStatusCondition(hyd, hypdeployment.DeleteOptionIneffective, metav1.ConditionTrue, err.Error(), hypdeployment.NoMatchingPayloadReason)
+	} else {
+		resolveStatusCondition(hyd, hypdeployment.DeleteOptionIneffective)
+	}
+
+	// the payload was rendered from the spec read at the start of the reconcile, do not write it once superseded
+	if superseded, err := r.specSuperseded(ctx, hyd); err != nil || superseded {
+		return ctrl.Result{Requeue: superseded}, err
+	}
+
+	// the manifestwork writes are the expensive part of a reconcile, past the budget they wait for it to refill.
+	// An unchanged manifestwork is not written, so it does not take from the budget
+	desired := m.Spec.DeepCopy()
+	desired.Workload.Manifests = payload
+	desired.ManifestConfigs = mwCfg
+	desired.DeleteOption = removal.deleteOption
+	writes := len(m.ResourceVersion) == 0 || !manifestWorkSpecEqual(&m.Spec, desired)
+	if writes {
+		if allowed, retryAfter := r.manifestWorkBudget().take(); !allowed {
+			r.Log.Info(fmt.Sprintf("reconcile budget exhausted, write the manifestwork %s in %s", getManifestWorkKey(hyd), retryAfter))
+			setStatusCondition(
+				hyd,
+				hypdeployment.ReconcileThrottled,
+				metav1.ConditionTrue,
+				fmt.Sprintf("The controller budget of %d manifestwork writes per %s is exhausted, retrying in %s",
+					r.manifestWorkBudget().size, r.manifestWorkBudget().window, retryAfter.Round(time.Millisecond)),
+				hypdeployment.BudgetExhaustedReason,
+			)
+
+			return ctrl.Result{RequeueAfter: retryAfter}, r.patchStatus(r.ctx, hyd, inHyd)
+		}
+	}
+
 	// the object in controllerutil.CreateOrUpdate will get override by a GET
 	// after the GET, the update will be called and the payload will be wrote to
 	// the in object, which will be send with a UPDATE
+	changed := false
 	update := func(in *workv1.ManifestWork, payload []workv1.Manifest) controllerutil.MutateFn {
 		return func() error {
+			// the manifestwork may have been created by another HypershiftDeployment since it was read
+			if err := checkManifestWorkOwner(hyd, m); err != nil {
+				return err
+			}
+
+			before := m.Spec.DeepCopy()
 			m.Spec.Workload.Manifests = payload
 			m.Spec.ManifestConfigs = mwCfg
+			m.Spec.DeleteOption = removal.deleteOption
+			changed = !manifestWorkSpecEqual(before, &m.Spec)
+			if !changed {
+				// keep the spec read back, so an unchanged manifestwork is not updated
+				m.Spec = *before
+			}
 			return nil
 		}
 	}
-	if _, err := controllerutil.CreateOrUpdate(r.ctx, r.Client, m, update(m, payload)); err != nil {
+	op := controllerutil.OperationResultNone
+	if r.featureEnabled(features.ManifestWorkServerSideApply) {
+		// the apply does not tell whether it changed the manifestwork, the generation does
+		existed, generation := len(m.ResourceVersion) != 0, m.Generation
+		if err := r.retryOnManifestWorkConflict(func() (err error) {
+			m, err = r.applyManifestwork(hyd, payload, mwCfg, removal.deleteOption)
+			return err
+		}); err != nil {
+			r.Log.Error(err, fmt.Sprintf("failed to apply the manifestwork %s", getManifestWorkKey(hyd)))
+			return r.manifestWorkWriteFailed(hyd, inHyd, err)
+		}
+
+		if !existed {
+			op = controllerutil.OperationResultCreated
+		} else if m.Generation != generation {
+			op = controllerutil.OperationResultUpdated
+		}
+	} else if err := r.retryOnManifestWorkConflict(func() (err error) {
+		// CreateOrUpdate gets the latest manifestwork before the update
+		op, err = controllerutil.CreateOrUpdate(r.ctx, r.Client, m, update(m, payload))
+		return err
+	}); err != nil {
+		var foreign *foreignManifestWorkError
+		if errors.As(err, &foreign) {
+			return r.manifestWorkOwnedByOther(hyd, foreign)
+		}
+
 		r.Log.Error(err, fmt.Sprintf("failed to CreateOrUpdate the existing manifestwork %s", getManifestWorkKey(hyd)))
-		return ctrl.Result{}, err
+		return r.manifestWorkWriteFailed(hyd, inHyd, err)
+
+	}
 
+	switch op {
+	case controllerutil.OperationResultCreated:
+		r.noteManifestWorkChange(reconcileResultCreated)
+		r.recordEvent(hyd, corev1.EventTypeNormal, ManifestWorkCreatedEvent, "Created ManifestWork %s on hosting cluster %s", getManifestWorkKey(hyd), helper.GetHostingCluster(hyd))
+	case controllerutil.OperationResultUpdated:
+		r.noteManifestWorkChange(reconcileResultUpdated)
+		r.recordEvent(hyd, corev1.EventTypeNormal, ManifestWorkUpdatedEvent, "Updated ManifestWork %s on hosting cluster %s", getManifestWorkKey(hyd), helper.GetHostingCluster(hyd))
 	}
 
 	r.Log.Info(fmt.Sprintf("CreateOrUpdate manifestwork %s for hypershiftDeployment: %s at hostingCluster: %s", getManifestWorkKey(hyd), req, helper.GetHostingCluster(hyd)))
 
+	if err := r.updateManifestWorkChunks(ctx, hyd, chunks, chunked, chunksCfg, rendered); err != nil {
+		var foreign *foreignManifestWorkError
+		if errors.As(err, &foreign) {
+			return r.manifestWorkOwnedByOther(hyd, foreign)
+		}
+
+		r.Log.Error(err, fmt.Sprintf("failed to write the chunks of the manifestwork %s", getManifestWorkKey(hyd)))
+		return ctrl.Result{}, err
+	}
+
+	// collected here so its token is saved with the status, the deferred collection is then a no-op
+	if err := r.collectSupportBundle(ctx, hyd, payload, m); err != nil {
+		r.Log.Error(err, "failed to collect the support bundle")
+	}
+
+	kubeconfigPending, err := r.pullKubeconfig(ctx, hyd)
+	if err != nil {
+		r.Log.Error(err, "failed to pull the kubeconfig to the hub")
+	}
+
+	if err := r.removeRenderedManifests(ctx, hyd); err != nil {
+		r.Log.Error(err, "failed to remove the rendered manifests of the dry run")
+	}
+
+	resolveStatusCondition(hyd, hypdeployment.SubnetZoneConflict)
+	resolveStatusCondition(hyd, hypdeployment.UnsupportedPlatform)
+	resolveStatusCondition(hyd, hypdeployment.TargetClusterCircuitOpen)
+	resolveStatusCondition(hyd, hypdeployment.VersionSkewViolation)
+	resolveStatusCondition(hyd, hypdeployment.MachineCIDROutOfRange)
+	resolveStatusCondition(hyd, hypdeployment.InsufficientPermissions)
+	resolveStatusCondition(hyd, hypdeployment.MissingPlatformCredentials)
+	resolveStatusCondition(hyd, hypdeployment.UnmetDependencies)
+	resolveStatusCondition(hyd, hypdeployment.ManifestWorkConflict)
+	resolveStatusCondition(hyd, hypdeployment.ReconcileThrottled)
+	resolveStatusCondition(hyd, hypdeployment.Conflict)
+
+	result := ctrl.Result{}
+	if len(deferred) != 0 {
+		r.Log.Info(fmt.Sprintf("defer %s until the maintenance window at %s", strings.Join(deferred, ", "), deferredUntil.Format(time.RFC3339)))
+		setStatusCondition(
+			hyd,
+			hypdeployment.DeferredUntil,
+			metav1.ConditionTrue,
+			fmt.Sprintf("%s: %s", deferredUntil.Format(time.RFC3339), strings.Join(deferred, ", ")),
+			hypdeployment.MaintenanceWindowReason,
+		)
+		result.RequeueAfter = deferredUntil.Sub(r.currentTime())
+	} else {
+		resolveStatusCondition(hyd, hypdeployment.DeferredUntil)
+	}
+
+	if len(blocked) != 0 {
+		msg := fmt.Sprintf("%s exceed %d%% of the applied replicas, set the %s annotation to a new value to confirm",
+			strings.Join(blocked, ", "), r.ScaleDownGuardPercent, constant.ConfirmScaleDownAnnotation)
+		r.Log.Info(msg)
+		setStatusCondition(hyd, hypdeployment.LargeScaleDownBlocked, metav1.ConditionTrue, msg, hypdeployment.ConfirmationRequiredReason)
+	} else {
+		resolveStatusCondition(hyd, hypdeployment.LargeScaleDownBlocked)
+	}
+
+	// persisted with the status of the HypershiftDeployment
+	if confirmed {
+		helper.RecordSideEffect(hyd, constant.IdempotencyKeyScaleDownConfirmation, confirmation)
+	}
+
+	if len(removal.removed) != 0 {
+		msg := fmt.Sprintf("NodePools %s are removed from the spec and deleted from the HostingCluster", strings.Join(removal.removed, ", "))
+		r.Log.Info(msg)
+		setStatusCondition(hyd, hypdeployment.NodePoolsRemoved, metav1.ConditionTrue, msg, hypdeployment.RemovingReason)
+	} else {
+		resolveStatusCondition(hyd, hypdeployment.NodePoolsRemoved)
+	}
+
+	if len(removal.removedSecrets) != 0 {
+		r.Log.Info(fmt.Sprintf("Secrets %s are removed from the payload and deleted from the HostingCluster", strings.Join(removal.removedSecrets, ", ")))
+	}
+
+	// check the staleness again once the work agent has run out of time to report
+	if feedbackRequeue > 0 && (result.RequeueAfter == 0 || feedbackRequeue < result.RequeueAfter) {
+		result.RequeueAfter = feedbackRequeue
+	}
+
+	// read the feedback again while the HostedCluster and NodePools are still converging
+	if poll := r.feedbackPollRequeue(hyd); poll > 0 && (result.RequeueAfter == 0 || poll < result.RequeueAfter) {
+		result.RequeueAfter = poll
+	}
+
+	// the kubeconfig is not synced to the hub yet
+	if kubeconfigPending && (result.RequeueAfter == 0 || kubeconfigPullRequeue < result.RequeueAfter) {
+		result.RequeueAfter = kubeconfigPullRequeue
+	}
+
 	setStatusCondition(
 		hyd,
 		hypdeployment.WorkConfigured,
@@ -367,17 +1127,208 @@ func (r *HypershiftDeploymentReconciler) createOrUpdateMainfestwork(ctx context.
 		hypdeployment.ConfiguredAsExpectedReason,
 	)
 
-	return ctrl.Result{}, r.Client.Status().Patch(r.ctx, hyd, client.MergeFrom(inHyd))
+	return result, r.patchStatus(r.ctx, hyd, inHyd)
+}
+
+// renderManifestPayload builds the manifestwork payload of the HypershiftDeployment, m is the applied
+// manifestwork the generated values are kept from
+func (r *HypershiftDeploymentReconciler) renderManifestPayload(ctx context.Context, hyd *hypdeployment.HypershiftDeployment,
+	providerSecret *corev1.Secret, m *workv1.ManifestWork) ([]workv1.Manifest, error) {
+	payload := []workv1.Manifest{}
+
+	manifestFuncs := []loadManifest{
+		ensureTaregetNamespace,
+		r.appendHostedCluster(ctx),
+		r.appendNodePool(ctx),
+		r.appendHostedClusterReferenceSecrets(ctx, providerSecret),
+		r.ensureConfiguration(ctx, m),
+		r.appendNodePoolTuningConfigs(ctx),
+		r.appendIdentityProviderReferences(ctx),
+		r.appendOperatorConfigurationReferences(ctx),
+	}
+
+	for _, f := range manifestFuncs {
+		err := f(hyd, &payload)
+		if err != nil {
+			r.Log.Error(err, "failed to load payload to manifestwork")
+			return nil, err
+		}
+	}
+
+	if err := r.transformPayloadSecrets(payload); err != nil {
+		r.Log.Error(err, "failed to transform the manifestwork payload secrets")
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// applyManifestwork sends the whole manifestwork as a server side apply patch, the fields that are
+// no longer in the payload are removed from the manifestwork
+func (r *HypershiftDeploymentReconciler) applyManifestwork(hyd *hypdeployment.HypershiftDeployment, payload []workv1.Manifest,
+	mwCfg []workv1.ManifestConfigOption, deleteOption *workv1.DeleteOption) (*workv1.ManifestWork, error) {
+	m, err := scaffoldManifestwork(hyd)
+	if err != nil {
+		return nil, err
+	}
+
+	m.SetGroupVersionKind(workv1.GroupVersion.WithKind("ManifestWork"))
+	m.Spec.Workload.Manifests = payload
+	m.Spec.ManifestConfigs = mwCfg
+	if deleteOption != nil {
+		m.Spec.DeleteOption = deleteOption
+	}
+
+	if err := r.Patch(r.ctx, m, client.Apply, client.FieldOwner(constant.HypershiftDeploymentFieldManager), client.ForceOwnership); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// manifestWorkSpecEqual compares the serialized specs, the payload read from the API server is raw while the
+// rendered one is typed, so the manifests are compared in their canonical form
+func manifestWorkSpecEqual(a, b *workv1.ManifestWorkSpec) bool {
+	rawA, errA := canonicalManifestWorkSpec(a)
+	rawB, errB := canonicalManifestWorkSpec(b)
+	return errA == nil && errB == nil && bytes.Equal(rawA, rawB)
+}
+
+// canonicalManifestWorkSpec serializes the spec with the keys of each manifest sorted and its null fields dropped,
+// the API server sorts the keys of the raw payload it returns while a typed object keeps the order of its fields
+func canonicalManifestWorkSpec(spec *workv1.ManifestWorkSpec) ([]byte, error) {
+	c := spec.DeepCopy()
+	for i, m := range c.Workload.Manifests {
+		raw := m.Raw
+		if len(raw) == 0 && m.Object != nil {
+			var err error
+			if raw, err = json.Marshal(m.Object); err != nil {
+				return nil, err
+			}
+		}
+
+		var obj interface{}
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return nil, err
+		}
+
+		canonical, err := json.Marshal(dropNullFields(obj))
+		if err != nil {
+			return nil, err
+		}
+		c.Workload.Manifests[i] = workv1.Manifest{RawExtension: runtime.RawExtension{Raw: canonical}}
+	}
+
+	return json.Marshal(c)
+}
+
+// dropNullFields removes the null fields of the maps, a typed object serializes its unset fields as null
+func dropNullFields(obj interface{}) interface{} {
+	switch o := obj.(type) {
+	case map[string]interface{}:
+		for k, v := range o {
+			if v == nil {
+				delete(o, k)
+				continue
+			}
+			o[k] = dropNullFields(v)
+		}
+	case []interface{}:
+		for i, v := range o {
+			o[i] = dropNullFields(v)
+		}
+	}
+
+	return obj
+}
+
+// retryOnManifestWorkConflict calls write again each time it fails with a conflict, up to ManifestWorkConflictRetries
+// times, write must read the latest manifestwork so a concurrent edit is not overwritten
+func (r *HypershiftDeploymentReconciler) retryOnManifestWorkConflict(write func() error) error {
+	if r.ManifestWorkConflictRetries <= 0 {
+		return write()
+	}
+
+	backoff := wait.Backoff{Steps: r.ManifestWorkConflictRetries + 1, Duration: 10 * time.Millisecond, Factor: 2.0, Jitter: 0.1}
+	return retry.RetryOnConflict(backoff, func() error {
+		err := write()
+		if apierrors.IsConflict(err) {
+			r.Log.Info(fmt.Sprintf("conflict when writing the manifestwork, retry: %v", err))
+		}
+		return err
+	})
+}
+
+// manifestWorkWriteFailed reports the conflicts left once the retries ran out in the ManifestWorkConflict condition,
+// the other errors are returned as is
+func (r *HypershiftDeploymentReconciler) manifestWorkWriteFailed(hyd, inHyd *hypdeployment.HypershiftDeployment, err error) (ctrl.Result, error) {
+	if r.ManifestWorkConflictRetries <= 0 || !apierrors.IsConflict(err) {
+		return ctrl.Result{}, err
+	}
+
+	setStatusCondition(
+		hyd,
+		hypdeployment.ManifestWorkConflict,
+		metav1.ConditionTrue,
+		fmt.Sprintf("The manifestwork %s still conflicted after %d retries, err: %v", getManifestWorkKey(hyd), r.ManifestWorkConflictRetries, err),
+		hypdeployment.UpdateConflictReason,
+	)
+
+	return ctrl.Result{Requeue: true}, r.patchStatus(r.ctx, hyd, inHyd)
 }
 
 func (r *HypershiftDeploymentReconciler) deleteManifestworkWaitCleanUp(ctx context.Context, hyd *hypdeployment.HypershiftDeployment) (ctrl.Result, error) {
+	// no manifestwork was ever created under an invalid name, there is nothing to clean up on the HostingCluster
+	if len(hyd.Spec.InfraID) != 0 && validateManifestWorkName(hyd.Spec.InfraID) != nil {
+		r.deprovisionDone(hyd)
+		setStatusCondition(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, "", hypdeployment.RemovingReason)
+		return ctrl.Result{}, nil
+	}
+
 	m, err := scaffoldManifestwork(hyd)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	if err := r.Get(ctx, types.NamespacedName{Name: m.GetName(), Namespace: m.GetNamespace()}, m); err != nil {
+	chunks, err := r.listManifestWorkChunks(ctx, hyd)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	for i := range chunks {
+		if res, err := r.deleteManifestWork(ctx, hyd, &chunks[i]); err != nil || !res.IsZero() {
+			return res, err
+		}
+	}
+
+	err = r.Get(ctx, types.NamespacedName{Name: m.GetName(), Namespace: m.GetNamespace()}, m)
+	if ownerErr := checkManifestWorkOwner(hyd, m); err == nil && ownerErr != nil {
+		// the manifestwork of another HypershiftDeployment is not deleted, this one was never created
+		r.Log.Info(ownerErr.Error())
+		err = apierrors.NewNotFound(workv1.Resource("manifestworks"), m.GetName())
+	}
+	if err != nil {
 		if apierrors.IsNotFound(err) {
+			if len(chunks) != 0 {
+				// wait for the work agent to clean up the chunks
+				setStatusCondition(hyd, hypdeployment.WorkConfigured, metav1.ConditionTrue, "Removing HypershiftDeployment's manifestwork and related resources", hypdeployment.RemovingReason)
+				return ctrl.Result{RequeueAfter: r.deprovisionRequeue(hyd), Requeue: true}, nil
+			}
+
+			if err := r.removeRenderedManifests(ctx, hyd); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			if err := r.removeHubKubeconfig(ctx, hyd); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			if err := r.removeSupportBundle(ctx, hyd); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			r.targetCircuitBreaker().forget(helper.GetHostingCluster(hyd), hyd.UID)
+			r.deprovisionDone(hyd)
 			setStatusCondition(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, "", hypdeployment.RemovingReason)
 			return ctrl.Result{}, nil
 		}
@@ -385,40 +1336,101 @@ func (r *HypershiftDeploymentReconciler) deleteManifestworkWaitCleanUp(ctx conte
 		return ctrl.Result{}, fmt.Errorf("failed to delete manifestwork, err: %v", err)
 	}
 
-	if m.GetDeletionTimestamp().IsZero() {
-		dpm := m.DeepCopy()
-		setManifestWorkSelectivelyDeleteOption(m, hyd)
-		if m.Spec.DeleteOption.PropagationPolicy != workv1.DeletePropagationPolicyTypeOrphan {
-			if !reflect.DeepEqual(dpm.Spec.DeleteOption, m.Spec.DeleteOption) {
-				patch := client.MergeFrom(dpm)
-				if err := r.Client.Patch(ctx, m, patch); err != nil {
-					return ctrl.Result{},
-						fmt.Errorf("failed to delete manifestwork, set selectively delete option err: %v", err)
-				}
+	if res, err := r.deleteManifestWork(ctx, hyd, m); err != nil || !res.IsZero() {
+		return res, err
+	}
 
-				r.Log.Info("pre delete the manifestwork, selectively delete option setting complete")
-			}
+	syncManifestworkStatusToHypershiftDeployment(hyd, mergeManifestWorkChunks(m, chunks))
+	//caller will execute the status update
+	setStatusCondition(hyd, hypdeployment.WorkConfigured, metav1.ConditionTrue, "Removing HypershiftDeployment's manifestwork and related resources", hypdeployment.RemovingReason)
+
+	return ctrl.Result{RequeueAfter: r.deprovisionRequeue(hyd), Requeue: true}, nil
+}
+
+// deleteManifestWork deletes the manifestwork or one of its chunks once the work agent consumed its delete option,
+// a zero result when the deletion is requested
+func (r *HypershiftDeploymentReconciler) deleteManifestWork(ctx context.Context, hyd *hypdeployment.HypershiftDeployment, m *workv1.ManifestWork) (ctrl.Result, error) {
+	if !m.GetDeletionTimestamp().IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	// the delete option depends on the override of the spec, do not act on a superseded one
+	if superseded, err := r.specSuperseded(ctx, hyd); err != nil || superseded {
+		return ctrl.Result{Requeue: superseded}, err
+	}
 
-			cond := condmeta.FindStatusCondition(m.Status.Conditions, string(workv1.WorkAvailable))
-			if cond == nil || cond.ObservedGeneration != m.Generation || cond.Status != metav1.ConditionTrue {
-				// Requeue the request, wait for the work agent to consume the delete option changes.
-				return ctrl.Result{RequeueAfter: 1 * time.Second, Requeue: true}, nil
+	dpm := m.DeepCopy()
+	setManifestWorkSelectivelyDeleteOption(m, hyd)
+	if len(m.Spec.Workload.Manifests) == 0 {
+		// nothing for the work agent to delete or orphan, waiting for it to consume the delete option only delays the deletion
+		r.Log.Info(fmt.Sprintf("manifestwork %s has no payload, delete it without setting the delete option", client.ObjectKeyFromObject(m)))
+	} else if m.Spec.DeleteOption.PropagationPolicy != workv1.DeletePropagationPolicyTypeOrphan {
+		if !reflect.DeepEqual(dpm.Spec.DeleteOption, m.Spec.DeleteOption) {
+			patch := client.MergeFrom(dpm)
+			if err := r.Client.Patch(ctx, m, patch); err != nil {
+				return ctrl.Result{},
+					fmt.Errorf("failed to delete manifestwork, set selectively delete option err: %v", err)
 			}
+
+			r.Log.Info("pre delete the manifestwork, selectively delete option setting complete")
 		}
 
-		if err := r.Delete(ctx, m); err != nil {
-			if !apierrors.IsNotFound(err) {
-				return ctrl.Result{}, fmt.Errorf("failed to delete manifestwork, err: %v", err)
-			}
+		cond := condmeta.FindStatusCondition(m.Status.Conditions, string(workv1.WorkAvailable))
+		if cond == nil || cond.ObservedGeneration != m.Generation || cond.Status != metav1.ConditionTrue {
+			// Requeue the request, wait for the work agent to consume the delete option changes.
+			return ctrl.Result{RequeueAfter: 1 * time.Second, Requeue: true}, nil
 		}
-		r.Log.Info("delete the manifestwork complete")
 	}
 
-	syncManifestworkStatusToHypershiftDeployment(hyd, m)
-	//caller will execute the status update
-	setStatusCondition(hyd, hypdeployment.WorkConfigured, metav1.ConditionTrue, "Removing HypershiftDeployment's manifestwork and related resources", hypdeployment.RemovingReason)
+	// the spec can change while waiting for the work agent to consume the delete option
+	if superseded, err := r.specSuperseded(ctx, hyd); err != nil || superseded {
+		return ctrl.Result{Requeue: superseded}, err
+	}
+
+	if err := r.Delete(ctx, m); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("failed to delete manifestwork, err: %v", err)
+		}
+	} else {
+		r.noteManifestWorkChange(reconcileResultDeleted)
+		r.recordEvent(hyd, corev1.EventTypeNormal, ManifestWorkDeletedEvent, "Deleted ManifestWork %s on hosting cluster %s", client.ObjectKeyFromObject(m), helper.GetHostingCluster(hyd))
+	}
+	r.Log.Info(fmt.Sprintf("delete the manifestwork %s complete", client.ObjectKeyFromObject(m)))
 
-	return ctrl.Result{RequeueAfter: 20 * time.Second, Requeue: true}, nil
+	return ctrl.Result{}, nil
+}
+
+// pullSecretFetchError is a failed read of the pull secret of the HostedCluster, the reconcile is requeued
+// according to its cause
+type pullSecretFetchError struct {
+	key types.NamespacedName
+	err error
+}
+
+func (e *pullSecretFetchError) Error() string {
+	return fmt.Sprintf("failed to get the pull secret %v, err: %v", e.key, e.err)
+}
+
+func (e *pullSecretFetchError) Unwrap() error {
+	return e.err
+}
+
+// pullSecretFetchFailed requeues the reconcile after a failed read of the pull secret. A missing pull secret is
+// reported in the PullSecretMissing condition and checked again later, any other error is taken as a blip of the
+// hub API server and retried right away without changing the conditions.
+func (r *HypershiftDeploymentReconciler) pullSecretFetchFailed(hyd *hypdeployment.HypershiftDeployment, err *pullSecretFetchError) (ctrl.Result, error) {
+	if apierrors.IsNotFound(err) {
+		return ctrl.Result{RequeueAfter: time.Minute * 1}, r.updateStatusConditionsOnChange(
+			hyd,
+			hypdeployment.PullSecretMissing,
+			metav1.ConditionTrue,
+			fmt.Sprintf("Pull secret %s not found, the ManifestWork is not updated until it is created", err.key),
+			hypdeployment.SecretNotFoundReason,
+		)
+	}
+
+	r.Log.Info(fmt.Sprintf("retrying the transient failure to get the pull secret %s: %v", err.key, err.err))
+	return ctrl.Result{Requeue: true}, nil
 }
 
 func (r *HypershiftDeploymentReconciler) appendHostedClusterReferenceSecrets(ctx context.Context, providerSecret *corev1.Secret) loadManifest {
@@ -438,14 +1450,26 @@ func (r *HypershiftDeploymentReconciler) appendHostedClusterReferenceSecrets(ctx
 		if len(hcSpec.PullSecret.Name) != 0 {
 			var pullCreds *corev1.Secret
 			if !hyd.Spec.Infrastructure.Configure {
-				pullCreds, err = r.generateSecret(ctx,
-					types.NamespacedName{Name: hcSpec.PullSecret.Name,
-						Namespace: hyd.GetNamespace()})
-
-				if err != nil {
+				origin := &corev1.Secret{}
+				key := types.NamespacedName{Name: hcSpec.PullSecret.Name, Namespace: hyd.GetNamespace()}
+				if err := r.Get(ctx, key, origin); err != nil {
 					log.Error(err, "failed to duplicate pull secret")
-					return err
+					if apierrors.IsNotFound(err) {
+						r.recordEvent(hyd, corev1.EventTypeWarning, PullSecretNotFoundEvent,
+							"Pull secret %s not found, the ManifestWork %s on hosting cluster %s is not updated", key, getManifestWorkKey(hyd), helper.GetHostingCluster(hyd))
+					}
+					return &pullSecretFetchError{key: key, err: err}
+				}
+				resolveStatusCondition(hyd, hypdeployment.PullSecretMissing)
+
+				if err := validatePullSecretType(origin); err != nil {
+					log.Info(err.Error())
+					setStatusCondition(hyd, hypdeployment.PullSecretTypeMismatch, metav1.ConditionTrue, err.Error(), hypdeployment.UnexpectedSecretTypeReason)
+				} else {
+					resolveStatusCondition(hyd, hypdeployment.PullSecretTypeMismatch)
 				}
+
+				pullCreds = duplicateSecretWithOverride(origin)
 			} else {
 				pullCreds = r.scaffoldPullSecret(hyd, *providerSecret)
 			}
@@ -453,14 +1477,20 @@ func (r *HypershiftDeploymentReconciler) appendHostedClusterReferenceSecrets(ctx
 			refSecrets = append(refSecrets, pullCreds)
 		}
 
-		if hcSpec.Platform.AWS != nil {
+		// The None platform runs on user supplied infrastructure, it has no cloud credentials
+		if hcSpec.Platform.Type == hyp.NonePlatform {
+			log.V(1).Info("Skipping the cloud credentials of the None platform")
+		} else if hcSpec.Platform.AWS != nil {
 			refSecrets = append(refSecrets, ScaffoldAWSSecrets(hyd, hostedCluster)...)
+		} else if hcSpec.Platform.Azure != nil && azureIdentity(hyd) != nil {
+			log.V(1).Info("Skipping the azure credentials secret, the HostedCluster authenticates with a managed identity")
 		} else if hcSpec.Platform.Azure != nil {
 			creds, err := getAzureCloudProviderCreds(providerSecret)
 			if err != nil {
-				return nil
+				log.Error(err, "failed to read the azure credentials from the provider secret")
+				return err
 			}
-			refSecrets = append(refSecrets, ScaffoldAzureCloudCredential(hyd, creds))
+			refSecrets = append(refSecrets, ScaffoldAzureCloudCredential(hyd, hostedCluster, creds))
 		}
 
 		sshKey := hcSpec.SSHKey
@@ -477,9 +1507,18 @@ func (r *HypershiftDeploymentReconciler) appendHostedClusterReferenceSecrets(ctx
 			refSecrets = append(refSecrets, s)
 		}
 
+		propagated := []*corev1.Secret{}
 		for _, s := range refSecrets {
 			o := duplicateSecretWithOverride(s, overrideNamespace(helper.GetHostingNamespace(hyd)))
 			*payload = append(*payload, workv1.Manifest{RawExtension: runtime.RawExtension{Object: o}})
+			propagated = append(propagated, o)
+		}
+
+		if err := validatePropagatedSecretSizes(propagated); err != nil {
+			log.Info(err.Error())
+			setStatusCondition(hyd, hypdeployment.PropagatedSecretTooLarge, metav1.ConditionTrue, err.Error(), hypdeployment.SecretTooLargeReason)
+		} else {
+			resolveStatusCondition(hyd, hypdeployment.PropagatedSecretTooLarge)
 		}
 
 		return nil
@@ -494,6 +1533,14 @@ func (r *HypershiftDeploymentReconciler) appendHostedCluster(ctx context.Context
 			return err
 		}
 
+		if imageRegistryManagementState(hyd) == hypdeployment.ImageRegistryRemoved {
+			setStatusCondition(hyd, hypdeployment.ImageRegistryDisabled, metav1.ConditionTrue,
+				"The internal image registry of the hosted cluster is removed", hypdeployment.ImageRegistryRemovedReason)
+		} else {
+			setStatusCondition(hyd, hypdeployment.ImageRegistryDisabled, metav1.ConditionFalse,
+				"The internal image registry of the hosted cluster is managed", hypdeployment.ImageRegistryManagedReason)
+		}
+
 		*payload = append(*payload, workv1.Manifest{RawExtension: runtime.RawExtension{Object: hc}})
 
 		return nil
@@ -546,17 +1593,64 @@ func (r *HypershiftDeploymentReconciler) appendNodePool(ctx context.Context) loa
 				}
 
 				// Just use the spec from the nodepool object ref
-				np := ScaffoldNodePool(hyd, npObj.Name, unstructNodePool.Object["spec"].(map[string]interface{}))
+				np, err := ScaffoldNodePool(hyd, npObj.Name, unstructNodePool.Object["spec"].(map[string]interface{}), r.InstanceTypeAliases)
+				if err != nil {
+					_ = r.updateStatusConditionsOnChange(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+
+					return err
+				}
+
+				// the HostedClusterRef is scaffolded under the name of the HypershiftDeployment, its NodePools follow it
+				if len(hyd.Spec.HostedClusterRef.Name) != 0 && npObj.Spec.ClusterName == hyd.Spec.HostedClusterRef.Name {
+					if err := unstructured.SetNestedField(np.Object, hyd.Name, "spec", "clusterName"); err != nil {
+						return err
+					}
+				}
 				*payload = append(*payload, workv1.Manifest{RawExtension: runtime.RawExtension{Object: np}})
 			}
 		} else {
 			for _, hdNp := range hyd.Spec.NodePools {
-				usNpSpec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&hdNp.Spec)
+				npSpec := hdNp.Spec.DeepCopy()
+				defaultNodePoolManagement(npSpec)
+				usNpSpec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(npSpec)
 				if err != nil {
 					return fmt.Errorf(fmt.Sprintf("failed to transform HypershiftDeployment.Spec.NodePools from hypershiftDeployment: %v:%v", hyd.Namespace, hdNp.Name))
 				}
 
-				np := ScaffoldNodePool(hyd, hdNp.Name, usNpSpec)
+				np, err := ScaffoldNodePool(hyd, hdNp.Name, usNpSpec, r.InstanceTypeAliases)
+				if err != nil {
+					_ = r.updateStatusConditionsOnChange(hyd, hypdeployment.WorkConfigured, metav1.ConditionFalse, err.Error(), hypdeployment.MisConfiguredReason)
+
+					return err
+				}
+				if len(hdNp.MachineCIDR) != 0 {
+					np.SetAnnotations(map[string]string{constant.NodePoolMachineCIDRAnnotation: hdNp.MachineCIDR})
+				}
+				if len(hdNp.TuningConfig) != 0 {
+					refs := []interface{}{}
+					for _, ref := range hdNp.TuningConfig {
+						refs = append(refs, map[string]interface{}{"name": ref.Name})
+					}
+
+					if err := unstructured.SetNestedSlice(np.Object, refs, "spec", "tuningConfig"); err != nil {
+						return fmt.Errorf("failed to set the tuningConfig of NodePool %v:%v, err: %w", hyd.Namespace, hdNp.Name, err)
+					}
+				}
+				if len(hdNp.Arch) != 0 {
+					if err := unstructured.SetNestedField(np.Object, hdNp.Arch, "spec", "arch"); err != nil {
+						return fmt.Errorf("failed to set the arch of NodePool %v:%v, err: %w", hyd.Namespace, hdNp.Name, err)
+					}
+				}
+				if hdNp.Kubevirt != nil {
+					if err := scaffoldKubevirtNodePoolOptions(np, hdNp.Kubevirt); err != nil {
+						return fmt.Errorf("failed to set the kubevirt options of NodePool %v:%v, err: %w", hyd.Namespace, hdNp.Name, err)
+					}
+				}
+				if hdNp.NodeVolumeDetachTimeout != nil {
+					if err := unstructured.SetNestedField(np.Object, hdNp.NodeVolumeDetachTimeout.Duration.String(), "spec", "nodeVolumeDetachTimeout"); err != nil {
+						return fmt.Errorf("failed to set the nodeVolumeDetachTimeout of NodePool %v:%v, err: %w", hyd.Namespace, hdNp.Name, err)
+					}
+				}
 				*payload = append(*payload, workv1.Manifest{RawExtension: runtime.RawExtension{Object: np}})
 			}
 		}
@@ -565,6 +1659,79 @@ func (r *HypershiftDeploymentReconciler) appendNodePool(ctx context.Context) loa
 	}
 }
 
+// hostedClusterFeedbackJsonPaths are the values the work agent reports back from the HostedCluster, read by the
+// status sync. A value is added to every manifestwork by appending it here
+var hostedClusterFeedbackJsonPaths = []workv1.JsonPath{
+	// mirroring https://github.com/openshift/hypershift/blob/b9418cb392b94bc6682c76ce21b5dfd2744b9e8c/api/v1alpha1/hostedcluster_types.go#L1209
+	{
+		Name: Reason,
+		Path: ".status.conditions[?(@.type==\"Available\")].reason",
+	},
+	{
+		Name: StatusFlag,
+		Path: ".status.conditions[?(@.type==\"Available\")].status",
+	},
+	{
+		Name: Message,
+		Path: ".status.conditions[?(@.type==\"Available\")].message",
+	},
+	{
+		Name: Progress,
+		Path: ".status.version.history[?(@.state!=\"\")].state",
+	},
+	{
+		Name: APIEndpointHost,
+		Path: ".status.controlPlaneEndpoint.host",
+	},
+	{
+		Name: APIEndpointPort,
+		Path: ".status.controlPlaneEndpoint.port",
+	},
+	{
+		Name: CreationTimestamp,
+		Path: ".metadata.creationTimestamp",
+	},
+	{
+		Name: KubeconfigName,
+		Path: ".status.kubeconfig.name",
+	},
+}
+
+// nodePoolFeedbackJsonPaths are the values the work agent reports back from each NodePool
+var nodePoolFeedbackJsonPaths = []workv1.JsonPath{
+	{
+		Name: Reason,
+		Path: ".status.conditions[?(@.type==\"Ready\")].reason",
+	},
+	{
+		Name: StatusFlag,
+		Path: ".status.conditions[?(@.type==\"Ready\")].status",
+	},
+	{
+		Name: Message,
+		Path: ".status.conditions[?(@.type==\"Ready\")].message",
+	},
+	{
+		Name: DesiredReplicas,
+		Path: ".spec.replicas",
+	},
+	{
+		Name: CurrentReplicas,
+		Path: ".status.replicas",
+	},
+	{
+		Name: CreationTimestamp,
+		Path: ".metadata.creationTimestamp",
+	},
+}
+
+// feedbackJsonPaths copies the default JSONPaths of a resource, so the package defaults are never appended to,
+// followed by the custom feedback rules of the HypershiftDeployment
+func feedbackJsonPaths(defaults []workv1.JsonPath, hyd *hypdeployment.HypershiftDeployment, resource hypdeployment.FeedbackResource) []workv1.JsonPath {
+	out := append([]workv1.JsonPath{}, defaults...)
+	return append(out, feedbackRuleJsonPaths(hyd.Spec.FeedbackRules, resource)...)
+}
+
 func getManifestWorkConfigs(hyd *hypdeployment.HypershiftDeployment) map[workv1.ResourceIdentifier]workv1.ManifestConfigOption {
 	out := map[workv1.ResourceIdentifier]workv1.ManifestConfigOption{}
 	k := workv1.ResourceIdentifier{
@@ -578,26 +1745,8 @@ func getManifestWorkConfigs(hyd *hypdeployment.HypershiftDeployment) map[workv1.
 		ResourceIdentifier: k,
 		FeedbackRules: []workv1.FeedbackRule{
 			{
-				Type: workv1.JSONPathsType,
-				// mirroring https://github.com/openshift/hypershift/blob/b9418cb392b94bc6682c76ce21b5dfd2744b9e8c/api/v1alpha1/hostedcluster_types.go#L1209
-				JsonPaths: []workv1.JsonPath{
-					{
-						Name: Reason,
-						Path: ".status.conditions[?(@.type==\"Available\")].reason",
-					},
-					{
-						Name: StatusFlag,
-						Path: ".status.conditions[?(@.type==\"Available\")].status",
-					},
-					{
-						Name: Message,
-						Path: ".status.conditions[?(@.type==\"Available\")].message",
-					},
-					{
-						Name: Progress,
-						Path: ".status.version.history[?(@.state!=\"\")].state",
-					},
-				},
+				Type:      workv1.JSONPathsType,
+				JsonPaths: feedbackJsonPaths(hostedClusterFeedbackJsonPaths, hyd, hypdeployment.FeedbackHostedCluster),
 			},
 		},
 	}
@@ -614,25 +1763,11 @@ func getManifestWorkConfigs(hyd *hypdeployment.HypershiftDeployment) map[workv1.
 			ResourceIdentifier: k,
 			FeedbackRules: []workv1.FeedbackRule{
 				{
-					Type: workv1.JSONPathsType,
-					JsonPaths: []workv1.JsonPath{
-						{
-							Name: Reason,
-							Path: ".status.conditions[?(@.type==\"Ready\")].reason",
-						},
-						{
-							Name: StatusFlag,
-							Path: ".status.conditions[?(@.type==\"Ready\")].status",
-						},
-						{
-							Name: Message,
-							Path: ".status.conditions[?(@.type==\"Ready\")].message",
-						},
-					},
+					Type:      workv1.JSONPathsType,
+					JsonPaths: feedbackJsonPaths(nodePoolFeedbackJsonPaths, hyd, hypdeployment.FeedbackNodePool),
 				},
 			},
 		}
-
 	}
 
 	return out
@@ -689,6 +1824,18 @@ func enableManifestStatusFeedback(m *workv1.ManifestWork, hyd *hypdeployment.Hyp
 		cfg = append(cfg, v)
 	}
 
+	// a stable order keeps an unchanged manifestwork from being updated
+	sort.Slice(cfg, func(i, j int) bool {
+		a, b := cfg[i].ResourceIdentifier, cfg[j].ResourceIdentifier
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
 	m.Spec.ManifestConfigs = cfg
 
 	return cfg
@@ -748,7 +1895,49 @@ func getStatusFeedbackAsCondition(m *workv1.ManifestWork, hyd *hypdeployment.Hyp
 		})
 	}
 
-	return out
+	return append(out, feedbackRuleConditions(m, hyd, idMap)...)
+}
+
+// getStatusFeedbackAPIEndpoint builds the API server URL from the control plane endpoint of the HostedCluster
+// feedback, the URL is empty until the HostedCluster reports its endpoint. It returns false when the work agent
+// has not reported on the HostedCluster.
+func getStatusFeedbackAPIEndpoint(m *workv1.ManifestWork, hyd *hypdeployment.HypershiftDeployment) (string, bool) {
+	if m == nil || hyd == nil {
+		return "", false
+	}
+
+	id := workv1.ResourceIdentifier{
+		Group:     hyp.GroupVersion.Group,
+		Resource:  HostedClusterResource,
+		Name:      hyd.Name,
+		Namespace: helper.GetHostingNamespace(hyd),
+	}
+
+	for _, obj := range m.Status.ResourceStatus.Manifests {
+		if resourceMeta(obj.ResourceMeta).ToIdentifier() != id {
+			continue
+		}
+
+		var host string
+		var port int64
+		for _, v := range obj.StatusFeedbacks.Values {
+			if v.Name == APIEndpointHost && v.Value.String != nil {
+				host = *v.Value.String
+			}
+
+			if v.Name == APIEndpointPort && v.Value.Integer != nil {
+				port = *v.Value.Integer
+			}
+		}
+
+		if len(host) == 0 || port == 0 {
+			return "", true
+		}
+
+		return "https://" + net.JoinHostPort(host, strconv.FormatInt(port, 10)), true
+	}
+
+	return "", false
 }
 
 type resourceMeta workv1.ManifestResourceMeta
@@ -786,6 +1975,28 @@ func getManifestPayloadSecretByName(manifests *[]workv1.Manifest, secretName str
 	return nil, nil
 }
 
+// isInManifestPayload checks if a resource of the given kind and name is already loaded to the payload
+func isInManifestPayload(manifests *[]workv1.Manifest, kind, name string) bool {
+	for _, v := range *manifests {
+		if len(v.Raw) != 0 {
+			u := &unstructured.Unstructured{}
+			if err := json.Unmarshal(v.Raw, u); err != nil {
+				continue
+			}
+
+			if u.GetKind() == kind && u.GetName() == name {
+				return true
+			}
+		} else if v.Object != nil && v.Object.GetObjectKind().GroupVersionKind().Kind == kind {
+			if o, ok := v.Object.(metav1.Object); ok && o.GetName() == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func getHostedClusterInManifestPayload(manifests *[]workv1.Manifest) *hyp.HostedCluster {
 	for _, v := range *manifests {
 		if v.Object.GetObjectKind().GroupVersionKind().Kind == "HostedCluster" {
@@ -0,0 +1,324 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	condmeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
+)
+
+// defaultManifestWorkChunkSize keeps a manifestwork well under the object size limit of etcd
+const defaultManifestWorkChunkSize = 900 * 1024
+
+func (r *HypershiftDeploymentReconciler) manifestWorkChunkSize() int {
+	if r.ManifestWorkChunkSize <= 0 {
+		return defaultManifestWorkChunkSize
+	}
+
+	return r.ManifestWorkChunkSize
+}
+
+// manifestWorkChunkName names the chunks after the manifestwork, the manifestwork itself is the chunk 0
+func manifestWorkChunkName(hyd *hypdeployment.HypershiftDeployment, index int) string {
+	return fmt.Sprintf("%s-%d", generateManifestName(hyd), index)
+}
+
+// manifestWorkChunkIndex is the index in the name of a chunk, 0 when the name is not the one of a chunk
+func manifestWorkChunkIndex(hyd *hypdeployment.HypershiftDeployment, name string) int {
+	i, err := strconv.Atoi(strings.TrimPrefix(name, generateManifestName(hyd)+"-"))
+	if err != nil || i < 1 {
+		return 0
+	}
+
+	return i
+}
+
+func scaffoldManifestWorkChunk(hyd *hypdeployment.HypershiftDeployment, index int) (*workv1.ManifestWork, error) {
+	w, err := scaffoldManifestwork(hyd)
+	if err != nil {
+		return nil, err
+	}
+
+	w.Name = manifestWorkChunkName(hyd, index)
+	w.Labels = map[string]string{constant.ManifestWorkChunkLabel: generateManifestName(hyd)}
+
+	return w, nil
+}
+
+// listManifestWorkChunks returns the chunks of the manifestwork of the HypershiftDeployment, in the order of their index
+func (r *HypershiftDeploymentReconciler) listManifestWorkChunks(ctx context.Context, hyd *hypdeployment.HypershiftDeployment) ([]workv1.ManifestWork, error) {
+	list := &workv1.ManifestWorkList{}
+	if err := r.List(ctx, list, client.InNamespace(helper.GetHostingCluster(hyd)),
+		client.MatchingLabels{constant.ManifestWorkChunkLabel: generateManifestName(hyd)}); err != nil {
+		return nil, fmt.Errorf("failed to list the chunks of the manifestwork %s, err: %w", getManifestWorkKey(hyd), err)
+	}
+
+	chunks := []workv1.ManifestWork{}
+	for _, w := range list.Items {
+		if manifestWorkChunkIndex(hyd, w.Name) != 0 && checkManifestWorkOwner(hyd, &w) == nil {
+			chunks = append(chunks, w)
+		}
+	}
+
+	sort.Slice(chunks, func(i, j int) bool {
+		return manifestWorkChunkIndex(hyd, chunks[i].Name) < manifestWorkChunkIndex(hyd, chunks[j].Name)
+	})
+
+	return chunks, nil
+}
+
+// manifestSize is the size of the manifest once serialized in the manifestwork
+func manifestSize(m workv1.Manifest) (int, error) {
+	if len(m.Raw) != 0 {
+		return len(m.Raw), nil
+	}
+
+	b, err := json.Marshal(m.Object)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal the manifestwork payload, err: %w", err)
+	}
+
+	return len(b), nil
+}
+
+// chunkPayload splits the payload once serialized over size. The HostedCluster, its secrets and every object but
+// the NodePools stay in the manifestwork, the first payload, the NodePools fill it up to size and the others fill
+// the chunks in the payload order. A payload within size is not split.
+func chunkPayload(payload []workv1.Manifest, size int) ([][]workv1.Manifest, error) {
+	sizes := make([]int, len(payload))
+	nodePool := make([]bool, len(payload))
+	total, mainSize := 0, 0
+	for i, m := range payload {
+		s, err := manifestSize(m)
+		if err != nil {
+			return nil, err
+		}
+
+		u, err := manifestToUnstructured(m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the manifestwork payload, err: %w", err)
+		}
+
+		sizes[i], nodePool[i] = s, u.GetKind() == "NodePool"
+		total += s
+		if !nodePool[i] {
+			mainSize += s
+		}
+	}
+
+	if total <= size {
+		return [][]workv1.Manifest{payload}, nil
+	}
+
+	if mainSize > size {
+		return nil, fmt.Errorf("the manifestwork payload without the NodePools is %d bytes, over the %d bytes of a manifestwork", mainSize, size)
+	}
+
+	// the chunk of each NodePool, once a NodePool does not fit in the manifestwork the next ones go to the chunks
+	chunkOf := map[int]int{}
+	chunk, chunkSize := 0, mainSize
+	for i := range payload {
+		if !nodePool[i] {
+			continue
+		}
+
+		if chunkSize+sizes[i] > size && (chunk == 0 || chunkSize != 0) {
+			chunk, chunkSize = chunk+1, 0
+		}
+
+		chunkOf[i] = chunk
+		chunkSize += sizes[i]
+	}
+
+	chunks := make([][]workv1.Manifest, chunk+1)
+	for i, m := range payload {
+		chunks[chunkOf[i]] = append(chunks[chunkOf[i]], m)
+	}
+
+	return chunks, nil
+}
+
+// manifestConfigsOfPayload keeps the manifest configs of the objects of the payload
+func manifestConfigsOfPayload(cfg []workv1.ManifestConfigOption, payload []workv1.Manifest) ([]workv1.ManifestConfigOption, error) {
+	ids := map[workv1.ResourceIdentifier]bool{}
+	for _, m := range payload {
+		u, err := manifestToUnstructured(m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the manifestwork payload, err: %w", err)
+		}
+
+		rule := orphaningRule(u)
+		ids[workv1.ResourceIdentifier{Group: rule.Group, Resource: rule.Resource, Name: rule.Name, Namespace: rule.Namespace}] = true
+	}
+
+	out := []workv1.ManifestConfigOption{}
+	for _, c := range cfg {
+		if ids[c.ResourceIdentifier] {
+			out = append(out, c)
+		}
+	}
+
+	return out, nil
+}
+
+// updateManifestWorkChunks writes the payload of each chunk, payload is the whole payload of the HypershiftDeployment.
+// A NodePool moved between the manifestwork and the chunks stays orphaned by the manifestwork it leaves, like a
+// NodePool of the payload, only the NodePools removed from the payload are deleted. The chunks no longer needed are
+// emptied the same way, then deleted. A chunk name taken by the manifestwork of another HypershiftDeployment returns a
+// foreignManifestWorkError, the manifestwork is left as is.
+func (r *HypershiftDeploymentReconciler) updateManifestWorkChunks(ctx context.Context, hyd *hypdeployment.HypershiftDeployment,
+	existing []workv1.ManifestWork, chunks [][]workv1.Manifest, mwCfg []workv1.ManifestConfigOption, payload []workv1.Manifest) error {
+	current := map[int]*workv1.ManifestWork{}
+	count := len(chunks)
+	for i := range existing {
+		index := manifestWorkChunkIndex(hyd, existing[i].Name)
+		current[index] = &existing[i]
+		if index > count {
+			count = index
+		}
+	}
+
+	for index := 1; index <= count; index++ {
+		w, err := scaffoldManifestWorkChunk(hyd, index)
+		if err != nil {
+			return err
+		}
+
+		applied := w
+		if c, ok := current[index]; ok {
+			if !c.GetDeletionTimestamp().IsZero() {
+				continue
+			}
+			applied = c
+		}
+
+		chunk := []workv1.Manifest{}
+		if index < len(chunks) {
+			chunk = chunks[index]
+		}
+
+		removal, err := removeManifests(applied, payload)
+		if err != nil {
+			return err
+		}
+		chunk = append(chunk, removal.kept...)
+
+		if len(chunk) == 0 {
+			if applied == w {
+				continue
+			}
+
+			if err := r.Delete(ctx, applied); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to delete the manifestwork chunk %s, err: %w", client.ObjectKeyFromObject(applied), err)
+			}
+			r.noteManifestWorkChange(reconcileResultDeleted)
+			r.recordEvent(hyd, corev1.EventTypeNormal, ManifestWorkDeletedEvent, "Deleted ManifestWork %s on hosting cluster %s", client.ObjectKeyFromObject(applied), helper.GetHostingCluster(hyd))
+			continue
+		}
+
+		cfg, err := manifestConfigsOfPayload(mwCfg, chunk)
+		if err != nil {
+			return err
+		}
+
+		changed := false
+		op, err := controllerutil.CreateOrUpdate(ctx, r.Client, w, func() error {
+			if err := checkManifestWorkOwner(hyd, w); err != nil {
+				return err
+			}
+
+			before := w.Spec.DeepCopy()
+			w.Labels = map[string]string{constant.ManifestWorkChunkLabel: generateManifestName(hyd)}
+			w.Spec.Workload.Manifests = chunk
+			w.Spec.ManifestConfigs = cfg
+			w.Spec.DeleteOption = removal.deleteOption
+			changed = !manifestWorkSpecEqual(before, &w.Spec)
+			if !changed {
+				w.Spec = *before
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write the manifestwork chunk %s, err: %w", client.ObjectKeyFromObject(w), err)
+		}
+
+		switch {
+		case op == controllerutil.OperationResultCreated:
+			r.noteManifestWorkChange(reconcileResultCreated)
+			r.recordEvent(hyd, corev1.EventTypeNormal, ManifestWorkCreatedEvent, "Created ManifestWork %s on hosting cluster %s", client.ObjectKeyFromObject(w), helper.GetHostingCluster(hyd))
+		case op == controllerutil.OperationResultUpdated && changed:
+			r.noteManifestWorkChange(reconcileResultUpdated)
+			r.recordEvent(hyd, corev1.EventTypeNormal, ManifestWorkUpdatedEvent, "Updated ManifestWork %s on hosting cluster %s", client.ObjectKeyFromObject(w), helper.GetHostingCluster(hyd))
+		}
+	}
+
+	return nil
+}
+
+// mergeManifestWorkChunks is the manifestwork with the status of its chunks: the manifests of the chunks and the ones
+// they report are added and a chunk that is not applied, not available, progressing or degraded reports it for all of them
+func mergeManifestWorkChunks(m *workv1.ManifestWork, chunks []workv1.ManifestWork) *workv1.ManifestWork {
+	if len(chunks) == 0 {
+		return m
+	}
+
+	merged := m.DeepCopy()
+	for _, c := range chunks {
+		merged.Spec.Workload.Manifests = append(merged.Spec.Workload.Manifests, c.Spec.Workload.Manifests...)
+		merged.Status.ResourceStatus.Manifests = append(merged.Status.ResourceStatus.Manifests, c.Status.ResourceStatus.Manifests...)
+
+		for _, t := range []struct {
+			condType string
+			healthy  metav1.ConditionStatus
+		}{
+			{workv1.WorkApplied, metav1.ConditionTrue},
+			{workv1.WorkAvailable, metav1.ConditionTrue},
+			{workv1.WorkProgressing, metav1.ConditionFalse},
+			{workv1.WorkDegraded, metav1.ConditionFalse},
+		} {
+			cond := condmeta.FindStatusCondition(c.Status.Conditions, t.condType)
+			if cond == nil || cond.Status == t.healthy {
+				continue
+			}
+
+			if mc := condmeta.FindStatusCondition(merged.Status.Conditions, t.condType); mc != nil && mc.Status != t.healthy {
+				continue
+			}
+
+			unhealthy := *cond
+			unhealthy.Message = fmt.Sprintf("ManifestWork %s: %s", client.ObjectKeyFromObject(&c), cond.Message)
+			condmeta.SetStatusCondition(&merged.Status.Conditions, unhealthy)
+		}
+	}
+
+	return merged
+}
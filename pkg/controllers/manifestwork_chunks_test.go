@@ -0,0 +1,276 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+)
+
+func secretManifest(name string, data string) workv1.Manifest {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	u.SetKind("Secret")
+	u.SetNamespace("clusters")
+	u.SetName(name)
+	unstructured.SetNestedField(u.Object, data, "stringData", "data")
+
+	return workv1.Manifest{RawExtension: runtime.RawExtension{Object: u}}
+}
+
+func manifestNames(t *testing.T, manifests []workv1.Manifest) []string {
+	names := []string{}
+	for _, m := range manifests {
+		u, err := manifestToUnstructured(m)
+		assert.Nil(t, err, "err nil when the manifest is readable")
+		names = append(names, u.GetName())
+	}
+	return names
+}
+
+func TestChunkPayload(t *testing.T) {
+	payload := []workv1.Manifest{
+		secretManifest("pull-secret", "a"),
+		nodePoolManifest("np-a"),
+		nodePoolManifest("np-b"),
+		secretManifest("ssh-key", "b"),
+		nodePoolManifest("np-c"),
+	}
+
+	mainSize, _ := manifestSize(payload[0])
+	npSize, _ := manifestSize(payload[1])
+
+	chunks, err := chunkPayload(payload, 1024*1024)
+	assert.Nil(t, err, "err nil when the payload fits")
+	assert.Equal(t, [][]workv1.Manifest{payload}, chunks, "the payload is not split")
+
+	// the manifestwork keeps its secrets and a NodePool, the next NodePools fill the chunks
+	chunks, err = chunkPayload(payload, 2*mainSize+npSize)
+	assert.Nil(t, err, "err nil when the payload is split")
+	if assert.Len(t, chunks, 2) {
+		assert.Equal(t, []string{"pull-secret", "np-a", "ssh-key"}, manifestNames(t, chunks[0]), "the payload order is kept")
+		assert.Equal(t, []string{"np-b", "np-c"}, manifestNames(t, chunks[1]))
+	}
+
+	_, err = chunkPayload(payload, mainSize)
+	assert.NotNil(t, err, "err when the payload without the NodePools does not fit")
+
+	big := secretManifest("big", strings.Repeat("x", 1024))
+	_, err = chunkPayload([]workv1.Manifest{big, nodePoolManifest("np-a")}, 1024)
+	assert.NotNil(t, err, "err when the secrets do not fit")
+}
+
+func TestMergeManifestWorkChunks(t *testing.T) {
+	m := &workv1.ManifestWork{}
+	setWorkApplied(m)
+	m.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{nodePoolResourceStatus("np-a")}
+
+	assert.Equal(t, m, mergeManifestWorkChunks(m, nil), "the manifestwork without chunks is unchanged")
+
+	healthy := workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: "test1-abcde-1", Namespace: "local-cluster"}}
+	setWorkApplied(&healthy)
+	healthy.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{nodePoolResourceStatus("np-b")}
+
+	failed := workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: "test1-abcde-2", Namespace: "local-cluster"}}
+	meta.SetStatusCondition(&failed.Status.Conditions, metav1.Condition{
+		Type:    string(workv1.WorkApplied),
+		Status:  metav1.ConditionFalse,
+		Reason:  "AppliedManifestWorkFailed",
+		Message: "failed to apply",
+	})
+
+	merged := mergeManifestWorkChunks(m, []workv1.ManifestWork{healthy, failed})
+	assert.Len(t, merged.Status.ResourceStatus.Manifests, 2, "the manifests of the chunks are reported")
+
+	c := meta.FindStatusCondition(merged.Status.Conditions, string(workv1.WorkApplied))
+	if assert.NotNil(t, c, "Applied condition is reported") {
+		assert.Equal(t, metav1.ConditionFalse, c.Status, "a chunk not applied is reported")
+		assert.Equal(t, "ManifestWork local-cluster/test1-abcde-2: failed to apply", c.Message)
+	}
+	assert.Equal(t, metav1.ConditionTrue, meta.FindStatusCondition(m.Status.Conditions, string(workv1.WorkApplied)).Status, "the manifestwork is not changed")
+}
+
+func TestManifestWorkChunks(t *testing.T) {
+	kubeClient := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	for _, name := range []string{"-2", "-3"} {
+		np := testHD.Spec.NodePools[0].DeepCopy()
+		np.Name = testHD.Spec.NodePools[0].Name + name
+		testHD.Spec.NodePools = append(testHD.Spec.NodePools, np)
+	}
+
+	kubeClient.Create(ctx, testHD)
+	defer kubeClient.Delete(ctx, testHD)
+
+	kubeClient.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: kubeClient,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var mw workv1.ManifestWork
+	assert.Nil(t, kubeClient.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+	assert.Len(t, payloadKinds(t, mw.Spec.Workload.Manifests)["NodePool"], 3, "the payload is not split by default")
+
+	// the manifestwork fits the HostedCluster, its secrets and a single NodePool
+	mainSize, npSize := 0, 0
+	for _, m := range mw.Spec.Workload.Manifests {
+		s, _ := manifestSize(m)
+		if u, _ := manifestToUnstructured(m); u.GetKind() == "NodePool" {
+			npSize = s
+			continue
+		}
+		mainSize += s
+	}
+	hdr.ManifestWorkChunkSize = mainSize + npSize + npSize/2
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	assert.Nil(t, kubeClient.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+	assert.Len(t, payloadKinds(t, mw.Spec.Workload.Manifests)["HostedCluster"], 1, "the HostedCluster stays in the manifestwork")
+	assert.Len(t, payloadKinds(t, mw.Spec.Workload.Manifests)["NodePool"], 1, "the manifestwork keeps a NodePool")
+
+	chunks, err := hdr.listManifestWorkChunks(ctx, testHD)
+	assert.Nil(t, err, "err nil when the chunks are listed")
+	if assert.Len(t, chunks, 1, "the other NodePools are in a chunk") {
+		assert.Equal(t, getManifestWorkKey(testHD).Name+"-1", chunks[0].Name)
+		assert.Equal(t, getManifestWorkKey(testHD).Name, chunks[0].Labels[constant.ManifestWorkChunkLabel])
+		assert.Len(t, payloadKinds(t, chunks[0].Spec.Workload.Manifests)["NodePool"], 2)
+	}
+
+	// the status of the chunks is reported with the one of the manifestwork
+	setWorkApplied(&mw)
+	assert.Nil(t, kubeClient.Update(ctx, &mw), "the manifestwork status is updated")
+	meta.SetStatusCondition(&chunks[0].Status.Conditions, metav1.Condition{
+		Type:    string(workv1.WorkApplied),
+		Status:  metav1.ConditionFalse,
+		Reason:  "AppliedManifestWorkFailed",
+		Message: "failed to apply",
+	})
+	assert.Nil(t, kubeClient.Update(ctx, &chunks[0]), "the chunk status is updated")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, kubeClient.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	c := meta.FindStatusCondition(resultHD.Status.Conditions, string(workv1.WorkApplied))
+	if assert.NotNil(t, c, "Applied condition is reported") {
+		assert.Equal(t, metav1.ConditionFalse, c.Status, "the chunk not applied is reported")
+		assert.Contains(t, c.Message, chunks[0].Name)
+	}
+
+	// the chunks are deleted with the manifestwork
+	for i := 0; i < 5; i++ {
+		res, err := hdr.deleteManifestworkWaitCleanUp(ctx, &resultHD)
+		assert.Nil(t, err, "err nil when the manifestworks are deleted")
+		if res.IsZero() {
+			break
+		}
+
+		// the work agent consumed the delete option
+		list := &workv1.ManifestWorkList{}
+		assert.Nil(t, kubeClient.List(ctx, list, client.InNamespace(testHD.Spec.HostingCluster)))
+		for i := range list.Items {
+			meta.SetStatusCondition(&list.Items[i].Status.Conditions, metav1.Condition{
+				Type:               string(workv1.WorkAvailable),
+				Status:             metav1.ConditionTrue,
+				Reason:             "ResourcesAvailable",
+				ObservedGeneration: list.Items[i].Generation,
+			})
+			assert.Nil(t, kubeClient.Update(ctx, &list.Items[i]))
+		}
+	}
+
+	chunks, err = hdr.listManifestWorkChunks(ctx, testHD)
+	assert.Nil(t, err, "err nil when the chunks are listed")
+	assert.Empty(t, chunks, "the chunks are deleted")
+	assert.True(t, apierrors.IsNotFound(kubeClient.Get(ctx, getManifestWorkKey(testHD), &mw)), "the manifestwork is deleted")
+}
+
+func TestManifestWorkChunkOfAnotherOwnerIsNotOverwritten(t *testing.T) {
+	kubeClient := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	np := testHD.Spec.NodePools[0].DeepCopy()
+	np.Name = testHD.Spec.NodePools[0].Name + "-2"
+	testHD.Spec.NodePools = append(testHD.Spec.NodePools, np)
+
+	kubeClient.Create(ctx, testHD)
+	defer kubeClient.Delete(ctx, testHD)
+
+	kubeClient.Create(ctx, getPullSecret(testHD))
+
+	// the chunk name of the HypershiftDeployment is the manifestwork name of another one
+	foreign := &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        getManifestWorkKey(testHD).Name + "-1",
+			Namespace:   testHD.Spec.HostingCluster,
+			Annotations: map[string]string{constant.CreatedByHypershiftDeployment: "other/test2"},
+		},
+	}
+	foreign.Spec.Workload.Manifests = []workv1.Manifest{secretManifest("other-secret", "other")}
+	assert.Nil(t, kubeClient.Create(ctx, foreign), "the foreign manifestwork is created")
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: kubeClient,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var mw workv1.ManifestWork
+	assert.Nil(t, kubeClient.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+	mainSize, npSize := 0, 0
+	for _, m := range mw.Spec.Workload.Manifests {
+		s, _ := manifestSize(m)
+		if u, _ := manifestToUnstructured(m); u.GetKind() == "NodePool" {
+			npSize = s
+			continue
+		}
+		mainSize += s
+	}
+	hdr.ManifestWorkChunkSize = mainSize + npSize + npSize/2
+
+	res, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when the conflict is reported")
+	assert.Equal(t, time.Minute, res.RequeueAfter, "requeued to notice the manifestwork is removed")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, kubeClient.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	c := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.Conflict))
+	if assert.NotNil(t, c, "Conflict condition is set") {
+		assert.Equal(t, metav1.ConditionTrue, c.Status, "the chunk name belongs to another HypershiftDeployment")
+		assert.Equal(t, hyd.ForeignOwnerReason, c.Reason)
+		assert.Contains(t, c.Message, "other/test2")
+	}
+
+	var resultMW workv1.ManifestWork
+	assert.Nil(t, kubeClient.Get(ctx, client.ObjectKeyFromObject(foreign), &resultMW), "the foreign manifestwork is found")
+	assert.Equal(t, foreign.ResourceVersion, resultMW.ResourceVersion, "the foreign manifestwork is not overwritten")
+	assert.Equal(t, []string{"other-secret"}, manifestNames(t, resultMW.Spec.Workload.Manifests))
+}
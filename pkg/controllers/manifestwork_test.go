@@ -3,6 +3,7 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -14,9 +15,11 @@ import (
 	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
 	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
 	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/features"
 	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
 	"github.com/stretchr/testify/assert"
 
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -44,7 +47,7 @@ func getHDforManifestWork() *hyd.HypershiftDeployment {
 	infraOut := getAWSInfrastructureOut()
 	testHD := getHypershiftDeployment("default", "test1", false)
 
-	testHD.Spec.Infrastructure.Platform = &hyd.Platforms{AWS: &hyd.AWSPlatform{}}
+	testHD.Spec.Infrastructure.Platform = &hyd.Platforms{AWS: &hyd.AWSPlatform{Region: infraOut.Region}}
 	testHD.Spec.Credentials = &hyd.CredentialARNs{AWS: &hyd.AWSCredentials{}}
 	testHD.Spec.InfraID = infraOut.InfraID
 	ScaffoldAWSHostedClusterSpec(testHD, infraOut)
@@ -253,6 +256,51 @@ func TestManifestWorkFlowBaseCase(t *testing.T) {
 	assert.Nil(t, checker.shouldHave(requiredResource), "err nil when all requrie resource exist in manifestwork")
 }
 
+func TestHostingNamespaceIndependentOfHostingCluster(t *testing.T) {
+	ctx := context.Background()
+
+	cases := []struct {
+		name             string
+		hostingNamespace string
+		expected         string
+	}{
+		{"default hosting namespace", "", "default"},
+		{"hosting namespace", "multicluster-engine", "multicluster-engine"},
+	}
+
+	for _, c := range cases {
+		client := initClient()
+
+		testHD := getHDforManifestWork()
+		testHD.Spec.HostingCluster = "local-cluster"
+		testHD.Spec.HostingNamespace = c.hostingNamespace
+		client.Create(ctx, testHD)
+		client.Create(ctx, getPullSecret(testHD))
+
+		hdr := &HypershiftDeploymentReconciler{
+			Client: client,
+			Log:    ctrl.Log.WithName("tester"),
+		}
+
+		_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+		assert.Nil(t, err, "err nil when reconcile was successful, %s", c.name)
+
+		// the hostingCluster picks the namespace of the manifestwork on the hub
+		var mw workv1.ManifestWork
+		assert.Nil(t, client.Get(ctx, types.NamespacedName{Namespace: "local-cluster", Name: testHD.Spec.InfraID}, &mw),
+			"the manifestwork is in the hostingCluster namespace, %s", c.name)
+
+		// the hostingNamespace picks the namespace of the payload on the HostingCluster
+		kinds := payloadKinds(t, mw.Spec.Workload.Manifests)
+		for _, kind := range []string{"HostedCluster", "NodePool", "Secret"} {
+			assert.NotEmpty(t, kinds[kind], "%s is in the payload, %s", kind, c.name)
+			for _, u := range kinds[kind] {
+				assert.Equal(t, c.expected, u.GetNamespace(), "%s %s is in the hosting namespace, %s", kind, u.GetName(), c.name)
+			}
+		}
+	}
+}
+
 func TestManifestWorkFlowBaseCaseWithObjectRef(t *testing.T) {
 	client := initClient()
 	ctx := context.Background()
@@ -711,8 +759,13 @@ func TestManifestWorkFlowWithSSHKey(t *testing.T) {
 
 	client.Create(context.Background(), testHD)
 
-	_, err := hdr.Reconcile(context.Background(), ctrl.Request{NamespacedName: getNN})
-	assert.NotNil(t, err, "fail on missing pull secret")
+	res, err := hdr.Reconcile(context.Background(), ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when the missing credentials are reported")
+	assert.NotZero(t, res.RequeueAfter, "requeued on missing aws credentials")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.True(t, meta.IsStatusConditionTrue(resultHD.Status.Conditions, string(hyd.MissingPlatformCredentials)), "aws credentials are missing")
 
 	// ensure the pull secret exist in cluster
 	// this pull secret is generated by the hypershift operator
@@ -786,6 +839,16 @@ func TestManifestWorkFlowWithSSHKey(t *testing.T) {
 	_, err = hdr.Reconcile(context.Background(), ctrl.Request{NamespacedName: getNN})
 	assert.Nil(t, err, "err nil when reconcile was successful")
 
+	// the removed ssh key is kept until the work agent applied the delete option that stops orphaning it
+	var mw workv1.ManifestWork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+	assert.Equal(t, workv1.DeletePropagationPolicyTypeSelectivelyOrphan, mw.Spec.DeleteOption.PropagationPolicy)
+	setWorkApplied(&mw)
+	assert.Nil(t, client.Update(ctx, &mw), "the manifestwork status is updated")
+
+	_, err = hdr.Reconcile(context.Background(), ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
 	deleted := map[kindAndKey]bool{
 		kindAndKey{
 			GroupVersionKind: schema.GroupVersionKind{
@@ -1661,6 +1724,7 @@ func TestDeleteManifestworkWaitCleanUp(t *testing.T) {
 	defer client.Delete(ctx, testHD)
 
 	mw, _ := scaffoldManifestwork(testHD)
+	ensureTaregetNamespace(testHD, &mw.Spec.Workload.Manifests)
 	client.Create(ctx, mw)
 	defer client.Delete(ctx, mw)
 
@@ -1687,3 +1751,1503 @@ func TestDeleteManifestworkWaitCleanUp(t *testing.T) {
 	err = client.Get(ctx, types.NamespacedName{Name: mw.Name, Namespace: mw.Namespace}, mw)
 	assert.True(t, apierrors.IsNotFound(err), "true when ManifestWork is removed")
 }
+
+func TestPlatformDNSPropagation(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	getSecretInPayload := func(payload []workv1.Manifest, name string) *corev1.Secret {
+		s, err := getManifestPayloadSecretByName(&payload, name)
+		assert.Nil(t, err, "err nil when the payload is readable")
+		return s
+	}
+
+	// AWS Route53
+	awsHD := getHDforManifestWork()
+	awsHD.Spec.HostedClusterSpec.DNS = hyp.DNSSpec{
+		BaseDomain:    "route53.example.com",
+		PublicZoneID:  "Z0PUBLIC",
+		PrivateZoneID: "Z0PRIVATE",
+	}
+	awsHD.Spec.Credentials.AWS.KubeCloudControllerARN = "arn:aws:iam::123456789012:role/test1-cloud-controller"
+
+	pullSecret := getPullSecret(awsHD)
+	client.Create(ctx, pullSecret)
+	defer client.Delete(ctx, pullSecret)
+
+	payload := []workv1.Manifest{}
+	assert.Nil(t, hdr.appendHostedCluster(ctx)(awsHD, &payload), "err nil when the hostedcluster is scaffolded")
+	assert.Nil(t, hdr.appendHostedClusterReferenceSecrets(ctx, &corev1.Secret{})(awsHD, &payload), "err nil when the secrets are scaffolded")
+
+	hc := getHostedClusterInManifestPayload(&payload)
+	assert.Equal(t, awsHD.Spec.HostedClusterSpec.DNS, hc.Spec.DNS, "route53 hosted zones are propagated")
+
+	cloudCtrl := getSecretInPayload(payload, hc.Spec.Platform.AWS.KubeCloudControllerCreds.Name)
+	assert.NotNil(t, cloudCtrl, "aws cloud controller credentials are shipped")
+	assert.Equal(t, helper.GetHostingNamespace(awsHD), cloudCtrl.Namespace)
+	assert.Contains(t, string(cloudCtrl.Data["credentials"]), awsHD.Spec.Credentials.AWS.KubeCloudControllerARN)
+
+	// Azure DNS
+	azureHD := getHypershiftDeployment("default", "test1", false)
+	azureHD.Spec.InfraID = "test1-abcde"
+	azureHD.Spec.Infrastructure.Platform = &hyd.Platforms{Azure: &hyd.AzurePlatform{}}
+	ScaffoldAzureHostedClusterSpec(azureHD, getAzureInfrastructureOut())
+	ScaffoldAzureNodePoolSpec(azureHD, getAzureInfrastructureOut())
+
+	payload = []workv1.Manifest{}
+	assert.Nil(t, hdr.appendHostedCluster(ctx)(azureHD, &payload), "err nil when the hostedcluster is scaffolded")
+	assert.Nil(t, hdr.appendHostedClusterReferenceSecrets(ctx, getProviderSecret())(azureHD, &payload), "err nil when the secrets are scaffolded")
+
+	hc = getHostedClusterInManifestPayload(&payload)
+	assert.Equal(t, azureHD.Spec.HostedClusterSpec.DNS, hc.Spec.DNS, "azure dns zones are propagated")
+	assert.NotEmpty(t, hc.Spec.DNS.PublicZoneID)
+	assert.NotEmpty(t, hc.Spec.DNS.PrivateZoneID)
+
+	azureCreds := getSecretInPayload(payload, hc.Spec.Platform.Azure.Credentials.Name)
+	assert.NotNil(t, azureCreds, "azure credentials are shipped")
+	assert.Equal(t, helper.GetHostingNamespace(azureHD), azureCreds.Namespace)
+	assert.Equal(t, "abcdef123456", string(azureCreds.Data["AZURE_CLIENT_SECRET"]))
+
+	// the azure credentials are required
+	badProvider := getProviderSecret()
+	badProvider.Data["osServicePrincipal.json"] = []byte("not-json")
+	payload = []workv1.Manifest{}
+	assert.Nil(t, hdr.appendHostedCluster(ctx)(azureHD, &payload), "err nil when the hostedcluster is scaffolded")
+	assert.NotNil(t, hdr.appendHostedClusterReferenceSecrets(ctx, badProvider)(azureHD, &payload), "err when the azure credentials can not be read")
+}
+
+// conflictingClient fails the next conflicts updates of the manifestworks with a conflict
+type conflictingClient struct {
+	client.Client
+	conflicts int
+	updates   int
+}
+
+func (c *conflictingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if _, ok := obj.(*workv1.ManifestWork); ok {
+		c.updates++
+		if c.conflicts > 0 {
+			c.conflicts--
+			return apierrors.NewConflict(workv1.Resource("manifestworks"), obj.GetName(), errors.New("the object has been modified"))
+		}
+	}
+
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func TestManifestWorkConflictRetries(t *testing.T) {
+	ctx := context.Background()
+	fakeClient := &conflictingClient{Client: initClient()}
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+
+	fakeClient.Create(ctx, testHD)
+	defer fakeClient.Delete(ctx, testHD)
+
+	fakeClient.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: fakeClient,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var resultHD hyd.HypershiftDeployment
+	replicas := int32(2)
+	scaleNodePool := func() {
+		assert.Nil(t, fakeClient.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+		replicas++
+		resultHD.Spec.NodePools[0].Spec.Replicas = &replicas
+		assert.Nil(t, fakeClient.Update(ctx, &resultHD), "is nil when the HypershiftDeployment is updated")
+	}
+	appliedReplicas := func() int64 {
+		var mw workv1.ManifestWork
+		assert.Nil(t, fakeClient.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+		np := getNodePoolInManifestWork(t, &mw, testHD.Spec.NodePools[0].Name)
+		npReplicas, _, _ := unstructured.NestedInt64(np.Object, "spec", "replicas")
+		return npReplicas
+	}
+
+	// without retries the conflict is returned
+	scaleNodePool()
+	fakeClient.conflicts, fakeClient.updates = 1, 0
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.True(t, apierrors.IsConflict(err), "conflict is returned without retries")
+	assert.Equal(t, 1, fakeClient.updates, "the update is not retried")
+
+	assert.Nil(t, fakeClient.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.Nil(t, meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.ManifestWorkConflict)), "no condition without retries")
+
+	// the conflicts stop before the retries run out
+	hdr.ManifestWorkConflictRetries = 3
+	fakeClient.conflicts, fakeClient.updates = 2, 0
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when a retry succeeded")
+	assert.Equal(t, 3, fakeClient.updates, "the update is retried until it succeeds")
+	assert.Equal(t, int64(replicas), appliedReplicas(), "the manifestwork is updated")
+
+	// the conflicts outlast the retries
+	scaleNodePool()
+	fakeClient.conflicts, fakeClient.updates = 10, 0
+	res, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when the conflict is reported")
+	assert.True(t, res.Requeue, "requeued when the retries ran out")
+	assert.Equal(t, 4, fakeClient.updates, "the update is retried a bounded number of times")
+	assert.Equal(t, int64(replicas-1), appliedReplicas(), "the manifestwork is not updated")
+
+	assert.Nil(t, fakeClient.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	c := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.ManifestWorkConflict))
+	assert.NotNil(t, c, "ManifestWorkConflict condition is set")
+	assert.Equal(t, metav1.ConditionTrue, c.Status, "the manifestwork conflicts")
+	assert.Equal(t, hyd.UpdateConflictReason, c.Reason)
+	assert.Contains(t, c.Message, "3 retries")
+
+	// the next reconcile resolves the condition
+	fakeClient.conflicts = 0
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Equal(t, int64(replicas), appliedReplicas(), "the manifestwork is updated")
+
+	assert.Nil(t, fakeClient.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.False(t, meta.IsStatusConditionTrue(resultHD.Status.Conditions, string(hyd.ManifestWorkConflict)), "conflict is resolved")
+}
+
+// racingClient creates the manifestwork of another HypershiftDeployment right after the first manifestwork read
+type racingClient struct {
+	client.Client
+	racer *workv1.ManifestWork
+}
+
+func (c *racingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	err := c.Client.Get(ctx, key, obj)
+	if _, ok := obj.(*workv1.ManifestWork); ok && c.racer != nil && apierrors.IsNotFound(err) {
+		racer := c.racer
+		c.racer = nil
+		if err := c.Client.Create(ctx, racer); err != nil {
+			return err
+		}
+	}
+
+	return err
+}
+
+func TestManifestWorkOwnedByAnotherHypershiftDeployment(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	ownerHD := getHDforManifestWork()
+	ownerHD.Spec.HostingCluster = "local-cluster"
+
+	client.Create(ctx, ownerHD)
+	defer client.Delete(ctx, ownerHD)
+
+	client.Create(ctx, getPullSecret(ownerHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var ownerMW workv1.ManifestWork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(ownerHD), &ownerMW), "the manifestwork is created")
+
+	// same name and infra-id in another namespace
+	copyHD := getHDforManifestWork()
+	copyHD.Namespace = "other"
+	copyHD.Spec.HostingCluster = "local-cluster"
+	replicas := int32(5)
+	copyHD.Spec.NodePools[0].Spec.Replicas = &replicas
+	copyKey := types.NamespacedName{Namespace: copyHD.Namespace, Name: copyHD.Name}
+
+	client.Create(ctx, copyHD)
+
+	pullSecret := getPullSecret(copyHD)
+	pullSecret.Namespace = copyHD.Namespace
+	client.Create(ctx, pullSecret)
+
+	res, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: copyKey})
+	assert.Nil(t, err, "err nil when the conflict is reported")
+	assert.Equal(t, time.Minute, res.RequeueAfter, "requeued to notice the manifestwork is removed")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, copyKey, &resultHD), "is nil when HypershiftDeployment resource is found")
+	c := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.Conflict))
+	if assert.NotNil(t, c, "Conflict condition is set") {
+		assert.Equal(t, metav1.ConditionTrue, c.Status, "the manifestwork belongs to another HypershiftDeployment")
+		assert.Equal(t, hyd.ForeignOwnerReason, c.Reason)
+		assert.Contains(t, c.Message, "default/test1")
+	}
+
+	var mw workv1.ManifestWork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(ownerHD), &mw), "the manifestwork is found")
+	assert.Equal(t, ownerMW.ResourceVersion, mw.ResourceVersion, "the manifestwork is not overwritten")
+	assert.Equal(t, "default/test1", mw.Annotations[constant.CreatedByHypershiftDeployment], "the manifestwork keeps its owner")
+
+	// the deletion of the copy leaves the manifestwork of the owner
+	assert.Nil(t, client.Delete(ctx, copyHD), "is nil when the HypershiftDeployment is deleted")
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: copyKey})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.True(t, apierrors.IsNotFound(client.Get(ctx, copyKey, &resultHD)), "the copy is removed")
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(ownerHD), &mw), "the manifestwork of the owner is not deleted")
+
+	// the owner still updates its manifestwork
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.False(t, meta.IsStatusConditionTrue(resultHD.Status.Conditions, string(hyd.Conflict)), "no conflict for the owner")
+}
+
+func TestManifestWorkCreatedByAnotherHypershiftDeploymentConcurrently(t *testing.T) {
+	ctx := context.Background()
+
+	copyHD := getHDforManifestWork()
+	copyHD.Namespace = "other"
+	copyHD.Spec.HostingCluster = "local-cluster"
+	copyKey := types.NamespacedName{Namespace: copyHD.Namespace, Name: copyHD.Name}
+
+	ownerHD := getHDforManifestWork()
+	ownerHD.Spec.HostingCluster = "local-cluster"
+	racer, err := scaffoldManifestwork(ownerHD)
+	assert.Nil(t, err, "err nil when the manifestwork is scaffolded")
+
+	fakeClient := &racingClient{Client: initClient(), racer: racer}
+	fakeClient.Create(ctx, copyHD)
+
+	pullSecret := getPullSecret(copyHD)
+	pullSecret.Namespace = copyHD.Namespace
+	fakeClient.Create(ctx, pullSecret)
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: fakeClient,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: copyKey})
+	assert.Nil(t, err, "err nil when the conflict is reported")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, fakeClient.Get(ctx, copyKey, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.True(t, meta.IsStatusConditionTrue(resultHD.Status.Conditions, string(hyd.Conflict)), "the manifestwork created meanwhile is reported")
+
+	var mw workv1.ManifestWork
+	assert.Nil(t, fakeClient.Get(ctx, getManifestWorkKey(ownerHD), &mw), "the manifestwork is found")
+	assert.Equal(t, "default/test1", mw.Annotations[constant.CreatedByHypershiftDeployment], "the manifestwork keeps its owner")
+	assert.Empty(t, mw.Spec.Workload.Manifests, "the payload of the copy is not written")
+}
+
+func TestManifestWorkGracePeriod(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.CreationTimestamp = metav1.NewTime(time.Now().Add(-2 * time.Minute))
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client:                  client,
+		Log:                     ctrl.Log.WithName("tester"),
+		ManifestWorkGracePeriod: 5 * time.Minute,
+	}
+
+	res, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.True(t, res.RequeueAfter > 2*time.Minute && res.RequeueAfter <= 3*time.Minute, "requeue when the grace period elapses, got %s", res.RequeueAfter)
+
+	mw := &workv1.ManifestWork{}
+	assert.True(t, apierrors.IsNotFound(client.Get(ctx, getManifestWorkKey(testHD), mw)), "manifestwork is not created during the grace period")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	c := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.WorkConfigured))
+	assert.Equal(t, hyd.BeingConfiguredReason, c.Reason, "waiting for the grace period")
+
+	// the grace period has elapsed
+	hdr.ManifestWorkGracePeriod = time.Minute
+
+	res, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Zero(t, res.RequeueAfter, "no requeue once the grace period elapsed")
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), mw), "manifestwork is created after the grace period")
+
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.True(t, meta.IsStatusConditionTrue(resultHD.Status.Conditions, string(hyd.WorkConfigured)), "the manifestwork is configured")
+
+	// the grace period only applies to the first creation
+	hdr.ManifestWorkGracePeriod = time.Hour
+
+	res, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Zero(t, res.RequeueAfter, "existing manifestwork is not delayed")
+}
+
+func getHostedClusterTolerationsInPayload(t *testing.T, payload []workv1.Manifest) []corev1.Toleration {
+	for _, m := range payload {
+		u, ok := m.Object.(*unstructured.Unstructured)
+		if !ok || u.GetKind() != "HostedCluster" {
+			continue
+		}
+
+		raw, _, err := unstructured.NestedSlice(u.Object, "spec", "tolerations")
+		assert.Nil(t, err, "err nil when spec.tolerations is readable")
+
+		b, err := json.Marshal(raw)
+		assert.Nil(t, err, "err nil when spec.tolerations is marshalled")
+
+		tolerations := []corev1.Toleration{}
+		assert.Nil(t, json.Unmarshal(b, &tolerations), "err nil when spec.tolerations are tolerations")
+		return tolerations
+	}
+
+	return nil
+}
+
+func TestControlPlaneTolerationsScaffolding(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	seconds := int64(300)
+	tolerations := []corev1.Toleration{
+		{Key: "node-role.kubernetes.io/infra", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+		{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "hosted-control-planes", Effect: corev1.TaintEffectNoExecute, TolerationSeconds: &seconds},
+	}
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.ControlPlaneTolerations = tolerations
+
+	payload := []workv1.Manifest{}
+	assert.Nil(t, hdr.appendHostedCluster(ctx)(testHD, &payload), "err nil when the hostedcluster is scaffolded")
+	assert.Equal(t, tolerations, getHostedClusterTolerationsInPayload(t, payload), "tolerations survive the scaffolding")
+
+	payload = []workv1.Manifest{}
+	assert.Nil(t, hdr.appendHostedCluster(ctx)(getHDforManifestWork(), &payload), "err nil when the hostedcluster is scaffolded")
+	assert.Empty(t, getHostedClusterTolerationsInPayload(t, payload), "no tolerations are added when none are set")
+
+	// tolerations of a referenced HostedCluster are kept and not duplicated
+	hc := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{}}}
+	existing := corev1.Toleration{Key: "existing", Operator: corev1.TolerationOpExists}
+	assert.Nil(t, appendControlPlaneTolerations(hc, []corev1.Toleration{existing}))
+	assert.Nil(t, appendControlPlaneTolerations(hc, append([]corev1.Toleration{existing}, tolerations...)))
+
+	found, _, err := unstructured.NestedSlice(hc.Object, "spec", "tolerations")
+	assert.Nil(t, err, "err nil when spec.tolerations is readable")
+	assert.Len(t, found, 3, "existing tolerations are kept once")
+}
+
+func TestClusterIDOverride(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	// the generated cluster id is kept when there is no override
+	testHD := getHDforManifestWork()
+	generated := testHD.Spec.HostedClusterSpec.ClusterID
+	assert.NotEmpty(t, generated, "a cluster id is generated")
+
+	payload := []workv1.Manifest{}
+	assert.Nil(t, hdr.appendHostedCluster(ctx)(testHD, &payload), "err nil when the hostedcluster is scaffolded")
+	assert.Equal(t, generated, getHostedClusterInManifestPayload(&payload).Spec.ClusterID, "the generated cluster id is propagated")
+
+	// the override wins over the generated cluster id
+	override := "7b2c1c1e-6d5f-4b8a-9d3e-2f1a0c9b8e7d"
+	testHD.Spec.ClusterID = override
+
+	payload = []workv1.Manifest{}
+	assert.Nil(t, hdr.appendHostedCluster(ctx)(testHD, &payload), "err nil when the hostedcluster is scaffolded")
+	assert.Equal(t, override, getHostedClusterInManifestPayload(&payload).Spec.ClusterID, "the cluster id override is propagated")
+
+	// no cluster id is generated when there is an override
+	testHD.Spec.HostedClusterSpec.ClusterID = ""
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+	assert.Nil(t, hdr.setDefaultValueForHostedCluster(ctx, testHD), "err nil when the defaults are set")
+	assert.Empty(t, testHD.Spec.HostedClusterSpec.ClusterID, "the cluster id is not generated when it is overridden")
+}
+
+// applyClient records the server side apply patches, the fake client does not support them so the
+// patched object is created or updated instead
+type applyClient struct {
+	client.Client
+	applied int
+}
+
+func (c *applyClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		return c.Client.Patch(ctx, obj, patch, opts...)
+	}
+
+	c.applied++
+
+	existing := obj.DeepCopyObject().(client.Object)
+	if err := c.Client.Get(ctx, client.ObjectKeyFromObject(obj), existing); err != nil {
+		return c.Client.Create(ctx, obj)
+	}
+
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	return c.Client.Update(ctx, obj)
+}
+
+func TestManifestWorkServerSideApplyFeatureGate(t *testing.T) {
+	ctx := context.Background()
+
+	cases := []struct {
+		name    string
+		gates   string
+		applied int
+	}{
+		{"feature off", "", 0},
+		{"feature on", "ManifestWorkServerSideApply=true", 2},
+	}
+
+	for _, c := range cases {
+		testHD := getHDforManifestWork()
+		testHD.Spec.HostingCluster = "local-cluster"
+
+		cl := &applyClient{Client: initClient()}
+		assert.Nil(t, cl.Create(ctx, testHD), "is nil when the HypershiftDeployment is created")
+		assert.Nil(t, cl.Create(ctx, getPullSecret(testHD)), "is nil when the pull secret is created")
+
+		gate := features.NewFeatureGate()
+		assert.Nil(t, gate.Set(c.gates), c.name)
+
+		hdr := &HypershiftDeploymentReconciler{
+			Client:      cl,
+			Log:         ctrl.Log.WithName("tester"),
+			FeatureGate: gate,
+		}
+
+		for i := 0; i < 2; i++ {
+			_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+			assert.Nil(t, err, "err nil when reconcile was successful")
+		}
+
+		assert.Equal(t, c.applied, cl.applied, c.name)
+
+		var mw workv1.ManifestWork
+		assert.Nil(t, cl.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found, %s", c.name)
+		assert.NotEmpty(t, mw.Spec.Workload.Manifests, "manifestwork has the payload, %s", c.name)
+		assert.NotNil(t, getNodePoolInManifestWork(t, &mw, testHD.Spec.NodePools[0].Name), "NodePool is in the manifestwork, %s", c.name)
+	}
+}
+
+func TestAWSCloudProviderConfigPropagation(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	subnetID := "subnet-custom"
+	testHD := getHypershiftDeployment("default", "test1", false)
+	testHD.Spec.Infrastructure.Platform = &hyd.Platforms{AWS: &hyd.AWSPlatform{Region: "us-east-1"}}
+	testHD.Spec.Credentials = &hyd.CredentialARNs{AWS: &hyd.AWSCredentials{}}
+	testHD.Spec.HostedClusterSpec = &hyp.HostedClusterSpec{
+		Release: hyp.Release{Image: constant.ReleaseImage},
+		Platform: hyp.PlatformSpec{
+			AWS: &hyp.AWSPlatformSpec{
+				CloudProviderConfig: &hyp.AWSCloudProviderConfig{
+					Subnet: &hyp.AWSResourceReference{ID: &subnetID},
+					VPC:    "vpc-custom",
+				},
+			},
+		},
+	}
+
+	infraOut := getAWSInfrastructureOut()
+	testHD.Spec.InfraID = infraOut.InfraID
+	ScaffoldAWSHostedClusterSpec(testHD, infraOut)
+	ScaffoldAWSNodePoolSpec(testHD, infraOut)
+	testHD.Spec.HostingCluster = "local-cluster"
+
+	cpc := testHD.Spec.HostedClusterSpec.Platform.AWS.CloudProviderConfig
+	assert.Equal(t, "vpc-custom", cpc.VPC, "supplied VPC is kept")
+	assert.Equal(t, subnetID, *cpc.Subnet.ID, "supplied subnet is kept")
+	assert.Equal(t, infraOut.Zones[0].Name, cpc.Zone, "missing zone is scaffolded")
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var mw workv1.ManifestWork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+
+	found := false
+	for _, m := range mw.Spec.Workload.Manifests {
+		u, err := manifestToUnstructured(m)
+		assert.Nil(t, err, "err nil when the manifest is readable")
+		if u.GetKind() != "HostedCluster" {
+			continue
+		}
+
+		found = true
+		vpc, _, _ := unstructured.NestedString(u.Object, "spec", "platform", "aws", "cloudProviderConfig", "vpc")
+		assert.Equal(t, "vpc-custom", vpc, "VPC is propagated to the HostedCluster")
+		subnet, _, _ := unstructured.NestedString(u.Object, "spec", "platform", "aws", "cloudProviderConfig", "subnet", "id")
+		assert.Equal(t, subnetID, subnet, "subnet is propagated to the HostedCluster")
+		zone, _, _ := unstructured.NestedString(u.Object, "spec", "platform", "aws", "cloudProviderConfig", "zone")
+		assert.Equal(t, infraOut.Zones[0].Name, zone, "zone is propagated to the HostedCluster")
+	}
+	assert.True(t, found, "HostedCluster is in the manifestwork")
+}
+
+// accessReviewClient answers the SelfSubjectAccessReviews, the verbs in denied are not allowed
+type accessReviewClient struct {
+	client.Client
+	denied  map[string]bool
+	reviews []authorizationv1.ResourceAttributes
+}
+
+func (c *accessReviewClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	ssar, ok := obj.(*authorizationv1.SelfSubjectAccessReview)
+	if !ok {
+		return c.Client.Create(ctx, obj, opts...)
+	}
+
+	attrs := ssar.Spec.ResourceAttributes
+	c.reviews = append(c.reviews, *attrs)
+	ssar.Status.Allowed = !c.denied[attrs.Verb]
+	if !ssar.Status.Allowed {
+		ssar.Status.Reason = "no RBAC policy matched"
+	}
+
+	return nil
+}
+
+func TestManifestWorkPermissionsDenied(t *testing.T) {
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+
+	cl := &accessReviewClient{Client: initClient(), denied: map[string]bool{"create": true}}
+	assert.Nil(t, cl.Create(ctx, testHD), "is nil when the HypershiftDeployment is created")
+	assert.Nil(t, cl.Create(ctx, getPullSecret(testHD)), "is nil when the pull secret is created")
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client:              cl,
+		Log:                 ctrl.Log.WithName("tester"),
+		ValidatePermissions: true,
+	}
+
+	res, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Equal(t, time.Minute, res.RequeueAfter, "retried once the permissions are granted")
+
+	assert.Len(t, cl.reviews, 1, "permissions are reviewed before the manifestwork is created")
+	assert.Equal(t, "local-cluster", cl.reviews[0].Namespace)
+	assert.Equal(t, workv1.GroupName, cl.reviews[0].Group)
+	assert.Equal(t, "manifestworks", cl.reviews[0].Resource)
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, cl.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+
+	c := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.InsufficientPermissions))
+	assert.NotNil(t, c, "InsufficientPermissions condition is reported")
+	assert.Equal(t, metav1.ConditionTrue, c.Status, "true when the create is denied")
+	assert.Equal(t, hyd.PermissionDeniedReason, c.Reason)
+	assert.Equal(t, "not allowed to create manifestworks.work.open-cluster-management.io in namespace local-cluster: no RBAC policy matched", c.Message)
+
+	var mw workv1.ManifestWork
+	assert.NotNil(t, cl.Get(ctx, getManifestWorkKey(testHD), &mw), "the manifestwork is not created")
+
+	// the permission is granted
+	cl.denied = map[string]bool{}
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	assert.Nil(t, cl.Get(ctx, getManifestWorkKey(testHD), &mw), "the manifestwork is created")
+
+	assert.Nil(t, cl.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	c = meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.InsufficientPermissions))
+	assert.Equal(t, metav1.ConditionFalse, c.Status, "false once the create is allowed")
+
+	// an existing manifestwork is reviewed for update
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Equal(t, "update", cl.reviews[len(cl.reviews)-1].Verb)
+}
+
+// supersedingClient changes the HypershiftDeployment spec the first time the manifestwork is read, like a user
+// editing the HypershiftDeployment while it is being reconciled
+type supersedingClient struct {
+	client.Client
+	hd        types.NamespacedName
+	supersede bool
+}
+
+func (c *supersedingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if _, ok := obj.(*workv1.ManifestWork); ok && c.supersede {
+		c.supersede = false
+
+		current := &hyd.HypershiftDeployment{}
+		if err := c.Client.Get(ctx, c.hd, current); err != nil {
+			return err
+		}
+
+		current.Generation++
+		current.Spec.Override = hyd.DeleteHostingNamespace
+		if err := c.Client.Update(ctx, current); err != nil {
+			return err
+		}
+	}
+
+	return c.Client.Get(ctx, key, obj)
+}
+
+func TestManifestWorkNotWrittenForSupersededSpec(t *testing.T) {
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Generation = 1
+
+	c := &supersedingClient{Client: initClient(), hd: getNN, supersede: true}
+	c.Create(ctx, testHD)
+	defer c.Delete(ctx, testHD)
+
+	c.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: c,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	res, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.True(t, res.Requeue, "requeued when the spec changed during the reconcile")
+
+	var mw workv1.ManifestWork
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, getManifestWorkKey(testHD), &mw)), "manifestwork is not written from the superseded spec")
+
+	// the next reconcile works on the current spec
+	res, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.False(t, res.Requeue, "not requeued when the spec did not change")
+	assert.Nil(t, c.Get(ctx, getManifestWorkKey(testHD), &mw), "manifestwork is created")
+}
+
+func TestManifestWorkNotDeletedForSupersededSpec(t *testing.T) {
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Generation = 1
+
+	c := &supersedingClient{Client: initClient(), hd: getNN}
+	c.Create(ctx, testHD)
+	defer c.Delete(ctx, testHD)
+
+	m, err := scaffoldManifestwork(testHD)
+	assert.Nil(t, err, "err nil when the manifestwork is scaffolded")
+	assert.Nil(t, c.Create(ctx, m), "is nil when the manifestwork is created")
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: c,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	c.supersede = true
+	res, err := hdr.deleteManifestworkWaitCleanUp(ctx, testHD)
+	assert.Nil(t, err, "err nil when the deletion is aborted")
+	assert.True(t, res.Requeue, "requeued when the spec changed during the deletion")
+
+	var mw workv1.ManifestWork
+	assert.Nil(t, c.Get(ctx, getManifestWorkKey(testHD), &mw), "manifestwork is not deleted")
+	assert.Equal(t, workv1.DeletePropagationPolicyTypeOrphan, mw.Spec.DeleteOption.PropagationPolicy, "delete option of the superseded spec is not set")
+}
+
+func TestImageRegistryManagementState(t *testing.T) {
+	ctx := context.Background()
+
+	cases := []struct {
+		name       string
+		registry   *hyd.ImageRegistry
+		annotation string
+		disabled   metav1.ConditionStatus
+		reason     string
+	}{
+		{"not configured", nil, "", metav1.ConditionFalse, hyd.ImageRegistryManagedReason},
+		{"enabled", &hyd.ImageRegistry{ManagementState: hyd.ImageRegistryManaged}, "Managed", metav1.ConditionFalse, hyd.ImageRegistryManagedReason},
+		{"disabled", &hyd.ImageRegistry{ManagementState: hyd.ImageRegistryRemoved}, "Removed", metav1.ConditionTrue, hyd.ImageRegistryRemovedReason},
+	}
+
+	for _, c := range cases {
+		client := initClient()
+
+		testHD := getHDforManifestWork()
+		testHD.Spec.HostingCluster = "local-cluster"
+		testHD.Spec.ImageRegistry = c.registry
+
+		client.Create(ctx, testHD)
+		client.Create(ctx, getPullSecret(testHD))
+
+		hdr := &HypershiftDeploymentReconciler{
+			Client: client,
+			Log:    ctrl.Log.WithName("tester"),
+		}
+
+		_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+		assert.Nil(t, err, "err nil when reconcile was successful", c.name)
+
+		var mw workv1.ManifestWork
+		assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found", c.name)
+
+		annotation := "not found"
+		for _, m := range mw.Spec.Workload.Manifests {
+			u, err := manifestToUnstructured(m)
+			assert.Nil(t, err, "err nil when the manifest is readable", c.name)
+			if u.GetKind() == "HostedCluster" {
+				annotation = u.GetAnnotations()[constant.ImageRegistryManagementStateAnnotation]
+			}
+		}
+		assert.Equal(t, c.annotation, annotation, c.name)
+
+		var resultHD hyd.HypershiftDeployment
+		assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found", c.name)
+		cond := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.ImageRegistryDisabled))
+		assert.NotNil(t, cond, "ImageRegistryDisabled condition is set", c.name)
+		assert.Equal(t, c.disabled, cond.Status, c.name)
+		assert.Equal(t, c.reason, cond.Reason, c.name)
+	}
+}
+
+func TestDeleteManifestWorkWithoutPayload(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Spec.Override = hyd.DeleteHostingNamespace
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	m, err := scaffoldManifestwork(testHD)
+	assert.Nil(t, err, "err nil when the manifestwork is scaffolded")
+	assert.Nil(t, client.Create(ctx, m), "is nil when the manifestwork is created")
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	// a foreground deletion would wait for the work agent to report the manifestwork available
+	_, err = hdr.deleteManifestworkWaitCleanUp(ctx, testHD)
+	assert.Nil(t, err, "err nil when the manifestwork is deleted")
+
+	var mw workv1.ManifestWork
+	assert.True(t, apierrors.IsNotFound(client.Get(ctx, getManifestWorkKey(testHD), &mw)), "manifestwork without payload is deleted right away")
+}
+
+func getNonePlatformHD() *hyd.HypershiftDeployment {
+	testHD := getHypershiftDeployment("default", "test1", false)
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Spec.InfraID = "test1-abcde"
+	testHD.Spec.HostedClusterSpec = &hyp.HostedClusterSpec{
+		InfraID:    testHD.Spec.InfraID,
+		Platform:   hyp.PlatformSpec{Type: hyp.NonePlatform},
+		PullSecret: corev1.LocalObjectReference{Name: testHD.Name + "-pull-secret"},
+		Release:    hyp.Release{Image: "quay.io/openshift-release-dev/ocp-release:4.10.15-x86_64"},
+		Networking: hyp.ClusterNetworking{
+			ServiceCIDR: "172.31.0.0/16",
+			PodCIDR:     "10.132.0.0/14",
+			NetworkType: hyp.OVNKubernetes,
+		},
+		Services: []hyp.ServicePublishingStrategyMapping{spsMap(hyp.APIServer, hyp.NodePort)},
+	}
+
+	replicas := int32(2)
+	testHD.Spec.NodePools = []*hyd.HypershiftNodePools{{
+		Name: "test1-np",
+		Spec: hyp.NodePoolSpec{
+			ClusterName: testHD.Name,
+			Replicas:    &replicas,
+			Platform:    hyp.NodePoolPlatform{Type: hyp.NonePlatform},
+			Release:     hyp.Release{Image: "quay.io/openshift-release-dev/ocp-release:4.10.15-x86_64"},
+		},
+	}}
+
+	return testHD
+}
+
+func TestNonePlatformManifestWork(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getNonePlatformHD()
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.True(t, meta.IsStatusConditionTrue(resultHD.Status.Conditions, string(hyd.WorkConfigured)), "manifestwork is configured")
+
+	var mw workv1.ManifestWork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+
+	kinds := payloadKinds(t, mw.Spec.Workload.Manifests)
+	assert.Len(t, kinds["HostedCluster"], 1)
+	assert.Len(t, kinds["NodePool"], 1)
+	assert.Len(t, kinds["Secret"], 1, "only the pull secret is propagated, there is no cloud credential")
+	assert.Equal(t, testHD.Name+"-pull-secret", kinds["Secret"][0].GetName())
+
+	platform, _, _ := unstructured.NestedMap(kinds["HostedCluster"][0].Object, "spec", "platform")
+	assert.Equal(t, map[string]interface{}{"type": "None"}, platform, "no cloud platform is injected in the HostedCluster")
+	services, _, _ := unstructured.NestedSlice(kinds["HostedCluster"][0].Object, "spec", "services")
+	assert.Len(t, services, 1, "no cloud publishing strategy is injected")
+	_, found, _ := unstructured.NestedFieldNoCopy(kinds["HostedCluster"][0].Object, "spec", "secretEncryption")
+	assert.False(t, found, "no encryption key is generated")
+
+	npPlatform, _, _ := unstructured.NestedMap(kinds["NodePool"][0].Object, "spec", "platform")
+	assert.Equal(t, map[string]interface{}{"type": "None"}, npPlatform, "no cloud platform is injected in the NodePool")
+}
+
+func TestNonePlatformMisconfigured(t *testing.T) {
+	ctx := context.Background()
+
+	cases := []struct {
+		name    string
+		update  func(*hyd.HypershiftDeployment)
+		message string
+	}{
+		{
+			name: "cloud platform section",
+			update: func(testHD *hyd.HypershiftDeployment) {
+				testHD.Spec.HostedClusterSpec.Platform.AWS = &hyp.AWSPlatformSpec{Region: "us-east-1"}
+			},
+			message: "can not declare the AWS platform",
+		},
+		{
+			name: "infrastructure configured",
+			update: func(testHD *hyd.HypershiftDeployment) {
+				testHD.Spec.Infrastructure.Configure = true
+				testHD.Spec.Infrastructure.CloudProvider.Name = "providersecret"
+				testHD.Spec.Infrastructure.Platform = &hyd.Platforms{AWS: &hyd.AWSPlatform{Region: "us-east-1"}}
+			},
+			message: "Infrastructure.Configure must be false",
+		},
+	}
+
+	for _, c := range cases {
+		client := initClient()
+
+		testHD := getNonePlatformHD()
+		c.update(testHD)
+		client.Create(ctx, testHD)
+		client.Create(ctx, getPullSecret(testHD))
+		client.Create(ctx, getProviderSecret())
+
+		hdr := &HypershiftDeploymentReconciler{
+			Client: client,
+			Log:    ctrl.Log.WithName("tester"),
+		}
+
+		_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+		assert.Nil(t, err, "err nil when the misconfiguration is reported", c.name)
+
+		var resultHD hyd.HypershiftDeployment
+		assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found", c.name)
+		cond := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.WorkConfigured))
+		assert.NotNil(t, cond, "WorkConfigured condition is set", c.name)
+		assert.Equal(t, metav1.ConditionFalse, cond.Status, c.name)
+		assert.Equal(t, hyd.MisConfiguredReason, cond.Reason, c.name)
+		assert.Contains(t, cond.Message, c.message, c.name)
+		assert.Equal(t, hyp.NonePlatform, resultHD.Spec.HostedClusterSpec.Platform.Type, "platform is not changed", c.name)
+
+		var mw workv1.ManifestWork
+		assert.NotNil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "manifestwork is not created", c.name)
+	}
+}
+
+func TestManifestAppliedCondition(t *testing.T) {
+	manifestCondition := func(kind, resource, namespace, name string, conds ...metav1.Condition) workv1.ManifestCondition {
+		return workv1.ManifestCondition{
+			ResourceMeta: workv1.ManifestResourceMeta{Kind: kind, Resource: resource, Namespace: namespace, Name: name},
+			Conditions:   conds,
+		}
+	}
+	applied := metav1.Condition{Type: string(workv1.ManifestApplied), Status: metav1.ConditionTrue, Reason: "AppliedManifestComplete"}
+
+	mw := &workv1.ManifestWork{}
+	_, ok := manifestAppliedCondition(mw)
+	assert.False(t, ok, "no condition until the work agent reports the manifests")
+
+	mw.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{
+		manifestCondition("Namespace", "namespaces", "", "clusters", applied),
+		manifestCondition("HostedCluster", HostedClusterResource, "clusters", "test1", applied),
+	}
+	c, ok := manifestAppliedCondition(mw)
+	assert.True(t, ok)
+	assert.Equal(t, metav1.ConditionTrue, c.Status, "true when every manifest is applied")
+
+	mw.Status.ResourceStatus.Manifests = append(mw.Status.ResourceStatus.Manifests,
+		manifestCondition("NodePool", NodePoolResource, "clusters", "test1-np",
+			metav1.Condition{Type: string(workv1.ManifestApplied), Status: metav1.ConditionFalse, Reason: "AppliedManifestFailed",
+				Message: `no matches for kind "NodePool" in version "hypershift.openshift.io/v1alpha1"`}),
+		manifestCondition("Secret", "secrets", "clusters", "test1-pull-secret", applied,
+			metav1.Condition{Type: string(workv1.ManifestDegraded), Status: metav1.ConditionTrue, Reason: "Degraded"}),
+	)
+	c, ok = manifestAppliedCondition(mw)
+	assert.True(t, ok)
+	assert.Equal(t, metav1.ConditionFalse, c.Status, "false when a manifest failed")
+	assert.Equal(t, hyd.ApplyFailedReason, c.Reason)
+	assert.Equal(t, `NodePool clusters/test1-np: no matches for kind "NodePool" in version "hypershift.openshift.io/v1alpha1"; Secret clusters/test1-pull-secret: Degraded`, c.Message)
+
+	// the sync copies the aggregated condition to the HypershiftDeployment
+	testHD := getHDforManifestWork()
+	syncManifestworkStatusToHypershiftDeployment(testHD, mw)
+	hdCond := condmeta.FindStatusCondition(testHD.Status.Conditions, string(hyd.ManifestApplied))
+	assert.NotNil(t, hdCond, "ManifestApplied condition is reported")
+	assert.Equal(t, c.Message, hdCond.Message)
+}
+
+func TestManifestAppliedConditionOnReconcile(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var mw workv1.ManifestWork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+
+	// the HostedCluster CRD is not installed on the hosting cluster
+	mw.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{{
+		ResourceMeta: workv1.ManifestResourceMeta{
+			Group:     hyp.GroupVersion.Group,
+			Kind:      "HostedCluster",
+			Resource:  HostedClusterResource,
+			Namespace: helper.GetHostingNamespace(testHD),
+			Name:      testHD.Name,
+		},
+		Conditions: []metav1.Condition{{
+			Type:    string(workv1.ManifestApplied),
+			Status:  metav1.ConditionFalse,
+			Reason:  "AppliedManifestFailed",
+			Message: `no matches for kind "HostedCluster" in version "hypershift.openshift.io/v1alpha1"`,
+		}},
+	}}
+	assert.Nil(t, client.Update(ctx, &mw), "the manifestwork status is updated")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+
+	c := condmeta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.ManifestApplied))
+	assert.NotNil(t, c, "ManifestApplied condition is reported")
+	assert.Equal(t, metav1.ConditionFalse, c.Status, "false when the HostedCluster is not applied")
+	assert.Equal(t, fmt.Sprintf(`HostedCluster %s/%s: no matches for kind "HostedCluster" in version "hypershift.openshift.io/v1alpha1"`,
+		helper.GetHostingNamespace(testHD), testHD.Name), c.Message)
+}
+
+func TestManifestsReadyCondition(t *testing.T) {
+	manifestCondition := func(kind, name string, applied, available metav1.ConditionStatus) workv1.ManifestCondition {
+		return workv1.ManifestCondition{
+			ResourceMeta: workv1.ManifestResourceMeta{Kind: kind, Namespace: "clusters", Name: name},
+			Conditions: []metav1.Condition{
+				{Type: string(workv1.ManifestApplied), Status: applied, Reason: "AppliedManifestComplete"},
+				{Type: string(workv1.ManifestAvailable), Status: available, Reason: "ResourceAvailable"},
+			},
+		}
+	}
+
+	mw := &workv1.ManifestWork{}
+	mw.Spec.Workload.Manifests = make([]workv1.Manifest, 2)
+	c := manifestsReadyCondition(mw)
+	assert.Equal(t, metav1.ConditionFalse, c.Status, "false until the work agent reports the manifests")
+	assert.Equal(t, "2 of 2 manifests not reported by the work agent", c.Message)
+
+	// all ready
+	mw.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{
+		manifestCondition("HostedCluster", "test1", metav1.ConditionTrue, metav1.ConditionTrue),
+		manifestCondition("NodePool", "test1", metav1.ConditionTrue, metav1.ConditionTrue),
+	}
+	c = manifestsReadyCondition(mw)
+	assert.Equal(t, metav1.ConditionTrue, c.Status, "true when every manifest is applied and available")
+	assert.Equal(t, hyd.AsExpectedReason, c.Reason)
+
+	// partially applied
+	mw.Status.ResourceStatus.Manifests[1] = manifestCondition("NodePool", "test1", metav1.ConditionFalse, metav1.ConditionFalse)
+	c = manifestsReadyCondition(mw)
+	assert.Equal(t, metav1.ConditionFalse, c.Status, "false when a manifest is not applied")
+	assert.Equal(t, hyd.ManifestsNotReadyReason, c.Reason)
+	assert.Equal(t, "NodePool clusters/test1: not applied and not available", c.Message)
+
+	// partially available
+	mw.Status.ResourceStatus.Manifests[1] = manifestCondition("NodePool", "test1", metav1.ConditionTrue, metav1.ConditionUnknown)
+	c = manifestsReadyCondition(mw)
+	assert.Equal(t, metav1.ConditionFalse, c.Status, "false when a manifest is not available")
+	assert.Equal(t, "NodePool clusters/test1: not available", c.Message)
+
+	// a manifest the work agent has not reported yet
+	mw.Status.ResourceStatus.Manifests = mw.Status.ResourceStatus.Manifests[:1]
+	c = manifestsReadyCondition(mw)
+	assert.Equal(t, metav1.ConditionFalse, c.Status, "false when a manifest is not reported")
+	assert.Equal(t, "1 of 2 manifests not reported by the work agent", c.Message)
+
+	// the manifests of the chunks must be ready too
+	mw.Status.ResourceStatus.Manifests = append(mw.Status.ResourceStatus.Manifests,
+		manifestCondition("NodePool", "test1", metav1.ConditionTrue, metav1.ConditionTrue))
+	chunk := workv1.ManifestWork{}
+	chunk.Spec.Workload.Manifests = make([]workv1.Manifest, 1)
+	merged := mergeManifestWorkChunks(mw, []workv1.ManifestWork{chunk})
+	assert.Equal(t, "1 of 3 manifests not reported by the work agent", manifestsReadyCondition(merged).Message)
+
+	chunk.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{
+		manifestCondition("NodePool", "test2", metav1.ConditionTrue, metav1.ConditionTrue),
+	}
+	merged = mergeManifestWorkChunks(mw, []workv1.ManifestWork{chunk})
+	assert.Equal(t, metav1.ConditionTrue, manifestsReadyCondition(merged).Status, "true when the chunk is ready")
+}
+
+func TestReadyStatusOnReconcile(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	client.Create(ctx, testHD)
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var mw workv1.ManifestWork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+
+	// the work agent reports every manifest applied and available
+	ready := []metav1.Condition{
+		{Type: string(workv1.ManifestApplied), Status: metav1.ConditionTrue, Reason: "AppliedManifestComplete"},
+		{Type: string(workv1.ManifestAvailable), Status: metav1.ConditionTrue, Reason: "ResourceAvailable"},
+	}
+	mw.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{}
+	for i, m := range mw.Spec.Workload.Manifests {
+		u, err := manifestToUnstructured(m)
+		assert.Nil(t, err, "err nil when the manifest is readable")
+		mw.Status.ResourceStatus.Manifests = append(mw.Status.ResourceStatus.Manifests, workv1.ManifestCondition{
+			ResourceMeta: workv1.ManifestResourceMeta{Ordinal: int32(i), Kind: u.GetKind(), Namespace: u.GetNamespace(), Name: u.GetName()},
+			Conditions:   ready,
+		})
+	}
+	assert.Nil(t, client.Status().Update(ctx, &mw), "err nil when the work agent reports the manifests")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.True(t, resultHD.Status.Ready, "ready when every manifest is applied and available")
+	assert.True(t, condmeta.IsStatusConditionTrue(resultHD.Status.Conditions, string(hyd.Ready)), "the Ready condition is true")
+
+	// a manifest is no longer available
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+	mw.Status.ResourceStatus.Manifests[0].Conditions = ready[:1]
+	assert.Nil(t, client.Status().Update(ctx, &mw), "err nil when the work agent reports the manifests")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.False(t, resultHD.Status.Ready, "not ready when a manifest is not available")
+	c := condmeta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.Ready))
+	if assert.NotNil(t, c, "the Ready condition is reported") {
+		assert.Equal(t, metav1.ConditionFalse, c.Status)
+		assert.Contains(t, c.Message, ": not available")
+	}
+}
+
+func TestAPIEndpointFeedback(t *testing.T) {
+	testHD := getHDforManifestWork()
+
+	hcStatus := func(values ...workv1.FeedbackValue) workv1.ManifestCondition {
+		return workv1.ManifestCondition{
+			ResourceMeta: workv1.ManifestResourceMeta{
+				Group:     hyp.GroupVersion.Group,
+				Resource:  HostedClusterResource,
+				Name:      testHD.Name,
+				Namespace: helper.GetHostingNamespace(testHD),
+			},
+			StatusFeedbacks: workv1.StatusFeedbackResult{Values: values},
+		}
+	}
+
+	paths := []string{}
+	for id, cfg := range getManifestWorkConfigs(testHD) {
+		if id.Resource == HostedClusterResource {
+			for _, p := range cfg.FeedbackRules[0].JsonPaths {
+				paths = append(paths, p.Name+"="+p.Path)
+			}
+		}
+	}
+	assert.Contains(t, paths, APIEndpointHost+"=.status.controlPlaneEndpoint.host", "the endpoint host is requested")
+	assert.Contains(t, paths, APIEndpointPort+"=.status.controlPlaneEndpoint.port", "the endpoint port is requested")
+
+	mw := &workv1.ManifestWork{}
+	syncManifestworkStatusToHypershiftDeployment(testHD, mw)
+	assert.Empty(t, testHD.Status.APIEndpoint, "empty until the work agent reports the HostedCluster")
+
+	// the HostedCluster is not ready, the endpoint is not set
+	mw.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{hcStatus()}
+	syncManifestworkStatusToHypershiftDeployment(testHD, mw)
+	assert.Empty(t, testHD.Status.APIEndpoint, "empty before the HostedCluster is ready")
+
+	host := "api.test1.example.com"
+	port := int64(6443)
+	mw.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{hcStatus(
+		workv1.FeedbackValue{Name: APIEndpointHost, Value: workv1.FieldValue{Type: workv1.String, String: &host}},
+		workv1.FeedbackValue{Name: APIEndpointPort, Value: workv1.FieldValue{Type: workv1.Integer, Integer: &port}},
+	)}
+	syncManifestworkStatusToHypershiftDeployment(testHD, mw)
+	assert.Equal(t, "https://api.test1.example.com:6443", testHD.Status.APIEndpoint, "the endpoint is mapped from the feedback")
+
+	// the feedback of another HostedCluster is ignored
+	other := hcStatus()
+	other.ResourceMeta.Name = "other"
+	mw.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{other}
+	syncManifestworkStatusToHypershiftDeployment(testHD, mw)
+	assert.Equal(t, "https://api.test1.example.com:6443", testHD.Status.APIEndpoint, "kept without feedback of the HostedCluster")
+}
+
+// failingSecretClient fails the Gets of a secret with err
+type failingSecretClient struct {
+	client.Client
+	secret string
+	err    error
+}
+
+func (c *failingSecretClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if _, ok := obj.(*corev1.Secret); ok && key.Name == c.secret && c.err != nil {
+		return c.err
+	}
+
+	return c.Client.Get(ctx, key, obj)
+}
+
+func TestPullSecretFetchFailures(t *testing.T) {
+	fakeClient := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+
+	fakeClient.Create(ctx, testHD)
+	defer fakeClient.Delete(ctx, testHD)
+
+	fc := &failingSecretClient{
+		Client: fakeClient,
+		secret: testHD.Spec.HostedClusterSpec.PullSecret.Name,
+		err:    apierrors.NewInternalError(errors.New("etcdserver: leader changed")),
+	}
+	hdr := &HypershiftDeploymentReconciler{
+		Client: fc,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	// a blip of the api server is retried right away without a condition
+	res, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when the transient failure is retried")
+	assert.True(t, res.Requeue, "requeued right away")
+	assert.Zero(t, res.RequeueAfter, "not delayed")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, fakeClient.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.Nil(t, meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.PullSecretMissing)), "no condition for a transient failure")
+
+	// the pull secret is not on the hub
+	fc.err = nil
+	res, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when the missing pull secret is reported")
+	assert.Equal(t, time.Minute, res.RequeueAfter, "checked again later")
+
+	assert.Nil(t, fakeClient.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	c := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.PullSecretMissing))
+	if assert.NotNil(t, c, "PullSecretMissing condition is reported") {
+		assert.Equal(t, metav1.ConditionTrue, c.Status)
+		assert.Equal(t, hyd.SecretNotFoundReason, c.Reason)
+		assert.Contains(t, c.Message, "default/test1-pull-secret")
+	}
+
+	var mw workv1.ManifestWork
+	assert.True(t, apierrors.IsNotFound(fakeClient.Get(ctx, getManifestWorkKey(testHD), &mw)), "the manifestwork is not created")
+
+	// the condition is resolved once the pull secret is created
+	fakeClient.Create(ctx, getPullSecret(testHD))
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	assert.Nil(t, fakeClient.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	c = meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.PullSecretMissing))
+	if assert.NotNil(t, c, "PullSecretMissing condition is kept") {
+		assert.Equal(t, metav1.ConditionFalse, c.Status, "false once the pull secret is found")
+	}
+	assert.Nil(t, fakeClient.Get(ctx, getManifestWorkKey(testHD), &mw), "the manifestwork is created")
+}
+
+func TestManifestWorkSpecEqual(t *testing.T) {
+	secret := &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: "pull-secret", Namespace: "clusters"},
+		Data:       map[string][]byte{".dockerconfigjson": []byte("docker-pull-secret")},
+	}
+	typed := &workv1.ManifestWorkSpec{}
+	typed.Workload.Manifests = []workv1.Manifest{{RawExtension: runtime.RawExtension{Object: secret}}}
+
+	// the API server returns the fields sorted, without the null ones
+	raw, err := json.Marshal(secret)
+	assert.Nil(t, err, "err nil when the secret is serialized")
+	var obj map[string]interface{}
+	assert.Nil(t, json.Unmarshal(raw, &obj), "err nil when the secret is read")
+	delete(obj["metadata"].(map[string]interface{}), "creationTimestamp")
+	raw, err = json.Marshal(obj)
+	assert.Nil(t, err, "err nil when the secret is serialized")
+	readBack := &workv1.ManifestWorkSpec{}
+	readBack.Workload.Manifests = []workv1.Manifest{{RawExtension: runtime.RawExtension{Raw: raw}}}
+
+	typedRaw, err := json.Marshal(secret)
+	assert.Nil(t, err, "err nil when the secret is serialized")
+	assert.NotEqual(t, string(raw), string(typedRaw), "the serialized secrets differ")
+	assert.True(t, manifestWorkSpecEqual(typed, readBack), "the order of the fields is ignored")
+
+	secret.Data[".dockerconfigjson"] = []byte("new-pull-secret")
+	assert.False(t, manifestWorkSpecEqual(typed, readBack), "a changed manifest is detected")
+}
+
+func TestManifestConfigsHaveAStableOrder(t *testing.T) {
+	testHD := getHDforManifestWork()
+	for _, name := range []string{"np-c", "np-a", "np-b", "np-e", "np-d"} {
+		testHD.Spec.NodePools = append(testHD.Spec.NodePools, &hyd.HypershiftNodePools{Name: name})
+	}
+
+	expected := enableManifestStatusFeedback(&workv1.ManifestWork{}, testHD)
+	assert.Len(t, expected, len(testHD.Spec.NodePools)+1, "a manifest config for the HostedCluster and each NodePool")
+
+	// the configs are built from a map, its iteration order changes from one call to the next
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, expected, enableManifestStatusFeedback(&workv1.ManifestWork{}, testHD), "the manifest configs keep the same order")
+	}
+}
+
+func TestManifestWorkUpdatedOnChange(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var mw workv1.ManifestWork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+	resourceVersion := mw.ResourceVersion
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+	assert.Equal(t, resourceVersion, mw.ResourceVersion, "an unchanged manifestwork is not updated")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	resultHD.Spec.HostedClusterSpec.Release.Image = "quay.io/openshift-release-dev/ocp-release:4.10.99-x86_64"
+	assert.Nil(t, client.Update(ctx, &resultHD), "HypershiftDeployment resource is updated")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+	assert.NotEqual(t, resourceVersion, mw.ResourceVersion, "the changed spec is applied")
+	hc := payloadKinds(t, mw.Spec.Workload.Manifests)["HostedCluster"]
+	if assert.Len(t, hc, 1) {
+		image, _, _ := unstructured.NestedString(hc[0].Object, "spec", "release", "image")
+		assert.Equal(t, "quay.io/openshift-release-dev/ocp-release:4.10.99-x86_64", image)
+	}
+}
+
+func TestDeleteOptionsOfSpec(t *testing.T) {
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingNamespace = "clusters"
+	mw := &workv1.ManifestWork{}
+
+	setManifestWorkSelectivelyDeleteOption(mw, testHD)
+	assert.Equal(t, workv1.DeletePropagationPolicyTypeSelectivelyOrphan, mw.Spec.DeleteOption.PropagationPolicy, "the override picks the delete option")
+	assert.Equal(t, []workv1.OrphaningRule{{Resource: "namespaces", Name: "clusters"}}, mw.Spec.DeleteOption.SelectivelyOrphan.OrphaningRules,
+		"only the hosting namespace is orphaned by default")
+
+	testHD.Spec.DeleteOptions = &hyd.DeleteOptions{
+		PropagationPolicy: hyd.DeletePropagationSelectivelyOrphan,
+		OrphaningRules: []hyd.OrphaningRule{
+			{Resource: "secrets", Namespace: "clusters", Name: "test1-pull-secret"},
+			{Resource: "namespaces", Name: "clusters"},
+		},
+	}
+	setManifestWorkSelectivelyDeleteOption(mw, testHD)
+	assert.Equal(t, &workv1.DeleteOption{
+		PropagationPolicy: workv1.DeletePropagationPolicyTypeSelectivelyOrphan,
+		SelectivelyOrphan: &workv1.SelectivelyOrphan{OrphaningRules: []workv1.OrphaningRule{
+			{Resource: "secrets", Namespace: "clusters", Name: "test1-pull-secret"},
+			{Resource: "namespaces", Name: "clusters"},
+		}},
+	}, mw.Spec.DeleteOption, "the delete options of the spec replace the option of the override")
+
+	testHD.Spec.Override = hyd.DeleteHostingNamespace
+	testHD.Spec.DeleteOptions = &hyd.DeleteOptions{PropagationPolicy: hyd.DeletePropagationOrphan}
+	setManifestWorkSelectivelyDeleteOption(mw, testHD)
+	assert.Equal(t, &workv1.DeleteOption{PropagationPolicy: workv1.DeletePropagationPolicyTypeOrphan}, mw.Spec.DeleteOption,
+		"the delete options of the spec win over the override")
+
+	// selectively orphaning nothing would delete everything
+	testHD.Spec.DeleteOptions = &hyd.DeleteOptions{PropagationPolicy: hyd.DeletePropagationSelectivelyOrphan}
+	setManifestWorkSelectivelyDeleteOption(mw, testHD)
+	assert.Equal(t, workv1.DeletePropagationPolicyTypeForeground, mw.Spec.DeleteOption.PropagationPolicy, "invalid delete options fall back to the override")
+}
+
+func TestDeleteOptionsPropagated(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Spec.DeleteOptions = &hyd.DeleteOptions{
+		PropagationPolicy: hyd.DeletePropagationSelectivelyOrphan,
+		OrphaningRules: []hyd.OrphaningRule{
+			{Resource: "secrets", Namespace: helper.GetHostingNamespace(testHD), Name: testHD.Name + "-pull-secret"},
+		},
+	}
+	client.Create(ctx, testHD)
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var mw workv1.ManifestWork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+	assert.Equal(t, workv1.DeletePropagationPolicyTypeOrphan, mw.Spec.DeleteOption.PropagationPolicy,
+		"the payload is orphaned until the HypershiftDeployment is deleted")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.Nil(t, meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.DeleteOptionIneffective)), "the orphaning rule matches the payload")
+
+	assert.Nil(t, client.Delete(ctx, &resultHD), "err nil when the HypershiftDeployment is deleted")
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+	assert.Equal(t, deleteOptionOfSpec(testHD.Spec.DeleteOptions), mw.Spec.DeleteOption, "the delete options of the spec are set before the deletion")
+}
+
+func TestDeleteOptionsIneffective(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Spec.DeleteOptions = &hyd.DeleteOptions{
+		PropagationPolicy: hyd.DeletePropagationSelectivelyOrphan,
+		OrphaningRules:    []hyd.OrphaningRule{{Resource: "secrets", Namespace: "other", Name: "kept"}},
+	}
+	client.Create(ctx, testHD)
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	c := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.DeleteOptionIneffective))
+	if assert.NotNil(t, c, "DeleteOptionIneffective condition is reported") {
+		assert.Equal(t, metav1.ConditionTrue, c.Status)
+		assert.Contains(t, c.Message, "secrets other/kept match no object")
+	}
+
+	// an invalid policy is rejected before the manifestwork is written
+	resultHD.Spec.DeleteOptions.OrphaningRules = nil
+	assert.Nil(t, client.Update(ctx, &resultHD), "err nil when the delete options are updated")
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when the validation failure is reported")
+
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	c = meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.WorkConfigured))
+	if assert.NotNil(t, c, "WorkConfigured condition is reported") {
+		assert.Equal(t, metav1.ConditionFalse, c.Status)
+		assert.Equal(t, "deleteOptions.orphaningRules are required by the SelectivelyOrphan propagationPolicy", c.Message)
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+)
+
+// The results of a reconcile, by the most significant change made to the manifestworks of the HypershiftDeployment
+const (
+	reconcileResultCreated   = "created"
+	reconcileResultUpdated   = "updated"
+	reconcileResultDeleted   = "deleted"
+	reconcileResultUnchanged = "unchanged"
+	reconcileResultError     = "error"
+)
+
+// reconcileResultRank orders the changes of a reconcile, a deletion wins over a creation which wins over an update
+var reconcileResultRank = map[string]int{
+	reconcileResultUpdated: 1,
+	reconcileResultCreated: 2,
+	reconcileResultDeleted: 3,
+}
+
+// managedManifestWorksListTimeout bounds the manifestwork list of a metrics scrape
+const managedManifestWorksListTimeout = 5 * time.Second
+
+// hostedClusterAvailableDuration observes the time from the HypershiftDeployment creation to
+// the HostedCluster becoming Available for the first time
+var hostedClusterAvailableDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+	Name:    "hypershiftdeployment_hostedcluster_available_duration_seconds",
+	Help:    "Time from the HypershiftDeployment creation to the HostedCluster Available condition first becoming True.",
+	Buckets: []float64{60, 300, 600, 900, 1200, 1800, 2700, 3600, 5400, 7200},
+})
+
+// platformConfiguredDuration observes the time from the HypershiftDeployment creation to the
+// infrastructure of the platform being configured for the first time
+var platformConfiguredDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+	Name:    "hypershiftdeployment_platform_configured_duration_seconds",
+	Help:    "Time from the HypershiftDeployment creation to the PlatformConfigured condition first becoming True.",
+	Buckets: []float64{30, 60, 120, 300, 600, 900, 1200, 1800, 3600},
+})
+
+// reconcileTotal counts the reconciles of the HypershiftDeployments by result
+var reconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "hypershiftdeployment_reconcile_total",
+	Help: "Number of HypershiftDeployment reconciles by result: a manifestwork was created, updated or deleted, nothing was changed, or the reconcile failed.",
+}, []string{"result"})
+
+var managedManifestWorksDesc = prometheus.NewDesc(
+	"hypershiftdeployment_managed_manifestworks",
+	"Number of manifestworks created by the HypershiftDeployments, the chunks included.",
+	nil, nil)
+
+func init() {
+	metrics.Registry.MustRegister(hostedClusterAvailableDuration, platformConfiguredDuration, reconcileTotal)
+}
+
+// managedManifestWorksCollector counts the manifestworks created by the HypershiftDeployments when the metrics
+// are scraped, the manifestworks are read from the cache of the manager
+type managedManifestWorksCollector struct {
+	client client.Reader
+}
+
+func (c *managedManifestWorksCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- managedManifestWorksDesc
+}
+
+func (c *managedManifestWorksCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), managedManifestWorksListTimeout)
+	defer cancel()
+
+	list := &workv1.ManifestWorkList{}
+	if err := c.client.List(ctx, list); err != nil {
+		ch <- prometheus.NewInvalidMetric(managedManifestWorksDesc, err)
+		return
+	}
+
+	count := 0
+	for _, w := range list.Items {
+		if len(w.GetAnnotations()[constant.CreatedByHypershiftDeployment]) != 0 {
+			count++
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(managedManifestWorksDesc, prometheus.GaugeValue, float64(count))
+}
+
+// registerManagedManifestWorksCollector adds the managed manifestworks count to the metrics of the manager, a
+// collector already registered by a previous setup is kept
+func registerManagedManifestWorksCollector(c client.Reader) error {
+	if err := metrics.Registry.Register(&managedManifestWorksCollector{client: c}); err != nil {
+		if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// noteManifestWorkChange keeps the most significant change made to the manifestworks by the current reconcile
+func (r *HypershiftDeploymentReconciler) noteManifestWorkChange(result string) {
+	if reconcileResultRank[result] > reconcileResultRank[r.manifestWorkChange] {
+		r.manifestWorkChange = result
+	}
+}
+
+// reconcileResult is the result of the current reconcile counted by the reconcile metric
+func (r *HypershiftDeploymentReconciler) reconcileResult(err error) string {
+	switch {
+	case err != nil:
+		return reconcileResultError
+	case r.manifestWorkChange != "":
+		return r.manifestWorkChange
+	default:
+		return reconcileResultUnchanged
+	}
+}
+
+// observeHostedClusterAvailable records the provisioning duration when the HostedClusterAvailable
+// condition turns True between the before and after copies of the HypershiftDeployment. Each
+// HypershiftDeployment is only observed once, so a flapping HostedCluster is not counted again.
+func (r *HypershiftDeploymentReconciler) observeHostedClusterAvailable(before, after *hypdeployment.HypershiftDeployment) {
+	if meta.IsStatusConditionTrue(before.Status.Conditions, string(hypdeployment.HostedClusterAvailable)) {
+		return
+	}
+
+	cond := meta.FindStatusCondition(after.Status.Conditions, string(hypdeployment.HostedClusterAvailable))
+	if cond == nil || cond.Status != "True" {
+		return
+	}
+
+	if _, observed := r.availableObserved.LoadOrStore(after.UID, struct{}{}); observed {
+		return
+	}
+
+	hostedClusterAvailableDuration.Observe(cond.LastTransitionTime.Sub(after.CreationTimestamp.Time).Seconds())
+}
+
+// observePlatformConfigured records the platform configuration duration when the PlatformConfigured
+// condition turns True between the before and after copies of the HypershiftDeployment. Each
+// HypershiftDeployment is only observed once, a reconfigured platform is not counted again.
+func (r *HypershiftDeploymentReconciler) observePlatformConfigured(before, after *hypdeployment.HypershiftDeployment) {
+	if meta.IsStatusConditionTrue(before.Status.Conditions, string(hypdeployment.PlatformConfigured)) {
+		return
+	}
+
+	cond := meta.FindStatusCondition(after.Status.Conditions, string(hypdeployment.PlatformConfigured))
+	if cond == nil || cond.Status != "True" {
+		return
+	}
+
+	if _, observed := r.configuredObserved.LoadOrStore(after.UID, struct{}{}); observed {
+		return
+	}
+
+	platformConfiguredDuration.Observe(cond.LastTransitionTime.Sub(after.CreationTimestamp.Time).Seconds())
+}
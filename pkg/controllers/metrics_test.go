@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	hyp "github.com/openshift/hypershift/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hydapi "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
+)
+
+func availableDurationSamples(t *testing.T) (uint64, float64) {
+	m := &dto.Metric{}
+	assert.Nil(t, hostedClusterAvailableDuration.Write(m), "is nil when the histogram is collected")
+	return m.GetHistogram().GetSampleCount(), m.GetHistogram().GetSampleSum()
+}
+
+func TestHostedClusterAvailableDurationMetric(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.CreationTimestamp = metav1.NewTime(time.Now().Add(-10 * time.Minute))
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	countBefore, sumBefore := availableDurationSamples(t)
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	count, _ := availableDurationSamples(t)
+	assert.Equal(t, countBefore, count, "not observed before the HostedCluster is available")
+
+	reason := "AsExpected"
+	status := "True"
+
+	var mw workv1.ManifestWork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+	mw.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{
+		{
+			ResourceMeta: workv1.ManifestResourceMeta{
+				Group:     hyp.GroupVersion.Group,
+				Resource:  HostedClusterResource,
+				Name:      testHD.Name,
+				Namespace: helper.GetHostingNamespace(testHD),
+			},
+			StatusFeedbacks: workv1.StatusFeedbackResult{
+				Values: []workv1.FeedbackValue{
+					{Name: Reason, Value: workv1.FieldValue{Type: workv1.String, String: &reason}},
+					{Name: StatusFlag, Value: workv1.FieldValue{Type: workv1.String, String: &status}},
+				},
+			},
+		},
+	}
+	assert.Nil(t, client.Status().Update(ctx, &mw), "is nil when the manifestwork status is updated")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	count, sum := availableDurationSamples(t)
+	assert.Equal(t, countBefore+1, count, "observed once the HostedCluster is available")
+	assert.GreaterOrEqual(t, sum-sumBefore, (10 * time.Minute).Seconds(), "duration is measured from the HypershiftDeployment creation")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	count, _ = availableDurationSamples(t)
+	assert.Equal(t, countBefore+1, count, "only observed the first time the HostedCluster is available")
+}
+
+func reconcileCount(t *testing.T, result string) float64 {
+	m := &dto.Metric{}
+	assert.Nil(t, reconcileTotal.WithLabelValues(result).Write(m), "is nil when the counter is collected")
+	return m.GetCounter().GetValue()
+}
+
+func TestReconcileTotalMetric(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	client.Create(ctx, testHD)
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	created, unchanged, deleted := reconcileCount(t, reconcileResultCreated), reconcileCount(t, reconcileResultUnchanged), reconcileCount(t, reconcileResultDeleted)
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Equal(t, created+1, reconcileCount(t, reconcileResultCreated), "counted as created when the manifestwork is created")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Equal(t, unchanged+1, reconcileCount(t, reconcileResultUnchanged), "counted as unchanged when the manifestwork is up to date")
+
+	assert.Nil(t, client.Delete(ctx, testHD), "err nil when the HypershiftDeployment is deleted")
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	// the work agent consumes the delete option
+	var mw workv1.ManifestWork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+	meta.SetStatusCondition(&mw.Status.Conditions, metav1.Condition{Type: workv1.WorkAvailable, Status: metav1.ConditionTrue,
+		Reason: "ResourcesAvailable", ObservedGeneration: mw.Generation})
+	assert.Nil(t, client.Status().Update(ctx, &mw), "err nil when the manifestwork status is updated")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Equal(t, deleted+1, reconcileCount(t, reconcileResultDeleted), "counted as deleted when the manifestwork is deleted")
+
+	hdr.manifestWorkChange = ""
+	hdr.noteManifestWorkChange(reconcileResultDeleted)
+	hdr.noteManifestWorkChange(reconcileResultUpdated)
+	assert.Equal(t, reconcileResultDeleted, hdr.reconcileResult(nil), "the deletion wins over the update")
+	assert.Equal(t, reconcileResultError, hdr.reconcileResult(assert.AnError), "a failed reconcile is an error")
+}
+
+func TestManagedManifestWorksCollector(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	managed := &workv1.ManifestWork{}
+	managed.Name = "managed"
+	managed.Namespace = "local-cluster"
+	managed.Annotations = map[string]string{constant.CreatedByHypershiftDeployment: "default" + constant.NamespaceNameSeperator + "test1"}
+	assert.Nil(t, client.Create(ctx, managed), "err nil when the manifestwork is created")
+
+	other := &workv1.ManifestWork{}
+	other.Name = "other"
+	other.Namespace = "local-cluster"
+	assert.Nil(t, client.Create(ctx, other), "err nil when the manifestwork is created")
+
+	collector := &managedManifestWorksCollector{client: client}
+	ch := make(chan prometheus.Metric, 1)
+	collector.Collect(ch)
+
+	m := &dto.Metric{}
+	assert.Nil(t, (<-ch).Write(m), "is nil when the gauge is collected")
+	assert.Equal(t, float64(1), m.GetGauge().GetValue(), "only the manifestworks of the HypershiftDeployments are counted")
+}
+
+func TestPlatformConfiguredDurationMetric(t *testing.T) {
+	ctx := context.Background()
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Spec.Infrastructure.Platform.AWS.Region = "us-east-1"
+	testHD.CreationTimestamp = metav1.NewTime(time.Now().Add(-5 * time.Minute))
+
+	r := GetHypershiftDeploymentReconciler()
+	hydapi.AddToScheme(r.Scheme)
+	r.Client.Create(ctx, testHD)
+	r.Client.Create(ctx, getS3Secret("local-cluster"))
+	defer r.Client.Delete(ctx, testHD)
+	r.InfraHandler = &FakeInfraHandler{}
+
+	samples := func() (uint64, float64) {
+		m := &dto.Metric{}
+		assert.Nil(t, platformConfiguredDuration.Write(m), "is nil when the histogram is collected")
+		return m.GetHistogram().GetSampleCount(), m.GetHistogram().GetSampleSum()
+	}
+	countBefore, sumBefore := samples()
+
+	_, err := r.createAWSInfra(testHD, getProviderSecret())
+	assert.Nil(t, err, "nil, when no problem occurs")
+	assert.True(t, meta.IsStatusConditionTrue(testHD.Status.Conditions, string(hydapi.PlatformConfigured)), "the platform is configured")
+
+	count, sum := samples()
+	assert.Equal(t, countBefore+1, count, "observed once the platform is configured")
+	assert.GreaterOrEqual(t, sum-sumBefore, (5 * time.Minute).Seconds(), "duration is measured from the HypershiftDeployment creation")
+
+	// the platform is configured again, ie after a reset of the condition
+	meta.RemoveStatusCondition(&testHD.Status.Conditions, string(hydapi.PlatformConfigured))
+	_, err = r.createAWSInfra(testHD, getProviderSecret())
+	assert.Nil(t, err, "nil, when no problem occurs")
+
+	count, _ = samples()
+	assert.Equal(t, countBefore+1, count, "only observed the first time the platform is configured")
+}
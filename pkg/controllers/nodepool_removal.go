@@ -0,0 +1,204 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	hyp "github.com/openshift/hypershift/api/v1alpha1"
+	condmeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	workv1 "open-cluster-management.io/api/work/v1"
+)
+
+// removableResources are the kinds of the payload deleted from the HostingCluster once removed from the payload,
+// with the resource the work agent reports them by
+var removableResources = map[string]schema.GroupResource{
+	"NodePool": {Group: hyp.GroupVersion.Group, Resource: NodePoolResource},
+	"Secret":   {Resource: "secrets"},
+}
+
+// payloadRemoval is the teardown of the NodePools and secrets of the applied manifestwork that are no longer in
+// the payload
+type payloadRemoval struct {
+	// removed lists the NodePools removed from the payload, a NodePool still reported in the resource status of
+	// the manifestwork is listed until the work agent applied its removal
+	removed []string
+
+	// removedSecrets lists the namespace/name of the secrets removed from the payload, like removed
+	removedSecrets []string
+
+	// kept are the manifests of the removed NodePools and secrets that are written again, until the work agent no
+	// longer orphans them
+	kept []workv1.Manifest
+
+	// deleteOption is the delete option written with the payload
+	deleteOption *workv1.DeleteOption
+}
+
+// removalName names a removed object, the NodePools are all in the hosting namespace
+func removalName(kind string, namespace string, name string) string {
+	if kind == "NodePool" {
+		return name
+	}
+
+	return namespace + "/" + name
+}
+
+// removeManifests plans the teardown of the NodePools and secrets removed from the payload. The default Orphan
+// delete option also orphans the objects removed from the payload, so the delete option is narrowed to orphan the
+// payload objects one by one. The work agent only deletes a removed object it stopped orphaning, the object is kept
+// in the payload until the work agent applied the narrowed delete option. The applied manifestwork is the record of
+// what was shipped, so a removal survives a restart of the controller.
+func removeManifests(m *workv1.ManifestWork, payload []workv1.Manifest) (*payloadRemoval, error) {
+	rendered := sets.NewString()
+	for _, pm := range payload {
+		u, err := manifestToUnstructured(pm)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the manifestwork payload, err: %w", err)
+		}
+
+		if _, ok := removableResources[u.GetKind()]; ok {
+			rendered.Insert(u.GetKind() + "/" + removalName(u.GetKind(), u.GetNamespace(), u.GetName()))
+		}
+	}
+
+	removed := map[string]sets.String{}
+	for kind := range removableResources {
+		removed[kind] = sets.NewString()
+	}
+
+	applied := []*unstructured.Unstructured{}
+	appliedManifests := []workv1.Manifest{}
+	for _, am := range m.Spec.Workload.Manifests {
+		u, err := manifestToUnstructured(am)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the applied manifestwork payload, err: %w", err)
+		}
+
+		name := removalName(u.GetKind(), u.GetNamespace(), u.GetName())
+		if _, ok := removableResources[u.GetKind()]; ok && !rendered.Has(u.GetKind()+"/"+name) {
+			removed[u.GetKind()].Insert(name)
+			applied = append(applied, u)
+			appliedManifests = append(appliedManifests, am)
+		}
+	}
+
+	for _, rs := range m.Status.ResourceStatus.Manifests {
+		for kind, gr := range removableResources {
+			name := removalName(kind, rs.ResourceMeta.Namespace, rs.ResourceMeta.Name)
+			if rs.ResourceMeta.Group == gr.Group && rs.ResourceMeta.Resource == gr.Resource && !rendered.Has(kind+"/"+name) {
+				removed[kind].Insert(name)
+			}
+		}
+	}
+
+	out := &payloadRemoval{
+		removed:        removed["NodePool"].List(),
+		removedSecrets: removed["Secret"].List(),
+		deleteOption:   m.Spec.DeleteOption,
+	}
+
+	option := m.Spec.DeleteOption
+	if len(out.removed) == 0 && len(out.removedSecrets) == 0 &&
+		(option == nil || option.PropagationPolicy != workv1.DeletePropagationPolicyTypeSelectivelyOrphan) {
+		return out, nil
+	}
+
+	var err error
+	if out.deleteOption, err = orphanPayloadDeleteOption(payload); err != nil {
+		return nil, err
+	}
+
+	for i, u := range applied {
+		if orphans(option, u) || !workApplied(m) {
+			out.kept = append(out.kept, appliedManifests[i])
+		}
+	}
+
+	return out, nil
+}
+
+// orphanPayloadDeleteOption orphans every object of the payload when the manifestwork is deleted, like the default
+// Orphan delete option, but lets the work agent delete the objects removed from the payload
+func orphanPayloadDeleteOption(payload []workv1.Manifest) (*workv1.DeleteOption, error) {
+	rules := []workv1.OrphaningRule{}
+	for _, pm := range payload {
+		u, err := manifestToUnstructured(pm)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the manifestwork payload, err: %w", err)
+		}
+
+		rules = append(rules, orphaningRule(u))
+	}
+
+	// keep the rules stable so an unchanged payload does not update the manifestwork
+	sort.Slice(rules, func(i, j int) bool {
+		a, b := rules[i], rules[j]
+		return a.Group+"/"+a.Resource+"/"+a.Namespace+"/"+a.Name < b.Group+"/"+b.Resource+"/"+b.Namespace+"/"+b.Name
+	})
+
+	return &workv1.DeleteOption{
+		PropagationPolicy: workv1.DeletePropagationPolicyTypeSelectivelyOrphan,
+		SelectivelyOrphan: &workv1.SelectivelyOrphan{OrphaningRules: rules},
+	}, nil
+}
+
+// orphaningRule matches u, the resource is the lowercase plural of its kind
+func orphaningRule(u *unstructured.Unstructured) workv1.OrphaningRule {
+	return workv1.OrphaningRule{
+		Group:     u.GroupVersionKind().Group,
+		Resource:  strings.ToLower(u.GetKind()) + "s",
+		Namespace: u.GetNamespace(),
+		Name:      u.GetName(),
+	}
+}
+
+// orphans reports whether the delete option orphans u
+func orphans(option *workv1.DeleteOption, u *unstructured.Unstructured) bool {
+	if option == nil {
+		return false
+	}
+
+	if option.PropagationPolicy == workv1.DeletePropagationPolicyTypeOrphan {
+		return true
+	}
+
+	if option.PropagationPolicy != workv1.DeletePropagationPolicyTypeSelectivelyOrphan || option.SelectivelyOrphan == nil {
+		return false
+	}
+
+	rule := orphaningRule(u)
+	for _, r := range option.SelectivelyOrphan.OrphaningRules {
+		if r == rule {
+			return true
+		}
+	}
+
+	return false
+}
+
+// workApplied reports whether the work agent applied the current generation of the manifestwork
+func workApplied(m *workv1.ManifestWork) bool {
+	cond := condmeta.FindStatusCondition(m.Status.Conditions, string(workv1.WorkApplied))
+	return cond != nil && cond.ObservedGeneration == m.Generation && cond.Status == metav1.ConditionTrue
+}
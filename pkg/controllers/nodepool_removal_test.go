@@ -0,0 +1,296 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	hyp "github.com/openshift/hypershift/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+func nodePoolManifest(name string) workv1.Manifest {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(hyp.GroupVersion.String())
+	u.SetKind("NodePool")
+	u.SetNamespace("clusters")
+	u.SetName(name)
+
+	return workv1.Manifest{RawExtension: runtime.RawExtension{Object: u}}
+}
+
+func nodePoolResourceStatus(name string) workv1.ManifestCondition {
+	return workv1.ManifestCondition{
+		ResourceMeta: workv1.ManifestResourceMeta{
+			Group:     hyp.GroupVersion.Group,
+			Resource:  NodePoolResource,
+			Namespace: "clusters",
+			Name:      name,
+		},
+	}
+}
+
+func setWorkApplied(m *workv1.ManifestWork) {
+	meta.SetStatusCondition(&m.Status.Conditions, metav1.Condition{
+		Type:               string(workv1.WorkApplied),
+		Status:             metav1.ConditionTrue,
+		Reason:             "AppliedManifestWorkComplete",
+		ObservedGeneration: m.Generation,
+	})
+}
+
+func TestRemoveNodePools(t *testing.T) {
+	payload := []workv1.Manifest{nodePoolManifest("np-a")}
+	orphan := &workv1.DeleteOption{PropagationPolicy: workv1.DeletePropagationPolicyTypeOrphan}
+
+	m := &workv1.ManifestWork{}
+	m.Spec.DeleteOption = orphan
+	m.Spec.Workload.Manifests = []workv1.Manifest{nodePoolManifest("np-a")}
+
+	removal, err := removeManifests(m, payload)
+	assert.Nil(t, err, "err nil when the payload is readable")
+	assert.Empty(t, removal.removed, "no NodePool is removed")
+	assert.Empty(t, removal.kept)
+	assert.Equal(t, orphan, removal.deleteOption, "the delete option is kept when no NodePool is removed")
+
+	// the removed NodePool is orphaned by the applied delete option, it is kept until the option is narrowed
+	m.Spec.Workload.Manifests = append(m.Spec.Workload.Manifests, nodePoolManifest("np-b"))
+	setWorkApplied(m)
+
+	removal, err = removeManifests(m, payload)
+	assert.Nil(t, err, "err nil when the payload is readable")
+	assert.Equal(t, []string{"np-b"}, removal.removed)
+	assert.Len(t, removal.kept, 1, "the orphaned NodePool is kept in the payload")
+	assert.Equal(t, workv1.DeletePropagationPolicyTypeSelectivelyOrphan, removal.deleteOption.PropagationPolicy)
+	assert.Equal(t, []workv1.OrphaningRule{{Group: hyp.GroupVersion.Group, Resource: NodePoolResource, Namespace: "clusters", Name: "np-a"}},
+		removal.deleteOption.SelectivelyOrphan.OrphaningRules, "only the payload objects are orphaned")
+
+	// the narrowed delete option is not applied yet
+	m.Spec.DeleteOption = removal.deleteOption
+	m.Generation++
+
+	removal, err = removeManifests(m, payload)
+	assert.Nil(t, err, "err nil when the payload is readable")
+	assert.Len(t, removal.kept, 1, "the NodePool is kept until the work agent applied the narrowed delete option")
+
+	// the narrowed delete option is applied, the NodePool is dropped and deleted by the work agent
+	setWorkApplied(m)
+
+	removal, err = removeManifests(m, payload)
+	assert.Nil(t, err, "err nil when the payload is readable")
+	assert.Equal(t, []string{"np-b"}, removal.removed)
+	assert.Empty(t, removal.kept, "the NodePool is dropped from the payload")
+
+	// the work agent still reports the NodePool
+	m.Spec.Workload.Manifests = payload
+	m.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{nodePoolResourceStatus("np-a"), nodePoolResourceStatus("np-b")}
+
+	removal, err = removeManifests(m, payload)
+	assert.Nil(t, err, "err nil when the payload is readable")
+	assert.Equal(t, []string{"np-b"}, removal.removed, "the NodePool is removed until the work agent stops reporting it")
+	assert.Empty(t, removal.kept)
+
+	m.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{nodePoolResourceStatus("np-a")}
+
+	removal, err = removeManifests(m, payload)
+	assert.Nil(t, err, "err nil when the payload is readable")
+	assert.Empty(t, removal.removed, "the removal is complete")
+	assert.Equal(t, workv1.DeletePropagationPolicyTypeSelectivelyOrphan, removal.deleteOption.PropagationPolicy, "the payload objects stay orphaned one by one")
+}
+
+func TestNodePoolRemovedTeardown(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	removedNP := testHD.Spec.NodePools[0].DeepCopy()
+	removedNP.Name = testHD.Spec.NodePools[0].Name + "-2"
+	testHD.Spec.NodePools = append(testHD.Spec.NodePools, removedNP)
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	reconcileAndGet := func() (*hyd.HypershiftDeployment, *workv1.ManifestWork) {
+		_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+		assert.Nil(t, err, "err nil when reconcile was successful")
+
+		var resultHD hyd.HypershiftDeployment
+		assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+
+		var mw workv1.ManifestWork
+		assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+
+		return &resultHD, &mw
+	}
+
+	nodePoolNames := func(mw *workv1.ManifestWork) []string {
+		names := []string{}
+		for _, u := range payloadKinds(t, mw.Spec.Workload.Manifests)["NodePool"] {
+			names = append(names, u.GetName())
+		}
+		return names
+	}
+
+	resultHD, mw := reconcileAndGet()
+	assert.ElementsMatch(t, []string{testHD.Spec.NodePools[0].Name, removedNP.Name}, nodePoolNames(mw))
+	assert.Equal(t, workv1.DeletePropagationPolicyTypeOrphan, mw.Spec.DeleteOption.PropagationPolicy, "the payload is orphaned")
+
+	// the work agent applied the manifestwork
+	setWorkApplied(mw)
+	assert.Nil(t, client.Update(ctx, mw), "the manifestwork status is updated")
+
+	resultHD.Spec.NodePools = resultHD.Spec.NodePools[:1]
+	assert.Nil(t, client.Update(ctx, resultHD), "HypershiftDeployment resource is updated")
+
+	// the delete option stops orphaning the removed NodePool first
+	resultHD, mw = reconcileAndGet()
+	assert.Contains(t, nodePoolNames(mw), removedNP.Name, "the removed NodePool is kept until it is no longer orphaned")
+	assert.Equal(t, workv1.DeletePropagationPolicyTypeSelectivelyOrphan, mw.Spec.DeleteOption.PropagationPolicy)
+	for _, rule := range mw.Spec.DeleteOption.SelectivelyOrphan.OrphaningRules {
+		assert.NotEqual(t, removedNP.Name, rule.Name, "the removed NodePool is not orphaned")
+	}
+	assert.Nil(t, validateDeleteOption(mw.Spec.DeleteOption, mw.Spec.Workload.Manifests), "the orphaning rules match the payload")
+
+	c := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.NodePoolsRemoved))
+	assert.NotNil(t, c, "NodePoolsRemoved condition is reported")
+	assert.Equal(t, metav1.ConditionTrue, c.Status, "true while the NodePool is torn down")
+	assert.Equal(t, hyd.RemovingReason, c.Reason)
+	assert.Contains(t, c.Message, removedNP.Name)
+
+	// the work agent applied the narrowed delete option, the NodePool is dropped from the payload
+	setWorkApplied(mw)
+	mw.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{nodePoolResourceStatus(removedNP.Name)}
+	assert.Nil(t, client.Update(ctx, mw), "the manifestwork status is updated")
+
+	resultHD, mw = reconcileAndGet()
+	assert.Equal(t, []string{testHD.Spec.NodePools[0].Name}, nodePoolNames(mw), "the removed NodePool is deleted by the work agent")
+	assert.True(t, meta.IsStatusConditionTrue(resultHD.Status.Conditions, string(hyd.NodePoolsRemoved)), "true while the work agent reports the NodePool")
+
+	// the work agent deleted the NodePool
+	mw.Status.ResourceStatus.Manifests = nil
+	assert.Nil(t, client.Update(ctx, mw), "the manifestwork status is updated")
+
+	resultHD, _ = reconcileAndGet()
+	c = meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.NodePoolsRemoved))
+	assert.Equal(t, metav1.ConditionFalse, c.Status, "false once the NodePool is torn down")
+}
+
+func TestRemoveSecrets(t *testing.T) {
+	payload := []workv1.Manifest{nodePoolManifest("np-a"), secretManifest("pull-secret", "a")}
+
+	m := &workv1.ManifestWork{}
+	m.Spec.DeleteOption = &workv1.DeleteOption{PropagationPolicy: workv1.DeletePropagationPolicyTypeOrphan}
+	m.Spec.Workload.Manifests = append(payload, secretManifest("ssh-key", "b"))
+	setWorkApplied(m)
+
+	removal, err := removeManifests(m, payload)
+	assert.Nil(t, err, "err nil when the payload is readable")
+	assert.Empty(t, removal.removed, "no NodePool is removed")
+	assert.Equal(t, []string{"clusters/ssh-key"}, removal.removedSecrets)
+	assert.Len(t, removal.kept, 1, "the orphaned secret is kept in the payload")
+	assert.Equal(t, workv1.DeletePropagationPolicyTypeSelectivelyOrphan, removal.deleteOption.PropagationPolicy)
+	assert.Equal(t, []workv1.OrphaningRule{
+		{Resource: "secrets", Namespace: "clusters", Name: "pull-secret"},
+		{Group: hyp.GroupVersion.Group, Resource: NodePoolResource, Namespace: "clusters", Name: "np-a"},
+	}, removal.deleteOption.SelectivelyOrphan.OrphaningRules, "only the payload objects are orphaned")
+
+	// the narrowed delete option is applied, the secret is dropped and deleted by the work agent
+	m.Spec.DeleteOption = removal.deleteOption
+	m.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{{
+		ResourceMeta: workv1.ManifestResourceMeta{Resource: "secrets", Namespace: "clusters", Name: "ssh-key"},
+	}}
+
+	removal, err = removeManifests(m, payload)
+	assert.Nil(t, err, "err nil when the payload is readable")
+	assert.Equal(t, []string{"clusters/ssh-key"}, removal.removedSecrets, "the secret is removed until the work agent stops reporting it")
+	assert.Empty(t, removal.kept, "the secret is dropped from the payload")
+}
+
+func TestSecretRemovedTeardown(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Spec.HostedClusterSpec.SSHKey = corev1.LocalObjectReference{Name: "test1-ssh-key"}
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+	client.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test1-ssh-key", Namespace: testHD.Namespace},
+		Data:       map[string][]byte{"id_rsa.pub": []byte("ssh-rsa AAAA")},
+	})
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	reconcileAndGet := func() *workv1.ManifestWork {
+		_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+		assert.Nil(t, err, "err nil when reconcile was successful")
+
+		var mw workv1.ManifestWork
+		assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+
+		return &mw
+	}
+
+	secretNames := func(mw *workv1.ManifestWork) []string {
+		names := []string{}
+		for _, u := range payloadKinds(t, mw.Spec.Workload.Manifests)["Secret"] {
+			names = append(names, u.GetName())
+		}
+		return names
+	}
+
+	mw := reconcileAndGet()
+	assert.Contains(t, secretNames(mw), "test1-ssh-key", "the ssh key is shipped")
+
+	// the work agent applied the manifestwork
+	setWorkApplied(mw)
+	assert.Nil(t, client.Update(ctx, mw), "the manifestwork status is updated")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	resultHD.Spec.HostedClusterSpec.SSHKey = corev1.LocalObjectReference{}
+	assert.Nil(t, client.Update(ctx, &resultHD), "HypershiftDeployment resource is updated")
+
+	// a new reconciler, the removal is read from the applied manifestwork
+	hdr = &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	mw = reconcileAndGet()
+	assert.Contains(t, secretNames(mw), "test1-ssh-key", "the removed secret is kept until it is no longer orphaned")
+	assert.Equal(t, workv1.DeletePropagationPolicyTypeSelectivelyOrphan, mw.Spec.DeleteOption.PropagationPolicy)
+	for _, rule := range mw.Spec.DeleteOption.SelectivelyOrphan.OrphaningRules {
+		assert.NotEqual(t, "test1-ssh-key", rule.Name, "the removed secret is not orphaned")
+	}
+
+	// the work agent applied the narrowed delete option, the secret is dropped from the payload
+	setWorkApplied(mw)
+	assert.Nil(t, client.Update(ctx, mw), "the manifestwork status is updated")
+
+	mw = reconcileAndGet()
+	assert.NotContains(t, secretNames(mw), "test1-ssh-key", "the removed secret is deleted by the work agent")
+}
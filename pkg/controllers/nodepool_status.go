@@ -0,0 +1,79 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	condmeta "k8s.io/apimachinery/pkg/api/meta"
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	hyp "github.com/openshift/hypershift/api/v1alpha1"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
+)
+
+// nodePoolStatusFeedback builds the status of the NodePools of the spec from the status feedback of the manifestwork,
+// in the order of the spec. A NodePool the work agent has not reported on yet only has its name, the Ready condition
+// keeps the transition time of the previous status while it does not change.
+func nodePoolStatusFeedback(m *workv1.ManifestWork, hyd *hypdeployment.HypershiftDeployment) []hypdeployment.NodePoolStatus {
+	if m == nil || hyd == nil || len(hyd.Spec.NodePools) == 0 {
+		return nil
+	}
+
+	feedback := map[string][]workv1.FeedbackValue{}
+	for _, obj := range m.Status.ResourceStatus.Manifests {
+		id := resourceMeta(obj.ResourceMeta).ToIdentifier()
+		if id.Group != hyp.GroupVersion.Group || id.Resource != NodePoolResource || id.Namespace != helper.GetHostingNamespace(hyd) {
+			continue
+		}
+
+		feedback[id.Name] = obj.StatusFeedbacks.Values
+	}
+
+	previous := map[string]hypdeployment.NodePoolStatus{}
+	for _, s := range hyd.Status.NodePools {
+		previous[s.Name] = s
+	}
+
+	out := make([]hypdeployment.NodePoolStatus, 0, len(hyd.Spec.NodePools))
+	for _, np := range hyd.Spec.NodePools {
+		s := hypdeployment.NodePoolStatus{Name: np.Name, Conditions: previous[np.Name].Conditions}
+
+		values := feedback[np.Name]
+		for _, v := range values {
+			if v.Value.Integer == nil {
+				continue
+			}
+
+			replicas := int32(*v.Value.Integer)
+			switch v.Name {
+			case DesiredReplicas:
+				s.DesiredReplicas = &replicas
+			case CurrentReplicas:
+				s.CurrentReplicas = &replicas
+			}
+		}
+
+		if ready, ok := feedbackToCondition(hyp.NodePoolReadyConditionType, values); ok {
+			condmeta.SetStatusCondition(&s.Conditions, ready)
+		}
+
+		out = append(out, s)
+	}
+
+	return out
+}
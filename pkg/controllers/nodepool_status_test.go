@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+func nodePoolFeedback(name string, desired, current int64, ready string) workv1.ManifestCondition {
+	reason := "ScalingUp"
+	if ready == "True" {
+		reason = "AsExpected"
+	}
+
+	mc := nodePoolResourceStatus(name)
+	mc.StatusFeedbacks.Values = []workv1.FeedbackValue{
+		{Name: Reason, Value: workv1.FieldValue{Type: workv1.String, String: &reason}},
+		{Name: StatusFlag, Value: workv1.FieldValue{Type: workv1.String, String: &ready}},
+		{Name: DesiredReplicas, Value: workv1.FieldValue{Type: workv1.Integer, Integer: &desired}},
+		{Name: CurrentReplicas, Value: workv1.FieldValue{Type: workv1.Integer, Integer: &current}},
+	}
+
+	return mc
+}
+
+func TestNodePoolStatusFeedback(t *testing.T) {
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingNamespace = "clusters"
+	testHD.Spec.NodePools = append(testHD.Spec.NodePools, &hyd.HypershiftNodePools{Name: "test2", Spec: testHD.Spec.NodePools[0].Spec})
+
+	paths := []string{}
+	for id, cfg := range getManifestWorkConfigs(testHD) {
+		if id.Resource == NodePoolResource && id.Name == "test1" {
+			for _, p := range cfg.FeedbackRules[0].JsonPaths {
+				paths = append(paths, p.Name+"="+p.Path)
+			}
+		}
+	}
+	assert.Contains(t, paths, DesiredReplicas+"=.spec.replicas", "the desired replicas are requested")
+	assert.Contains(t, paths, CurrentReplicas+"=.status.replicas", "the current replicas are requested")
+
+	mw := &workv1.ManifestWork{}
+	assert.Nil(t, nodePoolStatusFeedback(mw, getHypershiftDeployment("default", "test1", false)), "nil without NodePools")
+	assert.Equal(t, []hyd.NodePoolStatus{{Name: "test1"}, {Name: "test2"}}, nodePoolStatusFeedback(mw, testHD),
+		"only the names until the work agent reports the NodePools")
+
+	mw.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{
+		nodePoolFeedback("test2", 3, 3, "True"),
+		nodePoolFeedback("test1", 2, 1, "False"),
+		nodePoolFeedback("removed", 1, 1, "True"),
+	}
+	syncManifestworkStatusToHypershiftDeployment(testHD, mw)
+
+	status := testHD.Status.NodePools
+	if assert.Len(t, status, 2, "one status per NodePool of the spec") {
+		assert.Equal(t, "test1", status[0].Name, "in the order of the spec")
+		assert.Equal(t, int32(2), *status[0].DesiredReplicas)
+		assert.Equal(t, int32(1), *status[0].CurrentReplicas)
+		ready := meta.FindStatusCondition(status[0].Conditions, "Ready")
+		if assert.NotNil(t, ready, "the Ready condition is reported") {
+			assert.Equal(t, metav1.ConditionFalse, ready.Status)
+			assert.Equal(t, "ScalingUp", ready.Reason)
+		}
+
+		assert.Equal(t, "test2", status[1].Name)
+		assert.Equal(t, int32(3), *status[1].CurrentReplicas)
+		assert.True(t, meta.IsStatusConditionTrue(status[1].Conditions, "Ready"), "the NodePool is ready")
+	}
+
+	// the transition time is kept while the Ready condition does not change
+	transition := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	testHD.Status.NodePools[1].Conditions[0].LastTransitionTime = transition
+	syncManifestworkStatusToHypershiftDeployment(testHD, mw)
+	assert.Equal(t, transition, testHD.Status.NodePools[1].Conditions[0].LastTransitionTime, "the transition time is kept")
+
+	// an autoscaled NodePool has no desired replicas
+	mw.Status.ResourceStatus.Manifests[0].StatusFeedbacks.Values = mw.Status.ResourceStatus.Manifests[0].StatusFeedbacks.Values[:2]
+	syncManifestworkStatusToHypershiftDeployment(testHD, mw)
+	assert.Nil(t, testHD.Status.NodePools[1].DesiredReplicas, "unset without the desired replicas feedback")
+}
+
+func TestNodePoolStatusSynced(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Spec.HostingNamespace = "clusters"
+	client.Create(ctx, testHD)
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var mw workv1.ManifestWork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+	mw.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{nodePoolFeedback("test1", 2, 2, "True")}
+	assert.Nil(t, client.Status().Update(ctx, &mw), "err nil when the work agent reports the NodePool")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	if assert.Len(t, resultHD.Status.NodePools, 1, "the NodePool status is surfaced") {
+		np := resultHD.Status.NodePools[0]
+		assert.Equal(t, "test1", np.Name)
+		assert.Equal(t, int32(2), *np.DesiredReplicas)
+		assert.Equal(t, int32(2), *np.CurrentReplicas)
+		assert.True(t, meta.IsStatusConditionTrue(np.Conditions, "Ready"), "the NodePool is ready")
+	}
+}
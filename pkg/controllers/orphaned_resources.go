@@ -0,0 +1,64 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+// spokeClockSkewTolerance absorbs the clock difference between the hub and the HostingCluster apiservers
+const spokeClockSkewTolerance = time.Minute
+
+// orphanedSpokeResources lists the HostedCluster and NodePools the work agent found on the HostingCluster instead of
+// creating them. The work agent takes over a resource of the same name, so a resource created before the manifestwork
+// was left behind by a previous deployment, ie a failed run of a HypershiftDeployment of the same name.
+func orphanedSpokeResources(m *workv1.ManifestWork, hyd *hypdeployment.HypershiftDeployment) []string {
+	out := []string{}
+	if m == nil || hyd == nil || m.CreationTimestamp.IsZero() {
+		return out
+	}
+
+	idMap := getManifestWorkConfigs(hyd)
+	createdBefore := m.CreationTimestamp.Add(-spokeClockSkewTolerance)
+
+	for _, obj := range m.Status.ResourceStatus.Manifests {
+		if _, ok := idMap[resourceMeta(obj.ResourceMeta).ToIdentifier()]; !ok {
+			continue
+		}
+
+		for _, v := range obj.StatusFeedbacks.Values {
+			if v.Name != CreationTimestamp || v.Value.String == nil {
+				continue
+			}
+
+			created, err := time.Parse(time.RFC3339, *v.Value.String)
+			if err != nil || !created.Before(createdBefore) {
+				continue
+			}
+
+			out = append(out, fmt.Sprintf("%s %s/%s (created %s)", obj.ResourceMeta.Kind, obj.ResourceMeta.Namespace,
+				obj.ResourceMeta.Name, created.UTC().Format(time.RFC3339)))
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	hyp "github.com/openshift/hypershift/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
+)
+
+func createdFeedback(resource, kind string, testHD *hyd.HypershiftDeployment, name string, created time.Time) workv1.ManifestCondition {
+	ts := created.UTC().Format(time.RFC3339)
+	return workv1.ManifestCondition{
+		ResourceMeta: workv1.ManifestResourceMeta{
+			Group:     hyp.GroupVersion.Group,
+			Resource:  resource,
+			Kind:      kind,
+			Namespace: helper.GetHostingNamespace(testHD),
+			Name:      name,
+		},
+		StatusFeedbacks: workv1.StatusFeedbackResult{Values: []workv1.FeedbackValue{
+			{Name: CreationTimestamp, Value: workv1.FieldValue{Type: workv1.String, String: &ts}},
+		}},
+	}
+}
+
+func TestOrphanedSpokeResources(t *testing.T) {
+	testHD := getHDforManifestWork()
+	workCreated := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	paths := []string{}
+	for id, cfg := range getManifestWorkConfigs(testHD) {
+		if id.Resource == HostedClusterResource {
+			for _, p := range cfg.FeedbackRules[0].JsonPaths {
+				paths = append(paths, p.Name+"="+p.Path)
+			}
+		}
+	}
+	assert.Contains(t, paths, CreationTimestamp+"=.metadata.creationTimestamp", "the creation time is requested")
+
+	mw := &workv1.ManifestWork{}
+	mw.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{
+		createdFeedback(HostedClusterResource, "HostedCluster", testHD, testHD.Name, workCreated.Add(-24*time.Hour)),
+	}
+	assert.Empty(t, orphanedSpokeResources(mw, testHD), "nothing is reported before the manifestwork is created")
+
+	mw.CreationTimestamp = metav1.NewTime(workCreated)
+	mw.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{
+		createdFeedback(HostedClusterResource, "HostedCluster", testHD, testHD.Name, workCreated.Add(-24*time.Hour)),
+		createdFeedback(NodePoolResource, "NodePool", testHD, "test1", workCreated.Add(-30*time.Second)),
+		createdFeedback(NodePoolResource, "NodePool", testHD, "other", workCreated.Add(-24*time.Hour)),
+	}
+	assert.Equal(t, []string{"HostedCluster default/test1 (created 2022-05-31T12:00:00Z)"}, orphanedSpokeResources(mw, testHD),
+		"the HostedCluster created before the manifestwork is reported, the clock skew and the resources of other manifestworks are ignored")
+
+	mw.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{
+		createdFeedback(HostedClusterResource, "HostedCluster", testHD, testHD.Name, workCreated.Add(10*time.Second)),
+		createdFeedback(NodePoolResource, "NodePool", testHD, "test1", workCreated.Add(time.Minute)),
+	}
+	assert.Empty(t, orphanedSpokeResources(mw, testHD), "the resources created by the manifestwork are not reported")
+}
+
+func TestOrphanedSpokeResourcesCondition(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	client.Create(ctx, testHD)
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	// the HostedCluster of a previous run is still on the hosting cluster, the work agent took it over
+	workCreated := time.Now().Truncate(time.Second)
+	var mw workv1.ManifestWork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+	mw.CreationTimestamp = metav1.NewTime(workCreated)
+	assert.Nil(t, client.Update(ctx, &mw), "err nil when the manifestwork is updated")
+	mw.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{
+		createdFeedback(HostedClusterResource, "HostedCluster", testHD, testHD.Name, workCreated.Add(-2*time.Hour)),
+	}
+	assert.Nil(t, client.Status().Update(ctx, &mw), "err nil when the work agent reports the HostedCluster")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	c := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.OrphanedSpokeResources))
+	if assert.NotNil(t, c, "OrphanedSpokeResources condition is reported") {
+		assert.Equal(t, metav1.ConditionTrue, c.Status)
+		assert.Equal(t, hyd.PreExistingResourcesReason, c.Reason)
+		assert.Contains(t, c.Message, "HostedCluster default/test1")
+		assert.Contains(t, c.Message, "must be deleted from the hosting cluster")
+	}
+
+	// once deleted from the hosting cluster, the HostedCluster is recreated by the manifestwork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+	mw.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{
+		createdFeedback(HostedClusterResource, "HostedCluster", testHD, testHD.Name, workCreated.Add(time.Hour)),
+	}
+	assert.Nil(t, client.Status().Update(ctx, &mw), "err nil when the work agent reports the HostedCluster")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.True(t, meta.IsStatusConditionFalse(resultHD.Status.Conditions, string(hyd.OrphanedSpokeResources)), "the condition is resolved")
+}
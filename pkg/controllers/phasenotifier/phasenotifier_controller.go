@@ -0,0 +1,227 @@
+// Copyright Contributors to the Open Cluster Management project.
+
+package phasenotifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
+)
+
+const (
+	defaultFailureThreshold = 3
+	initialBackoff          = 5 * time.Second
+	maxBackoff              = 5 * time.Minute
+	webhookTimeout          = 10 * time.Second
+)
+
+// PhaseTransition is the JSON body posted to the webhook, OldPhase is empty for the first phase of a HypershiftDeployment
+type PhaseTransition struct {
+	Namespace string                     `json:"namespace"`
+	Name      string                     `json:"name"`
+	UID       types.UID                  `json:"uid"`
+	OldPhase  hypdeployment.CurrentPhase `json:"oldPhase,omitempty"`
+	NewPhase  hypdeployment.CurrentPhase `json:"newPhase"`
+}
+
+// Reconciler posts a PhaseTransition to a webhook each time the phase of a HypershiftDeployment changes.
+// A failed callback is retried with an exponential backoff and does not block the other controllers, the
+// last phase notified is kept in the status idempotency keys.
+type Reconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+
+	// URL receives the phase transitions
+	URL string
+	// HTTPClient posts the phase transitions, a client with a 10s timeout is used when nil
+	HTTPClient *http.Client
+	// FailureThreshold is the number of consecutive failed callbacks before the PhaseNotificationFailed
+	// condition is set, 3 when not set
+	FailureThreshold int
+
+	// failures holds, per HypershiftDeployment, the failed callbacks of the pending transition
+	failures sync.Map
+
+	// now is the clock of the retry backoff, time.Now when nil
+	now func() time.Time
+}
+
+type notifyFailure struct {
+	attempts int
+	next     time.Time
+}
+
+//+kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=hypershiftdeployments,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=hypershiftdeployments/status,verbs=get;update;patch
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.Log = log.FromContext(ctx)
+	log := r.Log.WithValues("PhaseNotifierReconciler", req.NamespacedName)
+
+	var hyd hypdeployment.HypershiftDeployment
+	if err := r.Get(ctx, req.NamespacedName, &hyd); err != nil {
+		if k8serrors.IsNotFound(err) {
+			r.failures.Delete(req.NamespacedName)
+			return ctrl.Result{}, nil
+		}
+
+		return ctrl.Result{}, err
+	}
+
+	newPhase := helper.GetPhase(&hyd)
+	if helper.SideEffectDone(&hyd, constant.IdempotencyKeyPhaseNotification, string(newPhase)) {
+		r.failures.Delete(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	// a status change of the HypershiftDeployment must not bypass the backoff of a failing webhook
+	failure := notifyFailure{}
+	if f, ok := r.failures.Load(req.NamespacedName); ok {
+		failure = f.(notifyFailure)
+	}
+	if wait := failure.next.Sub(r.currentTime()); wait > 0 {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	transition := PhaseTransition{
+		Namespace: hyd.Namespace,
+		Name:      hyd.Name,
+		UID:       hyd.UID,
+		OldPhase:  hypdeployment.CurrentPhase(hyd.Status.IdempotencyKeys[constant.IdempotencyKeyPhaseNotification]),
+		NewPhase:  newPhase,
+	}
+
+	if err := r.notify(ctx, transition); err != nil {
+		failure.attempts++
+		backoff := initialBackoff << (failure.attempts - 1)
+		if backoff > maxBackoff || backoff <= 0 {
+			backoff = maxBackoff
+		}
+		failure.next = r.currentTime().Add(backoff)
+		r.failures.Store(req.NamespacedName, failure)
+
+		log.Error(err, "failed to notify the phase transition", "oldPhase", transition.OldPhase, "newPhase", newPhase,
+			"attempts", failure.attempts, "retryAfter", backoff)
+
+		if failure.attempts < r.failureThreshold() {
+			return ctrl.Result{RequeueAfter: backoff}, nil
+		}
+
+		notifyErr := err
+		return ctrl.Result{RequeueAfter: backoff}, r.patchStatus(ctx, req.NamespacedName, func(hyd *hypdeployment.HypershiftDeployment) {
+			meta.SetStatusCondition(&hyd.Status.Conditions, metav1.Condition{
+				Type:               string(hypdeployment.PhaseNotificationFailed),
+				ObservedGeneration: hyd.Generation,
+				Status:             metav1.ConditionTrue,
+				Reason:             hypdeployment.WebhookFailedReason,
+				Message:            fmt.Sprintf("The transition to the %s phase was not notified, err: %v", newPhase, notifyErr),
+			})
+		})
+	}
+
+	log.V(1).Info("Notified the phase transition", "oldPhase", transition.OldPhase, "newPhase", newPhase)
+	r.failures.Delete(req.NamespacedName)
+
+	return ctrl.Result{}, r.patchStatus(ctx, req.NamespacedName, func(hyd *hypdeployment.HypershiftDeployment) {
+		helper.RecordSideEffect(hyd, constant.IdempotencyKeyPhaseNotification, string(newPhase))
+		if meta.IsStatusConditionTrue(hyd.Status.Conditions, string(hypdeployment.PhaseNotificationFailed)) {
+			meta.SetStatusCondition(&hyd.Status.Conditions, metav1.Condition{
+				Type:               string(hypdeployment.PhaseNotificationFailed),
+				ObservedGeneration: hyd.Generation,
+				Status:             metav1.ConditionFalse,
+				Reason:             hypdeployment.AsExpectedReason,
+			})
+		}
+	})
+}
+
+// patchStatus applies the change to the latest HypershiftDeployment. The patch is conditioned on its resourceVersion,
+// a merge patch replaces the whole conditions list and would otherwise drop the conditions the HypershiftDeployment
+// reconciler set in between. A conflict re-reads the HypershiftDeployment, once the retries are spent the error
+// requeues the request
+func (r *Reconciler) patchStatus(ctx context.Context, key types.NamespacedName, mutate func(*hypdeployment.HypershiftDeployment)) error {
+	return client.IgnoreNotFound(retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var hyd hypdeployment.HypershiftDeployment
+		if err := r.Get(ctx, key, &hyd); err != nil {
+			return err
+		}
+
+		patch := client.MergeFromWithOptions(hyd.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		mutate(&hyd)
+
+		return r.Status().Patch(ctx, &hyd, patch)
+	}))
+}
+
+// notify posts the transition to the webhook, any response other than a 2xx is an error
+func (r *Reconciler) notify(ctx context.Context, transition PhaseTransition) error {
+	body, err := json.Marshal(transition)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := r.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: webhookTimeout}
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s responded with %s", r.URL, resp.Status)
+	}
+
+	return nil
+}
+
+func (r *Reconciler) failureThreshold() int {
+	if r.FailureThreshold <= 0 {
+		return defaultFailureThreshold
+	}
+
+	return r.FailureThreshold
+}
+
+func (r *Reconciler) currentTime() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+
+	return time.Now()
+}
+
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&hypdeployment.HypershiftDeployment{}).
+		Named("hypershiftphasenotifier").Complete(r)
+}
@@ -0,0 +1,224 @@
+// Copyright Contributors to the Open Cluster Management project.
+
+package phasenotifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	hydapi "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+)
+
+const HYD_NAMESPACE = "testns"
+
+var s = clientgoscheme.Scheme
+
+var hydKey = types.NamespacedName{Namespace: HYD_NAMESPACE, Name: "test1"}
+
+func init() {
+	clientgoscheme.AddToScheme(s)
+
+	hydapi.AddToScheme(s)
+}
+
+func GetHypershiftDeployment() *hydapi.HypershiftDeployment {
+	return &hydapi.HypershiftDeployment{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      hydKey.Name,
+			Namespace: hydKey.Namespace,
+			UID:       "uid-1",
+		},
+	}
+}
+
+func GetPhaseNotifierReconciler(url string) *Reconciler {
+	return &Reconciler{
+		Client: clientfake.NewClientBuilder().WithScheme(s).Build(),
+		Log:    ctrl.Log.WithName("controllers").WithName("PhaseNotifierReconciler"),
+		Scheme: s,
+		URL:    url,
+	}
+}
+
+func TestPhaseTransitionCallback(t *testing.T) {
+	ctx := context.Background()
+
+	received := []PhaseTransition{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		transition := PhaseTransition{}
+		assert.Nil(t, json.NewDecoder(req.Body).Decode(&transition), "body is a phase transition")
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		received = append(received, transition)
+	}))
+	defer server.Close()
+
+	r := GetPhaseNotifierReconciler(server.URL)
+	assert.Nil(t, r.Create(ctx, GetHypershiftDeployment()), "hypershift deployment resource is created")
+
+	res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: hydKey})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Zero(t, res.RequeueAfter, "not requeued when the callback succeeded")
+	assert.Equal(t, []PhaseTransition{{Namespace: HYD_NAMESPACE, Name: "test1", UID: "uid-1", NewPhase: hydapi.PhaseProvisioning}}, received)
+
+	// no callback without a phase change
+	_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: hydKey})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Len(t, received, 1, "the phase is notified once")
+
+	var hyd hydapi.HypershiftDeployment
+	assert.Nil(t, r.Get(ctx, hydKey, &hyd), "hypershift deployment resource is retrieved")
+	assert.Equal(t, string(hydapi.PhaseProvisioning), hyd.Status.IdempotencyKeys[constant.IdempotencyKeyPhaseNotification], "notified phase is recorded")
+
+	meta.SetStatusCondition(&hyd.Status.Conditions, v1.Condition{Type: string(hydapi.HostedClusterAvailable), Status: v1.ConditionTrue, Reason: "AsExpected"})
+	assert.Nil(t, r.Status().Update(ctx, &hyd), "hypershift deployment status is updated")
+
+	_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: hydKey})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Len(t, received, 2, "the phase change is notified")
+	assert.Equal(t, hydapi.PhaseProvisioning, received[1].OldPhase)
+	assert.Equal(t, hydapi.PhaseReady, received[1].NewPhase)
+}
+
+func TestPhaseTransitionCallbackFailure(t *testing.T) {
+	ctx := context.Background()
+
+	calls := 0
+	failing := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls++
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	now := time.Date(2022, time.May, 2, 12, 0, 0, 0, time.UTC)
+	r := GetPhaseNotifierReconciler(server.URL)
+	r.now = func() time.Time { return now }
+	assert.Nil(t, r.Create(ctx, GetHypershiftDeployment()), "hypershift deployment resource is created")
+
+	var hyd hydapi.HypershiftDeployment
+	for i, backoff := range []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second} {
+		res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: hydKey})
+		assert.Nil(t, err, "a failed callback is not a reconcile error")
+		assert.Equal(t, backoff, res.RequeueAfter, "the callback is retried with a backoff")
+		assert.Equal(t, i+1, calls)
+
+		// a reconcile during the backoff does not call the webhook
+		res, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: hydKey})
+		assert.Nil(t, err, "err nil when reconcile was successful")
+		assert.Equal(t, backoff, res.RequeueAfter, "the backoff is kept")
+		assert.Equal(t, i+1, calls, "the webhook is not called during the backoff")
+
+		assert.Nil(t, r.Get(ctx, hydKey, &hyd), "hypershift deployment resource is retrieved")
+		cond := meta.FindStatusCondition(hyd.Status.Conditions, string(hydapi.PhaseNotificationFailed))
+		if i < defaultFailureThreshold-1 {
+			assert.Nil(t, cond, "a transient failure is not reported")
+		} else {
+			assert.NotNil(t, cond, "a persistent failure is reported")
+			assert.Equal(t, v1.ConditionTrue, cond.Status)
+			assert.Equal(t, hydapi.WebhookFailedReason, cond.Reason)
+			assert.Contains(t, cond.Message, "503")
+		}
+		assert.Empty(t, hyd.Status.IdempotencyKeys[constant.IdempotencyKeyPhaseNotification], "failed notification is not recorded")
+
+		now = now.Add(backoff)
+	}
+
+	failing = false
+	res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: hydKey})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Zero(t, res.RequeueAfter, "not requeued when the callback succeeded")
+
+	assert.Nil(t, r.Get(ctx, hydKey, &hyd), "hypershift deployment resource is retrieved")
+	assert.Equal(t, string(hydapi.PhaseProvisioning), hyd.Status.IdempotencyKeys[constant.IdempotencyKeyPhaseNotification], "notified phase is recorded")
+	assert.False(t, meta.IsStatusConditionTrue(hyd.Status.Conditions, string(hydapi.PhaseNotificationFailed)), "failure is resolved")
+}
+
+// staleClient returns a stale copy of the HypershiftDeployment on the given Get, like an informer cache lagging behind
+type staleClient struct {
+	client.Client
+	stale   *hydapi.HypershiftDeployment
+	staleAt int
+	gets    int
+}
+
+func (c *staleClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	c.gets++
+	if hyd, ok := obj.(*hydapi.HypershiftDeployment); ok && c.gets == c.staleAt {
+		c.stale.DeepCopyInto(hyd)
+		return nil
+	}
+
+	return c.Client.Get(ctx, key, obj)
+}
+
+func TestPhaseTransitionKeepsConcurrentConditions(t *testing.T) {
+	ctx := context.Background()
+
+	var r *Reconciler
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// the HypershiftDeployment reconciler sets a condition while the webhook is called
+		var hyd hydapi.HypershiftDeployment
+		assert.Nil(t, r.Get(ctx, hydKey, &hyd), "hypershift deployment resource is retrieved")
+		meta.SetStatusCondition(&hyd.Status.Conditions, v1.Condition{Type: string(hydapi.WorkConfigured), Status: v1.ConditionTrue, Reason: "AsExpected"})
+		assert.Nil(t, r.Status().Update(ctx, &hyd), "hypershift deployment status is updated")
+	}))
+	defer server.Close()
+
+	r = GetPhaseNotifierReconciler(server.URL)
+	assert.Nil(t, r.Create(ctx, GetHypershiftDeployment()), "hypershift deployment resource is created")
+
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: hydKey})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var hyd hydapi.HypershiftDeployment
+	assert.Nil(t, r.Get(ctx, hydKey, &hyd), "hypershift deployment resource is retrieved")
+	assert.Equal(t, string(hydapi.PhaseProvisioning), hyd.Status.IdempotencyKeys[constant.IdempotencyKeyPhaseNotification], "notified phase is recorded")
+	assert.True(t, meta.IsStatusConditionTrue(hyd.Status.Conditions, string(hydapi.WorkConfigured)), "the condition set during the callback is kept")
+}
+
+func TestPhaseTransitionStatusConflict(t *testing.T) {
+	ctx := context.Background()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	defer server.Close()
+
+	r := GetPhaseNotifierReconciler(server.URL)
+	assert.Nil(t, r.Create(ctx, GetHypershiftDeployment()), "hypershift deployment resource is created")
+
+	// the failure is resolved by the patch, so the patch carries the conditions
+	var hyd hydapi.HypershiftDeployment
+	assert.Nil(t, r.Get(ctx, hydKey, &hyd), "hypershift deployment resource is retrieved")
+	meta.SetStatusCondition(&hyd.Status.Conditions, v1.Condition{Type: string(hydapi.PhaseNotificationFailed), Status: v1.ConditionTrue, Reason: hydapi.WebhookFailedReason})
+	assert.Nil(t, r.Status().Update(ctx, &hyd), "hypershift deployment status is updated")
+
+	stale := hyd.DeepCopy()
+	meta.SetStatusCondition(&hyd.Status.Conditions, v1.Condition{Type: string(hydapi.WorkConfigured), Status: v1.ConditionTrue, Reason: "AsExpected"})
+	assert.Nil(t, r.Status().Update(ctx, &hyd), "hypershift deployment status is updated")
+
+	// the status patch reads the stale copy first, it conflicts and is applied to the latest one
+	r.Client = &staleClient{Client: r.Client, stale: stale, staleAt: 2}
+
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: hydKey})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	assert.Nil(t, r.Get(ctx, hydKey, &hyd), "hypershift deployment resource is retrieved")
+	assert.Equal(t, string(hydapi.PhaseProvisioning), hyd.Status.IdempotencyKeys[constant.IdempotencyKeyPhaseNotification], "notified phase is recorded")
+	assert.True(t, meta.IsStatusConditionTrue(hyd.Status.Conditions, string(hydapi.WorkConfigured)), "the condition of the latest copy is kept")
+	assert.False(t, meta.IsStatusConditionTrue(hyd.Status.Conditions, string(hydapi.PhaseNotificationFailed)), "failure is resolved")
+}
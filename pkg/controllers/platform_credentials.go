@@ -0,0 +1,131 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	apifixtures "github.com/openshift/hypershift/api/fixtures"
+	hyp "github.com/openshift/hypershift/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+const azureServicePrincipalKey = "osServicePrincipal.json"
+
+// missingPlatformCredentials lists the credentials the platform of the HostedCluster needs from the hub and that
+// are not there:
+//   - AWS, the credential secrets of a HostedClusterRef are copied from the HypershiftDeployment namespace, the
+//     ones of a HostedClusterSpec are generated from spec.credentials.aws
+//   - Azure, the service principal of the provider secret, unless the HostedCluster authenticates with the
+//     managed identity of spec.credentials.azureIdentity and the infrastructure is not configured
+func (r *HypershiftDeploymentReconciler) missingPlatformCredentials(ctx context.Context, hyd *hypdeployment.HypershiftDeployment,
+	providerSecret *corev1.Secret) ([]string, error) {
+	hcSpec := hyd.Spec.HostedClusterSpec
+	fromRef := !hyd.Spec.Infrastructure.Configure && len(hyd.Spec.HostedClusterRef.Name) != 0
+	if fromRef {
+		// OK to use typed client since it's just for validation
+		hc := &hyp.HostedCluster{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: hyd.Namespace, Name: hyd.Spec.HostedClusterRef.Name}, hc); err != nil {
+			// a missing HostedClusterRef is reported by the payload rendering
+			return nil, client.IgnoreNotFound(err)
+		}
+		hcSpec = &hc.Spec
+	}
+
+	if hcSpec == nil {
+		return nil, nil
+	}
+
+	missing := []string{}
+	switch hcSpec.Platform.Type {
+	case hyp.AWSPlatform:
+		if !fromRef || hcSpec.Platform.AWS == nil {
+			return nil, nil
+		}
+
+		for _, ref := range []struct {
+			field string
+			name  string
+		}{
+			{"controlPlaneOperatorCreds", hcSpec.Platform.AWS.ControlPlaneOperatorCreds.Name},
+			{"kubeCloudControllerCreds", hcSpec.Platform.AWS.KubeCloudControllerCreds.Name},
+			{"nodePoolManagementCreds", hcSpec.Platform.AWS.NodePoolManagementCreds.Name},
+		} {
+			if len(ref.name) == 0 {
+				missing = append(missing, fmt.Sprintf("platform.aws.%s is not set", ref.field))
+				continue
+			}
+
+			if err := r.Get(ctx, types.NamespacedName{Namespace: hyd.Namespace, Name: ref.name}, &corev1.Secret{}); err != nil {
+				if !apierrors.IsNotFound(err) {
+					return nil, err
+				}
+				missing = append(missing, fmt.Sprintf("secret %s/%s of platform.aws.%s", hyd.Namespace, ref.name, ref.field))
+			}
+		}
+
+	case hyp.AzurePlatform:
+		if azureIdentity(hyd) != nil && !hyd.Spec.Infrastructure.Configure {
+			return nil, nil
+		}
+
+		missing = append(missing, missingAzureServicePrincipal(providerSecret)...)
+	}
+
+	return missing, nil
+}
+
+// missingAzureServicePrincipal lists the fields of the service principal missing from the provider secret
+func missingAzureServicePrincipal(providerSecret *corev1.Secret) []string {
+	if providerSecret == nil || len(providerSecret.Name) == 0 {
+		return []string{"the provider secret of spec.infrastructure.cloudProvider"}
+	}
+
+	raw, ok := providerSecret.Data[azureServicePrincipalKey]
+	if !ok {
+		return []string{fmt.Sprintf("%s of secret %s/%s", azureServicePrincipalKey, providerSecret.Namespace, providerSecret.Name)}
+	}
+
+	creds := &apifixtures.AzureCreds{}
+	if err := json.Unmarshal(raw, creds); err != nil {
+		return []string{fmt.Sprintf("a valid %s in secret %s/%s", azureServicePrincipalKey, providerSecret.Namespace, providerSecret.Name)}
+	}
+
+	missing := []string{}
+	for _, field := range []struct {
+		name  string
+		value string
+	}{
+		{"subscriptionId", creds.SubscriptionID},
+		{"tenantId", creds.TenantID},
+		{"clientId", creds.ClientID},
+		{"clientSecret", creds.ClientSecret},
+	} {
+		if len(field.value) == 0 {
+			missing = append(missing, fmt.Sprintf("%s in %s of secret %s/%s", field.name, azureServicePrincipalKey, providerSecret.Namespace, providerSecret.Name))
+		}
+	}
+
+	return missing
+}
@@ -0,0 +1,243 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	hyp "github.com/openshift/hypershift/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+)
+
+func TestMissingAzureServicePrincipal(t *testing.T) {
+	cases := []struct {
+		name      string
+		principal []byte
+		missing   []string
+	}{
+		{"complete", getProviderSecret().Data[azureServicePrincipalKey], []string{}},
+		{"no service principal", nil, []string{"osServicePrincipal.json of secret default/providersecret"}},
+		{"not json", []byte("not-json"), []string{"a valid osServicePrincipal.json in secret default/providersecret"}},
+		{"missing fields", []byte(`{"clientId":"00000000-0000-0000-0000-000000000000","tenantId":"00000000-0000-0000-0000-000000000000"}`), []string{
+			"subscriptionId in osServicePrincipal.json of secret default/providersecret",
+			"clientSecret in osServicePrincipal.json of secret default/providersecret",
+		}},
+	}
+
+	for _, c := range cases {
+		providerSecret := getProviderSecret()
+		delete(providerSecret.Data, azureServicePrincipalKey)
+		if c.principal != nil {
+			providerSecret.Data[azureServicePrincipalKey] = c.principal
+		}
+
+		assert.Equal(t, c.missing, missingAzureServicePrincipal(providerSecret), c.name)
+	}
+
+	assert.Len(t, missingAzureServicePrincipal(&corev1.Secret{}), 1, "the provider secret is required")
+}
+
+func TestAzurePlatformCredentials(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHypershiftDeployment("default", "test1", false)
+	testHD.Spec.InfraID = "test1-abcde"
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Spec.Infrastructure.CloudProvider.Name = getProviderSecret().Name
+	testHD.Spec.Infrastructure.Platform = &hyd.Platforms{Azure: &hyd.AzurePlatform{}}
+	ScaffoldAzureHostedClusterSpec(testHD, getAzureInfrastructureOut())
+	ScaffoldAzureNodePoolSpec(testHD, getAzureInfrastructureOut())
+	// the subscription comes from the provider secret when the infrastructure is configured
+	testHD.Spec.HostedClusterSpec.Platform.Azure.SubscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	providerSecret := getProviderSecret()
+	providerSecret.Data[azureServicePrincipalKey] = []byte(`{"clientId":"00000000-0000-0000-0000-000000000000","tenantId":"00000000-0000-0000-0000-000000000000","subscriptionId":"00000000-0000-0000-0000-000000000000"}`)
+	client.Create(ctx, providerSecret)
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	// missing credentials
+	res, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when the credentials are reported")
+	assert.NotZero(t, res.RequeueAfter, "requeued until the credentials are found")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	c := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.MissingPlatformCredentials))
+	assert.NotNil(t, c, "MissingPlatformCredentials condition is set")
+	assert.Equal(t, metav1.ConditionTrue, c.Status, "credentials are missing")
+	assert.Equal(t, hyd.MisConfiguredReason, c.Reason)
+	assert.Equal(t, "Missing platform credentials: clientSecret in osServicePrincipal.json of secret default/providersecret", c.Message)
+
+	var mw workv1.ManifestWork
+	assert.NotNil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "manifestwork is not created without the credentials")
+
+	// present credentials
+	assert.Nil(t, client.Get(ctx, types.NamespacedName{Namespace: providerSecret.Namespace, Name: providerSecret.Name}, providerSecret))
+	providerSecret.Data[azureServicePrincipalKey] = getProviderSecret().Data[azureServicePrincipalKey]
+	assert.Nil(t, client.Update(ctx, providerSecret), "is nil when the provider secret is updated")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "manifestwork is created with the credentials")
+
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	c = meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.MissingPlatformCredentials))
+	assert.Equal(t, metav1.ConditionFalse, c.Status, "no credential is missing")
+}
+
+func TestAzureIdentityPlatformCredentials(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHypershiftDeployment("default", "test1", false)
+	testHD.Spec.InfraID = "test1-abcde"
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Spec.Infrastructure.Platform = &hyd.Platforms{Azure: &hyd.AzurePlatform{}}
+	testHD.Spec.Credentials = &hyd.CredentialARNs{AzureIdentity: &hyd.AzureIdentity{
+		ClientID:       "11111111-1111-1111-1111-111111111111",
+		TenantID:       "22222222-2222-2222-2222-222222222222",
+		SubscriptionID: "33333333-3333-3333-3333-333333333333",
+	}}
+	ScaffoldAzureHostedClusterSpec(testHD, getAzureInfrastructureOut())
+	ScaffoldAzureNodePoolSpec(testHD, getAzureInfrastructureOut())
+	testHD.Spec.HostedClusterSpec.Platform.Azure.SubscriptionID = "33333333-3333-3333-3333-333333333333"
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	// no provider secret is needed with a managed identity
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var mw workv1.ManifestWork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "manifestwork is created with the managed identity")
+
+	hcFound := false
+	for _, m := range mw.Spec.Workload.Manifests {
+		u := &unstructured.Unstructured{}
+		assert.Nil(t, u.UnmarshalJSON(m.Raw), "is nil when the manifest is read")
+		switch u.GetKind() {
+		case "Secret":
+			_, found, _ := unstructured.NestedString(u.Object, "data", "AZURE_CLIENT_SECRET")
+			assert.False(t, found, "the azure credentials secret %s is not copied", u.GetName())
+		case "HostedCluster":
+			hcFound = true
+			_, found, _ := unstructured.NestedMap(u.Object, "spec", "platform", "azure", "credentials")
+			assert.False(t, found, "the HostedCluster has no credentials secret")
+			assert.Equal(t, "11111111-1111-1111-1111-111111111111", u.GetAnnotations()[constant.AzureIdentityClientIDAnnotation])
+		}
+	}
+	assert.True(t, hcFound, "the HostedCluster is in the manifestwork")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	c := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.MissingPlatformCredentials))
+	if c != nil {
+		assert.Equal(t, metav1.ConditionFalse, c.Status, "no credential is missing")
+	}
+}
+
+func TestAWSPlatformCredentials(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Spec.HostedClusterSpec = nil
+	testHD.Spec.NodePools = nil
+
+	hostedCluster := getHostedCluster(testHD)
+	hostedCluster.Spec.Platform.AWS = &hyp.AWSPlatformSpec{
+		Region:                    "us-east-1",
+		ControlPlaneOperatorCreds: corev1.LocalObjectReference{Name: "cpo-creds"},
+		KubeCloudControllerCreds:  corev1.LocalObjectReference{Name: "kcc-creds"},
+		NodePoolManagementCreds:   corev1.LocalObjectReference{Name: "np-creds"},
+	}
+
+	client.Create(ctx, hostedCluster)
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+	client.Create(ctx, getSecret("cpo-creds"))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+	initFakeClient(hdr, hostedCluster)
+
+	// missing credentials
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when the credentials are reported")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	c := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.MissingPlatformCredentials))
+	assert.NotNil(t, c, "MissingPlatformCredentials condition is set")
+	assert.Equal(t, metav1.ConditionTrue, c.Status, "credentials are missing")
+	assert.Equal(t, "Missing platform credentials: secret default/kcc-creds of platform.aws.kubeCloudControllerCreds, "+
+		"secret default/np-creds of platform.aws.nodePoolManagementCreds", c.Message)
+
+	var mw workv1.ManifestWork
+	assert.NotNil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "manifestwork is not created without the credentials")
+
+	// present credentials
+	client.Create(ctx, getSecret("kcc-creds"))
+	client.Create(ctx, getSecret("np-creds"))
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "manifestwork is created with the credentials")
+
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	c = meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.MissingPlatformCredentials))
+	assert.Equal(t, metav1.ConditionFalse, c.Status, "no credential is missing")
+}
+
+func TestAWSSpecPlatformCredentials(t *testing.T) {
+	ctx := context.Background()
+	hdr := &HypershiftDeploymentReconciler{
+		Client: initClient(),
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	// the credential secrets of a HostedClusterSpec are generated from spec.credentials.aws
+	testHD := getHDforManifestWork()
+	missing, err := hdr.missingPlatformCredentials(ctx, testHD, &corev1.Secret{})
+	assert.Nil(t, err)
+	assert.Empty(t, missing, "no credential secret is required on the hub")
+
+	testHD = getNonePlatformHD()
+	missing, err = hdr.missingPlatformCredentials(ctx, testHD, &corev1.Secret{})
+	assert.Nil(t, err)
+	assert.Empty(t, missing, "the None platform needs no credentials")
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+)
+
+// priorityQueueFlushInterval is how often the pending requests are checked against the controller queue, for the
+// requests left pending while the requeues of the reconciles fill it
+const priorityQueueFlushInterval = 100 * time.Millisecond
+
+const (
+	reconcilePriorityLow = iota
+	reconcilePriorityNormal
+	reconcilePriorityHigh
+)
+
+// reconcilePriorities maps the values of the ReconcilePriorityLabel to their priority
+var reconcilePriorities = map[string]int{
+	"low":    reconcilePriorityLow,
+	"normal": reconcilePriorityNormal,
+	"high":   reconcilePriorityHigh,
+}
+
+// reconcilePriority returns the priority of the ReconcilePriorityLabel of the object, normal when not set or unknown
+func reconcilePriority(obj client.Object) int {
+	if p, ok := reconcilePriorities[obj.GetLabels()[constant.ReconcilePriorityLabel]]; ok {
+		return p
+	}
+
+	return reconcilePriorityNormal
+}
+
+type pendingRequest struct {
+	priority int
+	seq      uint64
+}
+
+// priorityQueue holds the requests of the watch events and hands them to the controller queue, highest priority
+// first and in arrival order within a priority. The controller queue is only given a request once it is empty, so
+// a request arriving later with a higher priority still goes ahead of the pending ones. A reconcile flushes when it
+// starts, the next request is queued as soon as a worker took the previous one. The requeues of the reconciles go
+// straight to the controller queue, the ordering is best effort.
+type priorityQueue struct {
+	sync.Mutex
+
+	queue   workqueue.Interface
+	pending map[reconcile.Request]pendingRequest
+	seq     uint64
+}
+
+func newPriorityQueue() *priorityQueue {
+	return &priorityQueue{pending: map[reconcile.Request]pendingRequest{}}
+}
+
+// add queues the request, a request already pending keeps its place and the highest of its priorities
+func (p *priorityQueue) add(q workqueue.Interface, req reconcile.Request, priority int) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.queue = q
+
+	if pending, ok := p.pending[req]; ok {
+		if priority > pending.priority {
+			pending.priority = priority
+			p.pending[req] = pending
+		}
+	} else {
+		p.seq++
+		p.pending[req] = pendingRequest{priority: priority, seq: p.seq}
+	}
+
+	p.flushLocked()
+}
+
+// flush hands the next pending request to the controller queue when it is empty
+func (p *priorityQueue) flush() {
+	p.Lock()
+	defer p.Unlock()
+
+	p.flushLocked()
+}
+
+func (p *priorityQueue) flushLocked() {
+	if p.queue == nil || p.queue.Len() != 0 || len(p.pending) == 0 {
+		return
+	}
+
+	var next reconcile.Request
+	var best pendingRequest
+	found := false
+	for req, pending := range p.pending {
+		if !found || pending.priority > best.priority || (pending.priority == best.priority && pending.seq < best.seq) {
+			next, best, found = req, pending, true
+		}
+	}
+
+	delete(p.pending, next)
+	p.queue.Add(next)
+}
+
+// Start hands the pending requests to the controller queue once the requeues drained it, until the context is done
+func (p *priorityQueue) Start(ctx context.Context) error {
+	ticker := time.NewTicker(priorityQueueFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			p.flush()
+		}
+	}
+}
+
+// priorityEnqueue enqueues the HypershiftDeployments an event maps to through the priority queue of the reconciler
+type priorityEnqueue struct {
+	r          *HypershiftDeploymentReconciler
+	toRequests handler.MapFunc
+}
+
+var _ handler.EventHandler = &priorityEnqueue{}
+
+func (e *priorityEnqueue) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.enqueue(evt.Object, q)
+}
+
+func (e *priorityEnqueue) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.enqueue(evt.ObjectOld, q)
+	e.enqueue(evt.ObjectNew, q)
+}
+
+func (e *priorityEnqueue) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.enqueue(evt.Object, q)
+}
+
+func (e *priorityEnqueue) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.enqueue(evt.Object, q)
+}
+
+func (e *priorityEnqueue) enqueue(obj client.Object, q workqueue.RateLimitingInterface) {
+	if obj == nil {
+		return
+	}
+
+	for _, req := range e.toRequests(obj) {
+		e.r.reconcileQueue().add(q, req, e.r.requestPriority(req, obj))
+	}
+}
+
+// requestPriority returns the priority of the HypershiftDeployment of the request, read from the event object when
+// it is the HypershiftDeployment and from the cache otherwise
+func (r *HypershiftDeploymentReconciler) requestPriority(req reconcile.Request, obj client.Object) int {
+	if hyd, ok := obj.(*hypdeployment.HypershiftDeployment); ok {
+		return reconcilePriority(hyd)
+	}
+
+	hyd := &hypdeployment.HypershiftDeployment{}
+	if err := r.Get(context.TODO(), req.NamespacedName, hyd); err != nil {
+		return reconcilePriorityNormal
+	}
+
+	return reconcilePriority(hyd)
+}
+
+func (r *HypershiftDeploymentReconciler) reconcileQueue() *priorityQueue {
+	r.priorityQueueOnce.Do(func() {
+		r.priorityQueue = newPriorityQueue()
+	})
+
+	return r.priorityQueue
+}
+
+// hypershiftDeploymentOf maps a HypershiftDeployment event to its own request
+func hypershiftDeploymentOf(obj client.Object) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(obj)}}
+}
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+)
+
+func priorityRequest(name string) ctrl.Request {
+	return ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: name}}
+}
+
+// drain runs the worker of the controller queue until it is empty, handing the pending requests as it goes
+func drain(p *priorityQueue, q workqueue.Interface) []string {
+	processed := []string{}
+	for q.Len() != 0 {
+		item, _ := q.Get()
+		processed = append(processed, item.(ctrl.Request).Name)
+		q.Done(item)
+		p.flush()
+	}
+
+	return processed
+}
+
+func TestReconcilePriority(t *testing.T) {
+	hd := getHypershiftDeployment("default", "test1", false)
+	assert.Equal(t, reconcilePriorityNormal, reconcilePriority(hd), "normal when the label is not set")
+
+	for value, priority := range map[string]int{
+		"high":   reconcilePriorityHigh,
+		"normal": reconcilePriorityNormal,
+		"low":    reconcilePriorityLow,
+		"urgent": reconcilePriorityNormal,
+	} {
+		hd.Labels = map[string]string{constant.ReconcilePriorityLabel: value}
+		assert.Equal(t, priority, reconcilePriority(hd), "priority of the label value "+value)
+	}
+}
+
+func TestPriorityQueueOrder(t *testing.T) {
+	q := workqueue.New()
+	defer q.ShutDown()
+	p := newPriorityQueue()
+
+	// the worker takes the first request while the others arrive
+	p.add(q, priorityRequest("low-1"), reconcilePriorityLow)
+	p.add(q, priorityRequest("low-2"), reconcilePriorityLow)
+	p.add(q, priorityRequest("normal-1"), reconcilePriorityNormal)
+	p.add(q, priorityRequest("high-1"), reconcilePriorityHigh)
+	p.add(q, priorityRequest("normal-2"), reconcilePriorityNormal)
+	p.add(q, priorityRequest("high-2"), reconcilePriorityHigh)
+
+	assert.Equal(t, 1, q.Len(), "the controller queue only holds one request")
+	assert.Equal(t, []string{"low-1", "high-1", "high-2", "normal-1", "normal-2", "low-2"}, drain(p, q),
+		"the higher priorities are processed first, in arrival order within a priority")
+
+	// a pending request raised to a higher priority keeps a single entry
+	p.add(q, priorityRequest("first"), reconcilePriorityNormal)
+	p.add(q, priorityRequest("normal-3"), reconcilePriorityNormal)
+	p.add(q, priorityRequest("low-3"), reconcilePriorityLow)
+	p.add(q, priorityRequest("low-3"), reconcilePriorityHigh)
+	p.add(q, priorityRequest("normal-3"), reconcilePriorityLow)
+
+	assert.Equal(t, []string{"first", "low-3", "normal-3"}, drain(p, q), "a pending request keeps its highest priority")
+}
+
+func TestPriorityEnqueue(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	high := getHypershiftDeployment("default", "high", false)
+	high.Labels = map[string]string{constant.ReconcilePriorityLabel: "high"}
+	low := getHypershiftDeployment("default", "low", false)
+	low.Labels = map[string]string{constant.ReconcilePriorityLabel: "low"}
+	normal := getHypershiftDeployment("default", "normal", false)
+	for _, hd := range []*hyd.HypershiftDeployment{high, low, normal} {
+		assert.Nil(t, client.Create(ctx, hd), "err nil when the HypershiftDeployment is created")
+	}
+
+	hdr := &HypershiftDeploymentReconciler{Client: client}
+	hdEvents := &priorityEnqueue{r: hdr, toRequests: hypershiftDeploymentOf}
+	mwEvents := &priorityEnqueue{r: hdr, toRequests: hdr.hypershiftDeploymentOfManifestWork}
+
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer q.ShutDown()
+
+	hdEvents.Create(event.CreateEvent{Object: normal}, q)
+	hdEvents.Create(event.CreateEvent{Object: low}, q)
+
+	// the priority of a manifestwork event is the one of its HypershiftDeployment
+	mw := &workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{
+		Name:        "high",
+		Namespace:   "local-cluster",
+		Annotations: map[string]string{constant.CreatedByHypershiftDeployment: "default" + constant.NamespaceNameSeperator + "high"},
+	}}
+	mwEvents.Update(event.UpdateEvent{ObjectOld: mw, ObjectNew: mw}, q)
+
+	assert.Equal(t, []string{"normal", "high", "low"}, drain(hdr.reconcileQueue(), q),
+		"the HypershiftDeployments are reconciled by priority")
+}
+
+func TestReconcileHandsTheNextPendingRequest(t *testing.T) {
+	hdr := &HypershiftDeploymentReconciler{
+		Client: initClient(),
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	q := workqueue.New()
+	defer q.ShutDown()
+
+	hdr.reconcileQueue().add(q, priorityRequest("first"), reconcilePriorityNormal)
+	hdr.reconcileQueue().add(q, priorityRequest("second"), reconcilePriorityNormal)
+	assert.Equal(t, 1, q.Len(), "the controller queue only holds one request")
+
+	item, _ := q.Get()
+	defer q.Done(item)
+
+	// no flush interval elapses, the reconcile of the first request queues the second one
+	_, err := hdr.Reconcile(context.Background(), item.(ctrl.Request))
+	assert.Nil(t, err, "err nil when the HypershiftDeployment is not found")
+	if assert.Equal(t, 1, q.Len(), "the next pending request is queued when the worker starts a reconcile") {
+		next, _ := q.Get()
+		defer q.Done(next)
+		assert.Equal(t, "second", next.(ctrl.Request).Name)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// defaultReconcileBudgetWindow is the window of the reconcile budget when ReconcileBudgetWindow is not set
+const defaultReconcileBudgetWindow = time.Minute
+
+// reconcileBudget is a token bucket shared by all the HypershiftDeployments. It holds up to size tokens,
+// refilled at size tokens per window, and each manifestwork write takes one. A burst can spend the whole
+// bucket at once, after that the writes are spread over the window.
+type reconcileBudget struct {
+	sync.Mutex
+
+	size   int
+	window time.Duration
+	now    func() time.Time
+
+	tokens   float64
+	refilled time.Time
+}
+
+func newReconcileBudget(size int, window time.Duration, now func() time.Time) *reconcileBudget {
+	if window <= 0 {
+		window = defaultReconcileBudgetWindow
+	}
+
+	return &reconcileBudget{
+		size:     size,
+		window:   window,
+		now:      now,
+		tokens:   float64(size),
+		refilled: now(),
+	}
+}
+
+func (b *reconcileBudget) enabled() bool {
+	return b != nil && b.size > 0
+}
+
+// take returns true and spends a token if one is left, otherwise it returns the time left before
+// the next token is refilled
+func (b *reconcileBudget) take() (bool, time.Duration) {
+	if !b.enabled() {
+		return true, 0
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	now := b.now()
+	if elapsed := now.Sub(b.refilled); elapsed > 0 {
+		b.tokens += float64(b.size) * float64(elapsed) / float64(b.window)
+		if b.tokens > float64(b.size) {
+			b.tokens = float64(b.size)
+		}
+	}
+	b.refilled = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	// round up so the retry does not come back a moment before the token is refilled
+	return false, time.Duration(math.Ceil((1 - b.tokens) * float64(b.window) / float64(b.size)))
+}
+
+func (r *HypershiftDeploymentReconciler) manifestWorkBudget() *reconcileBudget {
+	r.reconcileBudgetOnce.Do(func() {
+		r.reconcileBudget = newReconcileBudget(r.ReconcileBudget, r.ReconcileBudgetWindow, r.currentTime)
+	})
+
+	return r.reconcileBudget
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+func TestReconcileBudget(t *testing.T) {
+	now := time.Now()
+	b := newReconcileBudget(3, time.Minute, func() time.Time { return now })
+
+	for i := 0; i < 3; i++ {
+		allowed, _ := b.take()
+		assert.True(t, allowed, "true while the budget is not spent")
+	}
+
+	allowed, retryAfter := b.take()
+	assert.False(t, allowed, "false once the budget is spent")
+	assert.Equal(t, 20*time.Second, retryAfter, "retry once a token is refilled")
+
+	now = now.Add(10 * time.Second)
+	allowed, retryAfter = b.take()
+	assert.False(t, allowed, "false until a whole token is refilled")
+	assert.Equal(t, 10*time.Second, retryAfter, "the partial refill is kept")
+
+	now = now.Add(10 * time.Second)
+	allowed, _ = b.take()
+	assert.True(t, allowed, "true once a token is refilled")
+
+	// the refill is capped to the size of the budget
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		allowed, _ = b.take()
+		assert.True(t, allowed, "true while the budget is not spent")
+	}
+	allowed, _ = b.take()
+	assert.False(t, allowed, "false once the refilled budget is spent")
+
+	disabled := newReconcileBudget(0, time.Minute, time.Now)
+	for i := 0; i < 5; i++ {
+		allowed, _ = disabled.take()
+		assert.True(t, allowed, "true when the budget is disabled")
+	}
+
+	assert.Equal(t, defaultReconcileBudgetWindow, newReconcileBudget(1, 0, time.Now).window, "the default window is used when not set")
+}
+
+func TestReconcileThrottledCondition(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	now := time.Now()
+	hdr := &HypershiftDeploymentReconciler{
+		Client:                client,
+		Log:                   ctrl.Log.WithName("tester"),
+		ReconcileBudget:       1,
+		ReconcileBudgetWindow: time.Minute,
+		now:                   func() time.Time { return now },
+	}
+
+	throttledCondition := func() *metav1.Condition {
+		var resultHD hyd.HypershiftDeployment
+		assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+		return meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.ReconcileThrottled))
+	}
+
+	res, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Zero(t, res.RequeueAfter, "no requeue within the budget")
+	assert.Nil(t, throttledCondition(), "throttling is not reported within the budget")
+
+	res, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Zero(t, res.RequeueAfter, "an unchanged manifestwork is not written and takes nothing from the budget")
+	assert.Nil(t, throttledCondition(), "throttling is not reported when the manifestwork is unchanged")
+
+	var hd hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &hd), "is nil when HypershiftDeployment resource is found")
+	replicas := int32(5)
+	hd.Spec.NodePools[0].Spec.Replicas = &replicas
+	assert.Nil(t, client.Update(ctx, &hd), "is nil when the NodePool replicas are changed")
+
+	res, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was throttled")
+	assert.Equal(t, time.Minute, res.RequeueAfter, "requeue once the budget refills")
+
+	c := throttledCondition()
+	assert.NotNil(t, c, "ReconcileThrottled condition is reported")
+	assert.Equal(t, metav1.ConditionTrue, c.Status, "true when the budget is exhausted")
+	assert.Equal(t, hyd.BudgetExhaustedReason, c.Reason)
+	assert.Contains(t, c.Message, "1 manifestwork writes per 1m0s")
+
+	// recovery, the refilled budget lets the manifestwork write through
+	now = now.Add(time.Minute)
+	res, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Zero(t, res.RequeueAfter, "no requeue once the budget refilled")
+
+	c = throttledCondition()
+	assert.Equal(t, metav1.ConditionFalse, c.Status, "false once the manifestwork is written")
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/openshift/hypershift/cmd/version"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
+)
+
+const (
+	// releaseCacheTTL is how long a resolved release is used before the release stream is read again
+	releaseCacheTTL = time.Hour
+
+	releaseLookupInitialBackoff = 10 * time.Second
+	releaseLookupMaxBackoff     = 10 * time.Minute
+)
+
+// defaultReleaseResolver caches the latest release of the 4-stable stream for all the HypershiftDeployments
+var defaultReleaseResolver = newReleaseResolver(lookupStableRelease, releaseCacheTTL)
+
+func lookupStableRelease() (string, error) {
+	v, err := version.LookupDefaultOCPVersion()
+	if err != nil {
+		return "", err
+	}
+
+	return v.PullSpec, nil
+}
+
+// releaseResolver caches the release returned by lookup for ttl. A failed lookup is retried with an exponential
+// backoff, meanwhile the last resolved release keeps being returned, stale.
+type releaseResolver struct {
+	mu     sync.Mutex
+	lookup func() (string, error)
+	ttl    time.Duration
+
+	image      string
+	resolvedAt time.Time
+	failures   int
+	retryAt    time.Time
+	lastErr    error
+
+	// now is the clock of the cache, time.Now when nil
+	now func() time.Time
+}
+
+func newReleaseResolver(lookup func() (string, error), ttl time.Duration) *releaseResolver {
+	return &releaseResolver{lookup: lookup, ttl: ttl}
+}
+
+// resolve returns the release and when it was resolved. The cached release is returned until the ttl expires,
+// force reads the release stream again unless a failed lookup is being backed off. When the lookup fails, the
+// cached release, if any, is returned with the error.
+func (rr *releaseResolver) resolve(force bool) (string, time.Time, error) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	now := rr.currentTime()
+	if len(rr.image) != 0 && !force && now.Before(rr.resolvedAt.Add(rr.ttl)) {
+		return rr.image, rr.resolvedAt, nil
+	}
+
+	if now.Before(rr.retryAt) {
+		return rr.image, rr.resolvedAt, rr.lastErr
+	}
+
+	image, err := rr.lookup()
+	if err == nil && len(image) == 0 {
+		err = errors.New("the release stream returned no release")
+	}
+	if err != nil {
+		rr.failures++
+		backoff := releaseLookupInitialBackoff << (rr.failures - 1)
+		if backoff > releaseLookupMaxBackoff || backoff <= 0 {
+			backoff = releaseLookupMaxBackoff
+		}
+		rr.retryAt = now.Add(backoff)
+		rr.lastErr = fmt.Errorf("failed to resolve the latest stable release, retry in %s, err: %w", backoff, err)
+
+		return rr.image, rr.resolvedAt, rr.lastErr
+	}
+
+	rr.image, rr.resolvedAt = image, now
+	rr.failures, rr.retryAt, rr.lastErr = 0, time.Time{}, nil
+
+	return rr.image, rr.resolvedAt, nil
+}
+
+func (rr *releaseResolver) currentTime() time.Time {
+	if rr.now != nil {
+		return rr.now()
+	}
+
+	return time.Now()
+}
+
+func (r *HypershiftDeploymentReconciler) defaultReleaseResolver() *releaseResolver {
+	if r.releaseResolver != nil {
+		return r.releaseResolver
+	}
+
+	return defaultReleaseResolver
+}
+
+// resolveDefaultRelease records in the status the release used to scaffold a HostedCluster or NodePool without
+// release image. A new value of the RefreshReleaseAnnotation bypasses the cache once.
+func (r *HypershiftDeploymentReconciler) resolveDefaultRelease(hyd *hypdeployment.HypershiftDeployment) error {
+	refresh, hasRefresh := hyd.Annotations[constant.RefreshReleaseAnnotation]
+	force := hasRefresh && !helper.SideEffectDone(hyd, constant.IdempotencyKeyReleaseRefresh, refresh)
+	if !force && hyd.Spec.HostedClusterSpec != nil && len(hyd.Spec.NodePools) != 0 {
+		return nil
+	}
+
+	inHyd := hyd.DeepCopy()
+
+	image, resolvedAt, err := r.defaultReleaseResolver().resolve(force)
+	if err != nil {
+		r.Log.Error(err, "using the previously resolved release")
+	}
+
+	if len(image) != 0 {
+		hyd.Status.ReleaseResolution = &hypdeployment.ReleaseResolution{
+			Image:        image,
+			ResolvedTime: metav1.NewTime(resolvedAt),
+			Stale:        err != nil,
+		}
+	}
+	if force {
+		helper.RecordSideEffect(hyd, constant.IdempotencyKeyReleaseRefresh, refresh)
+	}
+
+	if equality.Semantic.DeepEqual(inHyd.Status, hyd.Status) {
+		return nil
+	}
+
+	return r.patchStatus(r.ctx, hyd, inHyd)
+}
+
+// defaultReleaseImage is the release of a HostedCluster or NodePool scaffolded without release image
+func defaultReleaseImage(hyd *hypdeployment.HypershiftDeployment) string {
+	if hyd.Status.ReleaseResolution != nil && len(hyd.Status.ReleaseResolution.Image) != 0 {
+		return hyd.Status.ReleaseResolution.Image
+	}
+
+	return getReleaseImagePullSpec()
+}
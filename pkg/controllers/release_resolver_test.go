@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+)
+
+// fakeReleaseStream returns its image, or its err, and counts the lookups
+type fakeReleaseStream struct {
+	image   string
+	err     error
+	lookups int
+}
+
+func (f *fakeReleaseStream) lookup() (string, error) {
+	f.lookups++
+	return f.image, f.err
+}
+
+func TestReleaseResolverCache(t *testing.T) {
+	stream := &fakeReleaseStream{image: "quay.io/openshift-release-dev/ocp-release:4.10.15-x86_64"}
+	now := time.Date(2022, time.May, 2, 12, 0, 0, 0, time.UTC)
+	rr := newReleaseResolver(stream.lookup, time.Hour)
+	rr.now = func() time.Time { return now }
+
+	image, resolvedAt, err := rr.resolve(false)
+	assert.Nil(t, err, "err nil when the release is resolved")
+	assert.Equal(t, stream.image, image)
+	assert.Equal(t, now, resolvedAt)
+	assert.Equal(t, 1, stream.lookups)
+
+	// cache hit
+	stream.image = "quay.io/openshift-release-dev/ocp-release:4.10.16-x86_64"
+	now = now.Add(30 * time.Minute)
+	image, resolvedAt, err = rr.resolve(false)
+	assert.Nil(t, err, "err nil when the release is cached")
+	assert.Equal(t, "quay.io/openshift-release-dev/ocp-release:4.10.15-x86_64", image, "the cached release is used within the ttl")
+	assert.Equal(t, now.Add(-30*time.Minute), resolvedAt)
+	assert.Equal(t, 1, stream.lookups, "the release stream is not read within the ttl")
+
+	// forced refresh
+	image, _, err = rr.resolve(true)
+	assert.Nil(t, err, "err nil when the release is refreshed")
+	assert.Equal(t, stream.image, image, "the refresh bypasses the cache")
+	assert.Equal(t, 2, stream.lookups)
+
+	// ttl expiry
+	stream.image = "quay.io/openshift-release-dev/ocp-release:4.10.17-x86_64"
+	now = now.Add(time.Hour)
+	image, resolvedAt, err = rr.resolve(false)
+	assert.Nil(t, err, "err nil when the release is resolved")
+	assert.Equal(t, stream.image, image, "the release is resolved again once the ttl expired")
+	assert.Equal(t, now, resolvedAt)
+	assert.Equal(t, 3, stream.lookups)
+}
+
+func TestReleaseResolverFailure(t *testing.T) {
+	stream := &fakeReleaseStream{err: errors.New("release stream unavailable")}
+	now := time.Date(2022, time.May, 2, 12, 0, 0, 0, time.UTC)
+	rr := newReleaseResolver(stream.lookup, time.Hour)
+	rr.now = func() time.Time { return now }
+
+	// nothing cached to fall back to
+	image, _, err := rr.resolve(false)
+	assert.NotNil(t, err, "err when the release stream can not be read")
+	assert.Empty(t, image, "no release without a cache")
+
+	// the failure is backed off, even when forced
+	_, _, err = rr.resolve(true)
+	assert.NotNil(t, err, "err while the failure is backed off")
+	assert.Equal(t, 1, stream.lookups, "the release stream is not read during the backoff")
+
+	now = now.Add(releaseLookupInitialBackoff)
+	stream.image, stream.err = "quay.io/openshift-release-dev/ocp-release:4.10.15-x86_64", nil
+	image, resolvedAt, err := rr.resolve(false)
+	assert.Nil(t, err, "err nil when the release stream recovered")
+	assert.Equal(t, stream.image, image)
+
+	// the cached release is used while the release stream fails
+	now = now.Add(2 * time.Hour)
+	stream.image, stream.err = "", errors.New("release stream unavailable")
+	for i, backoff := range []time.Duration{releaseLookupInitialBackoff, 2 * releaseLookupInitialBackoff, 4 * releaseLookupInitialBackoff} {
+		image, staleAt, err := rr.resolve(false)
+		assert.NotNil(t, err, "err when the release stream can not be read")
+		assert.Contains(t, err.Error(), backoff.String(), "the retry backs off")
+		assert.Equal(t, "quay.io/openshift-release-dev/ocp-release:4.10.15-x86_64", image, "the stale release is used")
+		assert.Equal(t, resolvedAt, staleAt, "the resolution time of the stale release is kept")
+		assert.Equal(t, i+3, stream.lookups)
+
+		now = now.Add(backoff)
+	}
+
+	// an empty release is a failure
+	stream.err = nil
+	image, _, err = rr.resolve(false)
+	assert.NotNil(t, err, "err when the release stream returns no release")
+	assert.Equal(t, "quay.io/openshift-release-dev/ocp-release:4.10.15-x86_64", image, "the stale release is used")
+}
+
+func TestResolveDefaultReleaseStatus(t *testing.T) {
+	r := GetHypershiftDeploymentReconciler()
+	ctx := context.Background()
+
+	stream := &fakeReleaseStream{image: "quay.io/openshift-release-dev/ocp-release:4.10.15-x86_64"}
+	now := time.Date(2022, time.May, 2, 12, 0, 0, 0, time.UTC)
+	r.releaseResolver = newReleaseResolver(stream.lookup, time.Hour)
+	r.releaseResolver.now = func() time.Time { return now }
+
+	testHD := getHypershiftDeployment("default", "test1", true)
+	testHD.Spec.HostedClusterSpec = nil
+	testHD.Spec.NodePools = nil
+	testHD.Spec.Infrastructure.Platform = &hyd.Platforms{AWS: &hyd.AWSPlatform{Region: "us-east-1"}}
+	assert.Nil(t, r.Create(ctx, testHD), "hypershift deployment resource is created")
+
+	key := types.NamespacedName{Namespace: testHD.Namespace, Name: testHD.Name}
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, r.Get(ctx, key, &resultHD))
+	assert.Nil(t, r.resolveDefaultRelease(&resultHD), "err nil when the release is resolved")
+
+	assert.Nil(t, r.Get(ctx, key, &resultHD))
+	assert.NotNil(t, resultHD.Status.ReleaseResolution, "the resolved release is in the status")
+	assert.Equal(t, stream.image, resultHD.Status.ReleaseResolution.Image)
+	assert.True(t, now.Equal(resultHD.Status.ReleaseResolution.ResolvedTime.Time), "the resolution time is in the status")
+	assert.False(t, resultHD.Status.ReleaseResolution.Stale)
+
+	// the scaffolding uses the resolved release
+	resultHD.Spec.InfraID = "test1-abcde"
+	ScaffoldAWSHostedClusterSpec(&resultHD, getAWSInfrastructureOut())
+	ScaffoldAWSNodePoolSpec(&resultHD, getAWSInfrastructureOut())
+	assert.Equal(t, stream.image, resultHD.Spec.HostedClusterSpec.Release.Image, "the HostedCluster uses the resolved release")
+	assert.Equal(t, stream.image, resultHD.Spec.NodePools[0].Spec.Release.Image, "the NodePool uses the resolved release")
+	assert.Nil(t, r.Update(ctx, &resultHD), "hypershift deployment resource is updated")
+
+	// nothing to resolve once scaffolded
+	stream.image = "quay.io/openshift-release-dev/ocp-release:4.10.16-x86_64"
+	now = now.Add(2 * time.Hour)
+	assert.Nil(t, r.resolveDefaultRelease(&resultHD))
+	assert.Equal(t, 1, stream.lookups, "the release stream is not read once scaffolded")
+
+	// the refresh annotation bypasses the cache once per value
+	resultHD.Annotations = map[string]string{constant.RefreshReleaseAnnotation: "1"}
+	assert.Nil(t, r.Update(ctx, &resultHD), "hypershift deployment resource is updated")
+	assert.Nil(t, r.resolveDefaultRelease(&resultHD), "err nil when the release is refreshed")
+	assert.Nil(t, r.resolveDefaultRelease(&resultHD), "err nil when the refresh is already done")
+	assert.Equal(t, 2, stream.lookups, "the release stream is read once for the refresh")
+
+	assert.Nil(t, r.Get(ctx, key, &resultHD))
+	assert.Equal(t, stream.image, resultHD.Status.ReleaseResolution.Image, "the refreshed release is in the status")
+	assert.Equal(t, "1", resultHD.Status.IdempotencyKeys[constant.IdempotencyKeyReleaseRefresh], "the refresh is recorded")
+
+	// a failed refresh keeps the stale release
+	stream.err = errors.New("release stream unavailable")
+	resultHD.Annotations[constant.RefreshReleaseAnnotation] = "2"
+	assert.Nil(t, r.Update(ctx, &resultHD), "hypershift deployment resource is updated")
+	assert.Nil(t, r.resolveDefaultRelease(&resultHD), "err nil when the stale release is used")
+
+	assert.Nil(t, r.Get(ctx, key, &resultHD))
+	assert.Equal(t, stream.image, resultHD.Status.ReleaseResolution.Image, "the stale release is kept")
+	assert.True(t, resultHD.Status.ReleaseResolution.Stale, "the release is reported stale")
+}
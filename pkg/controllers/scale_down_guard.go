@@ -0,0 +1,77 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
+)
+
+// scaleDownConfirmed is true when the ConfirmScaleDownAnnotation has a value not applied yet
+func scaleDownConfirmed(hyd *hypdeployment.HypershiftDeployment) (string, bool) {
+	token, ok := hyd.GetAnnotations()[constant.ConfirmScaleDownAnnotation]
+	return token, ok && !helper.SideEffectDone(hyd, constant.IdempotencyKeyScaleDownConfirmation, token)
+}
+
+// blockLargeScaleDowns keeps the applied replicas in the payload for the NodePools whose replicas are reduced by more
+// than percent of the applied replicas. The autoscaled NodePools and the smaller reductions are left as is. It
+// returns a description of each blocked reduction.
+func blockLargeScaleDowns(applied []workv1.Manifest, payload []workv1.Manifest, percent int) ([]string, error) {
+	existing := map[string]*unstructured.Unstructured{}
+	for _, m := range applied {
+		u, err := manifestToUnstructured(m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the applied manifestwork payload, err: %w", err)
+		}
+
+		if u.GetKind() == "NodePool" {
+			existing[u.GetName()] = u
+		}
+	}
+
+	blocked := []string{}
+	for _, m := range payload {
+		u, ok := m.Object.(*unstructured.Unstructured)
+		if !ok || u.GetKind() != "NodePool" {
+			continue
+		}
+
+		old, ok := existing[u.GetName()]
+		if !ok {
+			continue
+		}
+
+		oldReplicas, oldFound, _ := unstructured.NestedInt64(old.Object, "spec", "replicas")
+		newReplicas, newFound, _ := unstructured.NestedInt64(u.Object, "spec", "replicas")
+		if !oldFound || !newFound || (oldReplicas-newReplicas)*100 <= oldReplicas*int64(percent) {
+			continue
+		}
+
+		if err := unstructured.SetNestedField(u.Object, oldReplicas, "spec", "replicas"); err != nil {
+			return nil, err
+		}
+		blocked = append(blocked, fmt.Sprintf("NodePool %s scale down from %d to %d replicas", u.GetName(), oldReplicas, newReplicas))
+	}
+
+	return blocked, nil
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+)
+
+func nodePoolReplicasManifest(name string, replicas int64) workv1.Manifest {
+	m := nodePoolManifest(name)
+	unstructured.SetNestedField(m.Object.(*unstructured.Unstructured).Object, replicas, "spec", "replicas")
+	return m
+}
+
+func TestBlockLargeScaleDowns(t *testing.T) {
+	applied := []workv1.Manifest{
+		nodePoolReplicasManifest("np-small", 10),
+		nodePoolReplicasManifest("np-large", 50),
+		nodePoolManifest("np-autoscaled"),
+	}
+	payload := []workv1.Manifest{
+		nodePoolReplicasManifest("np-small", 6),
+		nodePoolReplicasManifest("np-large", 5),
+		nodePoolReplicasManifest("np-autoscaled", 1),
+		nodePoolReplicasManifest("np-new", 1),
+	}
+
+	blocked, err := blockLargeScaleDowns(applied, payload, 50)
+	assert.Nil(t, err, "err nil when the payload is readable")
+	assert.Equal(t, []string{"NodePool np-large scale down from 50 to 5 replicas"}, blocked, "only the reduction over 50% is blocked")
+
+	replicas := map[string]int64{}
+	for _, m := range payload {
+		u := m.Object.(*unstructured.Unstructured)
+		replicas[u.GetName()], _, _ = unstructured.NestedInt64(u.Object, "spec", "replicas")
+	}
+	assert.Equal(t, map[string]int64{"np-small": 6, "np-large": 50, "np-autoscaled": 1, "np-new": 1}, replicas,
+		"the blocked NodePool keeps its applied replicas")
+}
+
+func TestLargeScaleDownBlocked(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	replicas := int32(10)
+	testHD.Spec.NodePools[0].Spec.Replicas = &replicas
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client:                client,
+		Log:                   ctrl.Log.WithName("tester"),
+		ScaleDownGuardPercent: 50,
+	}
+
+	scaleTo := func(count int32) (*hyd.HypershiftDeployment, int64) {
+		var resultHD hyd.HypershiftDeployment
+		assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+		if count != 0 {
+			resultHD.Spec.NodePools[0].Spec.Replicas = &count
+			assert.Nil(t, client.Update(ctx, &resultHD), "HypershiftDeployment resource is updated")
+		}
+
+		_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+		assert.Nil(t, err, "err nil when reconcile was successful")
+
+		var mw workv1.ManifestWork
+		assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+		nodePools := payloadKinds(t, mw.Spec.Workload.Manifests)["NodePool"]
+		assert.Len(t, nodePools, 1)
+		applied, _, _ := unstructured.NestedInt64(nodePools[0].Object, "spec", "replicas")
+
+		assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+		return &resultHD, applied
+	}
+
+	_, applied := scaleTo(0)
+	assert.Equal(t, int64(10), applied, "the NodePool is created with its replicas")
+
+	// a small reduction is applied
+	resultHD, applied := scaleTo(7)
+	assert.Equal(t, int64(7), applied, "the reduction within the guard is applied")
+	assert.Nil(t, meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.LargeScaleDownBlocked)), "no scale down is blocked")
+
+	// a large reduction keeps the applied replicas
+	resultHD, applied = scaleTo(1)
+	assert.Equal(t, int64(7), applied, "the reduction over the guard is blocked")
+	c := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.LargeScaleDownBlocked))
+	if assert.NotNil(t, c, "LargeScaleDownBlocked condition is reported") {
+		assert.Equal(t, metav1.ConditionTrue, c.Status)
+		assert.Equal(t, hyd.ConfirmationRequiredReason, c.Reason)
+		assert.Contains(t, c.Message, "from 7 to 1 replicas")
+	}
+
+	// the confirmation applies the reduction once
+	resultHD.Annotations = map[string]string{constant.ConfirmScaleDownAnnotation: "1"}
+	assert.Nil(t, client.Update(ctx, resultHD), "HypershiftDeployment resource is updated")
+
+	resultHD, applied = scaleTo(0)
+	assert.Equal(t, int64(1), applied, "the confirmed reduction is applied")
+	assert.True(t, meta.IsStatusConditionFalse(resultHD.Status.Conditions, string(hyd.LargeScaleDownBlocked)), "the scale down is no longer blocked")
+	assert.Equal(t, "1", resultHD.Status.IdempotencyKeys[constant.IdempotencyKeyScaleDownConfirmation], "the confirmation is recorded")
+
+	_, applied = scaleTo(10)
+	assert.Equal(t, int64(10), applied, "a scale up is applied")
+
+	_, applied = scaleTo(2)
+	assert.Equal(t, int64(10), applied, "a confirmation already applied does not confirm the next reduction")
+}
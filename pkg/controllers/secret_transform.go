@@ -0,0 +1,91 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+)
+
+// SecretTransformer rewrites a secret before it is added to the manifestwork payload, ie to seal its data
+// with a key held by the HostingCluster so it is not shipped in cleartext. The returned object is applied
+// on the HostingCluster instead of the secret, and the HostingCluster must turn it back into the secret.
+// Transform should return the same object for an unchanged secret, otherwise the manifestwork is updated
+// on every reconcile.
+type SecretTransformer interface {
+	Transform(secret *corev1.Secret) (runtime.Object, error)
+}
+
+// transformPayloadSecrets replaces the secrets of the payload with the objects returned by the SecretTransformer,
+// the secrets are kept in cleartext when no SecretTransformer is configured
+func (r *HypershiftDeploymentReconciler) transformPayloadSecrets(payload []workv1.Manifest) error {
+	if r.SecretTransformer == nil {
+		return nil
+	}
+
+	for i, m := range payload {
+		secret, ok := m.Object.(*corev1.Secret)
+		if !ok {
+			continue
+		}
+
+		out, err := r.SecretTransformer.Transform(secret)
+		if err != nil {
+			return fmt.Errorf("failed to transform the secret %s/%s, err: %w", secret.Namespace, secret.Name, err)
+		}
+
+		// the annotation finds the transformed secret in the manifestwork, when its origin is not on the hub
+		accessor, err := meta.Accessor(out)
+		if err != nil {
+			return fmt.Errorf("transformed secret %s/%s has no metadata, err: %w", secret.Namespace, secret.Name, err)
+		}
+
+		annotations := accessor.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[constant.TransformedSecretAnnotation] = secret.Name
+		accessor.SetAnnotations(annotations)
+
+		payload[i] = workv1.Manifest{RawExtension: runtime.RawExtension{Object: out}}
+	}
+
+	return nil
+}
+
+// getTransformedSecretInManifestPayload returns the object a secret was transformed to, nil if not found
+func getTransformedSecretInManifestPayload(manifests *[]workv1.Manifest, secretName string) (*unstructured.Unstructured, error) {
+	for _, m := range *manifests {
+		u, err := manifestToUnstructured(m)
+		if err != nil {
+			return nil, err
+		}
+
+		if u.GetKind() != "Secret" && u.GetAnnotations()[constant.TransformedSecretAnnotation] == secretName {
+			return u, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+)
+
+// sealingTransformer turns the secrets into a sealed-secret style object, the data is only encoded
+type sealingTransformer struct{}
+
+func (sealingTransformer) Transform(secret *corev1.Secret) (runtime.Object, error) {
+	encrypted := map[string]interface{}{}
+	for k, v := range secret.Data {
+		encrypted[k] = "sealed:" + base64.StdEncoding.EncodeToString([]byte(strings.ToUpper(string(v))))
+	}
+
+	sealed := &unstructured.Unstructured{}
+	sealed.SetAPIVersion("bitnami.com/v1alpha1")
+	sealed.SetKind("SealedSecret")
+	sealed.SetName(secret.Name)
+	sealed.SetNamespace(secret.Namespace)
+	if err := unstructured.SetNestedMap(sealed.Object, encrypted, "spec", "encryptedData"); err != nil {
+		return nil, err
+	}
+
+	return sealed, nil
+}
+
+func payloadKinds(t *testing.T, manifests []workv1.Manifest) map[string][]*unstructured.Unstructured {
+	kinds := map[string][]*unstructured.Unstructured{}
+	for _, m := range manifests {
+		u, err := manifestToUnstructured(m)
+		assert.Nil(t, err, "err nil when the manifest is readable")
+		kinds[u.GetKind()] = append(kinds[u.GetKind()], u)
+	}
+
+	return kinds
+}
+
+func TestSecretTransformer(t *testing.T) {
+	ctx := context.Background()
+
+	for _, transformer := range []SecretTransformer{nil, sealingTransformer{}} {
+		client := initClient()
+
+		testHD := getHDforManifestWork()
+		testHD.Spec.HostingCluster = "local-cluster"
+
+		client.Create(ctx, testHD)
+		defer client.Delete(ctx, testHD)
+
+		pullSecret := getPullSecret(testHD)
+		client.Create(ctx, pullSecret)
+
+		hdr := &HypershiftDeploymentReconciler{
+			Client:            client,
+			Log:               ctrl.Log.WithName("tester"),
+			SecretTransformer: transformer,
+		}
+
+		_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+		assert.Nil(t, err, "err nil when reconcile was successful")
+
+		var mw workv1.ManifestWork
+		assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found")
+
+		raw, err := json.Marshal(mw.Spec.Workload.Manifests)
+		assert.Nil(t, err, "err nil when the payload is marshalled")
+
+		kinds := payloadKinds(t, mw.Spec.Workload.Manifests)
+		if transformer == nil {
+			assert.NotEmpty(t, kinds["Secret"], "secrets are propagated in cleartext by default")
+			assert.Empty(t, kinds["SealedSecret"], "secrets are not transformed by default")
+			continue
+		}
+
+		assert.Empty(t, kinds["Secret"], "no secret is propagated in cleartext")
+		assert.NotEmpty(t, kinds["SealedSecret"], "secrets are transformed")
+		for _, v := range pullSecret.Data {
+			assert.False(t, strings.Contains(string(raw), base64.StdEncoding.EncodeToString(v)), "secret value is not in the manifestwork")
+		}
+
+		found := false
+		for _, sealed := range kinds["SealedSecret"] {
+			if sealed.GetName() != pullSecret.Name {
+				continue
+			}
+
+			found = true
+			assert.Equal(t, pullSecret.Name, sealed.GetAnnotations()[constant.TransformedSecretAnnotation], "transformed object is annotated with the secret name")
+			data, _, _ := unstructured.NestedStringMap(sealed.Object, "spec", "encryptedData")
+			assert.Len(t, data, len(pullSecret.Data), "every key of the secret is transformed")
+		}
+		assert.True(t, found, "pull secret is transformed")
+	}
+}
+
+func TestTransformedSecretKeptFromManifestWork(t *testing.T) {
+	r := GetHypershiftDeploymentReconciler()
+	r.SecretTransformer = sealingTransformer{}
+	ctx := context.Background()
+
+	testHD := getHDforSecretEncryption(true)
+	scaffoldHostedClusterSpec(testHD)
+	keyName := testHD.Spec.HostedClusterSpec.SecretEncryption.AESCBC.ActiveKey.Name
+
+	m, err := scaffoldManifestwork(testHD)
+	assert.Nil(t, err)
+
+	// the generated encryption key only lives in the manifestwork, transformed
+	payload := []workv1.Manifest{}
+	r.appendHostedCluster(ctx)(testHD, &payload)
+	assert.Nil(t, r.ensureConfiguration(ctx, m)(testHD, &payload))
+	assert.Nil(t, r.transformPayloadSecrets(payload))
+
+	sealed := payloadKinds(t, payload)["SealedSecret"]
+	assert.Len(t, sealed, 1, "generated encryption key is transformed")
+
+	raw, err := json.Marshal(payload[len(payload)-1].Object)
+	assert.Nil(t, err)
+	m.Spec.Workload.Manifests = []workv1.Manifest{{RawExtension: runtime.RawExtension{Raw: raw}}}
+
+	// the next reconcile keeps the transformed key instead of generating a new one
+	payload = []workv1.Manifest{}
+	r.appendHostedCluster(ctx)(testHD, &payload)
+	assert.Nil(t, r.ensureConfiguration(ctx, m)(testHD, &payload))
+	assert.Nil(t, r.transformPayloadSecrets(payload))
+
+	kinds := payloadKinds(t, payload)
+	assert.Empty(t, kinds["Secret"], "encryption key is not generated again")
+	assert.Len(t, kinds["SealedSecret"], 1, "transformed encryption key is kept")
+	assert.Equal(t, keyName, kinds["SealedSecret"][0].GetAnnotations()[constant.TransformedSecretAnnotation])
+
+	previous, _, _ := unstructured.NestedStringMap(sealed[0].Object, "spec", "encryptedData")
+	kept, _, _ := unstructured.NestedStringMap(kinds["SealedSecret"][0].Object, "spec", "encryptedData")
+	assert.Equal(t, previous, kept, "transformed encryption key is not changed")
+}
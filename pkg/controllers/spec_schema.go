@@ -0,0 +1,93 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"sync"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiservervalidation "k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+	"sigs.k8s.io/yaml"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/config/crd"
+)
+
+var (
+	specValidatorOnce sync.Once
+	specValidator     *validate.SchemaValidator
+	specValidatorErr  error
+)
+
+// hypershiftDeploymentSpecValidator validates a spec against the schema of the CRD the controller is built with,
+// the installed CRD can be older than the controller
+func hypershiftDeploymentSpecValidator() (*validate.SchemaValidator, error) {
+	specValidatorOnce.Do(func() {
+		specValidator, specValidatorErr = newSpecValidator(crd.HypershiftDeployments)
+	})
+
+	return specValidator, specValidatorErr
+}
+
+func newSpecValidator(rawCRD []byte) (*validate.SchemaValidator, error) {
+	def := &apiextensionsv1.CustomResourceDefinition{}
+	if err := yaml.Unmarshal(rawCRD, def); err != nil {
+		return nil, fmt.Errorf("failed to read the HypershiftDeployment CRD, err: %w", err)
+	}
+
+	for _, v := range def.Spec.Versions {
+		if v.Name != hypdeployment.GroupVersion.Version || v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+
+		specSchema, ok := v.Schema.OpenAPIV3Schema.Properties["spec"]
+		if !ok {
+			break
+		}
+
+		internal := &apiextensions.JSONSchemaProps{}
+		if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(&specSchema, internal, nil); err != nil {
+			return nil, fmt.Errorf("failed to convert the HypershiftDeployment spec schema, err: %w", err)
+		}
+
+		validator, _, err := apiservervalidation.NewSchemaValidator(&apiextensions.CustomResourceValidation{OpenAPIV3Schema: internal})
+		return validator, err
+	}
+
+	return nil, fmt.Errorf("the HypershiftDeployment CRD has no spec schema for version %s", hypdeployment.GroupVersion.Version)
+}
+
+// validateSpecSchema returns the field errors of the spec, as the API server would for a HypershiftDeployment created
+// with the CRD of the controller
+func validateSpecSchema(hyd *hypdeployment.HypershiftDeployment) (field.ErrorList, error) {
+	validator, err := hypershiftDeploymentSpecValidator()
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&hyd.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return apiservervalidation.ValidateCustomResource(field.NewPath("spec"), spec, validator), nil
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	hyp "github.com/openshift/hypershift/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+func TestValidateSpecSchema(t *testing.T) {
+	fieldErrs, err := validateSpecSchema(getHDforManifestWork())
+	assert.Nil(t, err, "err nil when the schema is read")
+	assert.Empty(t, fieldErrs, "no field error for a valid spec")
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.Override = "KEEP"
+	testHD.Spec.HostedClusterSpec.Etcd.ManagementType = "External"
+	testHD.Spec.NodePools[0].Spec.Management.UpgradeType = "Rolling"
+
+	fieldErrs, err = validateSpecSchema(testHD)
+	assert.Nil(t, err, "err nil when the schema is read")
+	assert.Len(t, fieldErrs, 3, "every field error is reported")
+
+	fields := []string{}
+	for _, e := range fieldErrs {
+		fields = append(fields, e.Field)
+	}
+	assert.ElementsMatch(t, []string{
+		"spec.override",
+		"spec.hostedClusterSpec.etcd.managementType",
+		"spec.nodePools[0].spec.management.upgradeType",
+	}, fields)
+
+	_, err = newSpecValidator([]byte("kind: CustomResourceDefinition"))
+	assert.NotNil(t, err, "err when the CRD has no schema")
+}
+
+func TestSpecInvalidCondition(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Spec.HostedClusterSpec.Etcd.ManagementType = "External"
+	testHD.Spec.NodePools[0].Spec.Management.UpgradeType = "Rolling"
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client:             client,
+		Log:                ctrl.Log.WithName("tester"),
+		ValidateSpecSchema: true,
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when the invalid spec is reported")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+
+	c := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.SpecInvalid))
+	assert.NotNil(t, c, "SpecInvalid condition is reported")
+	assert.Equal(t, metav1.ConditionTrue, c.Status, "true when the spec does not match the schema")
+	assert.Equal(t, hyd.SchemaViolationReason, c.Reason)
+	assert.Contains(t, c.Message, `spec.hostedClusterSpec.etcd.managementType: Unsupported value: "External"`)
+	assert.Contains(t, c.Message, `spec.nodePools[0].spec.management.upgradeType: Unsupported value: "Rolling"`)
+
+	var mw workv1.ManifestWork
+	assert.NotNil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "no manifestwork for an invalid spec")
+
+	resultHD.Spec.HostedClusterSpec.Etcd.ManagementType = hyp.Managed
+	resultHD.Spec.NodePools[0].Spec.Management.UpgradeType = hyp.UpgradeTypeReplace
+	assert.Nil(t, client.Update(ctx, &resultHD), "HypershiftDeployment resource is updated")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	c = meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.SpecInvalid))
+	assert.Equal(t, metav1.ConditionFalse, c.Status, "false once the spec is fixed")
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "the manifestwork is created once the spec is fixed")
+}
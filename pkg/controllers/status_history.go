@@ -0,0 +1,37 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+// statusHistoryLimit is the number of transitions kept in status.history
+const statusHistoryLimit = 20
+
+// phaseHistoryType is the type of the transitions of the phase in status.history
+const phaseHistoryType = "Phase"
+
+// recordTransition appends the transition to status.history, dropping the oldest ones past statusHistoryLimit
+func recordTransition(hyd *hypdeployment.HypershiftDeployment, event hypdeployment.TransitionEvent) {
+	history := append(hyd.Status.History, event)
+	if len(history) > statusHistoryLimit {
+		history = append([]hypdeployment.TransitionEvent{}, history[len(history)-statusHistoryLimit:]...)
+	}
+
+	hyd.Status.History = history
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+func TestRecordTransitionEvictsOldest(t *testing.T) {
+	testHD := getHDforManifestWork()
+
+	for i := 0; i < statusHistoryLimit+5; i++ {
+		recordTransition(testHD, hyd.TransitionEvent{Type: fmt.Sprintf("Condition%d", i), To: "True"})
+	}
+
+	if assert.Len(t, testHD.Status.History, statusHistoryLimit, "the history is capped") {
+		assert.Equal(t, "Condition5", testHD.Status.History[0].Type, "the oldest transitions are evicted")
+		assert.Equal(t, fmt.Sprintf("Condition%d", statusHistoryLimit+4), testHD.Status.History[statusHistoryLimit-1].Type, "the latest transition is last")
+	}
+}
+
+func TestStatusHistoryTransitions(t *testing.T) {
+	testHD := getHDforManifestWork()
+
+	setStatusCondition(testHD, hyd.WorkConfigured, metav1.ConditionFalse, "", hyd.BeingConfiguredReason)
+	setStatusCondition(testHD, hyd.WorkConfigured, metav1.ConditionFalse, "still configuring", hyd.BeingConfiguredReason)
+	if assert.Len(t, testHD.Status.History, 1, "a new message is not a transition") {
+		assert.Equal(t, string(hyd.WorkConfigured), testHD.Status.History[0].Type)
+		assert.Empty(t, testHD.Status.History[0].From, "the condition was not set")
+		assert.Equal(t, "False", testHD.Status.History[0].To)
+		assert.False(t, testHD.Status.History[0].Time.IsZero(), "the transition time is recorded")
+	}
+
+	// the reason alone fails the HypershiftDeployment
+	setStatusCondition(testHD, hyd.WorkConfigured, metav1.ConditionFalse, "bad spec", hyd.MisConfiguredReason)
+	if assert.Len(t, testHD.Status.History, 2, "the phase change is recorded") {
+		assert.Equal(t, hyd.TransitionEvent{Time: testHD.Status.History[1].Time, Type: "Phase", From: "Provisioning", To: "Failed"}, testHD.Status.History[1])
+	}
+
+	setStatusCondition(testHD, hyd.WorkConfigured, metav1.ConditionTrue, "", hyd.ConfiguredAsExpectedReason)
+	setStatusCondition(testHD, hyd.HostedClusterAvailable, metav1.ConditionTrue, "", "HostedClusterAsExpected")
+
+	types := []string{}
+	for _, e := range testHD.Status.History[2:] {
+		types = append(types, fmt.Sprintf("%s %s->%s", e.Type, e.From, e.To))
+	}
+	assert.Equal(t, []string{
+		"ManifestWorkConfigured False->True",
+		"Phase Failed->Provisioning",
+		"HostedClusterAvailable ->True",
+		"Phase Provisioning->Ready",
+	}, types, "the condition transitions are followed by the phase transitions they cause")
+	assert.Equal(t, "HostedClusterAsExpected", testHD.Status.History[4].Reason, "the reason of the condition is recorded")
+}
+
+func TestStatusHistoryPersisted(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.Contains(t, resultHD.Status.History, hyd.TransitionEvent{
+		Time:   meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.WorkConfigured)).LastTransitionTime,
+		Type:   string(hyd.WorkConfigured),
+		To:     "True",
+		Reason: hyd.ConfiguredAsExpectedReason,
+	}, "the configured manifestwork is in the history")
+
+	// the second reconcile reads the manifestwork back, a reconcile that changes nothing then adds nothing
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+
+	history := resultHD.Status.History
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.Equal(t, history, resultHD.Status.History, "the history is unchanged")
+}
@@ -0,0 +1,117 @@
+// Copyright Contributors to the Open Cluster Management project.
+
+package summary
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
+)
+
+// SummaryName is the name of the HypershiftDeploymentSummary maintained in each namespace with HypershiftDeployments
+const SummaryName = "hypershiftdeployments"
+
+// Reconciler keeps a HypershiftDeploymentSummary per namespace with the count of
+// HypershiftDeployments by phase. The counts are eventually consistent, all the
+// HypershiftDeployment events of a namespace are collapsed into a single request.
+type Reconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=hypershiftdeploymentsummaries,verbs=create;get;list;patch;update;watch
+//+kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=hypershiftdeploymentsummaries/status,verbs=get;update;patch
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.Log = log.FromContext(ctx)
+	log := r.Log.WithValues("SummaryReconciler", req.NamespacedName)
+
+	hyds := &hypdeployment.HypershiftDeploymentList{}
+	if err := r.List(ctx, hyds, client.InNamespace(req.Namespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	status := countPhases(hyds.Items)
+
+	summary := &hypdeployment.HypershiftDeploymentSummary{}
+	err := r.Get(ctx, req.NamespacedName, summary)
+	switch {
+	case k8serrors.IsNotFound(err):
+		if status.Total == 0 {
+			return ctrl.Result{}, nil
+		}
+
+		log.Info("Create a new HypershiftDeploymentSummary resource")
+		summary.Name = req.Name
+		summary.Namespace = req.Namespace
+		if err := r.Create(ctx, summary); err != nil {
+			return ctrl.Result{}, err
+		}
+	case err != nil:
+		return ctrl.Result{}, err
+	}
+
+	// only write when a count changed, so a busy namespace does not churn the summary
+	status.LastUpdated = summary.Status.LastUpdated
+	if summary.Status == status {
+		return ctrl.Result{}, nil
+	}
+
+	patch := client.MergeFrom(summary.DeepCopy())
+	now := metav1.Now()
+	status.LastUpdated = &now
+	summary.Status = status
+
+	log.V(1).Info("Update the HypershiftDeploymentSummary counts", "total", status.Total, "ready", status.Ready,
+		"provisioning", status.Provisioning, "failed", status.Failed, "deleting", status.Deleting)
+	return ctrl.Result{}, r.Status().Patch(ctx, summary, patch)
+}
+
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&hypdeployment.HypershiftDeploymentSummary{}).
+		Watches(&source.Kind{Type: &hypdeployment.HypershiftDeployment{}}, handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+			return []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: SummaryName}},
+			}
+		})).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: 1,
+		}).Named("hypershiftdeploymentsummary").Complete(r)
+}
+
+func countPhases(hyds []hypdeployment.HypershiftDeployment) hypdeployment.HypershiftDeploymentSummaryStatus {
+	status := hypdeployment.HypershiftDeploymentSummaryStatus{}
+
+	for i := range hyds {
+		status.Total++
+
+		switch helper.GetPhase(&hyds[i]) {
+		case hypdeployment.PhaseReady:
+			status.Ready++
+		case hypdeployment.PhaseFailed:
+			status.Failed++
+		case hypdeployment.PhaseDeleting:
+			status.Deleting++
+		default:
+			status.Provisioning++
+		}
+	}
+
+	return status
+}
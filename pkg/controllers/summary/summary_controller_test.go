@@ -0,0 +1,148 @@
+// Copyright Contributors to the Open Cluster Management project.
+
+package summary
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	hydapi "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+const HYD_NAMESPACE = "testns"
+
+var s = clientgoscheme.Scheme
+
+func init() {
+	clientgoscheme.AddToScheme(s)
+
+	hydapi.AddToScheme(s)
+}
+
+func getRequest() ctrl.Request {
+	return ctrl.Request{NamespacedName: types.NamespacedName{Namespace: HYD_NAMESPACE, Name: SummaryName}}
+}
+
+func GetHypershiftDeployment(name string) *hydapi.HypershiftDeployment {
+	return &hydapi.HypershiftDeployment{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: HYD_NAMESPACE,
+		},
+	}
+}
+
+func GetSummaryReconciler() *Reconciler {
+	return &Reconciler{
+		Client: clientfake.NewClientBuilder().WithScheme(s).Build(),
+		Log:    ctrl.Log.WithName("controllers").WithName("SummaryReconciler"),
+		Scheme: s,
+	}
+}
+
+func setCondition(t *testing.T, ctx context.Context, client crclient.Client, name string,
+	conditionType hydapi.ConditionType, status v1.ConditionStatus, reason string) {
+	var hyd hydapi.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, types.NamespacedName{Namespace: HYD_NAMESPACE, Name: name}, &hyd), "hypershift deployment resource is retrieved")
+	meta.SetStatusCondition(&hyd.Status.Conditions, v1.Condition{Type: string(conditionType), Status: status, Reason: reason})
+	assert.Nil(t, client.Status().Update(ctx, &hyd), "hypershift deployment status is updated")
+}
+
+func reconcileSummary(t *testing.T, ctx context.Context, r *Reconciler) *hydapi.HypershiftDeploymentSummary {
+	_, err := r.Reconcile(ctx, getRequest())
+	assert.Nil(t, err, "reconcile was successful")
+
+	summary := &hydapi.HypershiftDeploymentSummary{}
+	assert.Nil(t, r.Client.Get(ctx, getRequest().NamespacedName, summary), "summary resource is retrieved")
+	return summary
+}
+
+func assertCounts(t *testing.T, summary *hydapi.HypershiftDeploymentSummary, total, ready, provisioning, failed, deleting int32) {
+	assert.Equal(t, total, summary.Status.Total, "total count")
+	assert.Equal(t, ready, summary.Status.Ready, "ready count")
+	assert.Equal(t, provisioning, summary.Status.Provisioning, "provisioning count")
+	assert.Equal(t, failed, summary.Status.Failed, "failed count")
+	assert.Equal(t, deleting, summary.Status.Deleting, "deleting count")
+}
+
+func TestSummaryNotCreatedWithoutHypershiftDeployments(t *testing.T) {
+	ctx := context.Background()
+	r := GetSummaryReconciler()
+
+	_, err := r.Reconcile(ctx, getRequest())
+	assert.Nil(t, err, "reconcile was successful")
+
+	err = r.Client.Get(ctx, getRequest().NamespacedName, &hydapi.HypershiftDeploymentSummary{})
+	assert.True(t, k8serrors.IsNotFound(err), "summary is not created for an empty namespace")
+}
+
+func TestSummaryCounts(t *testing.T) {
+	ctx := context.Background()
+	r := GetSummaryReconciler()
+
+	for _, name := range []string{"hd1", "hd2", "hd3"} {
+		assert.Nil(t, r.Client.Create(ctx, GetHypershiftDeployment(name)), "")
+	}
+	other := GetHypershiftDeployment("other")
+	other.Namespace = "otherns"
+	assert.Nil(t, r.Client.Create(ctx, other), "")
+
+	summary := reconcileSummary(t, ctx, r)
+	assertCounts(t, summary, 3, 0, 3, 0, 0)
+	assert.NotNil(t, summary.Status.LastUpdated, "last updated is set")
+
+	setCondition(t, ctx, r.Client, "hd1", hydapi.HostedClusterAvailable, v1.ConditionTrue, hydapi.AsExpectedReason)
+	setCondition(t, ctx, r.Client, "hd2", hydapi.WorkConfigured, v1.ConditionFalse, hydapi.MisConfiguredReason)
+	assertCounts(t, reconcileSummary(t, ctx, r), 3, 1, 1, 1, 0)
+
+	// the misconfiguration is fixed
+	setCondition(t, ctx, r.Client, "hd2", hydapi.WorkConfigured, v1.ConditionTrue, hydapi.ConfiguredAsExpectedReason)
+	assertCounts(t, reconcileSummary(t, ctx, r), 3, 1, 2, 0, 0)
+
+	// a problem condition takes precedence over an available HostedCluster
+	setCondition(t, ctx, r.Client, "hd1", hydapi.TargetClusterCircuitOpen, v1.ConditionTrue, hydapi.CircuitOpenReason)
+	assertCounts(t, reconcileSummary(t, ctx, r), 3, 0, 2, 1, 0)
+
+	hd3 := GetHypershiftDeployment("hd3")
+	assert.Nil(t, r.Client.Get(ctx, types.NamespacedName{Namespace: HYD_NAMESPACE, Name: "hd3"}, hd3), "")
+	hd3.Finalizers = []string{"test"}
+	assert.Nil(t, r.Client.Update(ctx, hd3), "")
+	assert.Nil(t, r.Client.Delete(ctx, hd3), "")
+	assertCounts(t, reconcileSummary(t, ctx, r), 3, 0, 1, 1, 1)
+
+	assert.Nil(t, r.Client.Get(ctx, types.NamespacedName{Namespace: HYD_NAMESPACE, Name: "hd3"}, hd3), "")
+	hd3.Finalizers = nil
+	assert.Nil(t, r.Client.Update(ctx, hd3), "")
+	assertCounts(t, reconcileSummary(t, ctx, r), 2, 0, 1, 1, 0)
+}
+
+func TestSummaryLastUpdatedOnlyOnChange(t *testing.T) {
+	ctx := context.Background()
+	r := GetSummaryReconciler()
+
+	assert.Nil(t, r.Client.Create(ctx, GetHypershiftDeployment("hd1")), "")
+
+	lastUpdated := v1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	summary := reconcileSummary(t, ctx, r)
+	summary.Status.LastUpdated = &lastUpdated
+	assert.Nil(t, r.Client.Status().Update(ctx, summary), "")
+
+	summary = reconcileSummary(t, ctx, r)
+	assert.True(t, lastUpdated.Equal(summary.Status.LastUpdated), "last updated is kept when the counts did not change")
+
+	setCondition(t, ctx, r.Client, "hd1", hydapi.HostedClusterAvailable, v1.ConditionTrue, hydapi.AsExpectedReason)
+	summary = reconcileSummary(t, ctx, r)
+	assertCounts(t, summary, 1, 1, 0, 0, 0)
+	assert.True(t, summary.Status.LastUpdated.After(lastUpdated.Time), "last updated moves when a count changed")
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
+)
+
+const (
+	redactedValue = "REDACTED"
+
+	supportBundlePayloadKey      = "payload.json"
+	supportBundleManifestWorkKey = "manifestwork.json"
+	supportBundleConditionsKey   = "conditions.json"
+)
+
+func supportBundleName(hyd *hypdeployment.HypershiftDeployment) string {
+	return hyd.Name + "-support-bundle"
+}
+
+// redactManifests returns a copy of the manifests with the data of every Secret replaced,
+// the keys are kept so the shape of the secret can still be analyzed
+func redactManifests(manifests []workv1.Manifest) ([]map[string]interface{}, error) {
+	out := []map[string]interface{}{}
+
+	for _, m := range manifests {
+		var obj map[string]interface{}
+
+		var err error
+		switch {
+		case len(m.Raw) != 0:
+			err = json.Unmarshal(m.Raw, &obj)
+		case m.Object != nil:
+			obj, err = runtime.DefaultUnstructuredConverter.ToUnstructured(m.Object)
+		default:
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		u := &unstructured.Unstructured{Object: obj}
+		if u.GetKind() == "Secret" {
+			for _, field := range []string{"data", "stringData"} {
+				data, found, _ := unstructured.NestedMap(obj, field)
+				if !found {
+					continue
+				}
+
+				for k := range data {
+					data[k] = redactedValue
+				}
+
+				if err := unstructured.SetNestedMap(obj, data, field); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		out = append(out, obj)
+	}
+
+	return out, nil
+}
+
+// collectSupportBundle dumps the rendered payload, the manifestwork and the conditions of the
+// HypershiftDeployment to a ConfigMap next to it. Secret data is redacted, then the collect-support
+// annotation is removed so the collection only runs once. When the reconcile stops before the payload
+// is rendered or the manifestwork exists, the bundle holds what is available.
+func (r *HypershiftDeploymentReconciler) collectSupportBundle(ctx context.Context, hyd *hypdeployment.HypershiftDeployment,
+	payload []workv1.Manifest, manifestwork *workv1.ManifestWork) error {
+	token, ok := hyd.GetAnnotations()[constant.CollectSupportAnnotation]
+	if !ok {
+		return nil
+	}
+
+	// the annotation removal failed after the bundle was collected for this token
+	if helper.SideEffectDone(hyd, constant.IdempotencyKeySupportBundle, token) {
+		return r.removeCollectSupportAnnotation(ctx, hyd)
+	}
+
+	redactedPayload, err := redactManifests(payload)
+	if err != nil {
+		return fmt.Errorf("failed to redact the manifestwork payload, err: %w", err)
+	}
+
+	contents := map[string]interface{}{
+		supportBundlePayloadKey:    redactedPayload,
+		supportBundleConditionsKey: hyd.Status.Conditions,
+	}
+
+	if manifestwork != nil {
+		work := manifestwork.DeepCopy()
+		redactedWorkload, err := redactManifests(work.Spec.Workload.Manifests)
+		if err != nil {
+			return fmt.Errorf("failed to redact the manifestwork workload, err: %w", err)
+		}
+		work.Spec.Workload.Manifests = nil
+
+		workObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(work)
+		if err != nil {
+			return err
+		}
+		if err := unstructured.SetNestedSlice(workObj, toInterfaceSlice(redactedWorkload), "spec", "workload", "manifests"); err != nil {
+			return err
+		}
+		contents[supportBundleManifestWorkKey] = workObj
+	}
+
+	data := map[string]string{}
+	for k, v := range contents {
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s for the support bundle, err: %w", k, err)
+		}
+		data[k] = string(b)
+	}
+
+	cm := &corev1.ConfigMap{}
+	cm.SetName(supportBundleName(hyd))
+	cm.SetNamespace(hyd.Namespace)
+
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		if cm.Labels == nil {
+			cm.Labels = map[string]string{}
+		}
+		cm.Labels[constant.InfraLabelName] = hyd.Spec.InfraID
+		cm.Data = data
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to save the support bundle %s/%s, err: %w", cm.Namespace, cm.Name, err)
+	}
+
+	r.Log.Info(fmt.Sprintf("collected support bundle %s/%s", cm.Namespace, cm.Name))
+
+	// persisted with the status of the HypershiftDeployment
+	helper.RecordSideEffect(hyd, constant.IdempotencyKeySupportBundle, token)
+
+	return r.removeCollectSupportAnnotation(ctx, hyd)
+}
+
+// removeSupportBundle deletes the support bundle of a deleted HypershiftDeployment
+func (r *HypershiftDeploymentReconciler) removeSupportBundle(ctx context.Context, hyd *hypdeployment.HypershiftDeployment) error {
+	cm := &corev1.ConfigMap{}
+	cm.SetName(supportBundleName(hyd))
+	cm.SetNamespace(hyd.Namespace)
+	if err := r.Delete(ctx, cm); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete the support bundle %s/%s, err: %w", cm.Namespace, cm.Name, err)
+	}
+
+	return nil
+}
+
+// removeCollectSupportAnnotation patches a copy of the HypershiftDeployment, so the in memory status
+// is not replaced by the one returned from the server
+func (r *HypershiftDeploymentReconciler) removeCollectSupportAnnotation(ctx context.Context, hyd *hypdeployment.HypershiftDeployment) error {
+	patched := hyd.DeepCopy()
+	origin := patched.DeepCopy()
+	delete(patched.Annotations, constant.CollectSupportAnnotation)
+
+	if err := r.Patch(ctx, patched, client.MergeFrom(origin)); err != nil {
+		return err
+	}
+
+	delete(hyd.Annotations, constant.CollectSupportAnnotation)
+	return nil
+}
+
+func toInterfaceSlice(in []map[string]interface{}) []interface{} {
+	out := make([]interface{}, 0, len(in))
+	for _, v := range in {
+		out = append(out, v)
+	}
+
+	return out
+}
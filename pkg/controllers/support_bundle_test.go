@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+)
+
+func TestCollectSupportBundle(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Annotations = map[string]string{constant.CollectSupportAnnotation: ""}
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	pullSecret := getPullSecret(testHD)
+	client.Create(ctx, pullSecret)
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.NotContains(t, resultHD.Annotations, constant.CollectSupportAnnotation, "annotation is cleared once the bundle is collected")
+
+	bundle := &corev1.ConfigMap{}
+	err = client.Get(ctx, types.NamespacedName{Namespace: testHD.Namespace, Name: supportBundleName(testHD)}, bundle)
+	assert.Nil(t, err, "is nil when the support bundle is found")
+
+	for _, k := range []string{supportBundlePayloadKey, supportBundleManifestWorkKey, supportBundleConditionsKey} {
+		assert.NotEmpty(t, bundle.Data[k], "support bundle contains %s", k)
+	}
+
+	payload := []map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal([]byte(bundle.Data[supportBundlePayloadKey]), &payload), "is nil when the payload is valid json")
+
+	kinds := map[string]bool{}
+	for _, obj := range payload {
+		kinds[obj["kind"].(string)] = true
+
+		if obj["kind"] == "Secret" {
+			for k, v := range obj["data"].(map[string]interface{}) {
+				assert.Equal(t, redactedValue, v, "secret data %s is redacted", k)
+			}
+		}
+	}
+	assert.True(t, kinds["HostedCluster"], "payload contains the HostedCluster")
+	assert.True(t, kinds["NodePool"], "payload contains the NodePool")
+	assert.True(t, kinds["Secret"], "payload contains the secrets")
+
+	for _, k := range []string{supportBundlePayloadKey, supportBundleManifestWorkKey} {
+		for _, v := range pullSecret.Data {
+			assert.False(t, strings.Contains(bundle.Data[k], base64.StdEncoding.EncodeToString(v)), "secret value is not in %s", k)
+		}
+	}
+
+	conditions := []metav1.Condition{}
+	assert.Nil(t, json.Unmarshal([]byte(bundle.Data[supportBundleConditionsKey]), &conditions), "is nil when the conditions are valid json")
+
+	// the bundle is only collected once
+	assert.Nil(t, client.Delete(ctx, bundle))
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	err = client.Get(ctx, types.NamespacedName{Namespace: testHD.Namespace, Name: supportBundleName(testHD)}, bundle)
+	assert.NotNil(t, err, "the support bundle is not collected again")
+
+	setCollectSupport := func(value string) {
+		var hd hyd.HypershiftDeployment
+		assert.Nil(t, client.Get(ctx, getNN, &hd), "is nil when HypershiftDeployment resource is found")
+		hd.Annotations = map[string]string{constant.CollectSupportAnnotation: value}
+		assert.Nil(t, client.Update(ctx, &hd), "is nil when the annotation is set")
+	}
+
+	// the same token does not collect the bundle again
+	setCollectSupport("")
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	err = client.Get(ctx, types.NamespacedName{Namespace: testHD.Namespace, Name: supportBundleName(testHD)}, bundle)
+	assert.NotNil(t, err, "the support bundle is not collected again for the same token")
+
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.NotContains(t, resultHD.Annotations, constant.CollectSupportAnnotation, "annotation is cleared")
+
+	// a new token collects a new bundle
+	setCollectSupport("2")
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+	err = client.Get(ctx, types.NamespacedName{Namespace: testHD.Namespace, Name: supportBundleName(testHD)}, bundle)
+	assert.Nil(t, err, "the support bundle is collected for a new token")
+
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.Equal(t, "2", resultHD.Status.IdempotencyKeys[constant.IdempotencyKeySupportBundle], "token is recorded in the status")
+}
+
+func TestCollectSupportBundleOnValidationFailure(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	testHD.Spec.NodePools[0].NodeVolumeDetachTimeout = &metav1.Duration{Duration: -time.Second}
+	testHD.Annotations = map[string]string{constant.CollectSupportAnnotation: ""}
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	_, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.NotContains(t, resultHD.Annotations, constant.CollectSupportAnnotation, "annotation is cleared once the bundle is collected")
+
+	bundle := &corev1.ConfigMap{}
+	err = client.Get(ctx, types.NamespacedName{Namespace: testHD.Namespace, Name: supportBundleName(testHD)}, bundle)
+	assert.Nil(t, err, "the support bundle is collected when the validation fails")
+	assert.Contains(t, bundle.Data[supportBundleConditionsKey], "nodeVolumeDetachTimeout -1s can not be negative", "the bundle holds the validation failure")
+	assert.NotContains(t, bundle.Data, supportBundleManifestWorkKey, "no manifestwork was created")
+}
+
+func TestRemoveSupportBundle(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	assert.Nil(t, hdr.removeSupportBundle(ctx, testHD), "is nil when there is no support bundle")
+
+	bundle := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: testHD.Namespace, Name: supportBundleName(testHD)}}
+	assert.Nil(t, client.Create(ctx, bundle), "is nil when the support bundle is created")
+
+	assert.Nil(t, hdr.removeSupportBundle(ctx, testHD), "is nil when the support bundle is deleted")
+	err := client.Get(ctx, types.NamespacedName{Namespace: testHD.Namespace, Name: supportBundleName(testHD)}, bundle)
+	assert.NotNil(t, err, "the support bundle is deleted")
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+// applyTemplate merges the HypershiftDeploymentTemplate referenced by the HypershiftDeployment into its spec,
+// before anything is scaffolded from it. The merged spec is only held in memory, see templateBase
+func (r *HypershiftDeploymentReconciler) applyTemplate(ctx context.Context, hyd *hypdeployment.HypershiftDeployment) error {
+	if hyd.Spec.TemplateRef == nil || hyd.Spec.TemplateRef.Name == "" {
+		return nil
+	}
+
+	var template hypdeployment.HypershiftDeploymentTemplate
+	if err := r.Get(ctx, types.NamespacedName{Namespace: hyd.Namespace, Name: hyd.Spec.TemplateRef.Name}, &template); err != nil {
+		// the inherited values were written to the spec by the reconciles before, a deleted template does not block the clean up
+		if apierrors.IsNotFound(err) && hyd.DeletionTimestamp != nil {
+			return nil
+		}
+		return fmt.Errorf("failed to get the HypershiftDeploymentTemplate %s, err: %w", hyd.Spec.TemplateRef.Name, err)
+	}
+
+	stored, err := r.storedSpec(ctx, hyd)
+	if err != nil {
+		return err
+	}
+
+	spec, err := mergeTemplateSpec(&template.Spec.Template, stored)
+	if err != nil {
+		return fmt.Errorf("failed to merge the HypershiftDeploymentTemplate %s, err: %w", template.Name, err)
+	}
+
+	hyd.Spec = *spec
+	return nil
+}
+
+// templateBase returns the HypershiftDeployment a patch of its spec is computed from: the stored one merged with its
+// template, like the one the reconcile works on. The inherited values are the same on both sides, so they are not
+// written to the HypershiftDeployment and a later change of the template still applies
+func (r *HypershiftDeploymentReconciler) templateBase(ctx context.Context, stored *hypdeployment.HypershiftDeployment) (*hypdeployment.HypershiftDeployment, error) {
+	base := stored.DeepCopy()
+	if err := r.applyTemplate(ctx, base); err != nil {
+		return nil, err
+	}
+
+	return base, nil
+}
+
+// storedSpec reads the spec of the HypershiftDeployment as the API server stores it. The typed spec can not tell an
+// omitted field from a false or 0 one, so the spec is read unstructured
+func (r *HypershiftDeploymentReconciler) storedSpec(ctx context.Context, hyd *hypdeployment.HypershiftDeployment) (map[string]interface{}, error) {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(hypdeployment.GroupVersion.WithKind("HypershiftDeployment"))
+	if err := r.Get(ctx, types.NamespacedName{Namespace: hyd.Namespace, Name: hyd.Name}, u); err != nil {
+		if apierrors.IsNotFound(err) {
+			return toJSONMap(&hyd.Spec)
+		}
+		return nil, fmt.Errorf("failed to get the HypershiftDeployment %s/%s, err: %w", hyd.Namespace, hyd.Name, err)
+	}
+
+	spec, _, err := unstructured.NestedMap(u.Object, "spec")
+	return spec, err
+}
+
+// mergeTemplateSpec overlays the fields set by the stored spec onto the template, the spec wins:
+//   - objects are merged field by field
+//   - lists are replaced as a whole, by a non empty list of the spec
+//   - an omitted field, an empty string or null of the spec inherits the template value, a false or 0 is kept
+//
+// The templateRef of the template is ignored, templates are not chained.
+func mergeTemplateSpec(template *hypdeployment.HypershiftDeploymentSpec, spec map[string]interface{}) (*hypdeployment.HypershiftDeploymentSpec, error) {
+	merged, err := toJSONMap(template)
+	if err != nil {
+		return nil, err
+	}
+	delete(merged, "templateRef")
+
+	overlayJSONMap(merged, spec)
+
+	raw, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &hypdeployment.HypershiftDeploymentSpec{}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func toJSONMap(in interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+
+	out := map[string]interface{}{}
+	return out, json.Unmarshal(raw, &out)
+}
+
+func overlayJSONMap(dst, src map[string]interface{}) {
+	for k, v := range src {
+		if srcMap, ok := v.(map[string]interface{}); ok {
+			if dstMap, ok := dst[k].(map[string]interface{}); ok {
+				overlayJSONMap(dstMap, srcMap)
+				continue
+			}
+		}
+
+		if inheritsTemplate(v) {
+			continue
+		}
+
+		dst[k] = v
+	}
+}
+
+// inheritsTemplate tells if a value of the spec leaves the field to the template, a false or 0 is a value
+func inheritsTemplate(v interface{}) bool {
+	switch value := v.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	case []interface{}:
+		return len(value) == 0
+	case map[string]interface{}:
+		return len(value) == 0
+	}
+
+	return false
+}
+
+// hypershiftDeploymentsOfTemplate maps a HypershiftDeploymentTemplate to the HypershiftDeployments referencing it
+func (r *HypershiftDeploymentReconciler) hypershiftDeploymentsOfTemplate(obj client.Object) []reconcile.Request {
+	hydList := &hypdeployment.HypershiftDeploymentList{}
+	if err := r.List(context.Background(), hydList, client.InNamespace(obj.GetNamespace())); err != nil {
+		r.Log.Error(err, "failed to list the HypershiftDeployments of the template", "template", client.ObjectKeyFromObject(obj))
+		return []reconcile.Request{}
+	}
+
+	req := []reconcile.Request{}
+	for _, hyd := range hydList.Items {
+		if hyd.Spec.TemplateRef != nil && hyd.Spec.TemplateRef.Name == obj.GetName() {
+			req = append(req, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&hyd)})
+		}
+	}
+
+	return req
+}
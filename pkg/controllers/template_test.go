@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+)
+
+func getHypershiftDeploymentTemplate(namespace string, spec hyd.HypershiftDeploymentSpec) *hyd.HypershiftDeploymentTemplate {
+	return &hyd.HypershiftDeploymentTemplate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "template1",
+			Namespace: namespace,
+		},
+		Spec: hyd.HypershiftDeploymentTemplateSpec{Template: spec},
+	}
+}
+
+func TestMergeTemplateSpec(t *testing.T) {
+	template := &hyd.HypershiftDeploymentSpec{
+		HostingCluster:   "local-cluster",
+		HostingNamespace: "clusters",
+		Infrastructure: hyd.InfraSpec{
+			Configure:     true,
+			CloudProvider: corev1.LocalObjectReference{Name: "aws-creds"},
+			Platform:      &hyd.Platforms{AWS: &hyd.AWSPlatform{Region: "us-east-1"}},
+		},
+		ControlPlaneTolerations: []corev1.Toleration{{Key: "template", Operator: corev1.TolerationOpExists}},
+		ImageRegistry:           &hyd.ImageRegistry{ManagementState: hyd.ImageRegistryRemoved},
+		TemplateRef:             &corev1.LocalObjectReference{Name: "chained"},
+		DryRun:                  true,
+		PullKubeconfigToHub:     true,
+	}
+
+	spec := &hyd.HypershiftDeploymentSpec{
+		HostingNamespace: "mine",
+		Infrastructure: hyd.InfraSpec{
+			Platform: &hyd.Platforms{AWS: &hyd.AWSPlatform{}},
+		},
+		ControlPlaneTolerations: []corev1.Toleration{{Key: "mine", Operator: corev1.TolerationOpExists}},
+		TemplateRef:             &corev1.LocalObjectReference{Name: "template1"},
+	}
+
+	// the stored spec, configure is omitted and dryRun is explicitly false
+	stored := func() map[string]interface{} {
+		m, err := toJSONMap(spec)
+		assert.Nil(t, err, "err nil when the spec is serialized")
+		delete(m["infrastructure"].(map[string]interface{}), "configure")
+		m["dryRun"] = false
+		return m
+	}
+
+	merged, err := mergeTemplateSpec(template, stored())
+	assert.Nil(t, err, "err nil when the template is merged")
+
+	// inherited
+	assert.Equal(t, "local-cluster", merged.HostingCluster, "empty field is inherited")
+	assert.True(t, merged.Infrastructure.Configure, "omitted field is inherited")
+	assert.True(t, merged.PullKubeconfigToHub, "omitted false field is inherited")
+	assert.Equal(t, "aws-creds", merged.Infrastructure.CloudProvider.Name, "nested field is inherited")
+	assert.Equal(t, "us-east-1", merged.Infrastructure.Platform.AWS.Region, "field of a partially set object is inherited")
+	assert.Equal(t, hyd.ImageRegistryRemoved, merged.ImageRegistry.ManagementState, "omitted object is inherited")
+
+	// overridden
+	assert.Equal(t, "mine", merged.HostingNamespace, "HypershiftDeployment wins")
+	assert.Equal(t, []corev1.Toleration{{Key: "mine", Operator: corev1.TolerationOpExists}}, merged.ControlPlaneTolerations, "list is replaced as a whole")
+	assert.Equal(t, "template1", merged.TemplateRef.Name, "templateRef of the template is ignored")
+	assert.False(t, merged.DryRun, "explicit false of the HypershiftDeployment wins")
+
+	spec.Infrastructure.Platform.AWS.Region = "eu-west-1"
+	spec.ImageRegistry = &hyd.ImageRegistry{ManagementState: hyd.ImageRegistryManaged}
+	merged, err = mergeTemplateSpec(template, stored())
+	assert.Nil(t, err, "err nil when the template is merged")
+	assert.Equal(t, "eu-west-1", merged.Infrastructure.Platform.AWS.Region, "nested field of the HypershiftDeployment wins")
+	assert.Equal(t, hyd.ImageRegistryManaged, merged.ImageRegistry.ManagementState, "object of the HypershiftDeployment wins")
+
+	assert.Equal(t, "us-east-1", template.Infrastructure.Platform.AWS.Region, "template is not modified")
+	assert.Equal(t, "chained", template.TemplateRef.Name, "template is not modified")
+}
+
+func TestHypershiftDeploymentTemplate(t *testing.T) {
+	client := initClient()
+	ctx := context.Background()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = ""
+	testHD.Spec.ImageRegistry = &hyd.ImageRegistry{ManagementState: hyd.ImageRegistryManaged}
+	testHD.Spec.TemplateRef = &corev1.LocalObjectReference{Name: "template1"}
+
+	client.Create(ctx, testHD)
+	defer client.Delete(ctx, testHD)
+
+	client.Create(ctx, getPullSecret(testHD))
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client: client,
+		Log:    ctrl.Log.WithName("tester"),
+	}
+
+	// the template does not exist
+	res, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when the missing template is reported")
+	assert.True(t, res.Requeue, "requeued when the template is missing")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	cond := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.WorkConfigured))
+	assert.NotNil(t, cond, "WorkConfigured condition is set")
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, hyd.MisConfiguredReason, cond.Reason)
+	assert.Contains(t, cond.Message, "template1")
+
+	template := getHypershiftDeploymentTemplate(testHD.Namespace, hyd.HypershiftDeploymentSpec{
+		HostingCluster: "local-cluster",
+		ImageRegistry:  &hyd.ImageRegistry{ManagementState: hyd.ImageRegistryRemoved},
+	})
+	assert.Nil(t, client.Create(ctx, template), "template is created")
+	assert.Equal(t, []ctrl.Request{{NamespacedName: getNN}}, hdr.hypershiftDeploymentsOfTemplate(template), "HypershiftDeployment is reconciled on a template change")
+
+	_, err = hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when reconcile was successful")
+
+	// the manifestwork goes to the HostingCluster of the template
+	testHD.Spec.HostingCluster = "local-cluster"
+	var mw workv1.ManifestWork
+	assert.Nil(t, client.Get(ctx, getManifestWorkKey(testHD), &mw), "is nil when the manifestwork is found in the HostingCluster of the template")
+
+	annotation := "not found"
+	for _, m := range mw.Spec.Workload.Manifests {
+		u, err := manifestToUnstructured(m)
+		assert.Nil(t, err, "err nil when the manifest is readable")
+		if u.GetKind() == "HostedCluster" {
+			annotation = u.GetAnnotations()[constant.ImageRegistryManagementStateAnnotation]
+		}
+	}
+	assert.Equal(t, string(hyd.ImageRegistryManaged), annotation, "image registry of the HypershiftDeployment wins over the template")
+
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+	assert.True(t, meta.IsStatusConditionTrue(resultHD.Status.Conditions, string(hyd.WorkConfigured)), "manifestwork is configured")
+	assert.Equal(t, "template1", resultHD.Spec.TemplateRef.Name, "templateRef is kept")
+	assert.Empty(t, resultHD.Spec.HostingCluster, "the inherited HostingCluster is not written to the HypershiftDeployment")
+	assert.NotEmpty(t, resultHD.Spec.InfraID, "the infra-id of the controller is written")
+	assert.Equal(t, hyd.ImageRegistryManaged, resultHD.Spec.ImageRegistry.ManagementState, "the spec of the HypershiftDeployment is kept")
+}
@@ -0,0 +1,1553 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	configv1 "github.com/openshift/api/config/v1"
+	hyp "github.com/openshift/hypershift/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/util/jsonpath"
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
+)
+
+const (
+	// awsZoneFilterName is the EC2 subnet filter used to pin a subnet reference to an availability zone
+	awsZoneFilterName = "availability-zone"
+
+	// maxNodePoolMinorVersionSkew is how many minor versions a NodePool can trail the control plane
+	maxNodePoolMinorVersionSkew = 2
+
+	// minHighlyAvailableNodes is the number of nodes needed to keep two replicas of the HighlyAvailable
+	// components on different nodes
+	minHighlyAvailableNodes = 2
+
+	// propagatedSecretSizeWarningThreshold is the encoded size of a propagated secret that is reported as too large,
+	// the whole manifestwork is limited to 500KiB
+	propagatedSecretSizeWarningThreshold = 100 * 1024
+)
+
+// resolveStatusCondition flips a previously reported problem condition to false, once the
+// problem is no longer detected. Conditions that were never reported are left untouched.
+func resolveStatusCondition(hyd *hypdeployment.HypershiftDeployment, conditionType hypdeployment.ConditionType) {
+	if meta.FindStatusCondition(hyd.Status.Conditions, string(conditionType)) == nil {
+		return
+	}
+
+	setStatusCondition(hyd, conditionType, metav1.ConditionFalse, "", hypdeployment.AsExpectedReason)
+}
+
+func awsSubnetZone(ref *hyp.AWSResourceReference) (string, string) {
+	if ref == nil || ref.ID == nil || len(*ref.ID) == 0 {
+		return "", ""
+	}
+
+	for _, f := range ref.Filters {
+		if f.Name == awsZoneFilterName && len(f.Values) == 1 {
+			return *ref.ID, f.Values[0]
+		}
+	}
+
+	return *ref.ID, ""
+}
+
+// validateSubnetZones makes sure a subnet ID is never mapped to more than one availability zone, the
+// zone of a subnet comes from the HostedCluster cloudProviderConfig or an "availability-zone" subnet filter
+func validateSubnetZones(hcSpec *hyp.HostedClusterSpec, nodePools []*hypdeployment.HypershiftNodePools) error {
+	zones := map[string]map[string][]string{}
+
+	add := func(owner string, ref *hyp.AWSResourceReference, zone string) {
+		subnet, filterZone := awsSubnetZone(ref)
+		if len(zone) == 0 {
+			zone = filterZone
+		}
+
+		if len(subnet) == 0 || len(zone) == 0 {
+			return
+		}
+
+		if zones[subnet] == nil {
+			zones[subnet] = map[string][]string{}
+		}
+		zones[subnet][zone] = append(zones[subnet][zone], owner)
+	}
+
+	if hcSpec != nil && hcSpec.Platform.AWS != nil && hcSpec.Platform.AWS.CloudProviderConfig != nil {
+		cpc := hcSpec.Platform.AWS.CloudProviderConfig
+		add("HostedCluster", cpc.Subnet, cpc.Zone)
+	}
+
+	for _, np := range nodePools {
+		if np.Spec.Platform.AWS != nil {
+			add("NodePool "+np.Name, np.Spec.Platform.AWS.Subnet, "")
+		}
+	}
+
+	conflicts := []string{}
+	for subnet, byZone := range zones {
+		if len(byZone) < 2 {
+			continue
+		}
+
+		mappings := []string{}
+		for zone, owners := range byZone {
+			mappings = append(mappings, fmt.Sprintf("%s(%s)", zone, strings.Join(owners, ",")))
+		}
+		sort.Strings(mappings)
+
+		conflicts = append(conflicts, fmt.Sprintf("subnet %s is mapped to zones %s", subnet, strings.Join(mappings, " and ")))
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	sort.Strings(conflicts)
+	return fmt.Errorf("%s", strings.Join(conflicts, "; "))
+}
+
+// validateNodePoolMachineCIDRs makes sure the machineCIDR of each NodePool is within the HostedCluster
+// machine network and does not overlap the machineCIDR of another NodePool
+func validateNodePoolMachineCIDRs(hcSpec *hyp.HostedClusterSpec, nodePools []*hypdeployment.HypershiftNodePools) error {
+	var machineNetwork *net.IPNet
+	pools := map[string]*net.IPNet{}
+	names := []string{}
+
+	for _, np := range nodePools {
+		if len(np.MachineCIDR) == 0 {
+			continue
+		}
+
+		if machineNetwork == nil {
+			if hcSpec == nil || len(hcSpec.Networking.MachineCIDR) == 0 {
+				return fmt.Errorf("NodePool %s machineCIDR requires the HostedCluster machineCIDR", np.Name)
+			}
+
+			_, cidr, err := net.ParseCIDR(hcSpec.Networking.MachineCIDR)
+			if err != nil {
+				return fmt.Errorf("HostedCluster machineCIDR %q is invalid", hcSpec.Networking.MachineCIDR)
+			}
+			machineNetwork = cidr
+		}
+
+		_, cidr, err := net.ParseCIDR(np.MachineCIDR)
+		if err != nil {
+			return fmt.Errorf("NodePool %s machineCIDR %q is invalid", np.Name, np.MachineCIDR)
+		}
+
+		hcOnes, hcBits := machineNetwork.Mask.Size()
+		npOnes, npBits := cidr.Mask.Size()
+		if hcBits != npBits || npOnes < hcOnes || !machineNetwork.Contains(cidr.IP) {
+			return fmt.Errorf("NodePool %s machineCIDR %s is not within the HostedCluster machineCIDR %s", np.Name, cidr, machineNetwork)
+		}
+
+		for _, name := range names {
+			if pools[name].Contains(cidr.IP) || cidr.Contains(pools[name].IP) {
+				return fmt.Errorf("NodePool %s machineCIDR %s overlaps NodePool %s machineCIDR %s", np.Name, cidr, name, pools[name])
+			}
+		}
+
+		pools[np.Name] = cidr
+		names = append(names, np.Name)
+	}
+
+	return nil
+}
+
+// releaseImageVersion reads the OCP version from the tag of a release image pull spec,
+// ie quay.io/openshift-release-dev/ocp-release:4.10.15-x86_64
+func releaseImageVersion(image string) (*version.Version, error) {
+	name := image[strings.LastIndex(image, "/")+1:]
+	if strings.Contains(name, "@") {
+		return nil, fmt.Errorf("release image %s is pinned by digest", image)
+	}
+
+	i := strings.LastIndex(name, ":")
+	if i < 0 {
+		return nil, fmt.Errorf("release image %s has no tag", image)
+	}
+
+	return version.ParseGeneric(name[i+1:])
+}
+
+// releaseImageArch reads the architecture suffix from the tag of a release image pull spec, ie x86_64, aarch64
+// or multi. An empty string is returned when the release is pinned by digest or the tag has no known suffix
+func releaseImageArch(image string) string {
+	name := image[strings.LastIndex(image, "/")+1:]
+	i := strings.LastIndex(name, ":")
+	if strings.Contains(name, "@") || i < 0 {
+		return ""
+	}
+
+	tag := name[i+1:]
+	for _, arch := range []string{"x86_64", "aarch64", "multi"} {
+		if strings.HasSuffix(tag, "-"+arch) {
+			return arch
+		}
+	}
+
+	return ""
+}
+
+// validateNodePoolArch makes sure the arch of a NodePool is supported by the platform and by the release image,
+// arm64 NodePools need AWS and a multi-arch release. Release images without a known architecture are not checked
+func validateNodePoolArch(npName string, arch string, platform hyp.PlatformType, releaseImage string) error {
+	relArch := releaseImageArch(releaseImage)
+
+	switch arch {
+	case "", hypdeployment.NodePoolArchAMD64:
+		if relArch == "aarch64" {
+			return fmt.Errorf("NodePool %s arch is amd64 but the release image %s is aarch64 only", npName, releaseImage)
+		}
+	case hypdeployment.NodePoolArchARM64:
+		if platform != hyp.AWSPlatform {
+			return fmt.Errorf("NodePool %s arch arm64 is only supported on the AWS platform, not %s", npName, platform)
+		}
+
+		if relArch == "x86_64" {
+			return fmt.Errorf("NodePool %s arch arm64 requires a multi-arch release image, %s is x86_64 only", npName, releaseImage)
+		}
+	default:
+		return fmt.Errorf("NodePool %s arch %q is not supported, use %s or %s", npName, arch,
+			hypdeployment.NodePoolArchAMD64, hypdeployment.NodePoolArchARM64)
+	}
+
+	return nil
+}
+
+// validateVersionSkew makes sure a NodePool release is not newer than the control plane and does not trail it
+// by more than maxNodePoolMinorVersionSkew minor versions. Release images without a parsable version are skipped.
+func validateVersionSkew(controlPlaneImage string, nodePoolName string, nodePoolImage string) error {
+	if len(nodePoolImage) == 0 || nodePoolImage == controlPlaneImage {
+		return nil
+	}
+
+	cpVersion, err := releaseImageVersion(controlPlaneImage)
+	if err != nil {
+		return nil
+	}
+
+	npVersion, err := releaseImageVersion(nodePoolImage)
+	if err != nil {
+		return nil
+	}
+
+	if cpVersion.Major() != npVersion.Major() {
+		return fmt.Errorf("NodePool %s version %s and control plane version %s have a different major version", nodePoolName, npVersion, cpVersion)
+	}
+
+	if npVersion.Minor() > cpVersion.Minor() {
+		return fmt.Errorf("NodePool %s version %s is newer than the control plane version %s", nodePoolName, npVersion, cpVersion)
+	}
+
+	if cpVersion.Minor()-npVersion.Minor() > maxNodePoolMinorVersionSkew {
+		return fmt.Errorf("NodePool %s version %s trails the control plane version %s by more than %d minor versions",
+			nodePoolName, npVersion, cpVersion, maxNodePoolMinorVersionSkew)
+	}
+
+	return nil
+}
+
+// validateNodePoolsVersionSkew checks the version skew of every NodePool against the HostedCluster release
+func validateNodePoolsVersionSkew(hcSpec *hyp.HostedClusterSpec, nodePools []*hypdeployment.HypershiftNodePools) error {
+	if hcSpec == nil {
+		return nil
+	}
+
+	for _, np := range nodePools {
+		if err := validateVersionSkew(hcSpec.Release.Image, np.Name, np.Spec.Release.Image); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateHighAvailability makes sure the NodePools have at least minHighlyAvailableNodes nodes when the HostedCluster
+// availability policy is HighlyAvailable, the minimum of an autoscaled NodePool is counted
+func validateHighAvailability(hcSpec *hyp.HostedClusterSpec, nodePools []*hypdeployment.HypershiftNodePools) error {
+	if hcSpec == nil {
+		return nil
+	}
+
+	policies := []string{}
+	if hcSpec.ControllerAvailabilityPolicy == hyp.HighlyAvailable {
+		policies = append(policies, "controllerAvailabilityPolicy")
+	}
+	if hcSpec.InfrastructureAvailabilityPolicy == hyp.HighlyAvailable {
+		policies = append(policies, "infrastructureAvailabilityPolicy")
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+
+	nodes := int32(0)
+	for _, np := range nodePools {
+		switch {
+		case np.Spec.AutoScaling != nil:
+			nodes += np.Spec.AutoScaling.Min
+		case np.Spec.Replicas != nil:
+			nodes += *np.Spec.Replicas
+		}
+	}
+
+	if nodes >= minHighlyAvailableNodes {
+		return nil
+	}
+
+	verb := "is"
+	if len(policies) > 1 {
+		verb = "are"
+	}
+
+	return fmt.Errorf("%s %s %s but the NodePools have %d node(s), at least %d are needed to spread the replicas",
+		strings.Join(policies, " and "), verb, hyp.HighlyAvailable, nodes, minHighlyAvailableNodes)
+}
+
+func invalidKeys(keys map[string]string, lower bool) []string {
+	out := []string{}
+	for k := range keys {
+		key := k
+		if lower {
+			key = strings.ToLower(k)
+		}
+
+		if len(validation.IsQualifiedName(key)) != 0 {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+
+	return out
+}
+
+// nodePoolConfigKey is the key of the serialized MachineConfig in a ConfigMap of a NodePool spec.config
+const nodePoolConfigKey = "config"
+
+// validateNodePoolConfigs checks that the ConfigMaps referenced by spec.config of the NodePools are in the payload,
+// next to the NodePool, and hold the serialized MachineConfig the HyperShift operator injects into the ignition
+func validateNodePoolConfigs(payload []workv1.Manifest) error {
+	configMaps := map[types.NamespacedName]*corev1.ConfigMap{}
+	for _, m := range payload {
+		if cm, ok := m.Object.(*corev1.ConfigMap); ok {
+			configMaps[types.NamespacedName{Namespace: cm.Namespace, Name: cm.Name}] = cm
+		}
+	}
+
+	problems := []string{}
+	for _, np := range getNodePoolsInManifestPayload(&payload) {
+		for _, ref := range np.Spec.Config {
+			cm, ok := configMaps[types.NamespacedName{Namespace: np.Namespace, Name: ref.Name}]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("nodePool %s configMap %s is not copied to namespace %s", np.Name, ref.Name, np.Namespace))
+				continue
+			}
+
+			if len(strings.TrimSpace(cm.Data[nodePoolConfigKey])) == 0 {
+				problems = append(problems, fmt.Sprintf("nodePool %s configMap %s has no %q key with a MachineConfig", np.Name, ref.Name, nodePoolConfigKey))
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("invalid NodePool config: %s", strings.Join(problems, "; "))
+}
+
+// validatePayloadMetadataKeys checks the label and annotation keys of every resource in the manifestwork payload,
+// the payload is not validated by the hub API server so an invalid key only fails when applied on the hosting cluster
+func validatePayloadMetadataKeys(payload []workv1.Manifest) error {
+	problems := []string{}
+
+	for _, m := range payload {
+		var obj metav1.Object
+		kind := ""
+
+		switch {
+		case len(m.Raw) != 0:
+			u := &unstructured.Unstructured{}
+			if err := json.Unmarshal(m.Raw, u); err != nil {
+				return err
+			}
+			obj, kind = u, u.GetKind()
+		case m.Object != nil:
+			o, err := meta.Accessor(m.Object)
+			if err != nil {
+				return err
+			}
+			obj, kind = o, m.Object.GetObjectKind().GroupVersionKind().Kind
+		default:
+			continue
+		}
+
+		res := fmt.Sprintf("%s %s/%s", kind, obj.GetNamespace(), obj.GetName())
+		if keys := invalidKeys(obj.GetLabels(), false); len(keys) != 0 {
+			problems = append(problems, fmt.Sprintf("%s label keys %q", res, keys))
+		}
+
+		// annotation keys follow the label key syntax, case insensitive
+		if keys := invalidKeys(obj.GetAnnotations(), true); len(keys) != 0 {
+			problems = append(problems, fmt.Sprintf("%s annotation keys %q", res, keys))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("invalid metadata keys: %s", strings.Join(problems, "; "))
+}
+
+// validateDeleteOption checks the delete option of the manifestwork acts on its payload, an option on an empty
+// payload or an orphaning rule that matches no payload object has no effect. The resource of an orphaning rule
+// is matched against the lowercase plural of the payload kinds.
+func validateDeleteOption(option *workv1.DeleteOption, payload []workv1.Manifest) error {
+	if option == nil {
+		return nil
+	}
+
+	if len(payload) == 0 {
+		return fmt.Errorf("the %s delete option has no effect, the manifestwork payload is empty", option.PropagationPolicy)
+	}
+
+	if option.PropagationPolicy != workv1.DeletePropagationPolicyTypeSelectivelyOrphan || option.SelectivelyOrphan == nil {
+		return nil
+	}
+
+	objects := []*unstructured.Unstructured{}
+	for _, m := range payload {
+		u, err := manifestToUnstructured(m)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, u)
+	}
+
+	unmatched := []string{}
+	for _, rule := range option.SelectivelyOrphan.OrphaningRules {
+		matched := false
+		for _, u := range objects {
+			if u.GroupVersionKind().Group == rule.Group && strings.ToLower(u.GetKind())+"s" == rule.Resource &&
+				u.GetNamespace() == rule.Namespace && u.GetName() == rule.Name {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			name := rule.Name
+			if len(rule.Namespace) != 0 {
+				name = rule.Namespace + "/" + rule.Name
+			}
+			unmatched = append(unmatched, fmt.Sprintf("%s %s", rule.Resource, name))
+		}
+	}
+
+	if len(unmatched) != 0 {
+		return fmt.Errorf("the orphaning rules of %s match no object of the manifestwork payload", strings.Join(unmatched, ", "))
+	}
+
+	return nil
+}
+
+// validateDeleteOptions checks the spec.deleteOptions, the SelectivelyOrphan policy needs the objects to orphan and the
+// other policies apply to every object of the payload
+func validateDeleteOptions(opts *hypdeployment.DeleteOptions) error {
+	if opts == nil {
+		return nil
+	}
+
+	switch opts.PropagationPolicy {
+	case hypdeployment.DeletePropagationForeground, hypdeployment.DeletePropagationOrphan:
+		if len(opts.OrphaningRules) != 0 {
+			return fmt.Errorf("deleteOptions.orphaningRules only apply to the %s propagationPolicy, not %s",
+				hypdeployment.DeletePropagationSelectivelyOrphan, opts.PropagationPolicy)
+		}
+	case hypdeployment.DeletePropagationSelectivelyOrphan:
+		if len(opts.OrphaningRules) == 0 {
+			return fmt.Errorf("deleteOptions.orphaningRules are required by the %s propagationPolicy", opts.PropagationPolicy)
+		}
+	default:
+		return fmt.Errorf("deleteOptions.propagationPolicy %q is not supported, must be one of %s, %s, %s", opts.PropagationPolicy,
+			hypdeployment.DeletePropagationForeground, hypdeployment.DeletePropagationOrphan, hypdeployment.DeletePropagationSelectivelyOrphan)
+	}
+
+	for i, r := range opts.OrphaningRules {
+		if len(r.Resource) == 0 || len(r.Name) == 0 {
+			return fmt.Errorf("deleteOptions.orphaningRules[%d] needs a resource and a name", i)
+		}
+	}
+
+	return nil
+}
+
+// supportedPlatformTypes are the platform types of the HyperShift API this controller scaffolds
+var supportedPlatformTypes = sets.NewString(
+	string(hyp.AWSPlatform),
+	string(hyp.NonePlatform),
+	string(hyp.IBMCloudPlatform),
+	string(hyp.AgentPlatform),
+	string(hyp.KubevirtPlatform),
+	string(hyp.AzurePlatform),
+	string(hyp.PowerVSPlatform),
+)
+
+// validatePlatformTypes checks the HostedClusterSpec and the NodePools have a platform type this controller knows,
+// every unknown type is listed. An empty type is left to the required fields check.
+func validatePlatformTypes(hyd *hypdeployment.HypershiftDeployment) error {
+	unknown := []string{}
+	if hcSpec := hyd.Spec.HostedClusterSpec; hcSpec != nil && len(hcSpec.Platform.Type) != 0 &&
+		!supportedPlatformTypes.Has(string(hcSpec.Platform.Type)) {
+		unknown = append(unknown, fmt.Sprintf("hostedClusterSpec.platform.type %q", hcSpec.Platform.Type))
+	}
+
+	for _, np := range hyd.Spec.NodePools {
+		if np == nil || len(np.Spec.Platform.Type) == 0 || supportedPlatformTypes.Has(string(np.Spec.Platform.Type)) {
+			continue
+		}
+
+		unknown = append(unknown, fmt.Sprintf("nodePool %s platform.type %q", np.Name, np.Spec.Platform.Type))
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("unsupported platform types: %s, must be one of %s", strings.Join(unknown, ", "),
+		strings.Join(supportedPlatformTypes.List(), ", "))
+}
+
+// validateNodePoolPlatform checks a NodePool only declares the settings of the HostedCluster platform, ie a
+// NodePool with an aws section is rejected under an Azure HostedCluster
+func validateNodePoolPlatform(npName string, hcPlatform hyp.PlatformType, np hyp.NodePoolPlatform) error {
+	declared := []struct {
+		platform hyp.PlatformType
+		set      bool
+	}{
+		{np.Type, true},
+		{hyp.AWSPlatform, np.AWS != nil},
+		{hyp.AzurePlatform, np.Azure != nil},
+		{hyp.IBMCloudPlatform, np.IBMCloud != nil},
+		{hyp.KubevirtPlatform, np.Kubevirt != nil},
+		{hyp.AgentPlatform, np.Agent != nil},
+		{hyp.PowerVSPlatform, np.PowerVS != nil},
+	}
+
+	for _, d := range declared {
+		if d.set && d.platform != hcPlatform {
+			return fmt.Errorf("nodePool %s declares the %s platform, the HostedCluster platform is %s", npName, d.platform, hcPlatform)
+		}
+	}
+
+	return nil
+}
+
+// validateNodePoolSecurityGroups checks each security group of an AWS NodePool is selected by one of id, arn or
+// filters. When required, the NodePool must select at least one security group, the node instances only get
+// the default security group of the VPC otherwise.
+func validateNodePoolSecurityGroups(npName string, np hyp.NodePoolPlatform, required bool) error {
+	if np.Type != hyp.AWSPlatform {
+		return nil
+	}
+
+	if np.AWS == nil || len(np.AWS.SecurityGroups) == 0 {
+		if !required {
+			return nil
+		}
+
+		return fmt.Errorf("nodePool %s requires at least one security group in platform.aws.securityGroups", npName)
+	}
+
+	for i, sg := range np.AWS.SecurityGroups {
+		selectors := 0
+		if sg.ID != nil && len(*sg.ID) != 0 {
+			selectors++
+		}
+		if sg.ARN != nil && len(*sg.ARN) != 0 {
+			selectors++
+		}
+		if len(sg.Filters) != 0 {
+			selectors++
+		}
+
+		if selectors != 1 {
+			return fmt.Errorf("nodePool %s platform.aws.securityGroups[%d] must set exactly one of id, arn or filters", npName, i)
+		}
+
+		for _, f := range sg.Filters {
+			if len(f.Name) == 0 || len(f.Values) == 0 {
+				return fmt.Errorf("nodePool %s platform.aws.securityGroups[%d] filters need a name and values", npName, i)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateNodePoolLifecycle checks the node lifecycle settings of a NodePool, the drain timeout and the
+// rolling update bounds can not be negative
+func validateNodePoolLifecycle(npName string, npSpec hyp.NodePoolSpec) error {
+	if npSpec.NodeDrainTimeout != nil && npSpec.NodeDrainTimeout.Duration < 0 {
+		return fmt.Errorf("NodePool %s nodeDrainTimeout %s can not be negative", npName, npSpec.NodeDrainTimeout.Duration)
+	}
+
+	if npSpec.Management.Replace == nil || npSpec.Management.Replace.RollingUpdate == nil {
+		return nil
+	}
+
+	ru := npSpec.Management.Replace.RollingUpdate
+	for _, f := range []struct {
+		name  string
+		value *intstr.IntOrString
+	}{{"maxUnavailable", ru.MaxUnavailable}, {"maxSurge", ru.MaxSurge}} {
+		field, v := f.name, f.value
+		if v == nil {
+			continue
+		}
+
+		// a percentage is scaled against 100 nodes, only its sign matters here
+		n, err := intstr.GetScaledValueFromIntOrPercent(v, 100, false)
+		if err != nil {
+			return fmt.Errorf("NodePool %s %s %s is invalid: %w", npName, field, v.String(), err)
+		}
+
+		if n < 0 {
+			return fmt.Errorf("NodePool %s %s %s can not be negative", npName, field, v.String())
+		}
+	}
+
+	return nil
+}
+
+// validateNodeVolumeDetachTimeout checks the nodeVolumeDetachTimeout of a NodePool is not negative
+func validateNodeVolumeDetachTimeout(npName string, timeout *metav1.Duration) error {
+	if timeout != nil && timeout.Duration < 0 {
+		return fmt.Errorf("NodePool %s nodeVolumeDetachTimeout %s can not be negative", npName, timeout.Duration)
+	}
+
+	return nil
+}
+
+// validateNodePoolManagement checks the upgrade settings of a NodePool apply to its upgrade type, an unset upgrade
+// type is defaulted to Replace. The replace settings, ie maxSurge and maxUnavailable, are not used by an InPlace
+// upgrade, and a rolling update needs room to replace a node.
+func validateNodePoolManagement(npName string, npSpec hyp.NodePoolSpec) error {
+	mgmt := npSpec.Management
+	switch mgmt.UpgradeType {
+	case "", hyp.UpgradeTypeReplace:
+	case hyp.UpgradeTypeInPlace:
+		if mgmt.Replace != nil {
+			return fmt.Errorf("NodePool %s management.replace only applies to the %s upgradeType, not %s", npName, hyp.UpgradeTypeReplace, hyp.UpgradeTypeInPlace)
+		}
+		return nil
+	default:
+		return fmt.Errorf("NodePool %s management.upgradeType %q is not one of %s or %s", npName, mgmt.UpgradeType, hyp.UpgradeTypeReplace, hyp.UpgradeTypeInPlace)
+	}
+
+	if mgmt.InPlace != nil {
+		return fmt.Errorf("NodePool %s management.inPlace only applies to the %s upgradeType", npName, hyp.UpgradeTypeInPlace)
+	}
+
+	if mgmt.Replace == nil || mgmt.Replace.RollingUpdate == nil {
+		return nil
+	}
+
+	if mgmt.Replace.Strategy == hyp.UpgradeStrategyOnDelete {
+		return fmt.Errorf("NodePool %s management.replace.rollingUpdate only applies to the %s strategy, not %s", npName,
+			hyp.UpgradeStrategyRollingUpdate, hyp.UpgradeStrategyOnDelete)
+	}
+
+	// the HyperShift defaults, a surge of one node and no unavailable node
+	ru := mgmt.Replace.RollingUpdate
+	surge, unavailable := intstr.FromInt(1), intstr.FromInt(0)
+	if ru.MaxSurge != nil {
+		surge = *ru.MaxSurge
+	}
+	if ru.MaxUnavailable != nil {
+		unavailable = *ru.MaxUnavailable
+	}
+
+	// a percentage is scaled against 100 nodes, only whether it is 0 matters here
+	s, errS := intstr.GetScaledValueFromIntOrPercent(&surge, 100, true)
+	u, errU := intstr.GetScaledValueFromIntOrPercent(&unavailable, 100, false)
+	if errS == nil && errU == nil && s == 0 && u == 0 {
+		return fmt.Errorf("NodePool %s management.replace.rollingUpdate maxSurge and maxUnavailable can not both be 0", npName)
+	}
+
+	return nil
+}
+
+// defaultNodePoolManagement sets the HyperShift default upgrade settings on a NodePool spec that leaves them unset,
+// a Replace upgrade with a rolling update of one surge node
+func defaultNodePoolManagement(npSpec *hyp.NodePoolSpec) {
+	mgmt := &npSpec.Management
+	if len(mgmt.UpgradeType) == 0 {
+		mgmt.UpgradeType = hyp.UpgradeTypeReplace
+	}
+
+	if mgmt.UpgradeType != hyp.UpgradeTypeReplace {
+		return
+	}
+
+	if mgmt.Replace == nil {
+		mgmt.Replace = &hyp.ReplaceUpgrade{}
+	}
+	if len(mgmt.Replace.Strategy) == 0 {
+		mgmt.Replace.Strategy = hyp.UpgradeStrategyRollingUpdate
+	}
+	if mgmt.Replace.Strategy == hyp.UpgradeStrategyRollingUpdate && mgmt.Replace.RollingUpdate == nil {
+		mgmt.Replace.RollingUpdate = &hyp.RollingUpdate{
+			MaxSurge:       &intstr.IntOrString{IntVal: 1},
+			MaxUnavailable: &intstr.IntOrString{IntVal: 0},
+		}
+	}
+}
+
+// validateNodePoolAutoScaling checks the autoscaling bounds of a NodePool, the cluster autoscaler owns the replicas
+// of an autoscaled NodePool so both can not be set
+func validateNodePoolAutoScaling(npName string, npSpec hyp.NodePoolSpec) error {
+	as := npSpec.AutoScaling
+	if as == nil {
+		return nil
+	}
+
+	if npSpec.Replicas != nil {
+		return fmt.Errorf("NodePool %s can not set both replicas and autoScaling", npName)
+	}
+
+	if as.Min < 1 {
+		return fmt.Errorf("NodePool %s autoScaling min %d must be at least 1", npName, as.Min)
+	}
+
+	if as.Min > as.Max {
+		return fmt.Errorf("NodePool %s autoScaling min %d is greater than max %d", npName, as.Min, as.Max)
+	}
+
+	return nil
+}
+
+// validateControlPlaneTolerations applies the pod toleration rules to the control plane tolerations, so an
+// invalid toleration is reported on the HypershiftDeployment instead of failing the manifestwork apply
+func validateControlPlaneTolerations(tolerations []corev1.Toleration) error {
+	for i, t := range tolerations {
+		field := fmt.Sprintf("controlPlaneTolerations[%d]", i)
+
+		if len(t.Key) != 0 {
+			if errs := validation.IsQualifiedName(t.Key); len(errs) != 0 {
+				return fmt.Errorf("%s key %q is invalid: %s", field, t.Key, strings.Join(errs, ", "))
+			}
+		} else if t.Operator != corev1.TolerationOpExists {
+			return fmt.Errorf("%s operator must be Exists when the key is empty", field)
+		}
+
+		if t.TolerationSeconds != nil && t.Effect != corev1.TaintEffectNoExecute {
+			return fmt.Errorf("%s effect must be NoExecute when tolerationSeconds is set", field)
+		}
+
+		switch t.Operator {
+		case corev1.TolerationOpEqual, "":
+			if errs := validation.IsValidLabelValue(t.Value); len(errs) != 0 {
+				return fmt.Errorf("%s value %q is invalid: %s", field, t.Value, strings.Join(errs, ", "))
+			}
+		case corev1.TolerationOpExists:
+			if len(t.Value) != 0 {
+				return fmt.Errorf("%s value must be empty when the operator is Exists", field)
+			}
+		default:
+			return fmt.Errorf("%s operator %q is not supported, must be one of Equal, Exists", field, t.Operator)
+		}
+
+		switch t.Effect {
+		case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute, "":
+		default:
+			return fmt.Errorf("%s effect %q is not supported, must be one of NoSchedule, PreferNoSchedule, NoExecute", field, t.Effect)
+		}
+	}
+
+	return nil
+}
+
+// validatePullSecretType checks the pull secret is of type kubernetes.io/dockerconfigjson, an Opaque secret
+// with the right data still works but is most likely a misconfiguration
+func validatePullSecretType(secret *corev1.Secret) error {
+	if secret.Type == corev1.SecretTypeDockerConfigJson {
+		return nil
+	}
+
+	secretType := secret.Type
+	if len(secretType) == 0 {
+		secretType = corev1.SecretTypeOpaque
+	}
+
+	return fmt.Errorf("pull secret %s/%s is of type %s, expected %s", secret.Namespace, secret.Name, secretType, corev1.SecretTypeDockerConfigJson)
+}
+
+// validatePropagatedSecretSizes reports the secrets with an encoded size over propagatedSecretSizeWarningThreshold,
+// so a bloated secret is found before the manifestwork is rejected for its aggregate size
+func validatePropagatedSecretSizes(secrets []*corev1.Secret) error {
+	oversized := []string{}
+	for _, s := range secrets {
+		b, err := json.Marshal(s)
+		if err != nil {
+			return fmt.Errorf("failed to marshal the secret %s/%s, err: %w", s.Namespace, s.Name, err)
+		}
+
+		if len(b) > propagatedSecretSizeWarningThreshold {
+			oversized = append(oversized, fmt.Sprintf("secret %s/%s is %d bytes", s.Namespace, s.Name, len(b)))
+		}
+	}
+
+	if len(oversized) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%s, over the %d bytes threshold of a propagated secret", strings.Join(oversized, ", "), propagatedSecretSizeWarningThreshold)
+}
+
+// validateHostedClusterSpec checks the HostedClusterSpec has the fields the HyperShift operator requires for its
+// platform, every missing field is listed so they can be fixed at once. The spec of a HostedClusterRef is not checked.
+func validateHostedClusterSpec(hyd *hypdeployment.HypershiftDeployment) error {
+	hcSpec := hyd.Spec.HostedClusterSpec
+	if hcSpec == nil {
+		return nil
+	}
+
+	type requiredField struct {
+		field string
+		set   bool
+	}
+
+	required := []requiredField{
+		{"release.image", len(hcSpec.Release.Image) != 0},
+		{"pullSecret.name", len(hcSpec.PullSecret.Name) != 0},
+		{"platform.type", len(hcSpec.Platform.Type) != 0},
+	}
+
+	switch hcSpec.Platform.Type {
+	case hyp.AWSPlatform:
+		aws := hcSpec.Platform.AWS
+		if aws == nil {
+			required = append(required, requiredField{"platform.aws", false})
+			break
+		}
+
+		required = append(required, []requiredField{
+			{"platform.aws.region", len(aws.Region) != 0},
+			{"platform.aws.controlPlaneOperatorCreds.name", len(aws.ControlPlaneOperatorCreds.Name) != 0},
+			{"platform.aws.kubeCloudControllerCreds.name", len(aws.KubeCloudControllerCreds.Name) != 0},
+			{"platform.aws.nodePoolManagementCreds.name", len(aws.NodePoolManagementCreds.Name) != 0},
+		}...)
+
+	case hyp.AzurePlatform:
+		azure := hcSpec.Platform.Azure
+		if azure == nil {
+			required = append(required, requiredField{"platform.azure", false})
+			break
+		}
+
+		// the managed identity sets the credentials, subscription and machine identity of the HostedCluster
+		identity := azureIdentity(hyd)
+		required = append(required, []requiredField{
+			{"platform.azure.credentials.name", len(azure.Credentials.Name) != 0 || identity != nil},
+			{"platform.azure.location", len(azure.Location) != 0},
+			{"platform.azure.resourceGroup", len(azure.ResourceGroupName) != 0},
+			{"platform.azure.vnetName", len(azure.VnetName) != 0},
+			{"platform.azure.vnetID", len(azure.VnetID) != 0},
+			{"platform.azure.subnetName", len(azure.SubnetName) != 0},
+			{"platform.azure.subscriptionID", len(azure.SubscriptionID) != 0 || identity != nil},
+			{"platform.azure.machineIdentityID", len(azure.MachineIdentityID) != 0 || (identity != nil && len(identity.MachineIdentityID) != 0)},
+			{"platform.azure.securityGroupName", len(azure.SecurityGroupName) != 0},
+		}...)
+
+	case hyp.AgentPlatform:
+		agent := hcSpec.Platform.Agent
+		if agent == nil {
+			required = append(required, requiredField{"platform.agent", false})
+			break
+		}
+
+		required = append(required, requiredField{"platform.agent.agentNamespace", len(agent.AgentNamespace) != 0})
+
+	case hyp.NonePlatform:
+		// the None platform runs on user supplied infrastructure, only the fields common to all platforms are required
+	}
+
+	missing := []string{}
+	for _, r := range required {
+		if !r.set {
+			missing = append(missing, "hostedClusterSpec."+r.field)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("missing required fields: %s", strings.Join(missing, ", "))
+}
+
+// validateAWSCloudProviderConfig checks an AWS HostedClusterSpec has the cloudProviderConfig the cloud
+// controller manager needs to provision load balancers and volumes
+func validateAWSCloudProviderConfig(hcSpec *hyp.HostedClusterSpec) error {
+	if hcSpec == nil || hcSpec.Platform.AWS == nil {
+		return nil
+	}
+
+	cpc := hcSpec.Platform.AWS.CloudProviderConfig
+	if cpc == nil {
+		return fmt.Errorf("hostedClusterSpec.platform.aws.cloudProviderConfig is required")
+	}
+
+	if len(cpc.VPC) == 0 {
+		return fmt.Errorf("hostedClusterSpec.platform.aws.cloudProviderConfig.vpc is required")
+	}
+
+	if cpc.Subnet != nil && cpc.Subnet.ID == nil && len(cpc.Subnet.Filters) == 0 {
+		return fmt.Errorf("hostedClusterSpec.platform.aws.cloudProviderConfig.subnet must have an id or filters")
+	}
+
+	return nil
+}
+
+// validateAWSEndpointAccess checks the publishing scope of the endpoints of an AWS HostedClusterSpec, unset
+// defaults to Public
+func validateAWSEndpointAccess(hcSpec *hyp.HostedClusterSpec) error {
+	if hcSpec == nil || hcSpec.Platform.AWS == nil {
+		return nil
+	}
+
+	switch hcSpec.Platform.AWS.EndpointAccess {
+	case "", hyp.Public, hyp.PublicAndPrivate, hyp.Private:
+		return nil
+	}
+
+	return fmt.Errorf("hostedClusterSpec.platform.aws.endpointAccess %q is not supported, must be one of %s, %s, %s",
+		hcSpec.Platform.AWS.EndpointAccess, hyp.Public, hyp.PublicAndPrivate, hyp.Private)
+}
+
+// validateNonePlatform checks a HostedClusterSpec of the None platform, the user supplied infrastructure, does
+// not declare the section of a cloud platform, that would propagate its cloud credentials
+func validateNonePlatform(hcSpec *hyp.HostedClusterSpec) error {
+	if hcSpec == nil || hcSpec.Platform.Type != hyp.NonePlatform {
+		return nil
+	}
+
+	declared := []struct {
+		platform hyp.PlatformType
+		set      bool
+	}{
+		{hyp.AWSPlatform, hcSpec.Platform.AWS != nil},
+		{hyp.AzurePlatform, hcSpec.Platform.Azure != nil},
+		{hyp.IBMCloudPlatform, hcSpec.Platform.IBMCloud != nil},
+		{hyp.AgentPlatform, hcSpec.Platform.Agent != nil},
+		{hyp.PowerVSPlatform, hcSpec.Platform.PowerVS != nil},
+	}
+
+	for _, d := range declared {
+		if d.set {
+			return fmt.Errorf("hostedClusterSpec.platform.type is None, it can not declare the %s platform", d.platform)
+		}
+	}
+
+	return nil
+}
+
+// The OS disk sizes of an Azure NodePool, the HyperShift API minimum and the largest Azure managed disk
+const (
+	azureMinDiskSizeGB = 16
+	azureMaxDiskSizeGB = 32767
+)
+
+// validateAzureNodePool makes sure the OS disk of an Azure NodePool can be provisioned, an unset disk size is
+// defaulted by the HyperShift API
+func validateAzureNodePool(npName string, np hyp.NodePoolPlatform) error {
+	if np.Type != hyp.AzurePlatform || np.Azure == nil || np.Azure.DiskSizeGB == 0 {
+		return nil
+	}
+
+	if size := np.Azure.DiskSizeGB; size < azureMinDiskSizeGB || size > azureMaxDiskSizeGB {
+		return fmt.Errorf("nodePool %s platform.azure.diskSizeGB %d must be between %d and %d", npName, size, azureMinDiskSizeGB, azureMaxDiskSizeGB)
+	}
+
+	return nil
+}
+
+// validateKubevirtNodePool makes sure a KubeVirt NodePool declares the root volume the VMs run from, and that
+// its persistent storage can be provisioned
+func validateKubevirtNodePool(npName string, np hyp.NodePoolPlatform) error {
+	if np.Type != hyp.KubevirtPlatform {
+		return nil
+	}
+
+	if np.Kubevirt == nil || np.Kubevirt.RootVolume == nil {
+		return fmt.Errorf("nodePool %s platform.kubevirt.rootVolume is required", npName)
+	}
+
+	volume := np.Kubevirt.RootVolume.KubevirtVolume
+	if len(volume.Type) != 0 && volume.Type != hyp.KubevirtVolumeTypePersistent {
+		return fmt.Errorf("nodePool %s platform.kubevirt.rootVolume.type %q is not supported, use %s", npName, volume.Type, hyp.KubevirtVolumeTypePersistent)
+	}
+
+	if volume.Persistent == nil {
+		return nil
+	}
+
+	if volume.Persistent.Size != nil && volume.Persistent.Size.Sign() <= 0 {
+		return fmt.Errorf("nodePool %s platform.kubevirt.rootVolume.persistent.size must be positive", npName)
+	}
+
+	if sc := volume.Persistent.StorageClass; sc != nil {
+		if errs := validation.IsDNS1123Subdomain(*sc); len(errs) != 0 {
+			return fmt.Errorf("nodePool %s platform.kubevirt.rootVolume.persistent.storageClass %q is invalid: %s", npName, *sc, strings.Join(errs, ", "))
+		}
+	}
+
+	return nil
+}
+
+// validateKubevirtNodePoolOptions makes sure the KubeVirt settings of a NodePool only apply to a KubeVirt NodePool
+// and that the affinity of its VMs can be scheduled by the infrastructure cluster
+func validateKubevirtNodePoolOptions(npName string, platform hyp.NodePoolPlatform, opts *hypdeployment.KubevirtNodePoolOptions) error {
+	if opts == nil {
+		return nil
+	}
+
+	if platform.Type != hyp.KubevirtPlatform {
+		return fmt.Errorf("nodePool %s kubevirt is only supported on the KubeVirt platform, not %s", npName, platform.Type)
+	}
+
+	switch opts.NetworkInterfaceMultiQueue {
+	case "", hypdeployment.KubevirtMultiQueueEnable, hypdeployment.KubevirtMultiQueueDisable:
+	default:
+		return fmt.Errorf("nodePool %s kubevirt.networkInterfaceMultiqueue %q is not supported, use %s or %s", npName,
+			opts.NetworkInterfaceMultiQueue, hypdeployment.KubevirtMultiQueueEnable, hypdeployment.KubevirtMultiQueueDisable)
+	}
+
+	if opts.Affinity == nil {
+		return nil
+	}
+
+	if err := validateNodeAffinity(opts.Affinity.NodeAffinity); err != nil {
+		return fmt.Errorf("nodePool %s kubevirt.affinity.%w", npName, err)
+	}
+
+	if pa := opts.Affinity.PodAffinity; pa != nil {
+		if err := validatePodAffinityTerms("podAffinity", pa.RequiredDuringSchedulingIgnoredDuringExecution, pa.PreferredDuringSchedulingIgnoredDuringExecution); err != nil {
+			return fmt.Errorf("nodePool %s kubevirt.affinity.%w", npName, err)
+		}
+	}
+
+	if paa := opts.Affinity.PodAntiAffinity; paa != nil {
+		if err := validatePodAffinityTerms("podAntiAffinity", paa.RequiredDuringSchedulingIgnoredDuringExecution, paa.PreferredDuringSchedulingIgnoredDuringExecution); err != nil {
+			return fmt.Errorf("nodePool %s kubevirt.affinity.%w", npName, err)
+		}
+	}
+
+	return nil
+}
+
+func validateNodeAffinity(na *corev1.NodeAffinity) error {
+	if na == nil {
+		return nil
+	}
+
+	if required := na.RequiredDuringSchedulingIgnoredDuringExecution; required != nil {
+		if len(required.NodeSelectorTerms) == 0 {
+			return fmt.Errorf("nodeAffinity.requiredDuringSchedulingIgnoredDuringExecution.nodeSelectorTerms must not be empty")
+		}
+
+		for i, term := range required.NodeSelectorTerms {
+			field := fmt.Sprintf("nodeAffinity.requiredDuringSchedulingIgnoredDuringExecution.nodeSelectorTerms[%d]", i)
+			if err := validateNodeSelectorTerm(field, term); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, pref := range na.PreferredDuringSchedulingIgnoredDuringExecution {
+		field := fmt.Sprintf("nodeAffinity.preferredDuringSchedulingIgnoredDuringExecution[%d]", i)
+		if pref.Weight < 1 || pref.Weight > 100 {
+			return fmt.Errorf("%s weight %d must be in the range 1-100", field, pref.Weight)
+		}
+
+		if err := validateNodeSelectorTerm(field+".preference", pref.Preference); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateNodeSelectorTerm(field string, term corev1.NodeSelectorTerm) error {
+	for i, req := range term.MatchExpressions {
+		reqField := fmt.Sprintf("%s.matchExpressions[%d]", field, i)
+		if errs := validation.IsQualifiedName(req.Key); len(errs) != 0 {
+			return fmt.Errorf("%s key %q is invalid: %s", reqField, req.Key, strings.Join(errs, ", "))
+		}
+
+		switch req.Operator {
+		case corev1.NodeSelectorOpIn, corev1.NodeSelectorOpNotIn:
+			if len(req.Values) == 0 {
+				return fmt.Errorf("%s values must be set when the operator is %s", reqField, req.Operator)
+			}
+		case corev1.NodeSelectorOpExists, corev1.NodeSelectorOpDoesNotExist:
+			if len(req.Values) != 0 {
+				return fmt.Errorf("%s values must be empty when the operator is %s", reqField, req.Operator)
+			}
+		case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+			if len(req.Values) != 1 {
+				return fmt.Errorf("%s must have a single value when the operator is %s", reqField, req.Operator)
+			}
+
+			if _, err := strconv.ParseInt(req.Values[0], 10, 64); err != nil {
+				return fmt.Errorf("%s value %q must be an integer when the operator is %s", reqField, req.Values[0], req.Operator)
+			}
+		default:
+			return fmt.Errorf("%s operator %q is not supported, must be one of In, NotIn, Exists, DoesNotExist, Gt, Lt", reqField, req.Operator)
+		}
+	}
+
+	for i, req := range term.MatchFields {
+		reqField := fmt.Sprintf("%s.matchFields[%d]", field, i)
+		if req.Key != "metadata.name" {
+			return fmt.Errorf("%s key %q is not supported, must be metadata.name", reqField, req.Key)
+		}
+
+		if req.Operator != corev1.NodeSelectorOpIn && req.Operator != corev1.NodeSelectorOpNotIn {
+			return fmt.Errorf("%s operator %q is not supported, must be one of In, NotIn", reqField, req.Operator)
+		}
+
+		if len(req.Values) != 1 {
+			return fmt.Errorf("%s must have a single value", reqField)
+		}
+	}
+
+	return nil
+}
+
+func validatePodAffinityTerms(field string, required []corev1.PodAffinityTerm, preferred []corev1.WeightedPodAffinityTerm) error {
+	for i, term := range required {
+		if err := validatePodAffinityTerm(fmt.Sprintf("%s.requiredDuringSchedulingIgnoredDuringExecution[%d]", field, i), term); err != nil {
+			return err
+		}
+	}
+
+	for i, weighted := range preferred {
+		termField := fmt.Sprintf("%s.preferredDuringSchedulingIgnoredDuringExecution[%d]", field, i)
+		if weighted.Weight < 1 || weighted.Weight > 100 {
+			return fmt.Errorf("%s weight %d must be in the range 1-100", termField, weighted.Weight)
+		}
+
+		if err := validatePodAffinityTerm(termField+".podAffinityTerm", weighted.PodAffinityTerm); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validatePodAffinityTerm(field string, term corev1.PodAffinityTerm) error {
+	if len(term.TopologyKey) == 0 {
+		return fmt.Errorf("%s topologyKey is required", field)
+	}
+
+	if errs := validation.IsQualifiedName(term.TopologyKey); len(errs) != 0 {
+		return fmt.Errorf("%s topologyKey %q is invalid: %s", field, term.TopologyKey, strings.Join(errs, ", "))
+	}
+
+	if _, err := metav1.LabelSelectorAsSelector(term.LabelSelector); err != nil {
+		return fmt.Errorf("%s labelSelector is invalid: %v", field, err)
+	}
+
+	if _, err := metav1.LabelSelectorAsSelector(term.NamespaceSelector); err != nil {
+		return fmt.Errorf("%s namespaceSelector is invalid: %v", field, err)
+	}
+
+	return nil
+}
+
+// validateIBMCloudPlatform makes sure the IBM Cloud provider type is one the cloud provider supports
+func validateIBMCloudPlatform(hcSpec *hyp.HostedClusterSpec) error {
+	if hcSpec == nil || hcSpec.Platform.IBMCloud == nil {
+		return nil
+	}
+
+	switch hcSpec.Platform.IBMCloud.ProviderType {
+	case "", configv1.IBMCloudProviderTypeClassic, configv1.IBMCloudProviderTypeVPC, configv1.IBMCloudProviderTypeUPI:
+		return nil
+	}
+
+	return fmt.Errorf("hostedClusterSpec.platform.ibmcloud.providerType %q is not one of %s, %s or %s", hcSpec.Platform.IBMCloud.ProviderType,
+		configv1.IBMCloudProviderTypeClassic, configv1.IBMCloudProviderTypeVPC, configv1.IBMCloudProviderTypeUPI)
+}
+
+// conflictingAnnotations lists the one time annotations the spec keeps from taking effect. The spec takes precedence,
+// the annotation stays pending and takes effect once the spec allows it:
+//   - collect-support, the support bundle is collected after the manifestwork is written
+//   - confirm-scale-down, the blocked reductions are confirmed when the manifestwork is written
+//
+// neither happens while spec.dryRun is true or spec.override is INFRA-ONLY.
+func conflictingAnnotations(hyd *hypdeployment.HypershiftDeployment) []string {
+	pending := []string{}
+	if token, ok := hyd.GetAnnotations()[constant.CollectSupportAnnotation]; ok && !helper.SideEffectDone(hyd, constant.IdempotencyKeySupportBundle, token) {
+		pending = append(pending, constant.CollectSupportAnnotation)
+	}
+	if _, ok := scaleDownConfirmed(hyd); ok {
+		pending = append(pending, constant.ConfirmScaleDownAnnotation)
+	}
+
+	blocker := ""
+	switch {
+	case hyd.Spec.Override == hypdeployment.InfraConfigureOnly:
+		blocker = fmt.Sprintf("spec.override is %s", hypdeployment.InfraConfigureOnly)
+	case hyd.Spec.DryRun:
+		blocker = "spec.dryRun is true"
+	}
+
+	if len(blocker) == 0 {
+		return nil
+	}
+
+	conflicts := []string{}
+	for _, a := range pending {
+		conflicts = append(conflicts, fmt.Sprintf("%s is pending while %s, the manifestwork is not written, remove the annotation or change the spec", a, blocker))
+	}
+
+	return conflicts
+}
+
+// validateSecretEncryptionKMS checks a kms secretEncryption uses the key management service of the platform of the
+// HostedCluster and references its keys the way the kms provider locates them. The credentials secrets are checked
+// with the other dependencies.
+func validateSecretEncryptionKMS(hcSpec *hyp.HostedClusterSpec) error {
+	if hcSpec == nil || hcSpec.SecretEncryption == nil || hcSpec.SecretEncryption.Type != hyp.KMS || hcSpec.SecretEncryption.KMS == nil {
+		return nil
+	}
+
+	kms := hcSpec.SecretEncryption.KMS
+	switch kms.Provider {
+	case hyp.AWS:
+		if hcSpec.Platform.Type != hyp.AWSPlatform {
+			return fmt.Errorf("hostedClusterSpec.secretEncryption.kms.provider AWS requires the AWS platform, not %s", hcSpec.Platform.Type)
+		}
+		if kms.AWS == nil {
+			return nil
+		}
+
+		if len(kms.AWS.Region) == 0 {
+			return fmt.Errorf("hostedClusterSpec.secretEncryption.kms.aws.region is required")
+		}
+		if err := validateAWSKMSKeyARN("activeKey", kms.AWS.ActiveKey.ARN, kms.AWS.Region); err != nil {
+			return err
+		}
+		if kms.AWS.BackupKey != nil {
+			return validateAWSKMSKeyARN("backupKey", kms.AWS.BackupKey.ARN, kms.AWS.Region)
+		}
+
+	case hyp.IBMCloud:
+		if hcSpec.Platform.Type != hyp.IBMCloudPlatform {
+			return fmt.Errorf("hostedClusterSpec.secretEncryption.kms.provider IBMCloud requires the IBMCloud platform, not %s", hcSpec.Platform.Type)
+		}
+		if kms.IBMCloud == nil {
+			return nil
+		}
+
+		if len(kms.IBMCloud.Region) == 0 {
+			return fmt.Errorf("hostedClusterSpec.secretEncryption.kms.ibmcloud.region is required")
+		}
+		if len(kms.IBMCloud.KeyList) == 0 {
+			return fmt.Errorf("hostedClusterSpec.secretEncryption.kms.ibmcloud.keyList requires at least one key")
+		}
+
+		versions := map[int]bool{}
+		for i, key := range kms.IBMCloud.KeyList {
+			if len(key.CRKID) == 0 || len(key.InstanceID) == 0 {
+				return fmt.Errorf("hostedClusterSpec.secretEncryption.kms.ibmcloud.keyList[%d] requires a crkID and an instanceID", i)
+			}
+			if !strings.HasPrefix(key.URL, "https://") {
+				return fmt.Errorf("hostedClusterSpec.secretEncryption.kms.ibmcloud.keyList[%d].url %q must be https", i, key.URL)
+			}
+			if versions[key.KeyVersion] {
+				return fmt.Errorf("hostedClusterSpec.secretEncryption.kms.ibmcloud.keyList[%d].keyVersion %d is used by another key", i, key.KeyVersion)
+			}
+			versions[key.KeyVersion] = true
+		}
+
+	default:
+		return fmt.Errorf("hostedClusterSpec.secretEncryption.kms.provider %q is not one of %s or %s", kms.Provider, hyp.AWS, hyp.IBMCloud)
+	}
+
+	return nil
+}
+
+// validateAWSKMSKeyARN checks an AWS kms key is an ARN of the kms service, ie arn:<partition>:kms:<region>:<account>:key/<id>
+// or an alias/<name>, in the region of the kms provider
+func validateAWSKMSKeyARN(field string, arn string, region string) error {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" || len(parts[1]) == 0 || parts[2] != "kms" || len(parts[4]) == 0 ||
+		!(strings.HasPrefix(parts[5], "key/") || strings.HasPrefix(parts[5], "alias/")) {
+		return fmt.Errorf("hostedClusterSpec.secretEncryption.kms.aws.%s.arn %q is not the ARN of a kms key", field, arn)
+	}
+
+	if parts[3] != region {
+		return fmt.Errorf("hostedClusterSpec.secretEncryption.kms.aws.%s.arn %q is not in the kms region %s", field, arn, region)
+	}
+
+	return nil
+}
+
+// validateAgentPlatform checks the namespace an Agent HostedClusterSpec searches the Agents in is a valid namespace name
+func validateAgentPlatform(hcSpec *hyp.HostedClusterSpec) error {
+	if hcSpec == nil || hcSpec.Platform.Agent == nil || len(hcSpec.Platform.Agent.AgentNamespace) == 0 {
+		return nil
+	}
+
+	if errs := validation.IsDNS1123Label(hcSpec.Platform.Agent.AgentNamespace); len(errs) != 0 {
+		return fmt.Errorf("hostedClusterSpec.platform.agent.agentNamespace %q is not a valid namespace name: %s",
+			hcSpec.Platform.Agent.AgentNamespace, strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+// validateAzurePlatform checks an Azure HostedClusterSpec has the resource group, vnet and subnet the
+// cloud provider needs on the HostedCluster
+func validateAzurePlatform(hcSpec *hyp.HostedClusterSpec) error {
+	if hcSpec == nil || hcSpec.Platform.Azure == nil {
+		return nil
+	}
+
+	ap := hcSpec.Platform.Azure
+	if len(ap.ResourceGroupName) == 0 {
+		return fmt.Errorf("hostedClusterSpec.platform.azure.resourceGroup is required")
+	}
+
+	if len(ap.VnetName) == 0 || len(ap.VnetID) == 0 {
+		return fmt.Errorf("hostedClusterSpec.platform.azure.vnetName and vnetID are required")
+	}
+
+	if len(ap.SubnetName) == 0 {
+		return fmt.Errorf("hostedClusterSpec.platform.azure.subnetName is required")
+	}
+
+	return nil
+}
+
+// validateAzureIdentity makes sure an Azure HypershiftDeployment authenticates with exactly one of the credentials
+// secret or the managed identity, and that the ids of the managed identity are UUIDs
+func validateAzureIdentity(hyd *hypdeployment.HypershiftDeployment) error {
+	identity := azureIdentity(hyd)
+	if identity == nil {
+		return nil
+	}
+
+	if hcSpec := hyd.Spec.HostedClusterSpec; hcSpec != nil {
+		if hcSpec.Platform.Azure == nil {
+			return fmt.Errorf("credentials.azureIdentity is only supported on the Azure platform, not %s", hcSpec.Platform.Type)
+		}
+
+		if len(hcSpec.Platform.Azure.Credentials.Name) != 0 {
+			return fmt.Errorf("hostedClusterSpec.platform.azure.credentials and credentials.azureIdentity are mutually exclusive, configure exactly one")
+		}
+
+		// the identity sets them on the HostedCluster, a different value would be silently replaced
+		ap := hcSpec.Platform.Azure
+		if len(ap.SubscriptionID) != 0 && ap.SubscriptionID != identity.SubscriptionID {
+			return fmt.Errorf("hostedClusterSpec.platform.azure.subscriptionID %q does not match credentials.azureIdentity.subscriptionID %q",
+				ap.SubscriptionID, identity.SubscriptionID)
+		}
+
+		if len(ap.MachineIdentityID) != 0 && len(identity.MachineIdentityID) != 0 && ap.MachineIdentityID != identity.MachineIdentityID {
+			return fmt.Errorf("hostedClusterSpec.platform.azure.machineIdentityID %q does not match credentials.azureIdentity.machineIdentityID %q",
+				ap.MachineIdentityID, identity.MachineIdentityID)
+		}
+	}
+
+	for _, id := range []struct {
+		field string
+		value string
+	}{
+		{"clientID", identity.ClientID},
+		{"tenantID", identity.TenantID},
+		{"subscriptionID", identity.SubscriptionID},
+	} {
+		if len(id.value) == 0 {
+			return fmt.Errorf("credentials.azureIdentity.%s is required", id.field)
+		}
+
+		if _, err := uuid.Parse(id.value); err != nil {
+			return fmt.Errorf("credentials.azureIdentity.%s %q is not a UUID", id.field, id.value)
+		}
+	}
+
+	return nil
+}
+
+// validateInfraID checks the HostedCluster of the payload carries the infra-id of the HypershiftDeployment, the
+// infra-id names the manifestwork and the resources of the HostingCluster
+func validateInfraID(infraID string, payload []workv1.Manifest) error {
+	hc := getHostedClusterInManifestPayload(&payload)
+	if hc == nil || len(hc.Spec.InfraID) == 0 || hc.Spec.InfraID == infraID {
+		return nil
+	}
+
+	return fmt.Errorf("hostedCluster infraID %q does not match infra-id %q", hc.Spec.InfraID, infraID)
+}
+
+// validateNodePoolBinding checks every NodePool of the payload is in the namespace of the HostedCluster and references
+// it by name, the HyperShift operator does not bind a NodePool to a HostedCluster of another namespace or name
+func validateNodePoolBinding(payload []workv1.Manifest) error {
+	hc := getHostedClusterInManifestPayload(&payload)
+	if hc == nil {
+		return nil
+	}
+
+	diverged := []string{}
+	for _, np := range getNodePoolsInManifestPayload(&payload) {
+		if np.Namespace != hc.Namespace || np.Spec.ClusterName != hc.Name {
+			diverged = append(diverged, fmt.Sprintf("nodePool %s/%s references cluster %s/%s", np.Namespace, np.Name, np.Namespace, np.Spec.ClusterName))
+		}
+	}
+
+	if len(diverged) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%s, the hostedCluster is %s/%s", strings.Join(diverged, ", "), hc.Namespace, hc.Name)
+}
+
+// validateProxy checks the proxy URLs are the http or https URLs the cluster-wide proxy accepts
+func validateProxy(proxy *hypdeployment.Proxy) error {
+	if proxy == nil {
+		return nil
+	}
+
+	check := func(field, value string, schemes ...string) error {
+		u, err := url.Parse(value)
+		if err != nil || len(u.Host) == 0 || !sets.NewString(schemes...).Has(u.Scheme) {
+			return fmt.Errorf("proxy.%s %q must be a URL with a host and a scheme among %s", field, value, strings.Join(schemes, ", "))
+		}
+
+		return nil
+	}
+
+	if len(proxy.HTTPProxy) != 0 {
+		if err := check("httpProxy", proxy.HTTPProxy, "http"); err != nil {
+			return err
+		}
+	}
+
+	if len(proxy.HTTPSProxy) != 0 {
+		if err := check("httpsProxy", proxy.HTTPSProxy, "http", "https"); err != nil {
+			return err
+		}
+	}
+
+	for i, e := range proxy.ReadinessEndpoints {
+		if err := check(fmt.Sprintf("readinessEndpoints[%d]", i), e, "http", "https"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateManifestWorkName checks the infra-id can name the manifestwork and its chunks. The infra-id also labels the
+// HypershiftDeployment and the payload, a valid label value fits in a name with the index of a chunk.
+func validateManifestWorkName(infraID string) error {
+	errs := validation.IsValidLabelValue(infraID)
+	errs = append(errs, validation.IsDNS1123Subdomain(infraID)...)
+	if len(errs) != 0 {
+		return fmt.Errorf("infra-id %q can not name the manifestwork: %s", infraID, strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+// validateClusterID checks the cluster ID override is a UUID in its canonical form
+func validateClusterID(clusterID string) error {
+	if len(clusterID) == 0 {
+		return nil
+	}
+
+	if _, err := uuid.Parse(clusterID); err != nil || len(clusterID) != 36 {
+		return fmt.Errorf("cluster-id %q is not a valid UUID", clusterID)
+	}
+
+	return nil
+}
+
+// validateFeedbackRules checks the JSONPaths of the custom feedback rules parse the way the work agent reads them,
+// and that every rule reports its own condition
+func validateFeedbackRules(rules []hypdeployment.FeedbackRule) error {
+	seen := sets.NewString()
+	for i, r := range rules {
+		field := fmt.Sprintf("feedbackRules[%d]", i)
+
+		switch r.Resource {
+		case hypdeployment.FeedbackHostedCluster, hypdeployment.FeedbackNodePool:
+		default:
+			return fmt.Errorf("%s resource %q is not supported, must be one of HostedCluster, NodePool", field, r.Resource)
+		}
+
+		if !feedbackRuleName.MatchString(r.Name) {
+			return fmt.Errorf("%s name %q must be UpperCamelCase", field, r.Name)
+		}
+
+		condType := feedbackRuleConditionType(r)
+		if condType == hypdeployment.HostedClusterAvailable || condType == hypdeployment.HostedClusterProgress {
+			return fmt.Errorf("%s name %q is reserved for the %s condition", field, r.Name, condType)
+		}
+
+		if seen.Has(string(condType)) {
+			return fmt.Errorf("%s name %q is already used by another %s rule", field, r.Name, r.Resource)
+		}
+		seen.Insert(string(condType))
+
+		if len(strings.TrimSpace(r.Path)) == 0 {
+			return fmt.Errorf("%s path is empty", field)
+		}
+
+		if err := jsonpath.New(r.Name).Parse(fmt.Sprintf("{%s}", r.Path)); err != nil {
+			return fmt.Errorf("%s path %q is not a valid JSONPath: %v", field, r.Path, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+// Copyright Contributors to the Open Cluster Management project.
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/helper"
+)
+
+// ValidatePath is the path the HypershiftDeployment validating webhook is served on
+const ValidatePath = "/validate-cluster-open-cluster-management-io-v1alpha1-hypershiftdeployment"
+
+// Validator rejects the updates of a HypershiftDeployment changing a field the ManifestWork already created for it
+// depends on: the infra-id names the ManifestWork and the resources of the HostingCluster, the platform type selects
+// the infrastructure and the hosting cluster is the namespace of the ManifestWork. The other fields, like the
+// replicas, release image and autoscaling of the NodePools, are reconciled and can be edited.
+type Validator struct {
+	Client  client.Client
+	decoder *admission.Decoder
+}
+
+//+kubebuilder:webhook:path=/validate-cluster-open-cluster-management-io-v1alpha1-hypershiftdeployment,mutating=false,failurePolicy=fail,sideEffects=None,groups=cluster.open-cluster-management.io,resources=hypershiftdeployments,verbs=update,versions=v1alpha1,name=vhypershiftdeployment.open-cluster-management.io,admissionReviewVersions=v1
+
+// SetupWithManager serves the validating webhook with the webhook server of the manager
+func (v *Validator) SetupWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(ValidatePath, &ctrlwebhook.Admission{Handler: v})
+
+	return nil
+}
+
+// InjectDecoder is called by the webhook server with a decoder of the manager scheme
+func (v *Validator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+
+	return nil
+}
+
+func (v *Validator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+
+	oldHyd := &hypdeployment.HypershiftDeployment{}
+	if err := v.decoder.DecodeRaw(req.OldObject, oldHyd); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	newHyd := &hypdeployment.HypershiftDeployment{}
+	if err := v.decoder.Decode(req, newHyd); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	violations, err := v.immutableFieldChanges(ctx, oldHyd, newHyd)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	if len(violations) != 0 {
+		return admission.Denied(fmt.Sprintf("HypershiftDeployment %s/%s can not be updated: %s",
+			newHyd.Namespace, newHyd.Name, strings.Join(violations, ", ")))
+	}
+
+	return admission.Allowed("")
+}
+
+// immutableFieldChanges lists the immutable fields changed by the update. A field that was not set yet can be set,
+// the controller sets the infra-id and the platform type when it scaffolds the HypershiftDeployment. A field that is
+// set can not be unset, an update unsetting it and the next one setting another value would change it
+func (v *Validator) immutableFieldChanges(ctx context.Context, oldHyd, newHyd *hypdeployment.HypershiftDeployment) ([]string, error) {
+	violations := []string{}
+
+	if len(oldHyd.Spec.InfraID) != 0 && oldHyd.Spec.InfraID != newHyd.Spec.InfraID {
+		violations = append(violations, fmt.Sprintf("spec.infraID is immutable, it can not be changed from %q to %q",
+			oldHyd.Spec.InfraID, newHyd.Spec.InfraID))
+	}
+
+	if oldType, newType := platformType(oldHyd), platformType(newHyd); len(oldType) != 0 && oldType != newType {
+		violations = append(violations, platformChange("spec.hostedClusterSpec.platform.type", oldType, newType))
+	}
+
+	if oldPlatform, newPlatform := infrastructurePlatform(oldHyd), infrastructurePlatform(newHyd); len(oldPlatform) != 0 && oldPlatform != newPlatform {
+		violations = append(violations, platformChange("spec.infrastructure.platform", oldPlatform, newPlatform))
+	}
+
+	if oldCluster, newCluster := helper.GetHostingCluster(oldHyd), helper.GetHostingCluster(newHyd); oldCluster != newCluster && len(oldHyd.Spec.InfraID) != 0 {
+		key := types.NamespacedName{Namespace: oldCluster, Name: oldHyd.Spec.InfraID}
+		if err := v.Client.Get(ctx, key, &workv1.ManifestWork{}); err != nil {
+			if !k8serrors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to get the ManifestWork %s: %w", key, err)
+			}
+		} else {
+			violations = append(violations, fmt.Sprintf("spec.hostingCluster is immutable once the ManifestWork %s exists, it can not be changed from %s to %s",
+				key, oldCluster, newCluster))
+		}
+	}
+
+	return violations, nil
+}
+
+func platformChange(field, oldPlatform, newPlatform string) string {
+	if len(newPlatform) == 0 {
+		return fmt.Sprintf("%s is immutable, it can not be unset from %s", field, oldPlatform)
+	}
+
+	return fmt.Sprintf("%s is immutable, it can not be changed from %s to %s", field, oldPlatform, newPlatform)
+}
+
+func platformType(hyd *hypdeployment.HypershiftDeployment) string {
+	if hyd.Spec.HostedClusterSpec == nil {
+		return ""
+	}
+
+	return string(hyd.Spec.HostedClusterSpec.Platform.Type)
+}
+
+// infrastructurePlatform is the cloud the controller configures the infrastructure in and scaffolds the platform from
+func infrastructurePlatform(hyd *hypdeployment.HypershiftDeployment) string {
+	switch p := hyd.Spec.Infrastructure.Platform; {
+	case p == nil:
+		return ""
+	case p.AWS != nil:
+		return "aws"
+	case p.Azure != nil:
+		return "azure"
+	}
+
+	return ""
+}
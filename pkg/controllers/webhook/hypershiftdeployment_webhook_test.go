@@ -0,0 +1,202 @@
+// Copyright Contributors to the Open Cluster Management project.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	hyp "github.com/openshift/hypershift/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	hydapi "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+var s = clientgoscheme.Scheme
+
+func init() {
+	clientgoscheme.AddToScheme(s)
+
+	hydapi.AddToScheme(s)
+
+	workv1.AddToScheme(s)
+}
+
+func GetHypershiftDeployment() *hydapi.HypershiftDeployment {
+	replicas := int32(2)
+	return &hydapi.HypershiftDeployment{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "test1",
+			Namespace: "testns",
+		},
+		Spec: hydapi.HypershiftDeploymentSpec{
+			InfraID:        "test1-abcde",
+			HostingCluster: "local-cluster",
+			HostedClusterSpec: &hyp.HostedClusterSpec{
+				Platform: hyp.PlatformSpec{Type: hyp.AWSPlatform},
+				Release:  hyp.Release{Image: "quay.io/openshift-release-dev/ocp-release:4.10.15-x86_64"},
+			},
+			Infrastructure: hydapi.InfraSpec{
+				Configure: true,
+				Platform:  &hydapi.Platforms{AWS: &hydapi.AWSPlatform{Region: "us-east-1"}},
+			},
+			NodePools: []*hydapi.HypershiftNodePools{{
+				Name: "test1",
+				Spec: hyp.NodePoolSpec{Replicas: &replicas},
+			}},
+		},
+	}
+}
+
+func GetValidator(objs ...client.Object) *Validator {
+	v := &Validator{Client: clientfake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()}
+	decoder, _ := admission.NewDecoder(s)
+	v.InjectDecoder(decoder)
+
+	return v
+}
+
+func updateRequest(t *testing.T, oldHyd, newHyd *hydapi.HypershiftDeployment) admission.Request {
+	oldRaw, err := json.Marshal(oldHyd)
+	assert.Nil(t, err, "is nil when the old HypershiftDeployment is serialized")
+	newRaw, err := json.Marshal(newHyd)
+	assert.Nil(t, err, "is nil when the new HypershiftDeployment is serialized")
+
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: admissionv1.Update,
+		OldObject: runtime.RawExtension{Raw: oldRaw},
+		Object:    runtime.RawExtension{Raw: newRaw},
+	}}
+}
+
+func getManifestWork() *workv1.ManifestWork {
+	return &workv1.ManifestWork{ObjectMeta: v1.ObjectMeta{Namespace: "local-cluster", Name: "test1-abcde"}}
+}
+
+func TestCreateIsAllowed(t *testing.T) {
+	raw, _ := json.Marshal(GetHypershiftDeployment())
+	res := GetValidator().Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: raw},
+	}})
+	assert.True(t, res.Allowed, "a HypershiftDeployment is created with any value")
+}
+
+func TestMutableFieldsAreAllowed(t *testing.T) {
+	oldHyd := GetHypershiftDeployment()
+	newHyd := oldHyd.DeepCopy()
+	replicas := int32(5)
+	newHyd.Spec.NodePools[0].Spec.Replicas = &replicas
+	newHyd.Spec.NodePools[0].Spec.Release.Image = "quay.io/openshift-release-dev/ocp-release:4.10.16-x86_64"
+	newHyd.Spec.HostedClusterSpec.Release.Image = "quay.io/openshift-release-dev/ocp-release:4.10.16-x86_64"
+	newHyd.Spec.NodePools = append(newHyd.Spec.NodePools, &hydapi.HypershiftNodePools{
+		Name: "test1-autoscaled",
+		Spec: hyp.NodePoolSpec{AutoScaling: &hyp.NodePoolAutoScaling{Min: 1, Max: 3}},
+	})
+
+	res := GetValidator(getManifestWork()).Handle(context.Background(), updateRequest(t, oldHyd, newHyd))
+	assert.True(t, res.Allowed, "the replicas, release image and autoscaling can be changed")
+}
+
+func TestUnsetFieldsCanBeSet(t *testing.T) {
+	oldHyd := GetHypershiftDeployment()
+	oldHyd.Spec.InfraID = ""
+	oldHyd.Spec.HostedClusterSpec.Platform.Type = ""
+	newHyd := GetHypershiftDeployment()
+
+	res := GetValidator().Handle(context.Background(), updateRequest(t, oldHyd, newHyd))
+	assert.True(t, res.Allowed, "the infra-id and platform type scaffolded by the controller are set")
+}
+
+func TestInfraIDIsImmutable(t *testing.T) {
+	oldHyd := GetHypershiftDeployment()
+	newHyd := oldHyd.DeepCopy()
+	newHyd.Spec.InfraID = "test1-fghij"
+
+	res := GetValidator().Handle(context.Background(), updateRequest(t, oldHyd, newHyd))
+	assert.False(t, res.Allowed, "the infra-id can not be changed")
+	assert.Equal(t, `HypershiftDeployment testns/test1 can not be updated: spec.infraID is immutable, it can not be changed from "test1-abcde" to "test1-fghij"`,
+		string(res.Result.Reason))
+}
+
+func TestPlatformTypeIsImmutable(t *testing.T) {
+	oldHyd := GetHypershiftDeployment()
+	newHyd := oldHyd.DeepCopy()
+	newHyd.Spec.HostedClusterSpec.Platform.Type = hyp.AzurePlatform
+
+	res := GetValidator().Handle(context.Background(), updateRequest(t, oldHyd, newHyd))
+	assert.False(t, res.Allowed, "the platform type can not be changed")
+	assert.Contains(t, string(res.Result.Reason), "spec.hostedClusterSpec.platform.type is immutable, it can not be changed from AWS to Azure")
+}
+
+func TestPlatformTypeCanNotBeUnsetThenChanged(t *testing.T) {
+	oldHyd := GetHypershiftDeployment()
+	unsetHyd := oldHyd.DeepCopy()
+	unsetHyd.Spec.HostedClusterSpec = nil
+
+	res := GetValidator().Handle(context.Background(), updateRequest(t, oldHyd, unsetHyd))
+	assert.False(t, res.Allowed, "the hostedClusterSpec carrying the platform type can not be dropped")
+	assert.Contains(t, string(res.Result.Reason), "spec.hostedClusterSpec.platform.type is immutable, it can not be unset from AWS")
+
+	// the second step of the bypass, from an unset platform type, is only reached when the first one is admitted
+	changedHyd := oldHyd.DeepCopy()
+	changedHyd.Spec.HostedClusterSpec.Platform.Type = hyp.AzurePlatform
+	res = GetValidator().Handle(context.Background(), updateRequest(t, oldHyd, changedHyd))
+	assert.False(t, res.Allowed, "the platform type can not be changed")
+}
+
+func TestInfrastructurePlatformIsImmutable(t *testing.T) {
+	oldHyd := GetHypershiftDeployment()
+	newHyd := oldHyd.DeepCopy()
+	newHyd.Spec.Infrastructure.Platform = &hydapi.Platforms{Azure: &hydapi.AzurePlatform{Location: "eastus"}}
+
+	res := GetValidator().Handle(context.Background(), updateRequest(t, oldHyd, newHyd))
+	assert.False(t, res.Allowed, "the infrastructure platform can not be changed")
+	assert.Contains(t, string(res.Result.Reason), "spec.infrastructure.platform is immutable, it can not be changed from aws to azure")
+
+	newHyd.Spec.Infrastructure.Platform = nil
+	res = GetValidator().Handle(context.Background(), updateRequest(t, oldHyd, newHyd))
+	assert.False(t, res.Allowed, "the infrastructure platform can not be unset")
+	assert.Contains(t, string(res.Result.Reason), "spec.infrastructure.platform is immutable, it can not be unset from aws")
+
+	oldHyd.Spec.Infrastructure.Platform = nil
+	newHyd.Spec.Infrastructure.Platform = &hydapi.Platforms{AWS: &hydapi.AWSPlatform{Region: "us-east-1"}}
+	res = GetValidator().Handle(context.Background(), updateRequest(t, oldHyd, newHyd))
+	assert.True(t, res.Allowed, "an unset infrastructure platform can be set")
+}
+
+func TestHostingClusterIsImmutableWithManifestWork(t *testing.T) {
+	oldHyd := GetHypershiftDeployment()
+	newHyd := oldHyd.DeepCopy()
+	newHyd.Spec.HostingCluster = "cluster1"
+
+	res := GetValidator().Handle(context.Background(), updateRequest(t, oldHyd, newHyd))
+	assert.True(t, res.Allowed, "the hosting cluster can be changed until the ManifestWork is created")
+
+	res = GetValidator(getManifestWork()).Handle(context.Background(), updateRequest(t, oldHyd, newHyd))
+	assert.False(t, res.Allowed, "the hosting cluster can not be changed once the ManifestWork exists")
+	assert.Contains(t, string(res.Result.Reason), "spec.hostingCluster is immutable once the ManifestWork local-cluster/test1-abcde exists, it can not be changed from local-cluster to cluster1")
+}
+
+func TestAllImmutableFieldChangesAreListed(t *testing.T) {
+	oldHyd := GetHypershiftDeployment()
+	newHyd := oldHyd.DeepCopy()
+	newHyd.Spec.InfraID = "test1-fghij"
+	newHyd.Spec.HostedClusterSpec.Platform.Type = hyp.KubevirtPlatform
+	newHyd.Spec.HostingCluster = "cluster1"
+
+	res := GetValidator(getManifestWork()).Handle(context.Background(), updateRequest(t, oldHyd, newHyd))
+	assert.False(t, res.Allowed, "the update is denied")
+	assert.Contains(t, string(res.Result.Reason), "spec.infraID is immutable")
+	assert.Contains(t, string(res.Result.Reason), "spec.hostedClusterSpec.platform.type is immutable")
+	assert.Contains(t, string(res.Result.Reason), "spec.hostingCluster is immutable")
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	workv1 "open-cluster-management.io/api/work/v1"
+)
+
+// WorkAPIAvailable reports whether the hub serves the ManifestWork API. Without it the manifestwork watch keeps the
+// manager from starting, so it is checked once before the reconciler is set up.
+func WorkAPIAvailable(mapper meta.RESTMapper) (bool, error) {
+	gvk := workv1.GroupVersion.WithKind("ManifestWork")
+	if _, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to discover the %s API, err: %w", gvk.GroupVersion(), err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	hyd "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+)
+
+func TestWorkAPIAvailable(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{hyd.GroupVersion})
+	mapper.Add(hyd.GroupVersion.WithKind("HypershiftDeployment"), meta.RESTScopeNamespace)
+
+	available, err := WorkAPIAvailable(mapper)
+	assert.Nil(t, err, "err nil when the work API is not served")
+	assert.False(t, available, "the work API is not served")
+
+	mapper.Add(workv1.GroupVersion.WithKind("ManifestWork"), meta.RESTScopeNamespace)
+
+	available, err = WorkAPIAvailable(mapper)
+	assert.Nil(t, err, "err nil when the work API is served")
+	assert.True(t, available, "the work API is served")
+}
+
+func TestWorkAPIUnavailableCondition(t *testing.T) {
+	ctx := context.Background()
+
+	// the hub does not know about the ManifestWork type
+	scheme := runtime.NewScheme()
+	hyd.AddToScheme(scheme)
+	corev1.AddToScheme(scheme)
+	client := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	testHD := getHDforManifestWork()
+	testHD.Spec.HostingCluster = "local-cluster"
+	assert.Nil(t, client.Create(ctx, testHD), "is nil when the HypershiftDeployment is created")
+	assert.Nil(t, client.Create(ctx, getPullSecret(testHD)), "is nil when the pull secret is created")
+
+	hdr := &HypershiftDeploymentReconciler{
+		Client:             client,
+		Log:                ctrl.Log.WithName("tester"),
+		WorkAPIUnavailable: true,
+	}
+
+	res, err := hdr.Reconcile(ctx, ctrl.Request{NamespacedName: getNN})
+	assert.Nil(t, err, "err nil when the missing work API is reported")
+	assert.Equal(t, ctrl.Result{}, res, "not requeued until the controller is restarted")
+
+	var resultHD hyd.HypershiftDeployment
+	assert.Nil(t, client.Get(ctx, getNN, &resultHD), "is nil when HypershiftDeployment resource is found")
+
+	c := meta.FindStatusCondition(resultHD.Status.Conditions, string(hyd.WorkAPIUnavailable))
+	assert.NotNil(t, c, "WorkAPIUnavailable condition is reported")
+	assert.Equal(t, metav1.ConditionTrue, c.Status)
+	assert.Equal(t, hyd.WorkAPINotInstalledReason, c.Reason)
+	assert.Contains(t, c.Message, workv1.GroupVersion.String())
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/component-base/featuregate"
+)
+
+const (
+	// ManifestWorkServerSideApply applies the manifestwork with a server side apply patch owned by the
+	// controller, instead of a get and update
+	ManifestWorkServerSideApply featuregate.Feature = "ManifestWorkServerSideApply"
+)
+
+// defaultFeatureGates are the features of the controller, all of them are off by default
+var defaultFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
+	ManifestWorkServerSideApply: {Default: false, PreRelease: featuregate.Alpha},
+}
+
+// NewFeatureGate returns a feature gate with the features of the controller registered, it is set
+// with --feature-gates=Feature1=true,Feature2=false
+func NewFeatureGate() featuregate.MutableFeatureGate {
+	gate := featuregate.NewFeatureGate()
+	utilruntime.Must(gate.Add(defaultFeatureGates))
+	return gate
+}
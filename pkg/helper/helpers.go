@@ -1,10 +1,16 @@
 package helper
 
 import (
+	"crypto/sha256"
 	"fmt"
+	"strings"
 
 	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
 	hydclient "github.com/stolostron/hypershift-deployment-controller/pkg/client"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/util/sets"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
 	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
@@ -30,8 +36,101 @@ func ManagedClusterName(hyd *hypdeployment.HypershiftDeployment) string {
 	return hyd.Spec.InfraID
 }
 
-// TODO(zhujian7) get this from hyd.Status.Kubeconfig
+const (
+	// infraIDSuffixLength is the length of the suffix appended to the name, like the hypershift CLI
+	infraIDSuffixLength = 5
+
+	// maxInfraIDLength keeps the infra-id a valid label value, it labels the HypershiftDeployment and the payload
+	maxInfraIDLength = 63
+
+	// infraIDAlphabet is the alphabet of utilrand.String
+	infraIDAlphabet = "bcdfghjklmnpqrstvwxz2456789"
+)
+
+// NewInfraID returns a unique infra-id for the HypershiftDeployment name
+func NewInfraID(name string) string {
+	return fmt.Sprintf("%s-%s", name, utilrand.String(infraIDSuffixLength))
+}
+
+// InfraIDFor returns the infra-id of the HypershiftDeployment name, the suffix is derived from the uid so the
+// same HypershiftDeployment always gets the same infra-id. A random suffix is used when there is no uid.
+func InfraIDFor(name string, uid types.UID) string {
+	if len(uid) == 0 {
+		return NewInfraID(name)
+	}
+
+	sum := sha256.Sum256([]byte(uid))
+	suffix := make([]byte, infraIDSuffixLength)
+	for i := range suffix {
+		suffix[i] = infraIDAlphabet[int(sum[i])%len(infraIDAlphabet)]
+	}
+
+	return fmt.Sprintf("%s-%s", name, suffix)
+}
+
+// ValidateInfraIDName checks an infra-id generated from the HypershiftDeployment name fits in a label value
+func ValidateInfraIDName(name string) error {
+	if maxNameLength := maxInfraIDLength - infraIDSuffixLength - 1; len(name) > maxNameLength {
+		return fmt.Errorf("name %q is too long to generate an infra-id, it must be at most %d characters or spec.infra-id must be set", name, maxNameLength)
+	}
+
+	return nil
+}
+
+// CloneHypershiftDeployment returns a copy of src named newName, in the same namespace, with a new infra-id. The
+// clone shares nothing with src: its status, finalizers and resourceVersion are cleared and the cluster ID is
+// generated again. The NodePools named after src are renamed after the clone so both can share a hosting namespace.
+func CloneHypershiftDeployment(src *hypdeployment.HypershiftDeployment, newName string) *hypdeployment.HypershiftDeployment {
+	clone := &hypdeployment.HypershiftDeployment{
+		TypeMeta: src.TypeMeta,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        newName,
+			Namespace:   src.Namespace,
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
+		},
+		Spec: *src.Spec.DeepCopy(),
+	}
+
+	for k, v := range src.Labels {
+		clone.Labels[k] = v
+	}
+
+	// the annotations mirroring the state of src, or asking for a one time action on src, are not copied
+	for k, v := range src.Annotations {
+		switch k {
+		case constant.PhaseAnnotation, constant.LastAppliedHashAnnotation, constant.CollectSupportAnnotation, constant.RefreshReleaseAnnotation:
+			continue
+		}
+		clone.Annotations[k] = v
+	}
+
+	clone.Spec.InfraID = NewInfraID(newName)
+	clone.Labels[constant.InfraLabelName] = clone.Spec.InfraID
+
+	clone.Spec.ClusterID = ""
+	if clone.Spec.HostedClusterSpec != nil {
+		clone.Spec.HostedClusterSpec.InfraID = clone.Spec.InfraID
+		clone.Spec.HostedClusterSpec.ClusterID = ""
+	}
+
+	for _, np := range clone.Spec.NodePools {
+		if np.Name == src.Name || strings.HasPrefix(np.Name, src.Name+"-") {
+			np.Name = newName + strings.TrimPrefix(np.Name, src.Name)
+		}
+		np.Spec.ClusterName = newName
+	}
+
+	return clone
+}
+
+// HostedKubeconfigName is the name of the admin kubeconfig synced from the HostingCluster to its namespace on the hub,
+// <hosting namespace>-<secret name>. The secret is the one of status.kubeconfig, or the HyperShift default before it
+// is reported
 func HostedKubeconfigName(hyd *hypdeployment.HypershiftDeployment) string {
+	if kubeconfig := hyd.Status.Kubeconfig; kubeconfig != nil {
+		return fmt.Sprintf("%s-%s", kubeconfig.Namespace, kubeconfig.Name)
+	}
 	return fmt.Sprintf("%s-%s-admin-kubeconfig", GetHostingNamespace(hyd), hyd.GetName())
 }
 
@@ -90,3 +189,43 @@ func IsClusterInClusterSet(client client.Client,
 
 	return false, nil
 }
+
+// SideEffectDone returns true when the side effecting operation already ran for the token
+func SideEffectDone(hyd *hypdeployment.HypershiftDeployment, operation, token string) bool {
+	done, ok := hyd.Status.IdempotencyKeys[operation]
+	return ok && done == token
+}
+
+// RecordSideEffect stores the token of the side effecting operation in the status, the caller
+// is responsible for persisting the status
+func RecordSideEffect(hyd *hypdeployment.HypershiftDeployment, operation, token string) {
+	if hyd.Status.IdempotencyKeys == nil {
+		hyd.Status.IdempotencyKeys = map[string]string{}
+	}
+	hyd.Status.IdempotencyKeys[operation] = token
+}
+
+// GetPhase derives the phase of a HypershiftDeployment from its conditions, a problem reported
+// by any condition takes precedence over the HostedCluster being available
+func GetPhase(hyd *hypdeployment.HypershiftDeployment) hypdeployment.CurrentPhase {
+	if hyd.DeletionTimestamp != nil {
+		return hypdeployment.PhaseDeleting
+	}
+
+	available := false
+	for _, c := range hyd.Status.Conditions {
+		if c.Reason == hypdeployment.MisConfiguredReason || c.Reason == hypdeployment.CircuitOpenReason {
+			return hypdeployment.PhaseFailed
+		}
+
+		if c.Type == string(hypdeployment.HostedClusterAvailable) && c.Status == metav1.ConditionTrue {
+			available = true
+		}
+	}
+
+	if available {
+		return hypdeployment.PhaseReady
+	}
+
+	return hypdeployment.PhaseProvisioning
+}
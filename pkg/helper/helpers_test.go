@@ -3,8 +3,11 @@ package helper
 import (
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 
+	hyp "github.com/openshift/hypershift/api/v1alpha1"
+
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
@@ -13,6 +16,9 @@ import (
 	cliScheme "k8s.io/client-go/kubernetes/scheme"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
 	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+
+	hypdeployment "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/constant"
 )
 
 var (
@@ -330,3 +336,122 @@ func TestIsClusterInClusterSet(t *testing.T) {
 		}
 	}
 }
+
+func TestCloneHypershiftDeployment(t *testing.T) {
+	src := &hypdeployment.HypershiftDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "cluster1",
+			Namespace:       "default",
+			UID:             "uid-1",
+			ResourceVersion: "42",
+			Generation:      3,
+			Finalizers:      []string{constant.DestroyFinalizer},
+			Labels:          map[string]string{constant.InfraLabelName: "cluster1-abcde", "env": "dev"},
+			Annotations: map[string]string{
+				constant.PhaseAnnotation:           string(hypdeployment.PhaseReady),
+				constant.LastAppliedHashAnnotation: "1234",
+				"team":                             "hcp",
+			},
+		},
+		Spec: hypdeployment.HypershiftDeploymentSpec{
+			InfraID:        "cluster1-abcde",
+			ClusterID:      "5a0b1b2e-a6b5-4b6a-9f1e-3e0c4b7f6d21",
+			HostingCluster: "local-cluster",
+			HostedClusterSpec: &hyp.HostedClusterSpec{
+				InfraID:   "cluster1-abcde",
+				ClusterID: "5a0b1b2e-a6b5-4b6a-9f1e-3e0c4b7f6d21",
+				Release:   hyp.Release{Image: "quay.io/openshift-release-dev/ocp-release:4.10.15-x86_64"},
+			},
+			NodePools: []*hypdeployment.HypershiftNodePools{
+				{Name: "cluster1", Spec: hyp.NodePoolSpec{ClusterName: "cluster1"}},
+				{Name: "cluster1-gpu", Spec: hyp.NodePoolSpec{ClusterName: "cluster1"}},
+				{Name: "workers", Spec: hyp.NodePoolSpec{ClusterName: "cluster1"}},
+			},
+		},
+		Status: hypdeployment.HypershiftDeploymentStatus{
+			Conditions: []metav1.Condition{{Type: string(hypdeployment.HostedClusterAvailable), Status: metav1.ConditionTrue}},
+		},
+	}
+
+	clone := CloneHypershiftDeployment(src, "cluster2")
+
+	if clone.Name != "cluster2" || clone.Namespace != src.Namespace {
+		t.Errorf("Expect the clone default/cluster2, return: %s/%s", clone.Namespace, clone.Name)
+	}
+	if len(clone.UID) != 0 || len(clone.ResourceVersion) != 0 || clone.Generation != 0 || len(clone.Finalizers) != 0 {
+		t.Errorf("Expect the clone to have no identity, return: %v", clone.ObjectMeta)
+	}
+	if !reflect.DeepEqual(clone.Status, hypdeployment.HypershiftDeploymentStatus{}) {
+		t.Errorf("Expect an empty status, return: %v", clone.Status)
+	}
+
+	if clone.Spec.InfraID == src.Spec.InfraID || !strings.HasPrefix(clone.Spec.InfraID, "cluster2-") {
+		t.Errorf("Expect a fresh infra-id for cluster2, return: %s", clone.Spec.InfraID)
+	}
+	if clone.Spec.HostedClusterSpec.InfraID != clone.Spec.InfraID || clone.Labels[constant.InfraLabelName] != clone.Spec.InfraID {
+		t.Errorf("Expect the HostedCluster and label to use the infra-id %s, return: %s and %s", clone.Spec.InfraID,
+			clone.Spec.HostedClusterSpec.InfraID, clone.Labels[constant.InfraLabelName])
+	}
+	if len(clone.Spec.ClusterID) != 0 || len(clone.Spec.HostedClusterSpec.ClusterID) != 0 {
+		t.Errorf("Expect the cluster ID to be generated again, return: %s and %s", clone.Spec.ClusterID, clone.Spec.HostedClusterSpec.ClusterID)
+	}
+	if clone.Spec.HostingCluster != src.Spec.HostingCluster || clone.Spec.HostedClusterSpec.Release != src.Spec.HostedClusterSpec.Release {
+		t.Errorf("Expect the spec to be copied, return: %v", clone.Spec)
+	}
+
+	if !reflect.DeepEqual(clone.Labels, map[string]string{constant.InfraLabelName: clone.Spec.InfraID, "env": "dev"}) {
+		t.Errorf("Expect the labels to be copied, return: %v", clone.Labels)
+	}
+	if !reflect.DeepEqual(clone.Annotations, map[string]string{"team": "hcp"}) {
+		t.Errorf("Expect the annotations mirroring the source state to be dropped, return: %v", clone.Annotations)
+	}
+
+	names := []string{}
+	for _, np := range clone.Spec.NodePools {
+		names = append(names, np.Name)
+		if np.Spec.ClusterName != "cluster2" {
+			t.Errorf("Expect NodePool %s to belong to cluster2, return: %s", np.Name, np.Spec.ClusterName)
+		}
+	}
+	if !reflect.DeepEqual(names, []string{"cluster2", "cluster2-gpu", "workers"}) {
+		t.Errorf("Expect the NodePools named after the source to be renamed, return: %v", names)
+	}
+
+	// the clone is independent of the source
+	clone.Labels["env"] = "prod"
+	clone.Spec.HostedClusterSpec.Release.Image = "quay.io/openshift-release-dev/ocp-release:4.10.16-x86_64"
+	clone.Spec.NodePools[2].Spec.ClusterName = "other"
+	if src.Labels["env"] != "dev" || src.Spec.HostedClusterSpec.Release.Image != "quay.io/openshift-release-dev/ocp-release:4.10.15-x86_64" ||
+		src.Spec.NodePools[2].Spec.ClusterName != "cluster1" || src.Spec.NodePools[0].Name != "cluster1" || src.Spec.InfraID != "cluster1-abcde" {
+		t.Errorf("Expect the source to be unchanged by the clone, return: %v", src)
+	}
+
+	if other := CloneHypershiftDeployment(src, "cluster2"); other.Spec.InfraID == clone.Spec.InfraID {
+		t.Errorf("Expect each clone to get a fresh infra-id, return: %s twice", clone.Spec.InfraID)
+	}
+}
+
+func TestInfraIDFor(t *testing.T) {
+	id := InfraIDFor("cluster1", "uid-1")
+	if !strings.HasPrefix(id, "cluster1-") || len(id) != len("cluster1-")+infraIDSuffixLength {
+		t.Errorf("Expect cluster1-<5 characters>, return: %s", id)
+	}
+	if again := InfraIDFor("cluster1", "uid-1"); again != id {
+		t.Errorf("Expect the same infra-id for the same uid, return: %s and %s", id, again)
+	}
+	if other := InfraIDFor("cluster1", "uid-2"); other == id {
+		t.Errorf("Expect another infra-id for another uid, return: %s", other)
+	}
+	if random := InfraIDFor("cluster1", ""); !strings.HasPrefix(random, "cluster1-") {
+		t.Errorf("Expect a random infra-id without uid, return: %s", random)
+	}
+}
+
+func TestValidateInfraIDName(t *testing.T) {
+	if err := ValidateInfraIDName(strings.Repeat("a", 57)); err != nil {
+		t.Errorf("Expect no error for a 57 characters name, return: %v", err)
+	}
+	if err := ValidateInfraIDName(strings.Repeat("a", 58)); err == nil {
+		t.Errorf("Expect an error for a 58 characters name")
+	}
+}
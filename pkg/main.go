@@ -20,6 +20,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -42,6 +44,10 @@ import (
 	clusteropenclustermanagementiov1alpha1 "github.com/stolostron/hypershift-deployment-controller/api/v1alpha1"
 	"github.com/stolostron/hypershift-deployment-controller/pkg/controllers"
 	"github.com/stolostron/hypershift-deployment-controller/pkg/controllers/autoimport"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/controllers/phasenotifier"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/controllers/summary"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/controllers/webhook"
+	"github.com/stolostron/hypershift-deployment-controller/pkg/features"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -70,6 +76,25 @@ func main() {
 	var probeAddr string
 	var enableLeaderElection bool
 	var validateClusterSecurity bool
+	var validatePermissions bool
+	var validateSpecSchema bool
+	var manifestWorkGracePeriod time.Duration
+	var manifestWorkConflictRetries int
+	var manifestWorkChunkSize int
+	var scaleDownGuardPercent int
+	var circuitBreakerThreshold int
+	var circuitBreakerCooldown time.Duration
+	var enableSummary bool
+	var enableWebhook bool
+	var reconcileBudget int
+	var reconcileBudgetWindow time.Duration
+	var reconcileTimeout time.Duration
+	var feedbackStaleAfter time.Duration
+	var feedbackPollInterval time.Duration
+	var deprovisionStuckAfter time.Duration
+	var phaseWebhookURL string
+	instanceTypeAliases := &controllers.InstanceTypeAliases{}
+	featureGate := features.NewFeatureGate()
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
@@ -78,6 +103,63 @@ func main() {
 	flag.BoolVar(&validateClusterSecurity, "validate-cluster-security", false,
 		"Enable HypershiftDeployment cluster security validation. "+
 			"Enabling this will ensure a HypershiftDeployment CR has the right permission to work on a given hosting cluster.")
+	flag.BoolVar(&validatePermissions, "validate-permissions", false,
+		"Enable the manifestwork permission check. "+
+			"Enabling this will report the denied verb in the InsufficientPermissions condition before the manifestwork is written.")
+	flag.BoolVar(&validateSpecSchema, "validate-spec-schema", false,
+		"Enable the HypershiftDeployment spec validation. "+
+			"Enabling this will report the field errors in the SpecInvalid condition before the HostedCluster and NodePools are scaffolded.")
+	flag.DurationVar(&manifestWorkGracePeriod, "manifestwork-grace-period", 0,
+		"How long to wait after a HypershiftDeployment is created before creating its manifestwork. "+
+			"This gives other controllers time to create the dependent resources.")
+	flag.IntVar(&manifestWorkConflictRetries, "manifestwork-conflict-retries", 0,
+		"Number of times a manifestwork write that conflicts with another writer is retried on the latest manifestwork "+
+			"before the ManifestWorkConflict condition is set. Set to 0 to disable the retries.")
+	flag.IntVar(&circuitBreakerThreshold, "circuit-breaker-threshold", 5,
+		"Number of consecutive manifestwork apply failures on a hosting cluster before its HypershiftDeployments stop being reconciled. "+
+			"Set to 0 to disable the circuit breaker.")
+	flag.DurationVar(&circuitBreakerCooldown, "circuit-breaker-cooldown", 5*time.Minute,
+		"How long the circuit of a failing hosting cluster stays open before a trial reconcile is allowed.")
+	flag.IntVar(&reconcileBudget, "reconcile-budget", 0,
+		"Number of manifestwork writes allowed per --reconcile-budget-window across all the HypershiftDeployments, "+
+			"the others are requeued with the ReconcileThrottled condition until the budget refills. Set to 0 to disable the budget.")
+	flag.DurationVar(&reconcileBudgetWindow, "reconcile-budget-window", time.Minute,
+		"The window over which the --reconcile-budget refills.")
+	flag.IntVar(&manifestWorkChunkSize, "manifestwork-chunk-size", 900*1024,
+		"Serialized size in bytes of a manifestwork payload past which the NodePools are written to additional manifestworks, "+
+			"so the manifestworks stay under the object size limit of etcd.")
+	flag.IntVar(&scaleDownGuardPercent, "scale-down-guard-percent", 50,
+		"Largest NodePool replica reduction, in percent of the applied replicas, applied without the confirm-scale-down "+
+			"annotation. A larger reduction is blocked with the LargeScaleDownBlocked condition. Set to 0 to disable the guard.")
+	flag.DurationVar(&reconcileTimeout, "reconcile-timeout", 0,
+		"Maximum duration of a single HypershiftDeployment reconcile, a slower reconcile is cancelled and requeued "+
+			"so it does not block the others. Set to 0 to disable the timeout.")
+	flag.DurationVar(&deprovisionStuckAfter, "deprovision-stuck-after", 30*time.Minute,
+		"How long the HostedCluster and NodePools of a deleted HypershiftDeployment can take to be cleaned up from the hosting cluster "+
+			"before the DeprovisionStuck condition is set. Set to 0 to disable the condition.")
+	flag.DurationVar(&feedbackStaleAfter, "feedback-stale-after", 0,
+		"How long the work agent can go without reporting on a pending manifestwork change before the FeedbackStale condition is set. "+
+			"Set to 0 to disable the check.")
+	flag.DurationVar(&feedbackPollInterval, "feedback-poll-interval", 0,
+		"How often the status feedback of a HostedCluster that is not available, or of NodePools that are not ready, is synced "+
+			"to the HypershiftDeployment in addition to the manifestwork events. Set to 0 to only sync on the manifestwork events.")
+	flag.Var(instanceTypeAliases, "instance-type-aliases",
+		"A comma separated list of alias=instanceType pairs, the NodePool instance types that match an alias are replaced "+
+			"with the instance type, ie small=t3.large,medium=m5.xlarge.")
+	flag.BoolVar(&instanceTypeAliases.Strict, "strict-instance-type-aliases", false,
+		"Reject the NodePool instance types that are neither an alias nor one of the instance types of --instance-type-aliases.")
+	flag.StringVar(&phaseWebhookURL, "phase-webhook-url", "",
+		"A URL that receives a POST with a JSON body each time the phase of a HypershiftDeployment changes. "+
+			"Failed callbacks are retried with a backoff. Leave empty to disable the callbacks.")
+	flag.BoolVar(&enableSummary, "enable-summary", false,
+		"Enable the HypershiftDeploymentSummary controller. "+
+			"Enabling this will maintain a summary with the count of HypershiftDeployments by phase in each namespace.")
+	flag.BoolVar(&enableWebhook, "enable-webhook", false,
+		"Serve the validating webhook rejecting the updates of the immutable fields of a HypershiftDeployment. "+
+			"The serving certificate is read from /tmp/k8s-webhook-server/serving-certs.")
+	flag.Func("feature-gates",
+		"A set of key=value pairs that enable the features still being rolled out, all features are off by default. "+
+			"Options are:\n"+strings.Join(featureGate.KnownFeatures(), "\n"), featureGate.Set)
 
 	flag.Parse()
 
@@ -109,12 +191,40 @@ func main() {
 	}
 
 	dynamicClient, _ := dynamic.NewForConfig(ctrl.GetConfigOrDie())
+	// Without the work API the controller still starts, the HypershiftDeployments report the missing API
+	workAPIAvailable, err := controllers.WorkAPIAvailable(mgr.GetRESTMapper())
+	if err != nil {
+		setupLog.Error(err, "unable to check the ManifestWork API")
+		os.Exit(1)
+	}
+	if !workAPIAvailable {
+		setupLog.Info("The ManifestWork API is not installed, the HypershiftDeployments will not be applied until it is installed and the controller restarted")
+	}
+
 	if err = (&controllers.HypershiftDeploymentReconciler{
-		Client:                  mgr.GetClient(),
-		DynamicClient:           dynamicClient,
-		Scheme:                  mgr.GetScheme(),
-		InfraHandler:            &controllers.DefaultInfraHandler{},
-		ValidateClusterSecurity: validateClusterSecurity,
+		Client:                      mgr.GetClient(),
+		DynamicClient:               dynamicClient,
+		Scheme:                      mgr.GetScheme(),
+		Recorder:                    mgr.GetEventRecorderFor("hypershift-deployment-controller"),
+		InfraHandler:                &controllers.DefaultInfraHandler{},
+		ValidateClusterSecurity:     validateClusterSecurity,
+		ValidatePermissions:         validatePermissions,
+		ValidateSpecSchema:          validateSpecSchema,
+		ManifestWorkGracePeriod:     manifestWorkGracePeriod,
+		ManifestWorkConflictRetries: manifestWorkConflictRetries,
+		ManifestWorkChunkSize:       manifestWorkChunkSize,
+		ScaleDownGuardPercent:       scaleDownGuardPercent,
+		CircuitBreakerThreshold:     circuitBreakerThreshold,
+		CircuitBreakerCooldown:      circuitBreakerCooldown,
+		ReconcileBudget:             reconcileBudget,
+		ReconcileBudgetWindow:       reconcileBudgetWindow,
+		ReconcileTimeout:            reconcileTimeout,
+		FeedbackStaleAfter:          feedbackStaleAfter,
+		FeedbackPollInterval:        feedbackPollInterval,
+		DeprovisionStuckAfter:       deprovisionStuckAfter,
+		InstanceTypeAliases:         instanceTypeAliases,
+		WorkAPIUnavailable:          !workAPIAvailable,
+		FeatureGate:                 featureGate,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "HypershiftDeployment")
 		os.Exit(1)
@@ -127,6 +237,36 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "AutoImport")
 		os.Exit(1)
 	}
+
+	if enableSummary {
+		if err = (&summary.Reconciler{
+			Client: mgr.GetClient(),
+			Scheme: mgr.GetScheme(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "HypershiftDeploymentSummary")
+			os.Exit(1)
+		}
+	}
+
+	if len(phaseWebhookURL) != 0 {
+		if err = (&phasenotifier.Reconciler{
+			Client: mgr.GetClient(),
+			Scheme: mgr.GetScheme(),
+			URL:    phaseWebhookURL,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "HypershiftDeploymentPhaseNotifier")
+			os.Exit(1)
+		}
+	}
+
+	if enableWebhook {
+		if err = (&webhook.Validator{
+			Client: mgr.GetClient(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "HypershiftDeployment")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {